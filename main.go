@@ -1,8 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/bitrise-io/go-steputils/v2/ruby"
 	"github.com/bitrise-io/go-steputils/v2/stepconf"
@@ -22,6 +25,7 @@ func main() {
 }
 
 func run() int {
+	startedOn := time.Now()
 	logger := log.NewLogger()
 	configParser := createConfigParser(logger)
 	config, err := configParser.ProcessInputs()
@@ -30,32 +34,121 @@ func run() int {
 		return 1
 	}
 
-	archiver, err := createXcodebuildArchiver(logger, config.LogFormatter)
+	if config.StructuredLog {
+		logger = step.NewEventLogger(logger, os.Stdout)
+	}
+
+	if len(config.ArchiveComparePaths) > 0 {
+		if err := step.CompareArchives(config.ArchiveComparePaths[0], config.ArchiveComparePaths[1], logger); err != nil {
+			logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to compare archives: %w", err)))
+			return 1
+		}
+		return 0
+	}
+
+	tracer, err := step.NewTracerFromEnv(env.NewRepository())
+	if err != nil {
+		logger.Warnf("Failed to configure trace export: %s", err)
+	}
+
+	archiver, err := createXcodebuildArchiver(logger, config.LogFormatter, config.LogFormatterCommandArgs, tracer)
 	if err != nil {
 		logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to process Step inputs: %w", err)))
 		return 1
 	}
 
+	if config.DSYMOnlyArchivePath != "" {
+		if err := archiver.ExportArchiveDSYMs(createDSYMOnlyOptions(config)); err != nil {
+			logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to export dSYMs: %w", err)))
+			return 1
+		}
+		return 0
+	}
+
 	archiver.EnsureDependencies()
+	defer archiver.CleanupBuildIsolation(config.Isolation)
 
-	exitCode := 0
-	runOpts := createRunOptions(config)
-	result, err := archiver.Run(runOpts)
-	if err != nil {
-		logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to execute Step main logic: %w", err)))
-		exitCode = 1
-		// don't return as step outputs needs to be exported even in case of failure (for example the xcodebuild logs)
+	for _, pth := range config.ExportOptionsMutatorList {
+		archiver.RegisterExportOptionsMutator(step.NewExternalExportOptionsMutator(pth, command.NewFactory(env.NewRepository())))
 	}
 
-	exportOpts := createExportOptions(config, result)
-	if err := archiver.ExportOutput(exportOpts); err != nil {
-		logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to export Step outputs: %w", err)))
+	if err := archiver.SyncTestDevices(config.TestDeviceSyncer); err != nil {
+		logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to register test devices: %w", err)))
 		return 1
 	}
 
+	exitCode := 0
+	var runErr, exportErr error
+	schemes := config.SchemeMatrix
+	var exportedFiles *step.ExportedFiles
+	if len(schemes) > 1 {
+		exportedFiles = &step.ExportedFiles{}
+	}
+	for i, scheme := range schemes {
+		if len(schemes) > 1 {
+			logger.Println()
+			logger.Infof("Archiving scheme (%d/%d): %s, configuration: %s, distribution method: %s", i+1, len(schemes), scheme.Scheme, scheme.Configuration, scheme.ExportMethod)
+		}
+
+		runOpts := createRunOptions(config)
+		runOpts.Scheme = scheme.Scheme
+		runOpts.Configuration = scheme.Configuration
+		runOpts.ExportMethod = scheme.ExportMethod
+		runOpts.ArtifactNameSuffix = scheme.ArtifactNameSuffix
+		runOpts.CodesignManager = scheme.CodesignManager
+		runOpts.SigningRepairer = scheme.SigningRepairer
+		runOpts.AdditionalCodesignManagers = scheme.AdditionalCodesignManagers
+		result, err := archiver.Run(runOpts)
+		if err != nil {
+			logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to execute Step main logic: %w", err)))
+			exitCode = 1
+			runErr = err
+			if config.DumpProfilesOnFailure {
+				archiver.ExportProfileDump(err, result, config.OutputDir, config.OutputOverwritePolicy)
+			}
+			// don't return as step outputs needs to be exported even in case of failure (for example the xcodebuild logs)
+		}
+
+		exportOpts := createExportOptions(config, result)
+		exportOpts.Scheme = scheme.Scheme
+		exportOpts.ExportedFiles = exportedFiles
+		if config.Provenance {
+			exportOpts.Provenance = step.NewProvenanceGeneratorFromEnv(env.NewRepository(), provenanceParameters(config, scheme), startedOn)
+		}
+		if err := archiver.ExportOutput(exportOpts); err != nil {
+			logger.Errorf("%s", errorutil.FormattedError(fmt.Errorf("Failed to export Step outputs: %w", err)))
+			exitCode = 1
+			exportErr = err
+		}
+
+		if exitCode != 0 {
+			// the remaining schemes are not archived after a failure
+			break
+		}
+	}
+
+	if tracer != nil {
+		if err := tracer.Export(errors.Join(runErr, exportErr)); err != nil {
+			logger.Warnf("Failed to export trace: %s", err)
+		}
+	}
+
 	return exitCode
 }
 
+func createDSYMOnlyOptions(config step.Config) step.DSYMOnlyOpts {
+	return step.DSYMOnlyOpts{
+		ArchivePath:           config.DSYMOnlyArchivePath,
+		OutputDir:             config.OutputDir,
+		OutputOverwritePolicy: config.OutputOverwritePolicy,
+		ExportAllDsyms:        config.ExportAllDsyms,
+		DSYMArchiveFormat:     config.DSYMArchiveFormat,
+		CompressionLevel:      config.CompressionLevel,
+		DeterministicArchives: config.DeterministicArchives,
+		DSYMUploadCommandArgs: config.DSYMUploadCommandArgs,
+	}
+}
+
 func createConfigParser(logger log.Logger) step.XcodebuildArchiveConfigParser {
 	envRepository := env.NewRepository()
 	inputParser := stepconf.NewInputParser(envRepository)
@@ -66,7 +159,7 @@ func createConfigParser(logger log.Logger) step.XcodebuildArchiveConfigParser {
 	return step.NewXcodeArchiveConfigParser(inputParser, xcodeVersionReader, fileManager, cmdFactory, logger)
 }
 
-func createXcodebuildArchiver(logger log.Logger, logFormatter string) (step.XcodebuildArchiver, error) {
+func createXcodebuildArchiver(logger log.Logger, logFormatter string, logFormatterCommandArgs []string, tracer *step.Tracer) (step.XcodebuildArchiver, error) {
 	envRepository := env.NewRepository()
 	pathProvider := pathutil.NewPathProvider()
 	pathChecker := pathutil.NewPathChecker()
@@ -75,12 +168,15 @@ func createXcodebuildArchiver(logger log.Logger, logFormatter string) (step.Xcod
 	cmdFactory := command.NewFactory(envRepository)
 	xcodeVersionReader := xcodeversion.NewXcodeVersionProvider(cmdFactory)
 
+	// the runners combine the xcodebuild stdout and stderr, the stderr is captured separately for the error paths
+	stderrCapture := step.NewXcodebuildStderrCapture(cmdFactory)
+
 	xcodeCommandRunner := xcodecommand.Runner(nil)
 	switch logFormatter {
 	case step.XcodebuildTool:
-		xcodeCommandRunner = xcodecommand.NewRawCommandRunner(logger, cmdFactory)
+		xcodeCommandRunner = xcodecommand.NewRawCommandRunner(logger, stderrCapture)
 	case step.XcbeautifyTool:
-		xcodeCommandRunner = xcodecommand.NewXcbeautifyRunner(logger, cmdFactory)
+		xcodeCommandRunner = xcodecommand.NewXcbeautifyRunner(logger, stderrCapture)
 	case step.XcprettyTool:
 		commandLocator := env.NewCommandLocator()
 		rubyComamndFactory, err := ruby.NewCommandFactory(cmdFactory, commandLocator)
@@ -89,12 +185,17 @@ func createXcodebuildArchiver(logger log.Logger, logFormatter string) (step.Xcod
 		}
 		rubyEnv := ruby.NewEnvironment(rubyComamndFactory, commandLocator, logger)
 
-		xcodeCommandRunner = xcodecommand.NewXcprettyCommandRunner(logger, cmdFactory, pathChecker, fileManager, rubyComamndFactory, rubyEnv)
+		xcodeCommandRunner = xcodecommand.NewXcprettyCommandRunner(logger, stderrCapture, pathChecker, fileManager, rubyComamndFactory, rubyEnv)
+	case step.CustomFormatterTool:
+		xcodeCommandRunner = step.NewCustomFormatterRunner(logFormatterCommandArgs, logger, stderrCapture)
 	default:
 		panic(fmt.Sprintf("Unknown log formatter: %s", logFormatter))
 	}
 
-	return step.NewXcodebuildArchiver(xcodeCommandRunner, logFormatter, xcodeVersionReader, pathProvider, pathChecker, pathModifier, fileManager, cmdFactory, logger), nil
+	archiver := step.NewXcodebuildArchiver(xcodeCommandRunner, logFormatter, xcodeVersionReader, pathProvider, pathChecker, pathModifier, fileManager, cmdFactory, logger, tracer)
+	archiver.SetXcodebuildStderrCapture(stderrCapture)
+
+	return archiver, nil
 }
 
 func createRunOptions(config step.Config) step.RunOpts {
@@ -102,16 +203,44 @@ func createRunOptions(config step.Config) step.RunOpts {
 		ProjectPath:         config.ProjectPath,
 		Scheme:              config.Scheme,
 		DestinationPlatform: config.DestinationPlatform,
+		Destination:         config.Destination,
 		Configuration:       config.Configuration,
 		XcodeMajorVersion:   config.XcodeMajorVersion,
 		ArtifactName:        config.ArtifactName,
+		BuildMode:           config.BuildMode,
+		DryRun:              config.DryRun,
+		SensitiveValues:     config.SensitiveValues(),
 
-		CodesignManager: config.CodesignManager,
+		CodesignManager:  config.CodesignManager,
+		SigningRepairer:  config.SigningRepairer,
+		KeychainPath:     config.KeychainPath,
+		KeychainPassword: string(config.KeychainPassword),
+		IsolatedKeychain: config.Isolation != nil,
 
+		ArchivedApplication:         config.ArchivedApplication,
 		PerformCleanAction:          config.PerformCleanAction,
 		XcconfigContent:             config.XcconfigContent,
 		XcodebuildAdditionalOptions: config.XcodebuildAdditionalOptions,
+		BuildSettingOverrides:       config.BuildSettingOverrides,
+		DerivedDataPath:             config.DerivedDataPath,
+		ArchivePath:                 config.ArchivePath,
 		CacheLevel:                  config.CacheLevel,
+		ExportBuildLogs:             config.ExportBuildLogs,
+		XcprettyReports:             config.XcprettyReportList,
+		BuildNumberMode:             config.BuildNumberMode,
+		BuildNumber:                 config.BuildNumber,
+		BuildNumberTool:             config.BuildNumberTool,
+		EncryptionUsage:             config.EncryptionUsage,
+		ResolvePackageDependencies:  config.ResolvePackageDependencies,
+		PackageResolutionRetries:    config.PackageResolutionRetries,
+		PackageMirrors:              config.PackageMirrorList,
+		PackageRegistryURL:          config.PackageRegistryURL,
+		PackageSCMProvider:          config.PackageSCMProvider,
+		PackageAuthProvider:         config.PackageAuthorizationProvider,
+		PackageHost:                 config.PackageHost,
+		PackageHostToken:            string(config.PackageHostToken),
+		SkipPackagePluginValidation: config.SkipPackagePluginValidation,
+		SkipMacroValidation:         config.SkipMacroValidation,
 
 		CustomExportOptionsPlistContent: config.ExportOptionsPlistContent,
 		ExportMethod:                    config.ExportMethod,
@@ -119,23 +248,97 @@ func createRunOptions(config step.Config) step.RunOpts {
 		ICloudContainerEnvironment:      config.ICloudContainerEnvironment,
 		ExportDevelopmentTeam:           config.ExportDevelopmentTeam,
 		UploadBitcode:                   config.UploadBitcode,
+		UploadSymbols:                   config.UploadSymbols,
 		CompileBitcode:                  config.CompileBitcode,
+		SimulatorSliceAction:            config.SimulatorSliceAction,
+		MixedTeamCheck:                  config.MixedTeamCheck,
+		CheckBinaryHygiene:              config.CheckBinaryHygiene,
+		StripFrameworkProfiles:          config.StripFrameworkProfiles,
+		SanitizeFrameworks:              config.SanitizeFrameworks,
+		StripBitcode:                    config.StripBitcode,
+		ManualIPAFallback:               config.ManualIPAFallback,
+		AdditionalExportMethods:         config.AdditionalExportMethodList,
+		ExportConcurrency:               config.ExportConcurrency,
+		OnDemandResources: step.OnDemandResourcesOpts{
+			EmbedAssetPacksInBundle: config.EmbedODRAssetPacksInBundle,
+			AssetPacksBaseURL:       config.ODRAssetPacksBaseURL,
+		},
 	}
 }
 
 func createExportOptions(config step.Config, result step.RunResult) step.ExportOpts {
 	return step.ExportOpts{
-		OutputDir:      config.OutputDir,
-		ArtifactName:   result.ArtifactName,
-		ExportAllDsyms: config.ExportAllDsyms,
+		OutputDir:             config.OutputDir,
+		OutputOverwritePolicy: config.OutputOverwritePolicy,
+		ProjectPath:           config.ProjectPath,
+		Scheme:                config.Scheme,
+		Configuration:         result.Configuration,
+		GitCommit:             config.GitCommit,
+		ArtifactName:          result.ArtifactName,
+		ArtifactNameTemplate:  config.ArtifactNameTemplate,
+		IPANameTemplate:       config.IPANameTemplate,
+		SimulatorAppPath:      result.SimulatorAppPath,
+		ExportSignedApp:       config.ExportSignedApp,
+		ExportAllDsyms:        config.ExportAllDsyms,
+		SBOMFormat:            config.SBOMFormat,
+		DerivedDataPath:       config.DerivedDataPath,
+		BuildSummary:          config.BuildSummary,
+		HTMLReportDir:         config.HTMLReportDir,
+
+		MinProfileValidityDays:   config.MinDaysProfileValid,
+		ExportSigningAssetBundle: config.ExportSigningAssetBundle,
+
+		ExportXCArchiveZip:    config.ExportXCArchiveZip,
+		CopyToOrganizer:       config.CopyToOrganizer,
+		DSYMUploadCommandArgs: config.DSYMUploadCommandArgs,
+		XCArchiveZipExcludes:  config.XCArchiveZipExcludeList,
+		CompressionLevel:      config.CompressionLevel,
+		DSYMArchiveFormat:     config.DSYMArchiveFormat,
+		DeterministicArchives: config.DeterministicArchives,
+		Checksums:             config.Checksums,
+
+		ArtifactSigningMethod:        config.ArtifactSigningMethod,
+		ArtifactSigningKey:           string(config.ArtifactSigningKey),
+		ArtifactSigningKeyPassphrase: string(config.ArtifactSigningKeyPassphrase),
+
+		SizeReportTopFilesCount: config.SizeReportTopFilesCount,
+		MaxIPASizeMB:            config.MaxIPASizeMB,
+		MaxAppSizeMB:            config.MaxAppSizeMB,
+
+		MaxWarnings:             config.MaxWarnings,
+		FailOnNewDeprecations:   config.FailOnNewDeprecations,
+		DeprecationBaselinePath: config.DeprecationBaselinePath,
+		WarningBudgetAction:     config.WarningBudgetAction,
 
-		Archive: result.Archive,
+		Archive:             result.Archive,
+		CompareEntitlements: config.CompareEntitlements,
 
-		ExportOptionsPath: result.ExportOptionsPath,
-		IPAExportDir:      result.IPAExportDir,
+		ResultBundlePath: result.ResultBundlePath,
+		BuildIssues:      result.BuildIssues,
+
+		ExportOptionsPath:    result.ExportOptionsPath,
+		CompareExportOptions: config.CompareExportOptions,
+		IPAExportDir:         result.IPAExportDir,
+		AdditionalIPAExports: result.AdditionalIPAExports,
 
 		XcodebuildArchiveLog:       result.XcodebuildArchiveLog,
 		XcodebuildExportArchiveLog: result.XcodebuildExportArchiveLog,
 		IDEDistrubutionLogsDir:     result.IDEDistrubutionLogsDir,
+		BuildLogsDir:               result.BuildLogsDir,
+		XcprettyReports:            result.XcprettyReports,
+	}
+}
+
+// provenanceParameters returns the Step inputs recorded as the external parameters of the build in the provenance statement.
+func provenanceParameters(config step.Config, scheme step.SchemeMatrixEntry) map[string]string {
+	parameters := map[string]string{
+		"project_path":        config.ProjectPath,
+		"scheme":              scheme.Scheme,
+		"configuration":       scheme.Configuration,
+		"distribution_method": scheme.ExportMethod,
+	}
+	if len(config.XcodebuildAdditionalOptions) > 0 {
+		parameters["xcodebuild_options"] = strings.Join(config.RedactedXcodebuildAdditionalOptions(), " ")
 	}
+	return parameters
 }
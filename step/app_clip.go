@@ -0,0 +1,70 @@
+package step
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// parentApplicationIdentifiersEntitlementKey lists the application identifiers (<App ID prefix>.<bundle ID>) of the App Clip's parent app.
+	parentApplicationIdentifiersEntitlementKey = "com.apple.developer.parent-application-identifiers"
+	// applicationIdentifierEntitlementKey is the application identifier of the app, its App ID prefix is the team ID,
+	// or a legacy App ID prefix different from the team ID.
+	applicationIdentifierEntitlementKey = "application-identifier"
+)
+
+// appClipIssues returns the issues of the App Clip's relation to the parent app, nil if the archive has no App Clip.
+func appClipIssues(archive Archive) []string {
+	clip := archive.Application.ClipApplication
+	if clip == nil {
+		return nil
+	}
+
+	parentBundleID := archive.Application.BundleIdentifier()
+	clipBundleID := clip.BundleIdentifier()
+
+	var issues []string
+	if !strings.HasPrefix(clipBundleID, parentBundleID+".") {
+		issues = append(issues, fmt.Sprintf("the App Clip's bundle ID (%s) is not prefixed by the parent app's bundle ID (%s.)", clipBundleID, parentBundleID))
+	}
+
+	// without the parent's application identifier entitlement the App ID prefix is unknown, any prefix is accepted
+	parentAppID, _ := archive.Application.Entitlements.GetString(applicationIdentifierEntitlementKey)
+	matchesParentAppID := func(identifier string) bool {
+		return identifier == parentAppID || (parentAppID == "" && strings.HasSuffix(identifier, "."+parentBundleID))
+	}
+	expectedParentAppID := parentAppID
+	if expectedParentAppID == "" {
+		expectedParentAppID = "<App ID prefix>." + parentBundleID
+	}
+
+	identifiers, ok := clip.Entitlements.GetStringArray(parentApplicationIdentifiersEntitlementKey)
+	if !ok || len(identifiers) == 0 {
+		return append(issues, fmt.Sprintf("the App Clip's %s entitlement is missing, it should contain %s", parentApplicationIdentifiersEntitlementKey, expectedParentAppID))
+	}
+	for _, identifier := range identifiers {
+		if matchesParentAppID(identifier) {
+			return issues
+		}
+	}
+	return append(issues, fmt.Sprintf("the App Clip's %s entitlement (%s) does not contain the parent app's identifier (%s)", parentApplicationIdentifiersEntitlementKey, strings.Join(identifiers, ", "), expectedParentAppID))
+}
+
+// checkAppClip fails if the App Clip's bundle ID or entitlements don't match the parent app, the export would be rejected anyway.
+func (s XcodebuildArchiver) checkAppClip(archive Archive) error {
+	issues := appClipIssues(archive)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid App Clip (%s):\n%s", archive.Application.ClipApplication.BundleIdentifier(), issueList(issues))
+}
+
+// issueList formats the issues as a list, one issue per line.
+func issueList(issues []string) string {
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, "- "+issue)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,90 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func testAppClipArchive(clipBundleID string, clipEntitlements plistutil.PlistData) Archive {
+	return testAppClipArchiveWithParentAppID("ABCD1234.io.bitrise.sample", clipBundleID, clipEntitlements)
+}
+
+func testAppClipArchiveWithParentAppID(parentAppID, clipBundleID string, clipEntitlements plistutil.PlistData) Archive {
+	parentEntitlements := plistutil.PlistData{}
+	if parentAppID != "" {
+		parentEntitlements[applicationIdentifierEntitlementKey] = parentAppID
+	}
+	archive := xcarchive.IosArchive{
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: xcarchive.IosBaseApplication{
+				InfoPlist:           plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.sample"},
+				Entitlements:        parentEntitlements,
+				ProvisioningProfile: profileutil.ProvisioningProfileInfoModel{TeamID: "ABCD1234"},
+			},
+			ClipApplication: &xcarchive.IosClipApplication{
+				IosBaseApplication: xcarchive.IosBaseApplication{
+					InfoPlist:    plistutil.PlistData{"CFBundleIdentifier": clipBundleID},
+					Entitlements: clipEntitlements,
+				},
+			},
+		},
+	}
+	return NewArchive(archive)
+}
+
+func Test_appClipIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive Archive
+		want    []string
+	}{
+		{
+			name:    "no App Clip",
+			archive: NewArchive(xcarchive.IosArchive{}),
+		},
+		{
+			name: "valid App Clip",
+			archive: testAppClipArchive("io.bitrise.sample.Clip", plistutil.PlistData{
+				parentApplicationIdentifiersEntitlementKey: []interface{}{"ABCD1234.io.bitrise.sample"},
+			}),
+		},
+		{
+			name: "legacy App ID prefix",
+			archive: testAppClipArchiveWithParentAppID("LEGACY99.io.bitrise.sample", "io.bitrise.sample.Clip", plistutil.PlistData{
+				parentApplicationIdentifiersEntitlementKey: []interface{}{"LEGACY99.io.bitrise.sample"},
+			}),
+		},
+		{
+			name: "parent without application identifier",
+			archive: testAppClipArchiveWithParentAppID("", "io.bitrise.sample.Clip", plistutil.PlistData{
+				parentApplicationIdentifiersEntitlementKey: []interface{}{"LEGACY99.io.bitrise.sample"},
+			}),
+		},
+		{
+			name: "bundle ID not prefixed, entitlement of another team",
+			archive: testAppClipArchive("io.bitrise.clip", plistutil.PlistData{
+				parentApplicationIdentifiersEntitlementKey: []interface{}{"EFGH5678.io.bitrise.sample"},
+			}),
+			want: []string{
+				"the App Clip's bundle ID (io.bitrise.clip) is not prefixed by the parent app's bundle ID (io.bitrise.sample.)",
+				"the App Clip's com.apple.developer.parent-application-identifiers entitlement (EFGH5678.io.bitrise.sample) does not contain the parent app's identifier (ABCD1234.io.bitrise.sample)",
+			},
+		},
+		{
+			name:    "missing entitlement",
+			archive: testAppClipArchive("io.bitrise.sample.Clip", plistutil.PlistData{}),
+			want: []string{
+				"the App Clip's com.apple.developer.parent-application-identifiers entitlement is missing, it should contain ABCD1234.io.bitrise.sample",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, appClipIssues(tt.archive))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package step
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+// FindLargestAppIcon returns the path of the largest app icon PNG inside the application bundle.
+// Only the icon PNGs copied next to the compiled asset catalog are considered,
+// the icons stored only in the asset catalog (Assets.car, for example the App Store icon) can't be extracted.
+// Returns an empty path if no icon is found.
+func FindLargestAppIcon(appPath string, infoPlist plistutil.PlistData, logger log.Logger) (string, error) {
+	iconNames := appIconNames(infoPlist)
+
+	var candidates []string
+	for _, name := range iconNames {
+		pattern := filepath.Join(escapeGlobPath(appPath), escapeGlobPath(name)+"*.png")
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", fmt.Errorf("failed to search for app icons using pattern: %s, error: %s", pattern, err)
+		}
+		candidates = append(candidates, matches...)
+	}
+
+	largestIconPath := ""
+	largestIconWidth := 0
+	for _, candidate := range candidates {
+		width, _, err := pngDimensions(candidate)
+		if err != nil {
+			logger.Debugf("Failed to read PNG dimensions of %s: %s", candidate, err)
+			continue
+		}
+		if width > largestIconWidth {
+			largestIconPath = candidate
+			largestIconWidth = width
+		}
+	}
+
+	return largestIconPath, nil
+}
+
+// appIconNames returns the icon file name prefixes declared in the Info.plist (iPhone and iPad variants).
+func appIconNames(infoPlist plistutil.PlistData) []string {
+	var names []string
+	for _, iconsKey := range []string{"CFBundleIcons", "CFBundleIcons~ipad"} {
+		icons, ok := infoPlist.GetMapStringInterface(iconsKey)
+		if !ok {
+			continue
+		}
+		primaryIcon, ok := icons.GetMapStringInterface("CFBundlePrimaryIcon")
+		if !ok {
+			continue
+		}
+
+		if name, ok := primaryIcon.GetString("CFBundleIconName"); ok && !sliceutil.IsStringInSlice(name, names) {
+			names = append(names, name)
+		}
+		if files, ok := primaryIcon.GetStringArray("CFBundleIconFiles"); ok {
+			for _, file := range files {
+				if !sliceutil.IsStringInSlice(file, names) {
+					names = append(names, file)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// pngDimensions reads the image size from the IHDR chunk.
+// Unlike image/png it also handles the Apple optimized (CgBI) PNGs found in application bundles.
+func pngDimensions(pth string) (int, int, error) {
+	f, err := os.Open(pth)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(f, signature); err != nil {
+		return 0, 0, err
+	}
+	if !bytes.Equal(signature, []byte("\x89PNG\r\n\x1a\n")) {
+		return 0, 0, fmt.Errorf("not a PNG file")
+	}
+
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(f, header); err != nil {
+			return 0, 0, fmt.Errorf("IHDR chunk not found: %s", err)
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:])
+
+		if chunkType == "IHDR" {
+			data := make([]byte, 8)
+			if _, err := io.ReadFull(f, data); err != nil {
+				return 0, 0, err
+			}
+			return int(binary.BigEndian.Uint32(data[:4])), int(binary.BigEndian.Uint32(data[4:])), nil
+		}
+
+		// skip the chunk data and its CRC
+		if _, err := f.Seek(int64(length)+4, io.SeekCurrent); err != nil {
+			return 0, 0, err
+		}
+	}
+}
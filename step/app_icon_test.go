@@ -0,0 +1,56 @@
+package step
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindLargestAppIcon(t *testing.T) {
+	appPath := filepath.Join(t.TempDir(), "My App.app")
+	require.NoError(t, os.MkdirAll(appPath, 0755))
+	writeTestPNG(t, filepath.Join(appPath, "AppIcon60x60@2x.png"), 120, false)
+	writeTestPNG(t, filepath.Join(appPath, "AppIcon60x60@3x.png"), 180, true)
+	writeTestPNG(t, filepath.Join(appPath, "LaunchImage.png"), 1024, false)
+
+	infoPlist := plistutil.PlistData{
+		"CFBundleIcons": map[string]interface{}{
+			"CFBundlePrimaryIcon": map[string]interface{}{
+				"CFBundleIconFiles": []interface{}{"AppIcon60x60"},
+				"CFBundleIconName":  "AppIcon",
+			},
+		},
+	}
+
+	got, err := FindLargestAppIcon(appPath, infoPlist, log.NewLogger())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(appPath, "AppIcon60x60@3x.png"), got)
+}
+
+func writeTestPNG(t *testing.T, pth string, size uint32, appleOptimized bool) {
+	content := []byte("\x89PNG\r\n\x1a\n")
+	if appleOptimized {
+		content = append(content, chunk("CgBI", []byte{0x50, 0x00, 0x20, 0x06})...)
+	}
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[:4], size)
+	binary.BigEndian.PutUint32(ihdr[4:8], size)
+	content = append(content, chunk("IHDR", ihdr)...)
+
+	require.NoError(t, os.WriteFile(pth, content, 0644))
+}
+
+func chunk(chunkType string, data []byte) []byte {
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(data)))
+
+	c := append(length, []byte(chunkType)...)
+	c = append(c, data...)
+	return append(c, 0, 0, 0, 0)
+}
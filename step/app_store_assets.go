@@ -0,0 +1,164 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+const (
+	launchStoryboardNameKey = "UILaunchStoryboardName"
+	launchScreenKey         = "UILaunchScreen"
+	launchScreensKey        = "UILaunchScreens"
+	sceneManifestKey        = "UIApplicationSceneManifest"
+	compiledStoryboardExt   = ".storyboardc"
+
+	// marketingIconPixelWidth is the size of the App Store icon, which should be in the app's asset catalog
+	marketingIconPixelWidth = 1024
+
+	assetCatalogIconImageType = "Icon Image"
+)
+
+// assetCatalogEntry is an item of the `assetutil --info` JSON output.
+type assetCatalogEntry struct {
+	AssetType  string `json:"AssetType"`
+	Name       string `json:"Name"`
+	PixelWidth int    `json:"PixelWidth"`
+}
+
+func readAssetCatalogInfo(cmdFactory command.Factory, assetCatalogPath string) ([]assetCatalogEntry, error) {
+	cmd := cmdFactory.Create("xcrun", []string{"assetutil", "--info", assetCatalogPath}, nil)
+	out, err := cmd.RunAndReturnTrimmedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %s", cmd.PrintableCommandArgs(), err)
+	}
+
+	var entries []assetCatalogEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse assetutil output: %s", err)
+	}
+	return entries, nil
+}
+
+// launchScreenIssues returns the issues of the launch screen configuration of the Info.plist,
+// storyboardExists reports if the compiled storyboard is in the app bundle.
+func launchScreenIssues(infoPlist plistutil.PlistData, storyboardExists func(name string) bool) []string {
+	var issues []string
+
+	storyboardName, hasStoryboard := infoPlist.GetString(launchStoryboardNameKey)
+	_, hasLaunchScreen := infoPlist.GetMapStringInterface(launchScreenKey)
+	_, hasLaunchScreens := infoPlist.GetMapStringInterface(launchScreensKey)
+	if !hasStoryboard && !hasLaunchScreen && !hasLaunchScreens {
+		issues = append(issues, fmt.Sprintf("the Info.plist has no launch screen (%s, %s or %s)", launchStoryboardNameKey, launchScreenKey, launchScreensKey))
+	} else if hasStoryboard && !storyboardExists(storyboardName) {
+		issues = append(issues, fmt.Sprintf("the launch storyboard (%s) is not found in the app bundle", storyboardName))
+	}
+
+	manifest, ok := infoPlist.GetMapStringInterface(sceneManifestKey)
+	if !ok {
+		return issues
+	}
+	configurations, _ := manifest.GetMapStringInterface("UISceneConfigurations")
+	for role, roleConfigurations := range configurations {
+		entries, ok := roleConfigurations.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, entry := range entries {
+			configuration, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := plistutil.PlistData(configuration).GetString("UISceneStoryboardFile"); ok && !storyboardExists(name) {
+				issues = append(issues, fmt.Sprintf("the storyboard (%s) of the %s scene configuration is not found in the app bundle", name, role))
+			}
+		}
+	}
+	return issues
+}
+
+// marketingIconIssues returns the issue of the App Store icon, which should be in the asset catalog of the app.
+func marketingIconIssues(entries []assetCatalogEntry, iconNames []string) []string {
+	for _, entry := range entries {
+		if entry.AssetType != assetCatalogIconImageType {
+			continue
+		}
+		if len(iconNames) > 0 && !sliceutil.IsStringInSlice(entry.Name, iconNames) {
+			continue
+		}
+		if entry.PixelWidth >= marketingIconPixelWidth {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("the %dx%d App Store icon is not found in the app's asset catalog", marketingIconPixelWidth, marketingIconPixelWidth)}
+}
+
+// appStoreAssetIssues returns the launch screen and App Store icon issues of the archived iOS app.
+// Apps built around a Messages application stub (for example iMessage sticker packs) are skipped,
+// the stub has no launch screen and its icon is in the asset catalog of the Messages extension.
+func (s XcodebuildArchiver) appStoreAssetIssues(archive Archive) []string {
+	if hasStub, err := archive.HasMessagesApplicationStub(); err != nil {
+		s.logger.Warnf("Failed to check Messages application stub: %s", err)
+	} else if hasStub {
+		s.logger.Debugf("The app is built around a Messages application stub, skipping the launch screen and App Store icon checks")
+		return nil
+	}
+
+	app := archive.Application
+	issues := launchScreenIssues(app.InfoPlist, func(name string) bool {
+		exists, err := v1pathutil.IsPathExists(filepath.Join(app.Path, name+compiledStoryboardExt))
+		return err == nil && exists
+	})
+
+	assetCatalogPath := filepath.Join(app.Path, "Assets.car")
+	if exists, err := v1pathutil.IsPathExists(assetCatalogPath); err != nil || !exists {
+		return append(issues, fmt.Sprintf("the app has no asset catalog, the %dx%d App Store icon is missing", marketingIconPixelWidth, marketingIconPixelWidth))
+	}
+	entries, err := readAssetCatalogInfo(s.cmdFactory, assetCatalogPath)
+	if err != nil {
+		s.logger.Warnf("Failed to read asset catalog info, skipping the App Store icon check: %s", err)
+		return issues
+	}
+	return append(issues, marketingIconIssues(entries, appIconNames(app.InfoPlist))...)
+}
+
+// exportsForAppStore returns true if any of the distribution methods is app-store,
+// auto-detect resolves to the distribution type of the archived app's provisioning profile.
+func exportsForAppStore(archive Archive, exportMethods ...string) bool {
+	for _, method := range exportMethods {
+		if method == "auto-detect" {
+			method = string(archive.Application.ProvisioningProfile.ExportType)
+		}
+		if exportoptions.Method(method).IsAppStore() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAppStoreAssets validates the launch screen and the App Store icon of iOS apps before the export,
+// it fails for app-store exports, as App Store Connect rejects the upload, and warns otherwise.
+func (s XcodebuildArchiver) checkAppStoreAssets(archive Archive, exportMethods ...string) error {
+	if archive.Platform() != "iphoneos" {
+		return nil
+	}
+
+	issues := s.appStoreAssetIssues(archive)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if exportsForAppStore(archive, exportMethods...) {
+		return fmt.Errorf("the app will be rejected by App Store Connect:\n%s", issueList(issues))
+	}
+
+	s.logger.Println()
+	s.logger.Warnf("The app would be rejected by App Store Connect:\n%s", issueList(issues))
+	return nil
+}
@@ -0,0 +1,79 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_launchScreenIssues(t *testing.T) {
+	storyboardExists := func(name string) bool { return name == "LaunchScreen" || name == "Main" }
+
+	require.Empty(t, launchScreenIssues(plistutil.PlistData{launchStoryboardNameKey: "LaunchScreen"}, storyboardExists))
+	require.Empty(t, launchScreenIssues(plistutil.PlistData{launchScreenKey: map[string]interface{}{}}, storyboardExists))
+
+	require.Equal(t, []string{
+		"the Info.plist has no launch screen (UILaunchStoryboardName, UILaunchScreen or UILaunchScreens)",
+	}, launchScreenIssues(plistutil.PlistData{}, storyboardExists))
+
+	require.Equal(t, []string{
+		"the launch storyboard (Launch) is not found in the app bundle",
+		"the storyboard (Scene) of the UIWindowSceneSessionRoleApplication scene configuration is not found in the app bundle",
+	}, launchScreenIssues(plistutil.PlistData{
+		launchStoryboardNameKey: "Launch",
+		sceneManifestKey: map[string]interface{}{
+			"UISceneConfigurations": map[string]interface{}{
+				"UIWindowSceneSessionRoleApplication": []interface{}{
+					map[string]interface{}{"UISceneStoryboardFile": "Main"},
+					map[string]interface{}{"UISceneStoryboardFile": "Scene"},
+				},
+			},
+		},
+	}, storyboardExists))
+}
+
+func Test_marketingIconIssues(t *testing.T) {
+	entries := []assetCatalogEntry{
+		{AssetType: assetCatalogIconImageType, Name: "AppIcon", PixelWidth: 180},
+		{AssetType: assetCatalogIconImageType, Name: "AlternateIcon", PixelWidth: 1024},
+		{AssetType: "Image", Name: "Background", PixelWidth: 2048},
+	}
+
+	require.Empty(t, marketingIconIssues(entries, nil))
+	require.Equal(t, []string{"the 1024x1024 App Store icon is not found in the app's asset catalog"}, marketingIconIssues(entries, []string{"AppIcon"}))
+}
+
+func Test_exportsForAppStore(t *testing.T) {
+	archive := NewArchive(xcarchive.IosArchive{
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: xcarchive.IosBaseApplication{
+				ProvisioningProfile: profileutil.ProvisioningProfileInfoModel{ExportType: exportoptions.MethodAppStore},
+			},
+		},
+	})
+
+	require.True(t, exportsForAppStore(archive, "development", "app-store"))
+	require.True(t, exportsForAppStore(archive, "auto-detect"))
+	require.False(t, exportsForAppStore(archive, "development", "ad-hoc"))
+}
+
+func TestXcodebuildArchiver_appStoreAssetIssues_messagesApplicationStub(t *testing.T) {
+	appPath := filepath.Join(t.TempDir(), "Stickers.app")
+	require.NoError(t, os.MkdirAll(appPath, 0755))
+	archive := NewArchive(xcarchive.IosArchive{Application: xcarchive.IosApplication{
+		IosBaseApplication: xcarchive.IosBaseApplication{Path: appPath, InfoPlist: plistutil.PlistData{}},
+	}})
+	archiver := XcodebuildArchiver{logger: log.NewLogger()}
+
+	require.Len(t, archiver.appStoreAssetIssues(archive), 2)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(appPath, messagesApplicationStubDir), 0755))
+	require.Empty(t, archiver.appStoreAssetIssues(archive))
+}
@@ -0,0 +1,54 @@
+package step
+
+import (
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+)
+
+// appleIDConnectionOverride returns the Apple ID credentials set by the Step inputs, nil if they are not set.
+func appleIDConnectionOverride(inputs Inputs) *devportalservice.AppleID {
+	if inputs.AppleID == "" || inputs.AppleIDPassword == "" {
+		return nil
+	}
+	return &devportalservice.AppleID{
+		Username:            inputs.AppleID,
+		Password:            string(inputs.AppleIDPassword),
+		Session:             string(inputs.AppleIDSession),
+		AppSpecificPassword: string(inputs.AppleIDAppSpecificPassword),
+	}
+}
+
+// apiKeyConnectionAvailable returns true if the App Store Connect API key is set by the Step inputs or by the Bitrise Apple Service connection.
+func apiKeyConnectionAvailable(inputs Inputs, bitriseConnection *devportalservice.AppleDeveloperConnection) bool {
+	if inputs.APIKeyPath != "" && inputs.APIKeyID != "" && inputs.APIKeyIssuerID != "" {
+		return true
+	}
+	return bitriseConnection != nil && bitriseConnection.APIKeyConnection != nil
+}
+
+// selectConnectionCredentials returns the auth type and the credentials of the Apple Developer Portal and App Store Connect operations.
+//
+// The Apple ID inputs override the Bitrise Apple Service connection of the apple-id code signing,
+// and they are the fallback of the api-key code signing for accounts without an App Store Connect API key.
+func selectConnectionCredentials(authType codesign.AuthType, bitriseConnection *devportalservice.AppleDeveloperConnection, inputs Inputs, logger log.Logger) (codesign.AuthType, devportalservice.Credentials, error) {
+	if appleID := appleIDConnectionOverride(inputs); appleID != nil {
+		switch {
+		case authType == codesign.AppleIDAuth:
+			logger.Infof("Overriding Bitrise Apple Service connection with Step-provided credentials (apple_id, apple_id_password)")
+			return authType, devportalservice.Credentials{AppleID: appleID}, nil
+		case authType == codesign.APIKeyAuth && !apiKeyConnectionAvailable(inputs, bitriseConnection):
+			logger.Warnf("App Store Connect API key is not available, falling back to Step-provided Apple ID credentials (apple_id, apple_id_password)")
+			return codesign.AppleIDAuth, devportalservice.Credentials{AppleID: appleID}, nil
+		}
+	}
+
+	connectionInputs := codesign.ConnectionOverrideInputs{
+		APIKeyPath:              inputs.APIKeyPath,
+		APIKeyID:                inputs.APIKeyID,
+		APIKeyIssuerID:          inputs.APIKeyIssuerID,
+		APIKeyEnterpriseAccount: inputs.APIKeyEnterpriseAccount,
+	}
+	credentials, err := codesign.SelectConnectionCredentials(authType, bitriseConnection, connectionInputs, logger)
+	return authType, credentials, err
+}
@@ -0,0 +1,66 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectConnectionCredentials(t *testing.T) {
+	appleIDInputs := Inputs{AppleID: "dev@example.com", AppleIDPassword: "password", AppleIDAppSpecificPassword: "abcd-efgh-ijkl-mnop"}
+	appleID := &devportalservice.AppleID{Username: "dev@example.com", Password: "password", AppSpecificPassword: "abcd-efgh-ijkl-mnop"}
+
+	tests := []struct {
+		name              string
+		authType          codesign.AuthType
+		bitriseConnection *devportalservice.AppleDeveloperConnection
+		inputs            Inputs
+		wantAuthType      codesign.AuthType
+		wantCredentials   devportalservice.Credentials
+		wantErr           bool
+	}{
+		{
+			name:            "Apple ID inputs override the Bitrise connection",
+			authType:        codesign.AppleIDAuth,
+			inputs:          appleIDInputs,
+			wantAuthType:    codesign.AppleIDAuth,
+			wantCredentials: devportalservice.Credentials{AppleID: appleID},
+		},
+		{
+			name:            "Apple ID inputs are the fallback of the missing API key",
+			authType:        codesign.APIKeyAuth,
+			inputs:          appleIDInputs,
+			wantAuthType:    codesign.AppleIDAuth,
+			wantCredentials: devportalservice.Credentials{AppleID: appleID},
+		},
+		{
+			name:              "API key connection is preferred",
+			authType:          codesign.APIKeyAuth,
+			bitriseConnection: &devportalservice.AppleDeveloperConnection{APIKeyConnection: &devportalservice.APIKeyConnection{KeyID: "ABCD1234"}},
+			inputs:            appleIDInputs,
+			wantAuthType:      codesign.APIKeyAuth,
+			wantCredentials:   devportalservice.Credentials{APIKey: &devportalservice.APIKeyConnection{KeyID: "ABCD1234"}},
+		},
+		{
+			name:         "no connection",
+			authType:     codesign.AppleIDAuth,
+			wantAuthType: codesign.AppleIDAuth,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authType, credentials, err := selectConnectionCredentials(tt.authType, tt.bitriseConnection, tt.inputs, log.NewLogger())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAuthType, authType)
+			require.Equal(t, tt.wantCredentials, credentials)
+		})
+	}
+}
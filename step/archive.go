@@ -5,33 +5,47 @@ import (
 	"os"
 	"strings"
 
+	"github.com/bitrise-io/go-utils/sliceutil"
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/bitrise-io/go-xcode/v2/xcodecommand"
 	"github.com/bitrise-io/go-xcode/xcodebuild"
 	cache "github.com/bitrise-io/go-xcode/xcodecache"
 )
 
-func runArchiveCommandWithRetry(xcodeCommandRunner xcodecommand.Runner, logFormatter string, archiveCmd *xcodebuild.CommandBuilder, swiftPackagesPath string, logger log.Logger) (string, error) {
-	output, err := runArchiveCommand(xcodeCommandRunner, logFormatter, archiveCmd, logger)
+func runArchiveCommandWithRetry(xcodeCommandRunner xcodecommand.Runner, logFormatter string, logFormatterArgs []string, archiveCmd *xcodebuild.CommandBuilder, swiftPackagesPath, resultBundlePath string, sensitiveValues []string, logger log.Logger) (string, error) {
+	output, err := runArchiveCommand(xcodeCommandRunner, logFormatter, logFormatterArgs, archiveCmd, sensitiveValues, logger)
 	if err != nil && swiftPackagesPath != "" && strings.Contains(output, cache.SwiftPackagesStateInvalid) {
 		logger.Warnf("Archive failed, swift packages cache is in an invalid state, error: %s", err)
 		if err := os.RemoveAll(swiftPackagesPath); err != nil {
 			return output, fmt.Errorf("failed to remove invalid Swift package caches, error: %s", err)
 		}
-		return runArchiveCommand(xcodeCommandRunner, logFormatter, archiveCmd, logger)
+		// xcodebuild fails if the result bundle of the failed archive already exists
+		if resultBundlePath != "" {
+			if err := os.RemoveAll(resultBundlePath); err != nil {
+				return output, fmt.Errorf("failed to remove the result bundle of the failed archive, error: %s", err)
+			}
+		}
+		return runArchiveCommand(xcodeCommandRunner, logFormatter, logFormatterArgs, archiveCmd, sensitiveValues, logger)
 	}
 	return output, err
 }
 
-func runArchiveCommand(xcodeCommandRunner xcodecommand.Runner, logFormatter string, archiveCmd *xcodebuild.CommandBuilder, logger log.Logger) (string, error) {
+func runArchiveCommand(xcodeCommandRunner xcodecommand.Runner, logFormatter string, logFormatterArgs []string, archiveCmd *xcodebuild.CommandBuilder, sensitiveValues []string, logger log.Logger) (string, error) {
 	// Log the full command with arguments
 	cmdArgs := archiveCmd.CommandArgs()
-	logger.Printf("Running xcodebuild command: xcodebuild %s", strings.Join(cmdArgs, " "))
+	logger.Printf("Running xcodebuild command: %s", printableCommand("xcodebuild", cmdArgs, sensitiveValues))
 	
-	output, err := xcodeCommandRunner.Run("", cmdArgs, []string{})
+	output, err := xcodeCommandRunner.Run("", cmdArgs, logFormatterArgs)
 	if logFormatter == XcodebuildTool || err != nil {
 		printLastLinesOfXcodebuildLog(logger, string(output.RawOut), err == nil)
 	}
+	if err != nil {
+		action := "build"
+		if sliceutil.IsStringInSlice("archive", cmdArgs) {
+			action = "archive"
+		}
+		err = newXcodebuildError(action, output.ExitCode, string(output.RawOut), err)
+	}
 
 	return string(output.RawOut), err
 }
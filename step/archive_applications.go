@@ -0,0 +1,101 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+// archiveApplications returns every application of the archive's Products/Applications directory, ordered by path.
+func archiveApplications(archivePath string) ([]xcarchive.IosApplication, error) {
+	productsDir, err := findDirCaseInsensitive(archivePath, "Products")
+	if err != nil || productsDir == "" {
+		return nil, err
+	}
+	appPaths, err := findBundles(productsDir, "Applications", ".app")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for applications in %s: %s", productsDir, err)
+	}
+
+	var applications []xcarchive.IosApplication
+	for _, appPath := range appPaths {
+		application, err := xcarchive.NewIosApplication(appPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse application (%s): %s", appPath, err)
+		}
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
+
+// Applications returns every application of the archive, xcarchive.IosArchive models only the main one.
+func (a Archive) Applications() ([]xcarchive.IosApplication, error) {
+	return archiveApplications(a.Path)
+}
+
+// applicationName returns the name of the application bundle without the .app extension.
+func applicationName(application xcarchive.IosApplication) string {
+	return strings.TrimSuffix(filepath.Base(application.Path), ".app")
+}
+
+// matchesApplicationSelector returns true if the selector is the application's name (with or without the .app extension) or bundle ID.
+func matchesApplicationSelector(application xcarchive.IosApplication, selector string) bool {
+	return selector == applicationName(application) || selector == filepath.Base(application.Path) || selector == application.BundleIdentifier()
+}
+
+// selectArchiveApplication replaces the main application of the archive with the application matching the selector.
+// Without a selector the application picked by xcarchive.NewIosArchive is kept.
+func selectArchiveApplication(archive xcarchive.IosArchive, applications []xcarchive.IosApplication, selector string) (xcarchive.IosArchive, error) {
+	if selector == "" {
+		return archive, nil
+	}
+
+	var available []string
+	for _, application := range applications {
+		if matchesApplicationSelector(application, selector) {
+			archive.Application = application
+			return archive, nil
+		}
+		available = append(available, fmt.Sprintf("%s (%s)", applicationName(application), application.BundleIdentifier()))
+	}
+	return xcarchive.IosArchive{}, fmt.Errorf("no application matches %s in the archive, available applications: %s", selector, strings.Join(available, ", "))
+}
+
+// newIosArchive parses the archive and selects its main application, if the archive contains multiple applications.
+// The archive path is resolved, as the bundles are discovered relative to it.
+func (s XcodebuildArchiver) newIosArchive(archivePath, applicationSelector string) (xcarchive.IosArchive, error) {
+	if resolvedPath, err := filepath.EvalSymlinks(archivePath); err == nil {
+		archivePath = resolvedPath
+	}
+
+	archive, err := xcarchive.NewIosArchive(archivePath)
+	if err != nil {
+		return xcarchive.IosArchive{}, err
+	}
+
+	applications, err := archiveApplications(archivePath)
+	if err != nil {
+		return xcarchive.IosArchive{}, err
+	}
+	if len(applications) < 2 && applicationSelector == "" {
+		return completeArchiveBundles(archive)
+	}
+
+	if archive, err = selectArchiveApplication(archive, applications, applicationSelector); err != nil {
+		return xcarchive.IosArchive{}, err
+	}
+	if archive, err = completeArchiveBundles(archive); err != nil {
+		return xcarchive.IosArchive{}, err
+	}
+
+	s.logger.Println()
+	s.logger.Printf("The archive contains %d applications:", len(applications))
+	for _, application := range applications {
+		s.logger.Printf("- %s (%s)", applicationName(application), application.BundleIdentifier())
+	}
+	s.logger.Printf("Using %s, set the Archived application input to select another one", applicationName(archive.Application))
+
+	return archive, nil
+}
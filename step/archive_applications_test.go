@@ -0,0 +1,36 @@
+package step
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectArchiveApplication(t *testing.T) {
+	newApplication := func(name, bundleID string) xcarchive.IosApplication {
+		return xcarchive.IosApplication{IosBaseApplication: xcarchive.IosBaseApplication{
+			Path:      filepath.Join("Products", "Applications", name+".app"),
+			InfoPlist: plistutil.PlistData{"CFBundleIdentifier": bundleID},
+		}}
+	}
+	admin := newApplication("Admin", "io.bitrise.admin")
+	sample := newApplication("Sample", "io.bitrise.sample")
+	archive := xcarchive.IosArchive{Application: admin}
+	applications := []xcarchive.IosApplication{admin, sample}
+
+	for _, selector := range []string{"Sample", "Sample.app", "io.bitrise.sample"} {
+		selected, err := selectArchiveApplication(archive, applications, selector)
+		require.NoError(t, err)
+		require.Equal(t, sample, selected.Application)
+	}
+
+	selected, err := selectArchiveApplication(archive, applications, "")
+	require.NoError(t, err)
+	require.Equal(t, admin, selected.Application)
+
+	_, err = selectArchiveApplication(archive, applications, "Other")
+	require.EqualError(t, err, "no application matches Other in the archive, available applications: Admin (io.bitrise.admin), Sample (io.bitrise.sample)")
+}
@@ -0,0 +1,217 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	archiveFactsVersions     = "Versions"
+	archiveFactsEntitlements = "Entitlements"
+	archiveFactsProfiles     = "Provisioning profiles"
+	archiveFactsFrameworks   = "Embedded frameworks"
+	archiveFactsBinarySizes  = "Binary sizes"
+)
+
+// archiveFactsSections is the order of the sections in the archive comparison.
+var archiveFactsSections = []string{archiveFactsVersions, archiveFactsEntitlements, archiveFactsProfiles, archiveFactsFrameworks, archiveFactsBinarySizes}
+
+// archiveFacts are the compared properties of an archive per section.
+type archiveFacts map[string]map[string]string
+
+func (f archiveFacts) set(section, key, value string) {
+	if f[section] == nil {
+		f[section] = map[string]string{}
+	}
+	f[section][key] = value
+}
+
+// archiveChange is a changed property of the compared archives.
+type archiveChange struct {
+	Section  string
+	Key      string
+	Previous string
+	Current  string
+}
+
+func (c archiveChange) String() string {
+	switch {
+	case c.Previous == "":
+		return fmt.Sprintf("+ %s: %s", c.Key, c.Current)
+	case c.Current == "":
+		return fmt.Sprintf("- %s: %s", c.Key, c.Previous)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Key, c.Previous, c.Current)
+	}
+}
+
+// parseArchiveComparePaths parses the two archive paths of the archive comparison, one path per line.
+func parseArchiveComparePaths(content string) ([]string, error) {
+	var paths []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	if len(paths) != 2 {
+		return nil, fmt.Errorf("two archive paths expected, got %d", len(paths))
+	}
+	for _, pth := range paths {
+		if filepath.Ext(pth) != ".xcarchive" {
+			return nil, fmt.Errorf("%s is not an .xcarchive", pth)
+		}
+	}
+	return paths, nil
+}
+
+// diffArchiveFacts returns the changed properties of the archives, sorted by the section and the key.
+func diffArchiveFacts(previous, current archiveFacts) []archiveChange {
+	var changes []archiveChange
+	for _, section := range archiveFactsSections {
+		keys := map[string]bool{}
+		for key := range previous[section] {
+			keys[key] = true
+		}
+		for key := range current[section] {
+			keys[key] = true
+		}
+
+		var sectionChanges []archiveChange
+		for key := range keys {
+			previousValue, currentValue := previous[section][key], current[section][key]
+			if previousValue != currentValue {
+				sectionChanges = append(sectionChanges, archiveChange{Section: section, Key: key, Previous: previousValue, Current: currentValue})
+			}
+		}
+		sort.Slice(sectionChanges, func(i, j int) bool {
+			return sectionChanges[i].Key < sectionChanges[j].Key
+		})
+		changes = append(changes, sectionChanges...)
+	}
+	return changes
+}
+
+func newArchiveFacts(archive Archive) (archiveFacts, error) {
+	facts := archiveFacts{}
+
+	facts.set(archiveFactsVersions, "Version", archive.Version())
+	facts.set(archiveFactsVersions, "Build number", archive.BuildNumber())
+	facts.set(archiveFactsVersions, "Minimum OS version", archive.MinimumOSVersion())
+	facts.set(archiveFactsVersions, "SDK", archive.SDK())
+	facts.set(archiveFactsVersions, "Xcode build", archive.XcodeBuild())
+
+	for bundleID, entitlements := range archive.BundleIDEntitlementsMap() {
+		for key, value := range entitlements {
+			content, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize entitlement (%s) of %s: %w", key, bundleID, err)
+			}
+			facts.set(archiveFactsEntitlements, bundleID+": "+key, string(content))
+		}
+	}
+
+	for _, bundle := range archive.Bundles() {
+		profile := bundle.ProvisioningProfile
+		if profile.UUID == "" {
+			continue
+		}
+		facts.set(archiveFactsProfiles, bundle.BundleIdentifier(), fmt.Sprintf("%s (%s, %s, team: %s)", profile.Name, profile.UUID, profile.ExportType, profile.TeamID))
+	}
+
+	frameworks, err := findBundles(archive.Application.Path, "Frameworks", ".framework")
+	if err != nil {
+		return nil, err
+	}
+	for _, framework := range frameworks {
+		version := "unknown version"
+		if infoPlist, err := plistutil.NewPlistDataFromFile(filepath.Join(framework, "Info.plist")); err == nil {
+			if shortVersion, ok := infoPlist.GetString("CFBundleShortVersionString"); ok {
+				version = shortVersion
+			}
+		}
+		facts.set(archiveFactsFrameworks, filepath.Base(framework), version)
+	}
+
+	executable, ok := archive.Application.InfoPlist.GetString("CFBundleExecutable")
+	if !ok {
+		return nil, fmt.Errorf("CFBundleExecutable not found in the app's Info.plist")
+	}
+	appSizes, err := archSizes([]string{filepath.Join(archive.Application.Path, executable)})
+	if err != nil {
+		return nil, err
+	}
+	for arch, size := range appSizes {
+		facts.set(archiveFactsBinarySizes, fmt.Sprintf("%s (%s)", executable, arch), fmt.Sprintf("%d bytes", size))
+	}
+	binaries, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, binary := range binaries {
+		sizes, err := archSizes([]string{binary})
+		if err != nil {
+			return nil, err
+		}
+		for arch, size := range sizes {
+			facts.set(archiveFactsBinarySizes, fmt.Sprintf("%s (%s)", filepath.Base(binary), arch), fmt.Sprintf("%d bytes", size))
+		}
+	}
+
+	return facts, nil
+}
+
+func readArchiveFacts(pth string) (archiveFacts, error) {
+	archive, err := xcarchive.NewIosArchive(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive (%s): %w", pth, err)
+	}
+	facts, err := newArchiveFacts(NewArchive(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive (%s): %w", pth, err)
+	}
+	return facts, nil
+}
+
+// CompareArchives prints the differences of the versions, entitlements, provisioning profiles, embedded frameworks and binary sizes of two archives.
+func CompareArchives(previousPath, currentPath string, logger log.Logger) error {
+	previous, err := readArchiveFacts(previousPath)
+	if err != nil {
+		return err
+	}
+	current, err := readArchiveFacts(currentPath)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Comparing archives:")
+	logger.Printf("- %s", previousPath)
+	logger.Printf("+ %s", currentPath)
+
+	changes := diffArchiveFacts(previous, current)
+	if len(changes) == 0 {
+		logger.Println()
+		logger.Donef("The archives are the same")
+		return nil
+	}
+
+	section := ""
+	for _, change := range changes {
+		if change.Section != section {
+			section = change.Section
+			logger.Println()
+			logger.Infof("%s:", section)
+		}
+		logger.Printf("%s", change)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseArchiveComparePaths(t *testing.T) {
+	paths, err := parseArchiveComparePaths("\n  ./baseline/Sample.xcarchive\n./Sample.xcarchive\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{"./baseline/Sample.xcarchive", "./Sample.xcarchive"}, paths)
+
+	paths, err = parseArchiveComparePaths("")
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	_, err = parseArchiveComparePaths("./Sample.xcarchive")
+	require.EqualError(t, err, "two archive paths expected, got 1")
+
+	_, err = parseArchiveComparePaths("./baseline/Sample.xcarchive\n./Sample.ipa")
+	require.EqualError(t, err, "./Sample.ipa is not an .xcarchive")
+}
+
+func Test_diffArchiveFacts(t *testing.T) {
+	previous := archiveFacts{}
+	previous.set(archiveFactsVersions, "Version", "1.0")
+	previous.set(archiveFactsVersions, "Build number", "41")
+	previous.set(archiveFactsFrameworks, "Alamofire.framework", "5.8.0")
+	previous.set(archiveFactsBinarySizes, "Sample (arm64)", "1024 bytes")
+
+	current := archiveFacts{}
+	current.set(archiveFactsVersions, "Version", "1.0")
+	current.set(archiveFactsVersions, "Build number", "42")
+	current.set(archiveFactsEntitlements, "io.bitrise.sample: aps-environment", `"production"`)
+	current.set(archiveFactsBinarySizes, "Sample (arm64)", "2048 bytes")
+
+	changes := diffArchiveFacts(previous, current)
+	require.Equal(t, []archiveChange{
+		{Section: archiveFactsVersions, Key: "Build number", Previous: "41", Current: "42"},
+		{Section: archiveFactsEntitlements, Key: "io.bitrise.sample: aps-environment", Current: `"production"`},
+		{Section: archiveFactsFrameworks, Key: "Alamofire.framework", Previous: "5.8.0"},
+		{Section: archiveFactsBinarySizes, Key: "Sample (arm64)", Previous: "1024 bytes", Current: "2048 bytes"},
+	}, changes)
+
+	require.Equal(t, "~ Build number: 41 -> 42", changes[0].String())
+	require.Equal(t, `+ io.bitrise.sample: aps-environment: "production"`, changes[1].String())
+	require.Equal(t, "- Alamofire.framework: 5.8.0", changes[2].String())
+
+	require.Empty(t, diffArchiveFacts(current, current))
+}
@@ -0,0 +1,42 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+)
+
+const xcarchiveExt = ".xcarchive"
+
+// resolveArchivePath returns the path of the generated xcarchive: the archivePath input, if it has the .xcarchive extension,
+// otherwise the <artifactName>.xcarchive in the archivePath directory. Without the input the archive is generated in the tmpDir.
+func resolveArchivePath(archivePath, artifactName, tmpDir string) string {
+	if archivePath == "" {
+		return filepath.Join(tmpDir, artifactName+xcarchiveExt)
+	}
+	if strings.EqualFold(filepath.Ext(archivePath), xcarchiveExt) {
+		return archivePath
+	}
+	return filepath.Join(archivePath, artifactName+xcarchiveExt)
+}
+
+// prepareArchivePath creates the parent directory of the archive and removes the archive of a previous build,
+// as xcodebuild merges the new archive into an existing one.
+func prepareArchivePath(archivePath string, logger log.Logger) error {
+	if _, err := os.Stat(archivePath); err == nil {
+		logger.Warnf("Removing the existing archive at: %s", archivePath)
+		if err := os.RemoveAll(archivePath); err != nil {
+			return fmt.Errorf("failed to remove the existing archive (%s): %s", archivePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the archive's directory (%s): %s", filepath.Dir(archivePath), err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveArchivePath(t *testing.T) {
+	require.Equal(t, "/tmp/xcodeArchive/Sample.xcarchive", resolveArchivePath("", "Sample", "/tmp/xcodeArchive"))
+	require.Equal(t, "/Users/vagrant/Archives/Release.xcarchive", resolveArchivePath("/Users/vagrant/Archives/Release.xcarchive", "Sample", "/tmp/xcodeArchive"))
+	require.Equal(t, "/Users/vagrant/Archives/Sample.xcarchive", resolveArchivePath("/Users/vagrant/Archives", "Sample", "/tmp/xcodeArchive"))
+}
+
+func Test_prepareArchivePath(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "Archives", "Sample.xcarchive")
+	require.NoError(t, prepareArchivePath(archivePath, log.NewLogger()))
+	require.DirExists(t, filepath.Dir(archivePath))
+	require.NoDirExists(t, archivePath)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(archivePath, "Products"), 0755))
+	require.NoError(t, prepareArchivePath(archivePath, log.NewLogger()))
+	require.NoDirExists(t, archivePath)
+}
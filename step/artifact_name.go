@@ -0,0 +1,83 @@
+package step
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// shortGitSHALength is the length of the {git_sha} placeholder's value
+const shortGitSHALength = 7
+
+// artifactNameValues are the values available in the artifact name templates.
+type artifactNameValues struct {
+	Scheme        string
+	ArtifactName  string
+	Configuration string
+	Version       string
+	Build         string
+	GitCommit     string
+	ExportMethod  string
+}
+
+// exportArtifactNameValues collects the artifact name template values of the export.
+func exportArtifactNameValues(opts ExportOpts) artifactNameValues {
+	values := artifactNameValues{
+		Scheme:        opts.Scheme,
+		ArtifactName:  opts.ArtifactName,
+		Configuration: opts.Configuration,
+		GitCommit:     opts.GitCommit,
+	}
+	if opts.Archive != nil {
+		archive := NewArchive(*opts.Archive)
+		values.Version = archive.Version()
+		values.Build = archive.BuildNumber()
+	}
+	if opts.ExportOptionsPath != "" {
+		if method, err := exportMethodFromExportOptions(opts.ExportOptionsPath); err == nil {
+			values.ExportMethod = string(method)
+		}
+	}
+	return values
+}
+
+// expandArtifactNameTemplate resolves the {scheme}, {product}, {configuration}, {version}, {build}, {git_sha} and {export_method}
+// placeholders of the template.
+// The given extension is stripped from the template, so both `{scheme}-{version}` and `{scheme}-{version}.ipa` are accepted.
+func expandArtifactNameTemplate(template, ext string, values artifactNameValues) string {
+	name := strings.TrimSuffix(template, ext)
+
+	gitSHA := values.GitCommit
+	if len(gitSHA) > shortGitSHALength {
+		gitSHA = gitSHA[:shortGitSHALength]
+	}
+
+	replacer := strings.NewReplacer(
+		"{scheme}", values.Scheme,
+		"{product}", values.ArtifactName,
+		"{configuration}", values.Configuration,
+		"{version}", values.Version,
+		"{build}", values.Build,
+		"{git_sha}", gitSHA,
+		"{export_method}", values.ExportMethod,
+	)
+	name = replacer.Replace(name)
+
+	// the resolved name is used as a file name
+	return fileNameSafe(name)
+}
+
+// artifactFileName returns the file name (without the extension) of an exported artifact:
+// the first non-empty template is expanded, the artifact name is used if no template is set.
+func artifactFileName(ext string, values artifactNameValues, templates ...string) string {
+	for _, template := range templates {
+		if template != "" {
+			return expandArtifactNameTemplate(template, ext, values)
+		}
+	}
+	return fileNameSafe(values.ArtifactName)
+}
+
+// fileNameSafe replaces the path separators of the name, which is used as a file name.
+func fileNameSafe(name string) string {
+	return strings.ReplaceAll(name, string(filepath.Separator), "_")
+}
@@ -0,0 +1,65 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_expandArtifactNameTemplate(t *testing.T) {
+	values := artifactNameValues{
+		Scheme:        "ios-sample",
+		ArtifactName:  "Sample",
+		Configuration: "Release",
+		Version:       "1.2.0",
+		Build:         "42",
+		GitCommit:     "8b1a9953c4611296a827abf8c47804d7e6c49c6b",
+		ExportMethod:  "ad-hoc",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "iTunes-style name",
+			template: "{scheme}-{version}({build}).ipa",
+			want:     "ios-sample-1.2.0(42)",
+		},
+		{
+			name:     "without extension",
+			template: "{product}_{version}",
+			want:     "Sample_1.2.0",
+		},
+		{
+			name:     "build metadata",
+			template: "{scheme}-{configuration}-{export_method}-{git_sha}",
+			want:     "ios-sample-Release-ad-hoc-8b1a995",
+		},
+		{
+			name:     "path separators are replaced",
+			template: "{scheme}/{build}",
+			want:     "ios-sample_42",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandArtifactNameTemplate(tt.template, ".ipa", values)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_artifactFileName(t *testing.T) {
+	values := artifactNameValues{Scheme: "ios-sample", ArtifactName: "Sample", Build: "42"}
+
+	require.Equal(t, "Sample", artifactFileName(".ipa", values))
+	require.Equal(t, "Sample", artifactFileName(".ipa", values, "", ""))
+	require.Equal(t, "ios-sample-42", artifactFileName(".xcarchive.zip", values, "", "{scheme}-{build}.xcarchive.zip"))
+	require.Equal(t, "42", artifactFileName(".ipa", values, "{build}", "{scheme}-{build}"))
+
+	values = artifactNameValues{Scheme: "Sample/Release", ArtifactName: "Sample/Ápp \"Beta\""}
+	require.Equal(t, "Sample_Ápp \"Beta\"", artifactFileName(".ipa", values))
+	require.Equal(t, "Sample_Release", artifactFileName(".ipa", values, "{scheme}"))
+}
@@ -0,0 +1,163 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	artifactSigningNone = "none"
+	artifactSigningGPG  = "gpg"
+	artifactSigningSSH  = "ssh"
+
+	// sshSignatureNamespace is the namespace of the SSH signatures, verify them with `ssh-keygen -Y verify -n file`
+	sshSignatureNamespace = "file"
+)
+
+// artifactSigner creates detached signatures of the exported artifacts.
+type artifactSigner struct {
+	method     string
+	key        string
+	passphrase string
+
+	cmdFactory command.Factory
+}
+
+// signatureExtension returns the extension of the detached signature files.
+func signatureExtension(method string) string {
+	if method == artifactSigningSSH {
+		return ".sig"
+	}
+	return ".asc"
+}
+
+// Sign creates the detached signatures of the given files and returns the signature paths.
+func (s artifactSigner) Sign(pths []string) ([]string, error) {
+	tmpDir, err := v1pathutil.NormalizedOSTempDirPath("__artifact_signing__")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tmp dir, error: %s", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	keyPath := filepath.Join(tmpDir, "signing.key")
+	key := s.key
+	if !strings.HasSuffix(key, "\n") {
+		// ssh-keygen fails to load a private key without the trailing newline
+		key += "\n"
+	}
+	if err := os.WriteFile(keyPath, []byte(key), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %s", err)
+	}
+
+	var sign func(pth string) error
+	switch s.method {
+	case artifactSigningGPG:
+		gnupgHome := filepath.Join(tmpDir, "gnupg")
+		if err := os.Mkdir(gnupgHome, 0700); err != nil {
+			return nil, err
+		}
+		defer s.stopGPGAgent(gnupgHome)
+		if err := s.importGPGKey(gnupgHome, keyPath); err != nil {
+			return nil, err
+		}
+		sign = func(pth string) error {
+			return s.signWithGPG(gnupgHome, pth)
+		}
+	case artifactSigningSSH:
+		sign = func(pth string) error {
+			return s.signWithSSH(keyPath, pth)
+		}
+	default:
+		return nil, fmt.Errorf("unknown signing method: %s", s.method)
+	}
+
+	var signaturePaths []string
+	for _, pth := range pths {
+		if err := sign(pth); err != nil {
+			return nil, fmt.Errorf("failed to sign %s: %w", filepath.Base(pth), err)
+		}
+		signaturePaths = append(signaturePaths, pth+signatureExtension(s.method))
+	}
+	return signaturePaths, nil
+}
+
+func (s artifactSigner) importGPGKey(gnupgHome, keyPath string) error {
+	cmd := s.cmdFactory.Create("gpg", []string{"--batch", "--import", keyPath}, &command.Opts{Env: []string{"GNUPGHOME=" + gnupgHome}})
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("failed to import GPG key: %s, output: %s", err, out)
+	}
+	return nil
+}
+
+// stopGPGAgent stops the agent started by gpg for the temporary home directory.
+func (s artifactSigner) stopGPGAgent(gnupgHome string) {
+	cmd := s.cmdFactory.Create("gpgconf", []string{"--kill", "gpg-agent"}, &command.Opts{Env: []string{"GNUPGHOME=" + gnupgHome}})
+	_ = cmd.Run()
+}
+
+func (s artifactSigner) signWithGPG(gnupgHome, pth string) error {
+	args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", pth + signatureExtension(artifactSigningGPG)}
+	opts := &command.Opts{Env: []string{"GNUPGHOME=" + gnupgHome}}
+	if s.passphrase != "" {
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+		opts.Stdin = strings.NewReader(s.passphrase)
+	}
+	args = append(args, pth)
+
+	cmd := s.cmdFactory.Create("gpg", args, opts)
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s, output: %s", err, out)
+	}
+	return nil
+}
+
+func (s artifactSigner) signWithSSH(keyPath, pth string) error {
+	// ssh-keygen writes the signature next to the file, with .sig extension
+	if err := os.RemoveAll(pth + signatureExtension(artifactSigningSSH)); err != nil {
+		return err
+	}
+
+	cmd := s.cmdFactory.Create("ssh-keygen", []string{"-Y", "sign", "-f", keyPath, "-n", sshSignatureNamespace, pth}, nil)
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s, output: %s", err, out)
+	}
+	return nil
+}
+
+func (s XcodebuildArchiver) exportArtifactSignatures(artifacts []exportedArtifact, signer artifactSigner) error {
+	var toSign []exportedArtifact
+	var pths []string
+	for _, artifact := range artifacts {
+		if artifact.SignatureEnvKey != "" {
+			toSign = append(toSign, artifact)
+			pths = append(pths, artifact.Path)
+		}
+	}
+	if len(toSign) == 0 {
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Signing artifacts (%s)...", signer.method)
+
+	signaturePaths, err := signer.Sign(pths)
+	if err != nil {
+		return err
+	}
+
+	for i, artifact := range toSign {
+		if err := exportEnvironmentWithEnvman(s.cmdFactory, artifact.SignatureEnvKey, signaturePaths[i]); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", artifact.SignatureEnvKey, err)
+		}
+		s.logger.Donef("The signature path is now available in the Environment Variable: %s (value: %s)", artifact.SignatureEnvKey, signaturePaths[i])
+	}
+
+	return nil
+}
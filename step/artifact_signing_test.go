@@ -0,0 +1,45 @@
+package step
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_signatureExtension(t *testing.T) {
+	require.Equal(t, ".asc", signatureExtension(artifactSigningGPG))
+	require.Equal(t, ".sig", signatureExtension(artifactSigningSSH))
+}
+
+func TestArtifactSigner_Sign_ssh(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath).Run())
+	key, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+
+	ipaPath := filepath.Join(dir, "App.ipa")
+	require.NoError(t, os.WriteFile(ipaPath, []byte("ipa"), 0644))
+
+	signer := artifactSigner{
+		method:     artifactSigningSSH,
+		key:        string(key),
+		cmdFactory: command.NewFactory(env.NewRepository()),
+	}
+	signaturePaths, err := signer.Sign([]string{ipaPath})
+	require.NoError(t, err)
+	require.Equal(t, []string{ipaPath + ".sig"}, signaturePaths)
+
+	signature, err := os.ReadFile(signaturePaths[0])
+	require.NoError(t, err)
+	require.Contains(t, string(signature), "BEGIN SSH SIGNATURE")
+}
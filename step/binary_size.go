@@ -0,0 +1,231 @@
+package step
+
+import (
+	"debug/macho"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	cpuSubtypeARM64E = 2
+	// cpuArm64_32 is the CPU type of the watchOS arm64_32 slices, debug/macho does not define it
+	cpuArm64_32 macho.Cpu = 0x0200000c
+
+	loadCmdBuildVersion      = 0x32
+	loadCmdVersionMinIPhone  = 0x25
+	loadCmdVersionMinTVOS    = 0x2f
+	loadCmdVersionMinWatchOS = 0x30
+)
+
+// Platforms of the LC_BUILD_VERSION load command
+const (
+	machoPlatformIOSSimulator      = 7
+	machoPlatformTVOSSimulator     = 8
+	machoPlatformWatchOSSimulator  = 9
+	machoPlatformVisionOSSimulator = 12
+)
+
+// binarySlice is an architecture slice of a Mach-O binary.
+type binarySlice struct {
+	Arch string
+	// Platform is the LC_BUILD_VERSION platform, 0 if the slice has no such load command
+	Platform uint32
+	// MinOS is the minimum OS version encoded as xxxx.yy.zz nibbles, 0 if unknown
+	MinOS uint32
+	// SegmentSizes are the file sizes of the segments, for example __TEXT, __DATA and __LINKEDIT
+	SegmentSizes map[string]uint64
+}
+
+// Size ...
+func (s binarySlice) Size() uint64 {
+	var size uint64
+	for _, segmentSize := range s.SegmentSizes {
+		size += segmentSize
+	}
+	return size
+}
+
+// readBinarySlices returns the architecture slices of a thin or universal Mach-O binary.
+func readBinarySlices(pth string) ([]binarySlice, error) {
+	var slices []binarySlice
+	if err := forEachMachOSlice(pth, func(f *macho.File) {
+		slices = append(slices, newBinarySlice(f))
+	}); err != nil {
+		return nil, err
+	}
+	return slices, nil
+}
+
+// forEachMachOSlice calls fn with each architecture slice of a thin or universal Mach-O binary.
+func forEachMachOSlice(pth string, fn func(f *macho.File)) error {
+	if fat, err := macho.OpenFat(pth); err == nil {
+		defer func() {
+			_ = fat.Close()
+		}()
+
+		for _, arch := range fat.Arches {
+			fn(arch.File)
+		}
+		return nil
+	} else if !errors.Is(err, macho.ErrNotFat) {
+		return err
+	}
+
+	f, err := macho.Open(pth)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fn(f)
+	return nil
+}
+
+func newBinarySlice(f *macho.File) binarySlice {
+	slice := binarySlice{
+		Arch:         archName(f.Cpu, f.SubCpu),
+		SegmentSizes: map[string]uint64{},
+	}
+	for _, load := range f.Loads {
+		if segment, ok := load.(*macho.Segment); ok && segment.Filesz > 0 {
+			slice.SegmentSizes[segment.Name] += segment.Filesz
+		}
+
+		raw := load.Raw()
+		if len(raw) < 16 {
+			continue
+		}
+		switch f.ByteOrder.Uint32(raw) {
+		case loadCmdBuildVersion:
+			slice.Platform = f.ByteOrder.Uint32(raw[8:])
+			slice.MinOS = f.ByteOrder.Uint32(raw[12:])
+		case loadCmdVersionMinIPhone, loadCmdVersionMinTVOS, loadCmdVersionMinWatchOS:
+			slice.MinOS = f.ByteOrder.Uint32(raw[8:])
+		}
+	}
+	return slice
+}
+
+// IsSimulator ...
+func (s binarySlice) IsSimulator() bool {
+	switch s.Platform {
+	case machoPlatformIOSSimulator, machoPlatformTVOSSimulator, machoPlatformWatchOSSimulator, machoPlatformVisionOSSimulator:
+		return true
+	}
+	return s.Arch == "x86_64" || s.Arch == "i386"
+}
+
+func archName(cpu macho.Cpu, subCpu uint32) string {
+	switch cpu {
+	case macho.CpuArm64:
+		// the upper bits of the subtype are capability flags
+		if subCpu&0xff == cpuSubtypeARM64E {
+			return "arm64e"
+		}
+		return "arm64"
+	case cpuArm64_32:
+		return "arm64_32"
+	case macho.CpuArm:
+		return "armv7"
+	case macho.CpuAmd64:
+		return "x86_64"
+	case macho.Cpu386:
+		return "i386"
+	default:
+		return strings.ToLower(cpu.String())
+	}
+}
+
+// archSizes sums the slice sizes of the given binaries per architecture.
+func archSizes(binaryPaths []string) (map[string]uint64, error) {
+	sizes := map[string]uint64{}
+	for _, pth := range binaryPaths {
+		slices, err := readBinarySlices(pth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Mach-O binary (%s): %s", pth, err)
+		}
+		for _, slice := range slices {
+			sizes[slice.Arch] += slice.Size()
+		}
+	}
+	return sizes, nil
+}
+
+// frameworkBinaries returns the executables of the frameworks embedded into the application.
+func frameworkBinaries(appPath string) ([]string, error) {
+	frameworks, err := findBundles(appPath, "Frameworks", ".framework")
+	if err != nil {
+		return nil, err
+	}
+
+	var binaries []string
+	for _, framework := range frameworks {
+		binaries = append(binaries, filepath.Join(framework, strings.TrimSuffix(filepath.Base(framework), ".framework")))
+	}
+	return binaries, nil
+}
+
+func (s XcodebuildArchiver) exportBinarySizes(archive Archive) error {
+	executable, ok := archive.Application.InfoPlist.GetString("CFBundleExecutable")
+	if !ok {
+		return fmt.Errorf("CFBundleExecutable not found in the app's Info.plist")
+	}
+	executablePath := filepath.Join(archive.Application.Path, executable)
+
+	slices, err := readBinarySlices(executablePath)
+	if err != nil {
+		return fmt.Errorf("failed to read Mach-O binary (%s): %s", executablePath, err)
+	}
+	for _, slice := range slices {
+		var segments []string
+		for name, size := range slice.SegmentSizes {
+			segments = append(segments, fmt.Sprintf("%s: %d", name, size))
+		}
+		sort.Strings(segments)
+		s.logger.Printf("%s (%s): %d bytes (%s)", executable, slice.Arch, slice.Size(), strings.Join(segments, ", "))
+	}
+
+	appSizes, err := archSizes([]string{executablePath})
+	if err != nil {
+		return err
+	}
+
+	binaries, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		return err
+	}
+	frameworkSizes, err := archSizes(binaries)
+	if err != nil {
+		return err
+	}
+
+	outputs := map[string]uint64{}
+	for arch, size := range appSizes {
+		outputs[bitriseAppExecutableSizeEnvKeyPrefix+strings.ToUpper(arch)] = size
+	}
+	for arch, size := range frameworkSizes {
+		s.logger.Printf("Frameworks (%s): %d bytes", arch, size)
+		outputs[bitriseFrameworksExecutableSizeEnvKeyPrefix+strings.ToUpper(arch)] = size
+	}
+
+	var keys []string
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		size := outputs[key]
+		if err := exportEnvironmentWithEnvman(s.cmdFactory, key, fmt.Sprintf("%d", size)); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", key, err)
+		}
+		s.logger.Donef("The binary size is now available in the Environment Variable: %s (value: %d)", key, size)
+	}
+
+	return nil
+}
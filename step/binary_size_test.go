@@ -0,0 +1,73 @@
+package step
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_archName(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpu    macho.Cpu
+		subCpu uint32
+		want   string
+	}{
+		{name: "arm64", cpu: macho.CpuArm64, subCpu: 0, want: "arm64"},
+		{name: "arm64e", cpu: macho.CpuArm64, subCpu: 2, want: "arm64e"},
+		{name: "arm64e with capability bits", cpu: macho.CpuArm64, subCpu: 0x80000002, want: "arm64e"},
+		{name: "x86_64", cpu: macho.CpuAmd64, subCpu: 3, want: "x86_64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, archName(tt.cpu, tt.subCpu))
+		})
+	}
+}
+
+func Test_readBinarySlices(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "MyApp")
+	require.NoError(t, os.WriteFile(pth, testMachO(macho.CpuArm64, 2, map[string]uint64{"__TEXT": 4096}), 0600))
+
+	slices, err := readBinarySlices(pth)
+	require.NoError(t, err)
+	require.Equal(t, []binarySlice{{Arch: "arm64e", SegmentSizes: map[string]uint64{"__TEXT": 4096}}}, slices)
+	require.Equal(t, uint64(4096), slices[0].Size())
+}
+
+// testMachO returns a minimal thin 64-bit Mach-O header with the given segments.
+func testMachO(cpu macho.Cpu, subCpu uint32, segments map[string]uint64) []byte {
+	const segmentCommandSize = 72
+
+	b := binary.LittleEndian.AppendUint32(nil, macho.Magic64)
+	b = binary.LittleEndian.AppendUint32(b, uint32(cpu))
+	b = binary.LittleEndian.AppendUint32(b, subCpu)
+	b = binary.LittleEndian.AppendUint32(b, uint32(macho.TypeExec))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(segments)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(segments)*segmentCommandSize))
+	b = binary.LittleEndian.AppendUint32(b, 0) // flags
+	b = binary.LittleEndian.AppendUint32(b, 0) // reserved
+
+	for name, size := range segments {
+		segname := make([]byte, 16)
+		copy(segname, name)
+
+		b = binary.LittleEndian.AppendUint32(b, uint32(macho.LoadCmdSegment64))
+		b = binary.LittleEndian.AppendUint32(b, segmentCommandSize)
+		b = append(b, segname...)
+		b = binary.LittleEndian.AppendUint64(b, 0)    // vmaddr
+		b = binary.LittleEndian.AppendUint64(b, size) // vmsize
+		b = binary.LittleEndian.AppendUint64(b, 0)    // fileoff
+		b = binary.LittleEndian.AppendUint64(b, size) // filesize
+		b = binary.LittleEndian.AppendUint32(b, 5)    // maxprot
+		b = binary.LittleEndian.AppendUint32(b, 5)    // initprot
+		b = binary.LittleEndian.AppendUint32(b, 0)    // nsects
+		b = binary.LittleEndian.AppendUint32(b, 0)    // flags
+	}
+
+	return b
+}
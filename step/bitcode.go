@@ -0,0 +1,109 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// bitcodeSegmentName is the segment embedding the bitcode into the Mach-O slices.
+const bitcodeSegmentName = "__LLVM"
+
+// hasBitcode returns true if any architecture slice of the binary embeds bitcode (or a bitcode marker).
+func hasBitcode(pth string) (bool, error) {
+	slices, err := readBinarySlices(pth)
+	if err != nil {
+		return false, err
+	}
+	for _, slice := range slices {
+		if _, ok := slice.SegmentSizes[bitcodeSegmentName]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s XcodebuildArchiver) stripBinaryBitcode(pth string) error {
+	cmd := s.cmdFactory.Create("xcrun", []string{"bitcode_strip", "-r", pth, "-o", pth}, nil)
+	s.logger.Printf("$ %s", cmd.PrintableCommandArgs())
+	if output, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+	}
+	return nil
+}
+
+// stripBitcode removes the bitcode segments of the executables of the archived bundles and their embedded frameworks,
+// then re-signs the modified frameworks and bundles (the embedded ones first) with the archive's signing identity.
+// It returns true if any binary contained bitcode.
+func (s XcodebuildArchiver) stripBitcode(archive Archive) (bool, error) {
+	s.logger.Println()
+	s.logger.Infof("Stripping bitcode from the archived binaries")
+
+	var identity string
+	strip := func(binary string) (bool, error) {
+		found, err := hasBitcode(binary)
+		if err != nil {
+			return false, fmt.Errorf("failed to read Mach-O binary (%s): %s", binary, err)
+		}
+		if !found {
+			return false, nil
+		}
+		if identity == "" {
+			if identity, err = archiveSigningIdentity(archive); err != nil {
+				return false, err
+			}
+		}
+		return true, s.stripBinaryBitcode(binary)
+	}
+
+	var modifiedBundles []string
+	bundles := archive.Bundles()
+	// the embedded bundles (extensions, watch app, App Clip) are listed after the bundles embedding them,
+	// they are processed in reverse order, so that they are signed before their parent
+	for i := len(bundles) - 1; i >= 0; i-- {
+		bundle := bundles[i]
+		modified := containsBundle(bundle.Path, modifiedBundles)
+
+		frameworks, err := frameworkBinaries(bundle.Path)
+		if err != nil {
+			return false, fmt.Errorf("failed to list embedded frameworks: %w", err)
+		}
+		for _, framework := range frameworks {
+			found, err := strip(framework)
+			if err != nil {
+				return false, err
+			}
+			if found {
+				modified = true
+				if err := s.resignFramework(filepath.Dir(framework), identity); err != nil {
+					return false, err
+				}
+			}
+		}
+
+		if executable, ok := bundleExecutable(bundle.Path, bundle.InfoPlist); ok {
+			found, err := strip(executable)
+			if err != nil {
+				return false, err
+			}
+			modified = modified || found
+		}
+
+		if !modified {
+			continue
+		}
+		modifiedBundles = append(modifiedBundles, bundle.Path)
+
+		cmd := s.cmdFactory.Create("codesign", resignBundleArgs(bundle.Path, identity), nil)
+		s.logger.Printf("$ %s", cmd.PrintableCommandArgs())
+		if output, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			return false, fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+		}
+	}
+
+	if len(modifiedBundles) == 0 {
+		s.logger.Donef("The archived binaries contain no bitcode")
+		return false, nil
+	}
+	s.logger.Donef("Bitcode stripped from the archived binaries")
+	return true, nil
+}
@@ -0,0 +1,26 @@
+package step
+
+import (
+	"debug/macho"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hasBitcode(t *testing.T) {
+	dir := t.TempDir()
+	withBitcode := filepath.Join(dir, "WithBitcode")
+	withoutBitcode := filepath.Join(dir, "WithoutBitcode")
+	require.NoError(t, os.WriteFile(withBitcode, testMachO(macho.CpuArm64, 0, map[string]uint64{"__TEXT": 4096, bitcodeSegmentName: 1024}), 0600))
+	require.NoError(t, os.WriteFile(withoutBitcode, testMachO(macho.CpuArm64, 0, map[string]uint64{"__TEXT": 4096}), 0600))
+
+	found, err := hasBitcode(withBitcode)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	found, err = hasBitcode(withoutBitcode)
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,71 @@
+package step
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+)
+
+// fetchBitriseConnection returns the Apple Service connection configured for the app on Bitrise,
+// nil if the Step doesn't run on Bitrise.
+// The connection is fetched once and shared by the code signing managers of the scheme matrix entries.
+func (s XcodebuildArchiveConfigParser) fetchBitriseConnection(config Config) (*devportalservice.AppleDeveloperConnection, error) {
+	if config.BuildURL == "" || config.BuildAPIToken == "" {
+		s.logger.Debugf("Not running on Bitrise, skipping Apple Service connection detection")
+		return nil, nil
+	}
+
+	devPortalClientFactory := devportalclient.NewFactory(s.logger, s.fileManager)
+	connection, err := devPortalClientFactory.CreateBitriseConnection(config.BuildURL, string(config.BuildAPIToken))
+	if err != nil {
+		return nil, err
+	}
+	logBitriseConnection(connection, config.Inputs, s.logger)
+	return connection, nil
+}
+
+// logBitriseConnection prints the detected connections, and warns about the connection override inputs repeating them.
+func logBitriseConnection(connection *devportalservice.AppleDeveloperConnection, inputs Inputs, logger log.Logger) {
+	if connection.APIKeyConnection == nil && connection.AppleIDConnection == nil {
+		logger.Printf("No Apple Service connection configured on Bitrise")
+		return
+	}
+
+	logger.Printf("Detected Apple Service connection:")
+	if apiKey := connection.APIKeyConnection; apiKey != nil {
+		logger.Printf("- API key: key ID %s, issuer ID %s", apiKey.KeyID, apiKey.IssuerID)
+		if inputs.APIKeyID == apiKey.KeyID && inputs.APIKeyIssuerID == apiKey.IssuerID {
+			logger.Warnf("The App Store Connect API key inputs repeat the Bitrise Apple Service connection, they can be cleared")
+		}
+	}
+	if appleID := connection.AppleIDConnection; appleID != nil {
+		logger.Printf("- Apple ID: %s", appleID.AppleID)
+		if inputs.AppleID == appleID.AppleID {
+			logger.Warnf("The Apple ID inputs repeat the Bitrise Apple Service connection, they can be cleared")
+		}
+	}
+}
+
+// resolveCodeSigningAuthSource returns the automatic code signing method,
+// the auto method selects the API key if one is available, and the Apple ID otherwise.
+func resolveCodeSigningAuthSource(source string, connection *devportalservice.AppleDeveloperConnection, inputs Inputs, logger log.Logger) (string, error) {
+	if source != codeSignSourceAuto {
+		return source, nil
+	}
+
+	var resolved string
+	switch {
+	case apiKeyConnectionAvailable(inputs, connection):
+		resolved = codeSignSourceAPIKey
+	case connection != nil && connection.AppleIDConnection != nil, appleIDConnectionOverride(inputs) != nil:
+		resolved = codeSignSourceAppleID
+	default:
+		logger.Warnf(devportalclient.NotConnectedWarning)
+		return "", fmt.Errorf("no Apple Service connection found, please connect an API key or an Apple ID to the app on Bitrise, or set the connection override inputs")
+	}
+
+	logger.Printf("Automatic code signing method: %s", resolved)
+	return resolved, nil
+}
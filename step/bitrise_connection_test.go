@@ -0,0 +1,41 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveCodeSigningAuthSource(t *testing.T) {
+	apiKeyConnection := &devportalservice.AppleDeveloperConnection{APIKeyConnection: &devportalservice.APIKeyConnection{KeyID: "ABCD1234"}, AppleIDConnection: &devportalservice.AppleIDConnection{AppleID: "dev@example.com"}}
+	appleIDConnection := &devportalservice.AppleDeveloperConnection{AppleIDConnection: &devportalservice.AppleIDConnection{AppleID: "dev@example.com"}}
+
+	tests := []struct {
+		name       string
+		source     string
+		connection *devportalservice.AppleDeveloperConnection
+		inputs     Inputs
+		want       string
+		wantErr    bool
+	}{
+		{name: "explicit method", source: codeSignSourceAppleID, connection: apiKeyConnection, want: codeSignSourceAppleID},
+		{name: "API key connection", source: codeSignSourceAuto, connection: apiKeyConnection, want: codeSignSourceAPIKey},
+		{name: "Apple ID connection", source: codeSignSourceAuto, connection: appleIDConnection, want: codeSignSourceAppleID},
+		{name: "API key inputs", source: codeSignSourceAuto, inputs: Inputs{APIKeyPath: "key.p8", APIKeyID: "ABCD1234", APIKeyIssuerID: "issuer"}, want: codeSignSourceAPIKey},
+		{name: "Apple ID inputs", source: codeSignSourceAuto, connection: &devportalservice.AppleDeveloperConnection{}, inputs: Inputs{AppleID: "dev@example.com", AppleIDPassword: "password"}, want: codeSignSourceAppleID},
+		{name: "no connection", source: codeSignSourceAuto, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveCodeSigningAuthSource(tt.source, tt.connection, tt.inputs, log.NewLogger())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
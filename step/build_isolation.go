@@ -0,0 +1,206 @@
+package step
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bitrise-io/go-steputils/v2/stepconf"
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	// profilesLockFile guards the shared provisioning profile directories of the builds running on the same machine.
+	profilesLockFile = "Library/MobileDevice/.steps-xcode-archive.lock"
+	// keychainsLockFile guards the user keychain search list of the builds running on the same machine.
+	keychainsLockFile = "Library/Keychains/.steps-xcode-archive.lock"
+)
+
+// BuildIsolation is the per-build keychain of the builds running concurrently on the same machine.
+// Provisioning profiles are not isolated: Xcode only reads them from the shared profiles directory.
+type BuildIsolation struct {
+	KeychainPath string
+}
+
+// isolatedKeychainCommandFactory keeps the user's default keychain unchanged while the certificates are installed
+// into the build keychain, the build keychain is added to the keychain search list instead (addKeychainToSearchList).
+type isolatedKeychainCommandFactory struct {
+	command.Factory
+}
+
+func (f isolatedKeychainCommandFactory) Create(name string, args []string, opts *command.Opts) command.Command {
+	if name == "security" && isSetDefaultKeychainArgs(args) {
+		return f.Factory.Create("security", []string{"default-keychain", "-d", "user"}, opts)
+	}
+	return f.Factory.Create(name, args, opts)
+}
+
+func isSetDefaultKeychainArgs(args []string) bool {
+	isDefaultKeychain, isSet := false, false
+	for _, arg := range args {
+		switch arg {
+		case "default-keychain":
+			isDefaultKeychain = true
+		case "-s":
+			isSet = true
+		}
+	}
+	return isDefaultKeychain && isSet
+}
+
+// isolatedKeychainPath returns the keychain path of the build, namespaced by the build slug (or the process ID outside of Bitrise).
+func isolatedKeychainPath(tmpDir, buildSlug string) string {
+	id := buildSlug
+	if id == "" {
+		id = strconv.Itoa(os.Getpid())
+	}
+	return filepath.Join(tmpDir, fmt.Sprintf("steps-xcode-archive-%s.keychain", id))
+}
+
+func randomKeychainPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// createBuildIsolation replaces the Keychain inputs with a per-build keychain,
+// the keychain is created when the code signing certificates are installed.
+func (s XcodebuildArchiveConfigParser) createBuildIsolation(config *Config) (*BuildIsolation, error) {
+	password, err := randomKeychainPassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keychain password: %w", err)
+	}
+
+	isolation := &BuildIsolation{
+		KeychainPath: isolatedKeychainPath(os.TempDir(), config.BuildSlug),
+	}
+	s.logger.Printf("Using build keychain: %s", isolation.KeychainPath)
+
+	config.KeychainPath = isolation.KeychainPath
+	config.KeychainPassword = stepconf.Secret(password)
+	return isolation, nil
+}
+
+// CleanupBuildIsolation removes the build keychain from the keychain search list and deletes it.
+func (s XcodebuildArchiver) CleanupBuildIsolation(isolation *BuildIsolation) {
+	if isolation == nil {
+		return
+	}
+
+	if err := s.removeKeychainFromSearchList(isolation.KeychainPath); err != nil {
+		s.logger.Warnf("Failed to remove build keychain from the keychain search list: %s", err)
+	}
+
+	keychainPath, ok := resolveKeychainPath(isolation.KeychainPath)
+	if !ok {
+		return
+	}
+	if output, err := s.runSecurity("delete-keychain", keychainPath); err != nil {
+		s.logger.Warnf("Failed to delete build keychain: %s", output)
+	}
+}
+
+// parseKeychainSearchList parses the output of `security list-keychains`, one quoted keychain path per line.
+func parseKeychainSearchList(output string) []string {
+	var keychains []string
+	for _, line := range strings.Split(output, "\n") {
+		if keychain := strings.Trim(line, "\" \t"); keychain != "" {
+			keychains = append(keychains, keychain)
+		}
+	}
+	return keychains
+}
+
+// isSameKeychain compares keychain paths with and without the -db suffix, as the search list lists the keychain files.
+func isSameKeychain(a, b string) bool {
+	return strings.TrimSuffix(a, "-db") == strings.TrimSuffix(b, "-db")
+}
+
+// updateKeychainSearchList rewrites the user keychain search list while holding the keychains lock,
+// so that the concurrent builds don't drop each other's keychains.
+func (s XcodebuildArchiver) updateKeychainSearchList(update func(keychains []string) []string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(filepath.Join(homeDir, keychainsLockFile))
+	if err != nil {
+		return fmt.Errorf("failed to lock keychain search list: %w", err)
+	}
+	defer unlock()
+
+	output, err := s.runSecurity("list-keychains", "-d", "user")
+	if err != nil {
+		return fmt.Errorf("failed to read keychain search list: %s", output)
+	}
+
+	args := append([]string{"list-keychains", "-d", "user", "-s"}, update(parseKeychainSearchList(output))...)
+	if output, err := s.runSecurity(args...); err != nil {
+		return fmt.Errorf("failed to set keychain search list: %s", output)
+	}
+	return nil
+}
+
+// addKeychainToSearchList makes the build keychain's certificates visible to codesign without changing the default keychain.
+func (s XcodebuildArchiver) addKeychainToSearchList(pth string) error {
+	return s.updateKeychainSearchList(func(keychains []string) []string {
+		for _, keychain := range keychains {
+			if isSameKeychain(keychain, pth) {
+				return keychains
+			}
+		}
+		return append(keychains, pth)
+	})
+}
+
+func (s XcodebuildArchiver) removeKeychainFromSearchList(pth string) error {
+	return s.updateKeychainSearchList(func(keychains []string) []string {
+		var kept []string
+		for _, keychain := range keychains {
+			if !isSameKeychain(keychain, pth) {
+				kept = append(kept, keychain)
+			}
+		}
+		return kept
+	})
+}
+
+// lockFile takes an exclusive lock of the file, waiting for the other builds holding it.
+// The returned function releases the lock.
+func lockFile(pth string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(pth, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		_ = file.Close()
+	}, nil
+}
+
+// lockProfiles locks the provisioning profile directories while the code signing assets are installed.
+// Profiles are not isolated between the builds, every build sees every installed profile;
+// they are named by their UUID, so the builds don't overwrite each other's profiles, only their writes are serialized.
+func (s XcodebuildArchiver) lockProfiles() (func(), error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	pth := filepath.Join(homeDir, profilesLockFile)
+	s.logger.Debugf("Locking provisioning profiles: %s", pth)
+	return lockFile(pth)
+}
@@ -0,0 +1,75 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isolatedKeychainPath(t *testing.T) {
+	require.Equal(t, "/tmp/steps-xcode-archive-a1b2c3.keychain", isolatedKeychainPath("/tmp", "a1b2c3"))
+	require.Equal(t, "/tmp/steps-xcode-archive-"+strconv.Itoa(os.Getpid())+".keychain", isolatedKeychainPath("/tmp", ""))
+}
+
+func Test_randomKeychainPassword(t *testing.T) {
+	first, err := randomKeychainPassword()
+	require.NoError(t, err)
+	second, err := randomKeychainPassword()
+	require.NoError(t, err)
+	require.Len(t, first, 32)
+	require.NotEqual(t, first, second)
+}
+
+func Test_lockFile(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "locks", "profiles.lock")
+
+	unlock, err := lockFile(pth)
+	require.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlockSecond, err := lockFile(pth)
+		require.NoError(t, err)
+		unlockSecond()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the lock is taken twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lock is not released")
+	}
+}
+
+func Test_isSetDefaultKeychainArgs(t *testing.T) {
+	require.True(t, isSetDefaultKeychainArgs([]string{"-v", "default-keychain", "-s", "/tmp/build.keychain"}))
+	require.False(t, isSetDefaultKeychainArgs([]string{"default-keychain", "-d", "user"}))
+	require.False(t, isSetDefaultKeychainArgs([]string{"list-keychains", "-s", "/tmp/build.keychain"}))
+}
+
+func Test_parseKeychainSearchList(t *testing.T) {
+	output := `    "/Users/vagrant/Library/Keychains/login.keychain-db"
+    "/tmp/steps-xcode-archive-a1b2c3.keychain-db"
+`
+	require.Equal(t, []string{
+		"/Users/vagrant/Library/Keychains/login.keychain-db",
+		"/tmp/steps-xcode-archive-a1b2c3.keychain-db",
+	}, parseKeychainSearchList(output))
+	require.Empty(t, parseKeychainSearchList(""))
+}
+
+func Test_isSameKeychain(t *testing.T) {
+	require.True(t, isSameKeychain("/tmp/build.keychain-db", "/tmp/build.keychain"))
+	require.False(t, isSameKeychain("/tmp/other.keychain-db", "/tmp/build.keychain"))
+}
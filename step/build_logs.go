@@ -0,0 +1,126 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1command "github.com/bitrise-io/go-utils/command"
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+)
+
+const (
+	generateLinkMapBuildSetting = "LD_GENERATE_MAP_FILE"
+	activityLogExtension        = ".xcactivitylog"
+	linkMapFileMarker           = "-LinkMap-"
+
+	activityLogsDirName = "ActivityLogs"
+	linkMapsDirName     = "LinkMaps"
+)
+
+// buildLogsBuildSettings returns the build settings making the linker write the link maps.
+func buildLogsBuildSettings(exportBuildLogs bool) []string {
+	if !exportBuildLogs {
+		return nil
+	}
+	return []string{generateLinkMapBuildSetting + "=YES"}
+}
+
+// derivedDataPathFromBuildSettings returns the DerivedData directory of the project,
+// based on the BUILD_DIR build setting (<DerivedData>/Build/Products).
+func derivedDataPathFromBuildSettings(buildDir string) string {
+	if buildDir == "" {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(buildDir))
+}
+
+func (s XcodebuildArchiver) projectDerivedDataPath(xcodeProj *xcodeproj.XcodeProj, target, configuration string, customOptions []string) (string, error) {
+	settings, err := s.buildSettingsProvider.TargetBuildSettings(xcodeProj, target, configuration, customOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build settings: %s", err)
+	}
+	buildDir, err := settings.String("BUILD_DIR")
+	if err != nil {
+		return "", fmt.Errorf("failed to read BUILD_DIR build setting: %s", err)
+	}
+	return derivedDataPathFromBuildSettings(buildDir), nil
+}
+
+// findBuildLogs returns the activity logs and link maps written into the DerivedData directory since the given time.
+func findBuildLogs(derivedDataPath string, since time.Time) ([]string, []string, error) {
+	var activityLogs []string
+	activityLogsDir := filepath.Join(derivedDataPath, "Logs", "Build")
+	if exist, err := v1pathutil.IsDirExists(activityLogsDir); err != nil {
+		return nil, nil, err
+	} else if exist {
+		entries, err := os.ReadDir(activityLogsDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			if filepath.Ext(entry.Name()) != activityLogExtension {
+				continue
+			}
+			if info, err := entry.Info(); err == nil && !info.ModTime().Before(since) {
+				activityLogs = append(activityLogs, filepath.Join(activityLogsDir, entry.Name()))
+			}
+		}
+	}
+
+	var linkMaps []string
+	intermediatesDir := filepath.Join(derivedDataPath, "Build", "Intermediates.noindex")
+	if exist, err := v1pathutil.IsDirExists(intermediatesDir); err != nil {
+		return nil, nil, err
+	} else if exist {
+		if err := filepath.Walk(intermediatesDir, func(pth string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.Contains(info.Name(), linkMapFileMarker) && filepath.Ext(pth) == ".txt" && !info.ModTime().Before(since) {
+				linkMaps = append(linkMaps, pth)
+			}
+			return nil
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return activityLogs, linkMaps, nil
+}
+
+// collectBuildLogs copies the activity logs and link maps of the archive action into a temporary directory.
+func (s XcodebuildArchiver) collectBuildLogs(derivedDataPath string, since time.Time) (string, error) {
+	activityLogs, linkMaps, err := findBuildLogs(derivedDataPath, since)
+	if err != nil {
+		return "", fmt.Errorf("failed to search for build logs in %s: %s", derivedDataPath, err)
+	}
+
+	s.logger.Printf("Found %d activity logs and %d link maps.", len(activityLogs), len(linkMaps))
+	if len(activityLogs) == 0 && len(linkMaps) == 0 {
+		return "", nil
+	}
+
+	tmpDir, err := v1pathutil.NormalizedOSTempDirPath("__build_logs__")
+	if err != nil {
+		return "", fmt.Errorf("failed to create tmp dir, error: %s", err)
+	}
+	buildLogsDir := filepath.Join(tmpDir, "build-logs")
+
+	for dirName, files := range map[string][]string{activityLogsDirName: activityLogs, linkMapsDirName: linkMaps} {
+		dir := filepath.Join(buildLogsDir, dirName)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		for _, pth := range files {
+			if err := v1command.CopyFile(pth, filepath.Join(dir, filepath.Base(pth))); err != nil {
+				return "", fmt.Errorf("failed to copy (%s) to (%s): %s", pth, dir, err)
+			}
+		}
+	}
+
+	return buildLogsDir, nil
+}
@@ -0,0 +1,44 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_derivedDataPathFromBuildSettings(t *testing.T) {
+	require.Equal(t, "/Users/vagrant/Library/Developer/Xcode/DerivedData/App-abc", derivedDataPathFromBuildSettings("/Users/vagrant/Library/Developer/Xcode/DerivedData/App-abc/Build/Products"))
+	require.Equal(t, "", derivedDataPathFromBuildSettings(""))
+}
+
+func Test_findBuildLogs(t *testing.T) {
+	derivedDataPath := t.TempDir()
+	since := time.Now().Add(-time.Minute)
+
+	writeFile := func(pth string, modTime time.Time) string {
+		pth = filepath.Join(derivedDataPath, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+		require.NoError(t, os.WriteFile(pth, []byte("content"), 0644))
+		require.NoError(t, os.Chtimes(pth, modTime, modTime))
+		return pth
+	}
+
+	activityLog := writeFile("Logs/Build/E1.xcactivitylog", time.Now())
+	writeFile("Logs/Build/E0.xcactivitylog", since.Add(-time.Hour))
+	writeFile("Logs/Build/LogStoreManifest.plist", time.Now())
+	linkMap := writeFile("Build/Intermediates.noindex/ArchiveIntermediates/App/IntermediateBuildFilesPath/App.build/Release-iphoneos/App.build/App-LinkMap-normal-arm64.txt", time.Now())
+	writeFile("Build/Intermediates.noindex/ArchiveIntermediates/App/IntermediateBuildFilesPath/App.build/Release-iphoneos/App.build/App.LinkFileList", time.Now())
+
+	activityLogs, linkMaps, err := findBuildLogs(derivedDataPath, since)
+	require.NoError(t, err)
+	require.Equal(t, []string{activityLog}, activityLogs)
+	require.Equal(t, []string{linkMap}, linkMaps)
+}
+
+func Test_buildLogsBuildSettings(t *testing.T) {
+	require.Equal(t, []string{"LD_GENERATE_MAP_FILE=YES"}, buildLogsBuildSettings(true))
+	require.Nil(t, buildLogsBuildSettings(false))
+}
@@ -0,0 +1,74 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+)
+
+const (
+	// Build number modes
+	buildNumberModeNone      = "none"
+	buildNumberModeSet       = "set"
+	buildNumberModeIncrement = "increment"
+
+	// Build number tools
+	buildNumberToolBuildSettings = "build_settings"
+	buildNumberToolAgvtool       = "agvtool"
+
+	currentProjectVersionBuildSetting = "CURRENT_PROJECT_VERSION"
+)
+
+type buildNumberOpts struct {
+	Mode        string
+	BuildNumber string
+	Tool        string
+
+	XcodeProj *xcodeproj.XcodeProj
+	DryRun    bool
+}
+
+// updateBuildNumber sets or increments the build number (CFBundleVersion) before archiving.
+// With the build_settings tool the project files are left untouched and the returned build setting
+// (CURRENT_PROJECT_VERSION) needs to be passed to the archive command,
+// the agvtool tool updates the project files in place.
+// Only agvtool increments the build number, as the incremented build number has to be saved into the project files
+// (the input validation rejects the increment mode with the build_settings tool).
+func updateBuildNumber(opts buildNumberOpts, cmdFactory command.Factory, logger log.Logger) ([]string, error) {
+	if opts.Mode == buildNumberModeNone || opts.Mode == "" {
+		return nil, nil
+	}
+
+	switch opts.Tool {
+	case buildNumberToolAgvtool:
+		args := []string{"new-version", "-all", opts.BuildNumber}
+		if opts.Mode == buildNumberModeIncrement {
+			args = []string{"next-version", "-all"}
+		}
+
+		cmd := cmdFactory.Create("agvtool", args, &command.Opts{Dir: filepath.Dir(opts.XcodeProj.Path)})
+		if opts.DryRun {
+			logger.Printf("Dry run, skipping: $ %s", cmd.PrintableCommandArgs())
+			return nil, nil
+		}
+		logger.Printf("$ %s", cmd.PrintableCommandArgs())
+		if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			return nil, fmt.Errorf("agvtool failed: %s, output: %s", err, out)
+		}
+
+		return nil, nil
+	case buildNumberToolBuildSettings:
+		if opts.Mode == buildNumberModeIncrement {
+			return nil, fmt.Errorf("the %s build number tool can't increment the build number, use %s", buildNumberToolBuildSettings, buildNumberToolAgvtool)
+		}
+
+		logger.Printf("Build number: %s", opts.BuildNumber)
+
+		return []string{currentProjectVersionBuildSetting + "=" + opts.BuildNumber}, nil
+	default:
+		return nil, fmt.Errorf("unknown build number tool: %s", opts.Tool)
+	}
+}
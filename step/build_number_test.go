@@ -0,0 +1,33 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_updateBuildNumber_dryRunSkipsAgvtool(t *testing.T) {
+	cmdFactory := command.NewFactory(env.NewRepository())
+
+	options, err := updateBuildNumber(buildNumberOpts{
+		Mode:        buildNumberModeSet,
+		BuildNumber: "42",
+		Tool:        buildNumberToolAgvtool,
+		XcodeProj:   &xcodeproj.XcodeProj{Path: "/not/existing/MyApp.xcodeproj"},
+		DryRun:      true,
+	}, cmdFactory, log.NewLogger())
+	require.NoError(t, err)
+	require.Nil(t, options)
+}
+
+func Test_updateBuildNumber_buildSettingsIncrement(t *testing.T) {
+	_, err := updateBuildNumber(buildNumberOpts{
+		Mode: buildNumberModeIncrement,
+		Tool: buildNumberToolBuildSettings,
+	}, command.NewFactory(env.NewRepository()), log.NewLogger())
+	require.Error(t, err)
+}
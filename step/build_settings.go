@@ -0,0 +1,29 @@
+package step
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// buildSettingRegexp matches KEY=VALUE lines, including conditional assignments like OTHER_SWIFT_FLAGS[sdk=iphoneos*]=-D DEVICE.
+var buildSettingRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\[[^\]]+\])*)\s*=(.*)$`)
+
+// parseBuildSettings parses the KEY=VALUE lines into xcodebuild build setting arguments.
+func parseBuildSettings(content string) ([]string, error) {
+	var settings []string
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := buildSettingRegexp.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("line %d (%s) is not a valid KEY=VALUE build setting", i+1, line)
+		}
+
+		settings = append(settings, match[1]+"="+strings.TrimSpace(match[2]))
+	}
+	return settings, nil
+}
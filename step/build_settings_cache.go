@@ -0,0 +1,38 @@
+package step
+
+import (
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/xcodeproject/serialized"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+)
+
+// CachedTargetBuildSettingsProvider memoizes the target build settings within a Step run,
+// as every `xcodebuild -showBuildSettings` call can take minutes on large workspaces.
+type CachedTargetBuildSettingsProvider struct {
+	provider TargetBuildSettingsProvider
+	settings map[string]serialized.Object
+}
+
+// NewCachedTargetBuildSettingsProvider ...
+func NewCachedTargetBuildSettingsProvider(provider TargetBuildSettingsProvider) *CachedTargetBuildSettingsProvider {
+	return &CachedTargetBuildSettingsProvider{
+		provider: provider,
+		settings: map[string]serialized.Object{},
+	}
+}
+
+// TargetBuildSettings returns the cached build settings, or reads them with the underlying provider.
+func (p *CachedTargetBuildSettingsProvider) TargetBuildSettings(xcodeProj *xcodeproj.XcodeProj, target, configuration string, customOptions ...string) (serialized.Object, error) {
+	key := strings.Join(append([]string{xcodeProj.Path, target, configuration}, customOptions...), "\x00")
+	if settings, ok := p.settings[key]; ok {
+		return settings, nil
+	}
+
+	settings, err := p.provider.TargetBuildSettings(xcodeProj, target, configuration, customOptions...)
+	if err != nil {
+		return nil, err
+	}
+	p.settings[key] = settings
+	return settings, nil
+}
@@ -0,0 +1,51 @@
+package step
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/xcodeproject/serialized"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedTargetBuildSettingsProvider_TargetBuildSettings(t *testing.T) {
+	xcodeProj := &xcodeproj.XcodeProj{Path: "/project/MyApp.xcodeproj"}
+	releaseSettings := serialized.Object{"SDKROOT": "iphoneos", "CONFIGURATION": "Release"}
+	debugSettings := serialized.Object{"SDKROOT": "iphoneos", "CONFIGURATION": "Debug"}
+
+	provider := &MockTargetBuildSettingsProvider{}
+	provider.On("TargetBuildSettings", xcodeProj, "MyApp", "Release").Return(releaseSettings, nil).Once()
+	provider.On("TargetBuildSettings", xcodeProj, "MyApp", "Debug").Return(debugSettings, nil).Once()
+
+	cachedProvider := NewCachedTargetBuildSettingsProvider(provider)
+	for i := 0; i < 2; i++ {
+		settings, err := cachedProvider.TargetBuildSettings(xcodeProj, "MyApp", "Release")
+		require.NoError(t, err)
+		require.Equal(t, releaseSettings, settings)
+	}
+
+	settings, err := cachedProvider.TargetBuildSettings(xcodeProj, "MyApp", "Debug")
+	require.NoError(t, err)
+	require.Equal(t, debugSettings, settings)
+
+	provider.AssertExpectations(t)
+	provider.AssertNumberOfCalls(t, "TargetBuildSettings", 2)
+}
+
+func TestCachedTargetBuildSettingsProvider_TargetBuildSettings_errorNotCached(t *testing.T) {
+	xcodeProj := &xcodeproj.XcodeProj{Path: "/project/MyApp.xcodeproj"}
+
+	provider := &MockTargetBuildSettingsProvider{}
+	provider.On("TargetBuildSettings", mock.Anything, mock.Anything, mock.Anything).Return(serialized.Object(nil), errors.New("xcodebuild failed")).Once()
+	provider.On("TargetBuildSettings", mock.Anything, mock.Anything, mock.Anything).Return(serialized.Object{"SDKROOT": "iphoneos"}, nil).Once()
+
+	cachedProvider := NewCachedTargetBuildSettingsProvider(provider)
+	_, err := cachedProvider.TargetBuildSettings(xcodeProj, "MyApp", "Release")
+	require.Error(t, err)
+
+	settings, err := cachedProvider.TargetBuildSettings(xcodeProj, "MyApp", "Release")
+	require.NoError(t, err)
+	require.Equal(t, serialized.Object{"SDKROOT": "iphoneos"}, settings)
+}
@@ -0,0 +1,53 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseBuildSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "settings with spaces and comments",
+			content: "# comment\nCURRENT_PROJECT_VERSION = 42\n\nOTHER_SWIFT_FLAGS=$(inherited) -D BETA\n",
+			want:    []string{"CURRENT_PROJECT_VERSION=42", "OTHER_SWIFT_FLAGS=$(inherited) -D BETA"},
+		},
+		{
+			name:    "conditional setting",
+			content: "ONLY_ACTIVE_ARCH[config=Debug][sdk=*]=YES",
+			want:    []string{"ONLY_ACTIVE_ARCH[config=Debug][sdk=*]=YES"},
+		},
+		{
+			name:    "missing value separator",
+			content: "CURRENT_PROJECT_VERSION",
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			content: "-allowProvisioningUpdates=YES",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBuildSettings(tt.content)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
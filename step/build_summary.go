@@ -0,0 +1,235 @@
+package step
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	// buildSummaryReportName is the name of the build summary's directory in the HTML report dir and the annotation context
+	buildSummaryReportName = "xcode-archive"
+	// profileExpiryWarningDays is the remaining validity below which a provisioning profile is highlighted in the summary
+	profileExpiryWarningDays = 30
+)
+
+type buildSummaryProfile struct {
+	BundleID       string
+	Name           string
+	ExportType     string
+	ExpirationDate time.Time
+}
+
+// buildSummary is the short overview of the Step's result, published on the build page.
+type buildSummary struct {
+	AppName       string
+	BundleID      string
+	Version       string
+	Build         string
+	Scheme        string
+	SigningMethod string
+	ExportMethod  string
+	Profiles      []buildSummaryProfile
+	// IPASize is the size of the exported .ipa in bytes, 0 if no IPA was exported
+	IPASize            int64
+	AppDSYMCount       int
+	FrameworkDSYMCount int
+	// MinProfileValidityDays is the minimum remaining validity of the provisioning profiles (min_profile_validity)
+	MinProfileValidityDays int
+}
+
+// summaryProfiles returns the provisioning profiles of the bundle IDs, ordered by the bundle IDs.
+func summaryProfiles(profilesByBundleID map[string]profileutil.ProvisioningProfileInfoModel) []buildSummaryProfile {
+	var profiles []buildSummaryProfile
+	for bundleID, profile := range profilesByBundleID {
+		profiles = append(profiles, buildSummaryProfile{
+			BundleID:       bundleID,
+			Name:           profile.Name,
+			ExportType:     string(profile.ExportType),
+			ExpirationDate: profile.ExpirationDate,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].BundleID < profiles[j].BundleID
+	})
+	return profiles
+}
+
+func newBuildSummary(scheme string, archive xcarchive.IosArchive) buildSummary {
+	appName, _ := archive.Application.InfoPlist.GetString("CFBundleDisplayName")
+	if appName == "" {
+		appName, _ = archive.Application.InfoPlist.GetString("CFBundleName")
+	}
+
+	signingMethod := "manual"
+	if archive.IsXcodeManaged() {
+		signingMethod = "Xcode managed"
+	}
+
+	a := NewArchive(archive)
+	return buildSummary{
+		AppName:       appName,
+		BundleID:      archive.Application.BundleIdentifier(),
+		Version:       a.Version(),
+		Build:         a.BuildNumber(),
+		Scheme:        scheme,
+		SigningMethod: signingMethod,
+		Profiles:      summaryProfiles(archive.BundleIDProfileInfoMap()),
+	}
+}
+
+func (s buildSummary) ipaSize() string {
+	if s.IPASize == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f MB", float64(s.IPASize)/bytesInMB)
+}
+
+func (s buildSummary) exportMethod() string {
+	if s.ExportMethod == "" {
+		return "-"
+	}
+	return s.ExportMethod
+}
+
+func (p buildSummaryProfile) remainingDays(now time.Time) int {
+	return int(p.ExpirationDate.Sub(now).Hours() / 24)
+}
+
+func (p buildSummaryProfile) expiry(now time.Time, minValidityDays int) string {
+	days := p.remainingDays(now)
+	expiry := fmt.Sprintf("%s (%d days)", p.ExpirationDate.Format("2006-01-02"), days)
+	if days < minValidityDays {
+		expiry += fmt.Sprintf(" ⛔ below the minimum validity (%d days)", minValidityDays)
+	} else if days < profileExpiryWarningDays {
+		expiry += " ⚠️"
+	}
+	return expiry
+}
+
+// Markdown renders the summary as a Markdown document, used as the build annotation.
+func (s buildSummary) Markdown(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s %s (%s)\n\n", s.AppName, s.Version, s.Build)
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Bundle ID | `%s` |\n", s.BundleID)
+	fmt.Fprintf(&b, "| Scheme | %s |\n", s.Scheme)
+	fmt.Fprintf(&b, "| Signing | %s, %s |\n", s.SigningMethod, s.exportMethod())
+	fmt.Fprintf(&b, "| IPA size | %s |\n", s.ipaSize())
+	fmt.Fprintf(&b, "| dSYMs | %d app, %d framework |\n", s.AppDSYMCount, s.FrameworkDSYMCount)
+
+	if len(s.Profiles) > 0 {
+		fmt.Fprintf(&b, "\n| Bundle ID | Provisioning profile | Type | Expires |\n|---|---|---|---|\n")
+		for _, profile := range s.Profiles {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", profile.BundleID, profile.Name, profile.ExportType, profile.expiry(now, s.MinProfileValidityDays))
+		}
+	}
+
+	return b.String()
+}
+
+var buildSummaryHTMLTemplate = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Summary.AppName}} {{.Summary.Version}} ({{.Summary.Build}})</title>
+<style>
+body { font-family: -apple-system, Helvetica, sans-serif; margin: 24px; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ddd; padding: 6px 12px; text-align: left; }
+</style>
+</head>
+<body>
+<h2>{{.Summary.AppName}} {{.Summary.Version}} ({{.Summary.Build}})</h2>
+<table>
+<tr><th>Bundle ID</th><td>{{.Summary.BundleID}}</td></tr>
+<tr><th>Scheme</th><td>{{.Summary.Scheme}}</td></tr>
+<tr><th>Signing</th><td>{{.Summary.SigningMethod}}, {{.ExportMethod}}</td></tr>
+<tr><th>IPA size</th><td>{{.IPASize}}</td></tr>
+<tr><th>dSYMs</th><td>{{.Summary.AppDSYMCount}} app, {{.Summary.FrameworkDSYMCount}} framework</td></tr>
+</table>
+{{if .Profiles}}<table>
+<tr><th>Bundle ID</th><th>Provisioning profile</th><th>Type</th><th>Expires</th></tr>
+{{range .Profiles}}<tr><td>{{.BundleID}}</td><td>{{.Name}}</td><td>{{.ExportType}}</td><td>{{.Expiry}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// HTML renders the summary as a standalone HTML page, used as the build's HTML report.
+func (s buildSummary) HTML(now time.Time) (string, error) {
+	type profileRow struct {
+		buildSummaryProfile
+		Expiry string
+	}
+	var profiles []profileRow
+	for _, profile := range s.Profiles {
+		profiles = append(profiles, profileRow{buildSummaryProfile: profile, Expiry: profile.expiry(now, s.MinProfileValidityDays)})
+	}
+
+	var b bytes.Buffer
+	if err := buildSummaryHTMLTemplate.Execute(&b, map[string]interface{}{
+		"Summary":      s,
+		"ExportMethod": s.exportMethod(),
+		"IPASize":      s.ipaSize(),
+		"Profiles":     profiles,
+	}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// publishBuildSummary writes the summary to the HTML report dir (if set) and adds it as a build annotation.
+func (s XcodebuildArchiver) publishBuildSummary(summary buildSummary, htmlReportDir string) error {
+	now := time.Now()
+
+	if htmlReportDir != "" {
+		content, err := summary.HTML(now)
+		if err != nil {
+			return fmt.Errorf("failed to render HTML summary: %s", err)
+		}
+
+		reportDir := filepath.Join(htmlReportDir, buildSummaryReportName)
+		if err := os.MkdirAll(reportDir, 0755); err != nil {
+			return fmt.Errorf("failed to create report dir (%s): %s", reportDir, err)
+		}
+		reportPath := filepath.Join(reportDir, "index.html")
+		if err := os.WriteFile(reportPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write HTML summary: %s", err)
+		}
+		s.logger.Donef("The build summary is available in the HTML report: %s", reportPath)
+	}
+
+	if annotated, err := s.annotateBuild(summary.Markdown(now), "info", buildSummaryReportName); err != nil {
+		return err
+	} else if annotated {
+		s.logger.Donef("The build summary is added to the build page.")
+	}
+
+	return nil
+}
+
+// annotateBuild adds a Markdown annotation with the given style (info, warning or error) to the build page.
+// Annotations with the same context replace each other. Returns false if the Bitrise CLI is not available.
+func (s XcodebuildArchiver) annotateBuild(markdown, style, context string) (bool, error) {
+	if _, err := exec.LookPath("bitrise"); err != nil {
+		s.logger.Debugf("Bitrise CLI not found, skipping the build annotation")
+		return false, nil
+	}
+
+	args := []string{":annotations", "annotate", markdown, "--style", style, "--context", context}
+	cmd := s.cmdFactory.Create("bitrise", args, nil)
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to annotate the build: %s, output: %s", err, out)
+	}
+	return true, nil
+}
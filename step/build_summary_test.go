@@ -0,0 +1,63 @@
+package step
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testBuildSummary() buildSummary {
+	return buildSummary{
+		AppName:       "Sample <App>",
+		BundleID:      "io.bitrise.sample",
+		Version:       "1.2.0",
+		Build:         "42",
+		Scheme:        "Sample",
+		SigningMethod: "manual",
+		ExportMethod:  "app-store",
+		Profiles: []buildSummaryProfile{
+			{BundleID: "io.bitrise.sample", Name: "Sample App Store", ExportType: "app-store", ExpirationDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+			{BundleID: "io.bitrise.sample.widget", Name: "Sample Widget App Store", ExportType: "app-store", ExpirationDate: time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)},
+		},
+		IPASize:            25 * bytesInMB,
+		AppDSYMCount:       1,
+		FrameworkDSYMCount: 3,
+	}
+}
+
+func Test_buildSummary_Markdown(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	want := "### Sample <App> 1.2.0 (42)\n\n" +
+		"| | |\n|---|---|\n" +
+		"| Bundle ID | `io.bitrise.sample` |\n" +
+		"| Scheme | Sample |\n" +
+		"| Signing | manual, app-store |\n" +
+		"| IPA size | 25.0 MB |\n" +
+		"| dSYMs | 1 app, 3 framework |\n" +
+		"\n| Bundle ID | Provisioning profile | Type | Expires |\n|---|---|---|---|\n" +
+		"| `io.bitrise.sample` | Sample App Store | app-store | 2024-12-31 (244 days) |\n" +
+		"| `io.bitrise.sample.widget` | Sample Widget App Store | app-store | 2024-05-11 (10 days) ⚠️ |\n"
+	require.Equal(t, want, testBuildSummary().Markdown(now))
+}
+
+func Test_buildSummary_HTML(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	html, err := testBuildSummary().HTML(now)
+	require.NoError(t, err)
+	require.Contains(t, html, "<h2>Sample &lt;App&gt; 1.2.0 (42)</h2>")
+	require.Contains(t, html, "<tr><th>IPA size</th><td>25.0 MB</td></tr>")
+	require.Contains(t, html, "<td>io.bitrise.sample.widget</td><td>Sample Widget App Store</td><td>app-store</td><td>2024-05-11 (10 days) ⚠️</td>")
+}
+
+func Test_buildSummaryProfile_expiry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	profile := buildSummaryProfile{ExpirationDate: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)}
+
+	require.Equal(t, "2024-06-15 (45 days)", profile.expiry(now, 0))
+	require.Equal(t, "2024-06-15 (45 days) ⛔ below the minimum validity (60 days)", profile.expiry(now, 60))
+	require.Equal(t, []buildSummaryProfile{profile}, profilesBelowMinValidity([]buildSummaryProfile{profile}, 60, now))
+	require.Empty(t, profilesBelowMinValidity([]buildSummaryProfile{profile}, 30, now))
+}
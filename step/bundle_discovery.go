@@ -0,0 +1,133 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+// findBundles returns the bundles (directories with the ext extension) of the subdir of the dir, ordered by path.
+// Unlike filepath.Glob, the subdir and the extension are matched case-insensitively (PlugIns and Plugins
+// are the same directory on the default APFS volumes, but not on case-sensitive ones) and symlinks are followed.
+func findBundles(dir, subdir, ext string) ([]string, error) {
+	bundlesDir, err := findDirCaseInsensitive(dir, subdir)
+	if err != nil || bundlesDir == "" {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(bundlesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []string
+	for _, entry := range entries {
+		if !strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+			continue
+		}
+		pth := filepath.Join(bundlesDir, entry.Name())
+		if info, err := os.Stat(pth); err != nil || !info.IsDir() {
+			continue
+		}
+		bundles = append(bundles, pth)
+	}
+	sort.Strings(bundles)
+	return bundles, nil
+}
+
+// findDirCaseInsensitive returns the path of the dir's child directory, which name matches the name case-insensitively,
+// empty if it is not found. An exact match is preferred.
+func findDirCaseInsensitive(dir, name string) (string, error) {
+	if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+		return filepath.Join(dir, name), nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Name(), name) {
+			continue
+		}
+		pth := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(pth); err == nil && info.IsDir() {
+			return pth, nil
+		}
+	}
+	return "", nil
+}
+
+// completeArchiveBundles looks up the extensions, watch app and App Clip of the main application, which
+// xcarchive.NewIosArchive missed, as it discovers them by case-sensitive glob patterns.
+func completeArchiveBundles(archive xcarchive.IosArchive) (xcarchive.IosArchive, error) {
+	application := &archive.Application
+
+	if len(application.Extensions) == 0 {
+		extensions, err := findExtensions(application.Path)
+		if err != nil {
+			return xcarchive.IosArchive{}, err
+		}
+		application.Extensions = extensions
+	}
+
+	if application.WatchApplication == nil {
+		watchApps, err := findBundles(application.Path, "Watch", ".app")
+		if err != nil {
+			return xcarchive.IosArchive{}, err
+		}
+		if len(watchApps) > 0 {
+			watchApp, err := xcarchive.NewIosWatchApplication(watchApps[0])
+			if err != nil {
+				return xcarchive.IosArchive{}, err
+			}
+			application.WatchApplication = &watchApp
+		}
+	}
+	if watchApp := application.WatchApplication; watchApp != nil && len(watchApp.Extensions) == 0 {
+		extensions, err := findExtensions(watchApp.Path)
+		if err != nil {
+			return xcarchive.IosArchive{}, err
+		}
+		watchApp.Extensions = extensions
+	}
+
+	if application.ClipApplication == nil {
+		clipApps, err := findBundles(application.Path, "AppClips", ".app")
+		if err != nil {
+			return xcarchive.IosArchive{}, err
+		}
+		if len(clipApps) > 0 {
+			clipApp, err := xcarchive.NewIosClipApplication(clipApps[0])
+			if err != nil {
+				return xcarchive.IosArchive{}, err
+			}
+			application.ClipApplication = &clipApp
+		}
+	}
+
+	return archive, nil
+}
+
+func findExtensions(bundlePath string) ([]xcarchive.IosExtension, error) {
+	pths, err := findBundles(bundlePath, "PlugIns", ".appex")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for extensions in %s: %s", bundlePath, err)
+	}
+
+	var extensions []xcarchive.IosExtension
+	for _, pth := range pths {
+		extension, err := xcarchive.NewIosExtension(pth)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, extension)
+	}
+	return extensions, nil
+}
@@ -0,0 +1,45 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findBundles(t *testing.T) {
+	appPath := filepath.Join(t.TempDir(), "Sample App.app")
+	pluginsDir := filepath.Join(appPath, "Plugins")
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginsDir, "Widget.appex"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginsDir, "Share.APPEX"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "File.appex"), nil, 0644))
+
+	linkedExtension := filepath.Join(t.TempDir(), "Intents.appex")
+	require.NoError(t, os.MkdirAll(linkedExtension, 0755))
+	require.NoError(t, os.Symlink(linkedExtension, filepath.Join(pluginsDir, "Intents.appex")))
+
+	bundles, err := findBundles(appPath, "PlugIns", ".appex")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(pluginsDir, "Intents.appex"),
+		filepath.Join(pluginsDir, "Share.APPEX"),
+		filepath.Join(pluginsDir, "Widget.appex"),
+	}, bundles)
+
+	linkedFrameworksDir := filepath.Join(t.TempDir(), "Frameworks")
+	require.NoError(t, os.MkdirAll(filepath.Join(linkedFrameworksDir, "Sample.framework"), 0755))
+	require.NoError(t, os.Symlink(linkedFrameworksDir, filepath.Join(appPath, "Frameworks")))
+
+	bundles, err = findBundles(appPath, "Frameworks", ".framework")
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(appPath, "Frameworks", "Sample.framework")}, bundles)
+
+	bundles, err = findBundles(appPath, "Watch", ".app")
+	require.NoError(t, err)
+	require.Empty(t, bundles)
+
+	bundles, err = findBundles(filepath.Join(appPath, "Missing.app"), "PlugIns", ".appex")
+	require.NoError(t, err)
+	require.Empty(t, bundles)
+}
@@ -0,0 +1,96 @@
+package step
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	checksumsNone    = "none"
+	checksumsOutputs = "outputs"
+	checksumsFile    = "file"
+
+	checksumsFileName = "checksums.txt"
+)
+
+// exportedArtifact is a file artifact deployed to the output directory.
+type exportedArtifact struct {
+	Path string
+	// Type is the kind of the artifact (ipa, xcarchive-zip, dsym), listed in the exported files manifest
+	Type         string
+	ExportMethod string
+	// Variant distinguishes the artifacts of the same type, for example the thinned variants of the .ipa
+	Variant string
+	// Scheme and Configuration identify the archived entry in a multi-scheme run
+	Scheme        string
+	Configuration string
+	// ChecksumEnvKey is the Environment Variable of the artifact's SHA-256 checksum, empty if only the checksums file lists it
+	ChecksumEnvKey string
+	// SignatureEnvKey is the Environment Variable of the artifact's detached signature, empty if the artifact is not signed
+	SignatureEnvKey string
+}
+
+func sha256OfFile(pth string) (string, error) {
+	f, err := os.Open(pth)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checksumsFileContent returns the checksums in the format of the `shasum -a 256` command,
+// so the artifacts can be verified with `shasum -a 256 -c checksums.txt`.
+func checksumsFileContent(artifacts []exportedArtifact, checksums []string) string {
+	var lines []string
+	for i, artifact := range artifacts {
+		lines = append(lines, fmt.Sprintf("%s  %s", checksums[i], filepath.Base(artifact.Path)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (s XcodebuildArchiver) exportChecksums(artifacts []exportedArtifact, mode, outputDir string, outputPath func(string) (string, error)) error {
+	var checksums []string
+	for _, artifact := range artifacts {
+		checksum, err := sha256OfFile(artifact.Path)
+		if err != nil {
+			return fmt.Errorf("failed to calculate checksum of %s: %s", artifact.Path, err)
+		}
+		checksums = append(checksums, checksum)
+
+		if artifact.ChecksumEnvKey == "" {
+			continue
+		}
+		if err := exportEnvironmentWithEnvman(s.cmdFactory, artifact.ChecksumEnvKey, checksum); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", artifact.ChecksumEnvKey, err)
+		}
+		s.logger.Donef("The SHA-256 checksum of %s is now available in the Environment Variable: %s (value: %s)", filepath.Base(artifact.Path), artifact.ChecksumEnvKey, checksum)
+	}
+
+	if mode != checksumsFile {
+		return nil
+	}
+
+	checksumsPath, err := outputPath(filepath.Join(outputDir, checksumsFileName))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, checksumsFileContent(artifacts, checksums), checksumsPath, bitriseChecksumsPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseChecksumsPthEnvKey, err)
+	}
+	s.logger.Donef("The checksums file path is now available in the Environment Variable: %s (value: %s)", bitriseChecksumsPthEnvKey, checksumsPath)
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sha256OfFile(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "App.ipa")
+	require.NoError(t, os.WriteFile(pth, []byte("hello"), 0644))
+
+	checksum, err := sha256OfFile(pth)
+	require.NoError(t, err)
+	require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", checksum)
+}
+
+func Test_checksumsFileContent(t *testing.T) {
+	artifacts := []exportedArtifact{
+		{Path: "/deploy/App.xcarchive.zip", ChecksumEnvKey: bitriseXCArchiveZipSHA256EnvKey},
+		{Path: "/deploy/App.ipa", ChecksumEnvKey: bitriseIPASHA256EnvKey},
+	}
+	require.Equal(t, "aaa  App.xcarchive.zip\nbbb  App.ipa\n", checksumsFileContent(artifacts, []string{"aaa", "bbb"}))
+}
@@ -0,0 +1,139 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/projectmanager"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+)
+
+// codeSigningMode is the code signing asset management detected by the detect automatic code signing method.
+type codeSigningMode string
+
+const (
+	// codeSigningModeXcode is the xcodebuild managed signing (-allowProvisioningUpdates)
+	codeSigningModeXcode codeSigningMode = "xcodebuild managed (cloud) signing"
+	// codeSigningModeBitrise is the Bitrise-managed automatic code signing
+	codeSigningModeBitrise codeSigningMode = "Bitrise-managed code signing"
+	// codeSigningModeManual is the manual signing with the installed certificates and profiles
+	codeSigningModeManual codeSigningMode = "manual signing"
+)
+
+// codeSigningProjectState is the signing state of the project and of the machine, the code signing mode is detected from.
+type codeSigningProjectState struct {
+	// ManagedSigning is true if Automatically manage signing is enabled in Xcode for the main target
+	ManagedSigning bool
+	// ProfilesInstalled is true if an installed profile signs the main target with the export method
+	ProfilesInstalled bool
+}
+
+// selectCodeSigningMode returns the code signing mode, the automatic code signing method realizing it and the reason of the choice.
+// The installed profiles of manual signing projects are preferred over the Apple Service connection,
+// so no asset is generated on the Apple Developer Portal if the project can be signed locally.
+func selectCodeSigningMode(config Config, connection *devportalservice.AppleDeveloperConnection, state codeSigningProjectState) (codeSigningMode, string, string) {
+	apiKey := apiKeyConnectionAvailable(config.Inputs, connection)
+	appleID := (connection != nil && connection.AppleIDConnection != nil) || appleIDConnectionOverride(config.Inputs) != nil
+
+	switch {
+	case !state.ManagedSigning && state.ProfilesInstalled:
+		return codeSigningModeManual, codeSignSourceOff, "Automatically manage signing is disabled in Xcode and the installed provisioning profiles sign the project"
+	case state.ManagedSigning && apiKey && config.XcodeMajorVersion >= 13 && config.MinDaysProfileValid == 0 && !config.ReadOnlyAppStoreConnect:
+		return codeSigningModeXcode, codeSignSourceAPIKey, "Automatically manage signing is enabled in Xcode and an App Store Connect API key is available"
+	case apiKey:
+		return codeSigningModeBitrise, codeSignSourceAPIKey, "an App Store Connect API key is available"
+	case appleID:
+		return codeSigningModeBitrise, codeSignSourceAppleID, "an Apple ID connection is available"
+	default:
+		return codeSigningModeManual, codeSignSourceOff, "no Apple Service connection is available"
+	}
+}
+
+// shouldConsiderXcodeSigning returns true if the code signing manager may use xcodebuild managed signing.
+// A detected Bitrise-managed code signing mode enforces the Bitrise-managed signing, so the detected mode is the one applied.
+// xcodebuild (-allowProvisioningUpdates) may register devices and generate profiles, which the read-only mode forbids.
+func shouldConsiderXcodeSigning(config Config) bool {
+	return !config.ReadOnlyAppStoreConnect && config.codeSigningMode != codeSigningModeBitrise
+}
+
+// detectCodeSigningMode analyzes the project's signing style, the installed profiles and the Apple Service connection,
+// and returns the detected code signing mode and the automatic code signing method realizing it.
+func (s XcodebuildArchiveConfigParser) detectCodeSigningMode(config Config, connection *devportalservice.AppleDeveloperConnection) (codeSigningMode, string, error) {
+	project, err := projectmanager.NewProject(projectmanager.InitParams{
+		ProjectOrWorkspacePath: config.ProjectPath,
+		SchemeName:             config.Scheme,
+		ConfigurationName:      config.Configuration,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	var state codeSigningProjectState
+	if state.ManagedSigning, err = project.IsSigningManagedAutomatically(); err != nil {
+		return "", "", err
+	}
+	if !state.ManagedSigning {
+		if state.ProfilesInstalled, err = s.profilesInstalled(config, project); err != nil {
+			s.logger.Warnf("Failed to check the installed provisioning profiles: %s", err)
+		}
+	}
+
+	mode, source, reason := selectCodeSigningMode(config, connection, state)
+	s.logger.Printf("Code signing mode: %s", mode)
+	s.logger.Printf("Reason: %s", reason)
+	if mode == codeSigningModeManual && !state.ProfilesInstalled {
+		s.logger.Warnf("No installed provisioning profile signs the project, the archive may fail")
+	}
+	return mode, source, nil
+}
+
+// profilesInstalled returns true if an installed profile signs the project's main target with the export method.
+func (s XcodebuildArchiveConfigParser) profilesInstalled(config Config, project projectmanager.Project) (bool, error) {
+	bundleID, err := project.MainTargetBundleID()
+	if err != nil {
+		return false, err
+	}
+	platform, err := project.Platform()
+	if err != nil {
+		return false, err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return false, err
+	}
+	profiles, err := listInstalledProfiles(homeDir, s.logger)
+	if err != nil {
+		return false, err
+	}
+
+	macCatalyst := isMacCatalystBuild(config.Destination, config.XcodebuildAdditionalOptions)
+	minExpiration := time.Now().AddDate(0, 0, config.MinDaysProfileValid)
+	for _, profile := range profiles {
+		info := profile.Info
+		if autocodesign.DistributionType(info.ExportType) != autocodesign.DistributionType(config.ExportMethod) || !minExpiration.Before(info.ExpirationDate) {
+			continue
+		}
+		if !hasMatchingProfilePlatform(profile.Platforms, platform, macCatalyst) {
+			continue
+		}
+		if hasMatchingProfileBundleID(info.BundleID, bundleID, macCatalyst) || matchesAnyEntitlementValue([]string{info.BundleID}, bundleID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveCodeSigningMode replaces the detect automatic code signing method with the method of the detected code signing mode,
+// the returned mode is empty for the other methods.
+func (s XcodebuildArchiveConfigParser) resolveCodeSigningMode(config Config, connection *devportalservice.AppleDeveloperConnection) (string, codeSigningMode, error) {
+	if config.CodeSigningAuthSource != codeSignSourceDetect {
+		return config.CodeSigningAuthSource, "", nil
+	}
+	mode, source, err := s.detectCodeSigningMode(config, connection)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to detect the code signing mode: %w", err)
+	}
+	return source, mode, nil
+}
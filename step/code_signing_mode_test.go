@@ -0,0 +1,76 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectCodeSigningMode(t *testing.T) {
+	apiKeyConnection := &devportalservice.AppleDeveloperConnection{APIKeyConnection: &devportalservice.APIKeyConnection{}}
+	appleIDConnection := &devportalservice.AppleDeveloperConnection{AppleIDConnection: &devportalservice.AppleIDConnection{}}
+
+	tests := []struct {
+		name       string
+		config     Config
+		connection *devportalservice.AppleDeveloperConnection
+		state      codeSigningProjectState
+		wantMode   codeSigningMode
+		wantSource string
+	}{
+		{
+			name:       "manual signing project with installed profiles",
+			config:     Config{XcodeMajorVersion: 15},
+			connection: apiKeyConnection,
+			state:      codeSigningProjectState{ProfilesInstalled: true},
+			wantMode:   codeSigningModeManual,
+			wantSource: codeSignSourceOff,
+		},
+		{
+			name:       "managed signing project with API key",
+			config:     Config{XcodeMajorVersion: 15},
+			connection: apiKeyConnection,
+			state:      codeSigningProjectState{ManagedSigning: true},
+			wantMode:   codeSigningModeXcode,
+			wantSource: codeSignSourceAPIKey,
+		},
+		{
+			name:       "managed signing project with minimum profile validity",
+			config:     Config{XcodeMajorVersion: 15, Inputs: Inputs{MinDaysProfileValid: 30}},
+			connection: apiKeyConnection,
+			state:      codeSigningProjectState{ManagedSigning: true},
+			wantMode:   codeSigningModeBitrise,
+			wantSource: codeSignSourceAPIKey,
+		},
+		{
+			name:       "manual signing project without installed profiles",
+			config:     Config{XcodeMajorVersion: 15},
+			connection: appleIDConnection,
+			wantMode:   codeSigningModeBitrise,
+			wantSource: codeSignSourceAppleID,
+		},
+		{
+			name:       "no connection",
+			config:     Config{XcodeMajorVersion: 15},
+			state:      codeSigningProjectState{ManagedSigning: true},
+			wantMode:   codeSigningModeManual,
+			wantSource: codeSignSourceOff,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, source, reason := selectCodeSigningMode(tt.config, tt.connection, tt.state)
+			require.Equal(t, tt.wantMode, mode)
+			require.Equal(t, tt.wantSource, source)
+			require.NotEmpty(t, reason)
+		})
+	}
+}
+
+func Test_shouldConsiderXcodeSigning(t *testing.T) {
+	require.True(t, shouldConsiderXcodeSigning(Config{}))
+	require.True(t, shouldConsiderXcodeSigning(Config{codeSigningMode: codeSigningModeXcode}))
+	require.False(t, shouldConsiderXcodeSigning(Config{codeSigningMode: codeSigningModeBitrise}))
+	require.False(t, shouldConsiderXcodeSigning(Config{Inputs: Inputs{ReadOnlyAppStoreConnect: true}}))
+}
@@ -0,0 +1,116 @@
+package step
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/errorfinder"
+	"github.com/bitrise-io/go-xcode/v2/xcodecommand"
+	version "github.com/hashicorp/go-version"
+)
+
+// CustomFormatterRunner is an xcodebuild runner that pipes the xcodebuild output into an external formatter command.
+// The formatter reads the xcodebuild output on its stdin, the raw output is kept for the exported logs.
+type CustomFormatterRunner struct {
+	formatterArgs  []string
+	logger         log.Logger
+	commandFactory command.Factory
+}
+
+// NewCustomFormatterRunner returns a new xcodebuild runner using the given formatter command and its arguments.
+func NewCustomFormatterRunner(formatterArgs []string, logger log.Logger, commandFactory command.Factory) xcodecommand.Runner {
+	return &CustomFormatterRunner{
+		formatterArgs:  formatterArgs,
+		logger:         logger,
+		commandFactory: commandFactory,
+	}
+}
+
+// Run runs xcodebuild using the custom formatter command as an output formatter
+func (c *CustomFormatterRunner) Run(workDir string, xcodebuildArgs []string, formatterArgs []string) (xcodecommand.Output, error) {
+	var (
+		buildOutBuffer         bytes.Buffer
+		pipeReader, pipeWriter = io.Pipe()
+		buildOutWriter         = io.MultiWriter(&buildOutBuffer, pipeWriter)
+		unbufferedIOEnv        = []string{"NSUnbufferedIO=YES"}
+	)
+
+	buildCmd := c.commandFactory.Create("xcodebuild", xcodebuildArgs, &command.Opts{
+		Stdout:      buildOutWriter,
+		Stderr:      buildOutWriter,
+		Env:         unbufferedIOEnv,
+		Dir:         workDir,
+		ErrorFinder: errorfinder.FindXcodebuildErrors,
+	})
+
+	args := append(append([]string{}, c.formatterArgs[1:]...), formatterArgs...)
+	formatterCmd := c.commandFactory.Create(c.formatterArgs[0], args, &command.Opts{
+		Stdin:  pipeReader,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Env:    unbufferedIOEnv,
+	})
+
+	c.logger.TPrintf("$ set -o pipefail && %s | %s", buildCmd.PrintableCommandArgs(), formatterCmd.PrintableCommandArgs())
+
+	// the formatter failure does not fail the build, the raw xcodebuild log is exported anyway
+	formatterStartErr := formatterCmd.Start()
+	formatterDone := make(chan error, 1)
+	go func() {
+		err := formatterStartErr
+		if err == nil {
+			err = formatterCmd.Wait()
+		}
+		// once the formatter exited (or failed to start) nothing reads the pipe,
+		// the rest of the output is discarded, so that xcodebuild doesn't block writing it
+		_, _ = io.Copy(io.Discard, pipeReader)
+		formatterDone <- err
+	}()
+
+	err := buildCmd.Start()
+	if err == nil {
+		err = buildCmd.Wait()
+	}
+
+	if closeErr := pipeWriter.Close(); closeErr != nil {
+		c.logger.Warnf("Failed to close xcodebuild-formatter pipe: %s", closeErr)
+	}
+	if formatterErr := <-formatterDone; formatterErr != nil {
+		c.logger.Warnf("Log formatter command failed: %s", formatterErr)
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+
+		var exerr *exec.ExitError
+		if errors.As(err, &exerr) {
+			exitCode = exerr.ExitCode()
+		}
+	}
+
+	return xcodecommand.Output{
+		RawOut:   buildOutBuffer.Bytes(),
+		ExitCode: exitCode,
+	}, err
+}
+
+// CheckInstall checks if the formatter command is available, the version of an arbitrary command is not known.
+func (c *CustomFormatterRunner) CheckInstall() (*version.Version, error) {
+	c.logger.Println()
+	c.logger.Infof("Checking log formatter (%s)", c.formatterArgs[0])
+
+	pth, err := exec.LookPath(c.formatterArgs[0])
+	if err != nil {
+		return nil, fmt.Errorf("log formatter command (%s) not found: %w", c.formatterArgs[0], err)
+	}
+	c.logger.Printf("- log formatter path: %s", pth)
+
+	return nil, nil
+}
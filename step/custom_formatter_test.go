@@ -0,0 +1,54 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFormatterRunner_CheckInstall(t *testing.T) {
+	cmdFactory := command.NewFactory(env.NewRepository())
+
+	runner := NewCustomFormatterRunner([]string{"cat", "-u"}, log.NewLogger(), cmdFactory)
+	formatterVersion, err := runner.CheckInstall()
+	require.NoError(t, err)
+	require.Nil(t, formatterVersion)
+
+	runner = NewCustomFormatterRunner([]string{"non-existing-formatter"}, log.NewLogger(), cmdFactory)
+	_, err = runner.CheckInstall()
+	require.Error(t, err)
+}
+
+func TestCustomFormatterRunner_Run_formatterExitsEarly(t *testing.T) {
+	binDir := t.TempDir()
+	xcodebuild := "#!/bin/sh\nfor i in $(seq 1 200000); do echo \"line $i\"; done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "xcodebuild"), []byte(xcodebuild), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	runner := NewCustomFormatterRunner([]string{"head", "-n", "1"}, log.NewLogger(), command.NewFactory(env.NewRepository()))
+
+	type result struct {
+		rawOut string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := runner.Run("", []string{"archive"}, nil)
+		done <- result{rawOut: string(output.RawOut), err: err}
+	}()
+
+	select {
+	case res := <-done:
+		require.NoError(t, res.err)
+		require.True(t, strings.HasSuffix(strings.TrimSpace(res.rawOut), "line 200000"))
+	case <-time.After(30 * time.Second):
+		t.Fatal("xcodebuild blocked on the output of the exited formatter")
+	}
+}
@@ -0,0 +1,62 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+
+	stepcache "github.com/bitrise-io/go-steputils/cache"
+)
+
+const (
+	cacheLevelSwiftPackages = "swift_packages"
+	cacheLevelDerivedData   = "derived_data"
+)
+
+// derivedDataOptions returns the xcodebuild options for building into the given DerivedData directory.
+func derivedDataOptions(derivedDataPath string) []string {
+	if derivedDataPath == "" {
+		return nil
+	}
+	return []string{"-derivedDataPath", derivedDataPath}
+}
+
+// derivedDataSwiftPackagesPath returns the directory the Swift packages are checked out to, when using a custom DerivedData path.
+func derivedDataSwiftPackagesPath(derivedDataPath string) string {
+	return filepath.Join(derivedDataPath, "SourcePackages")
+}
+
+// derivedDataCacheExcludes returns the DerivedData contents, which change in every build and are not needed for an incremental build.
+func derivedDataCacheExcludes(derivedDataPath string) []string {
+	return []string{
+		"!" + filepath.Join(derivedDataPath, "Logs"),
+		"!" + filepath.Join(derivedDataPath, "info.plist"),
+		// Excluding manifest.db will result in a stable cache, as this file is modified in every build.
+		"!" + filepath.Join(derivedDataSwiftPackagesPath(derivedDataPath), "manifest.db"),
+	}
+}
+
+// collectSwiftPackages marks the Swift packages checked out into the custom DerivedData directory to be added to the cache.
+func collectSwiftPackages(derivedDataPath string) error {
+	swiftPackagesPath := derivedDataSwiftPackagesPath(derivedDataPath)
+
+	cache := stepcache.New()
+	cache.IncludePath(swiftPackagesPath)
+	cache.ExcludePath("!" + filepath.Join(swiftPackagesPath, "manifest.db"))
+
+	if err := cache.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache, error: %s", err)
+	}
+	return nil
+}
+
+// collectDerivedData marks the DerivedData directory to be added to the cache.
+func collectDerivedData(derivedDataPath string) error {
+	cache := stepcache.New()
+	cache.IncludePath(derivedDataPath)
+	cache.ExcludePath(derivedDataCacheExcludes(derivedDataPath)...)
+
+	if err := cache.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache, error: %s", err)
+	}
+	return nil
+}
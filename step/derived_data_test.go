@@ -0,0 +1,21 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_derivedDataOptions(t *testing.T) {
+	require.Nil(t, derivedDataOptions(""))
+	require.Equal(t, []string{"-derivedDataPath", "/tmp/DerivedData"}, derivedDataOptions("/tmp/DerivedData"))
+}
+
+func Test_derivedDataCacheExcludes(t *testing.T) {
+	want := []string{
+		"!/tmp/DerivedData/Logs",
+		"!/tmp/DerivedData/info.plist",
+		"!/tmp/DerivedData/SourcePackages/manifest.db",
+	}
+	require.Equal(t, want, derivedDataCacheExcludes("/tmp/DerivedData"))
+}
@@ -0,0 +1,105 @@
+package step
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+const requiredDeviceCapabilitiesKey = "UIRequiredDeviceCapabilities"
+
+// deviceExcludingCapabilities are the capabilities, which make the app unavailable for a group of devices.
+var deviceExcludingCapabilities = map[string]string{
+	"telephony":                           "iPads and iPod touch devices",
+	"sms":                                 "iPads and iPod touch devices",
+	"nfc":                                 "iPads, iPod touch devices and iPhones older than iPhone 7",
+	"gps":                                 "Wi-Fi only iPads and iPod touch devices",
+	"arkit":                               "devices with an A8 or older chip",
+	"iphone-ipad-minimum-performance-a12": "devices with an A11 or older chip",
+}
+
+// requiredDeviceCapabilities returns the capabilities of the UIRequiredDeviceCapabilities, which is either
+// an array of the required capabilities or a dictionary of the required (true) and prohibited (false) capabilities.
+func requiredDeviceCapabilities(infoPlist plistutil.PlistData) []string {
+	if capabilities, ok := infoPlist.GetStringArray(requiredDeviceCapabilitiesKey); ok {
+		return capabilities
+	}
+
+	capabilitiesByName, ok := infoPlist.GetMapStringInterface(requiredDeviceCapabilitiesKey)
+	if !ok {
+		return nil
+	}
+	var capabilities []string
+	for name := range capabilitiesByName {
+		if required, ok := capabilitiesByName.GetBool(name); ok && required {
+			capabilities = append(capabilities, name)
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// deviceCapabilityIssues compares the required capabilities with the device architectures of the main executable
+// and the deployment target. It returns the combinations rejected by App Store Connect and the ones excluding devices.
+func deviceCapabilityIssues(capabilities, archs []string, minOS uint32) []string {
+	var issues []string
+
+	minOS32BitUnsupported, _ := parseMachOVersion("11.0")
+	hasArmv7 := sliceutil.IsStringInSlice("armv7", archs)
+	requiresArm64 := sliceutil.IsStringInSlice("arm64", capabilities)
+
+	if sliceutil.IsStringInSlice("armv7", capabilities) && !hasArmv7 {
+		issues = append(issues, fmt.Sprintf("%s contains armv7, but the binary has no armv7 slice (architectures: %s): App Store Connect rejects the upload", requiredDeviceCapabilitiesKey, strings.Join(archs, ", ")))
+	}
+	if requiresArm64 && hasArmv7 {
+		issues = append(issues, fmt.Sprintf("%s contains arm64, which excludes the 32-bit devices the armv7 slice is built for", requiredDeviceCapabilitiesKey))
+	}
+	if !requiresArm64 && !hasArmv7 && len(archs) > 0 && minOS < minOS32BitUnsupported {
+		issues = append(issues, fmt.Sprintf("the binary has only 64-bit slices and the deployment target (%s) supports 32-bit devices, but %s doesn't contain arm64: App Store Connect rejects the upload", formatMachOVersion(minOS), requiredDeviceCapabilitiesKey))
+	}
+
+	for _, capability := range capabilities {
+		if devices, ok := deviceExcludingCapabilities[capability]; ok {
+			issues = append(issues, fmt.Sprintf("%s contains %s, which makes the app unavailable for %s", requiredDeviceCapabilitiesKey, capability, devices))
+		}
+	}
+	return issues
+}
+
+// checkDeviceCapabilities warns about the UIRequiredDeviceCapabilities of the archived iOS app,
+// which don't fit the binary or silently exclude devices.
+func (s XcodebuildArchiver) checkDeviceCapabilities(archive Archive) {
+	if archive.Platform() != "iphoneos" {
+		return
+	}
+
+	minOS, err := parseMachOVersion(archive.MinimumOSVersion())
+	if err != nil {
+		s.logger.Debugf("Failed to parse the app's MinimumOSVersion, skipping the device capabilities check: %s", err)
+		return
+	}
+
+	executable, ok := bundleExecutable(archive.Application.Path, archive.Application.InfoPlist)
+	if !ok {
+		return
+	}
+	slices, err := readBinarySlices(executable)
+	if err != nil {
+		s.logger.Warnf("Failed to read Mach-O binary (%s): %s", executable, err)
+		return
+	}
+
+	var archs []string
+	for _, slice := range slices {
+		if !slice.IsSimulator() && !sliceutil.IsStringInSlice(slice.Arch, archs) {
+			archs = append(archs, slice.Arch)
+		}
+	}
+
+	for _, issue := range deviceCapabilityIssues(requiredDeviceCapabilities(archive.Application.InfoPlist), archs, minOS) {
+		s.logger.Warnf("%s", issue)
+	}
+}
@@ -0,0 +1,68 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_requiredDeviceCapabilities(t *testing.T) {
+	require.Equal(t, []string{"arm64", "telephony"}, requiredDeviceCapabilities(plistutil.PlistData{
+		requiredDeviceCapabilitiesKey: []interface{}{"arm64", "telephony"},
+	}))
+	require.Equal(t, []string{"arm64", "metal"}, requiredDeviceCapabilities(plistutil.PlistData{
+		requiredDeviceCapabilitiesKey: map[string]interface{}{"metal": true, "arm64": true, "telephony": false},
+	}))
+	require.Nil(t, requiredDeviceCapabilities(plistutil.PlistData{}))
+}
+
+func Test_deviceCapabilityIssues(t *testing.T) {
+	iOS10, err := parseMachOVersion("10.0")
+	require.NoError(t, err)
+	iOS15, err := parseMachOVersion("15.0")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		capabilities []string
+		archs        []string
+		minOS        uint32
+		want         []string
+	}{
+		{
+			name:         "arm64 app",
+			capabilities: []string{"arm64"},
+			archs:        []string{"arm64"},
+			minOS:        iOS15,
+		},
+		{
+			name:         "armv7 capability without armv7 slice",
+			capabilities: []string{"armv7", "telephony"},
+			archs:        []string{"arm64"},
+			minOS:        iOS15,
+			want: []string{
+				"UIRequiredDeviceCapabilities contains armv7, but the binary has no armv7 slice (architectures: arm64): App Store Connect rejects the upload",
+				"UIRequiredDeviceCapabilities contains telephony, which makes the app unavailable for iPads and iPod touch devices",
+			},
+		},
+		{
+			name:         "arm64 capability with armv7 slice",
+			capabilities: []string{"arm64"},
+			archs:        []string{"armv7", "arm64"},
+			minOS:        iOS10,
+			want:         []string{"UIRequiredDeviceCapabilities contains arm64, which excludes the 32-bit devices the armv7 slice is built for"},
+		},
+		{
+			name:  "64-bit only binary supporting 32-bit devices",
+			archs: []string{"arm64"},
+			minOS: iOS10,
+			want:  []string{"the binary has only 64-bit slices and the deployment target (10.0) supports 32-bit devices, but UIRequiredDeviceCapabilities doesn't contain arm64: App Store Connect rejects the upload"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, deviceCapabilityIssues(tt.capabilities, tt.archs, tt.minOS))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-xcode/v2/exportoptionsgenerator"
+	"github.com/bitrise-io/go-xcode/xcodebuild"
+)
+
+// printPlannedIPAExport prints the export options and the xcodebuild command the IPA export would use.
+// The archive's provisioning profiles are not known before archiving,
+// so the export options are generated from the export info read from the project.
+func (s XcodebuildArchiver) printPlannedIPAExport(opts xcodeIPAExportOpts, archivePath string, archiveInfo exportoptionsgenerator.ArchiveInfo) error {
+	s.logger.Println()
+	s.logger.Infof("Planned export options:")
+
+	exportOptionsContent := opts.CustomExportOptionsPlistContent
+	if exportOptionsContent == "" {
+		exportOptions, err := s.generateExportOptions(opts, archiveInfo, "", opts.XcodeAuthOptions != nil)
+		if err != nil {
+			return err
+		}
+		if exportOptionsContent, err = exportOptions.String(); err != nil {
+			return fmt.Errorf("failed to serialize export options: %w", err)
+		}
+	}
+	s.logger.Printf("%s", exportOptionsContent)
+
+	exportCmd := xcodebuild.NewExportCommand()
+	exportCmd.SetArchivePath(archivePath)
+	exportCmd.SetExportDir(filepath.Join(filepath.Dir(archivePath), "exported"))
+	exportCmd.SetExportOptionsPlist(filepath.Join(filepath.Dir(archivePath), "export_options.plist"))
+	if opts.XcodeAuthOptions != nil {
+		exportCmd.SetAuthentication(*opts.XcodeAuthOptions)
+	}
+
+	s.logger.Println()
+	s.logger.Printf("Planned xcodebuild command: %s", printableCommand("xcodebuild", exportCmd.CommandArgs(), s.sensitiveValues))
+	s.logger.Println()
+	s.logger.Donef("Dry run finished, no archive was created")
+	return nil
+}
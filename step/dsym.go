@@ -0,0 +1,243 @@
+package step
+
+import (
+	"debug/macho"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+// loadCmdUUID is the LC_UUID load command, which holds the UUID matching the binary with its dSYM.
+const loadCmdUUID = 0x1b
+
+// DSYMUUID maps an architecture slice of a dSYM's DWARF binary to its UUID, which crash reports refer to.
+type DSYMUUID struct {
+	UUID string `json:"uuid"`
+	Arch string `json:"arch"`
+	DSYM string `json:"dsym"`
+}
+
+// machOUUID returns the formatted UUID of the LC_UUID load command of the binary, empty if it is missing.
+func machOUUID(f *macho.File) string {
+	for _, load := range f.Loads {
+		raw := load.Raw()
+		if len(raw) < 24 || f.ByteOrder.Uint32(raw[0:4]) != loadCmdUUID {
+			continue
+		}
+		u := raw[8:24]
+		return strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]))
+	}
+	return ""
+}
+
+// readDSYMUUIDs returns the UUIDs of every architecture slice of the DWARF binaries in the dSYM.
+func readDSYMUUIDs(dsymPath string) ([]DSYMUUID, error) {
+	binaries, err := filepath.Glob(filepath.Join(escapeGlobPath(dsymPath), "Contents", "Resources", "DWARF", "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var uuids []DSYMUUID
+	for _, binary := range binaries {
+		if err := forEachMachOSlice(binary, func(f *macho.File) {
+			if uuid := machOUUID(f); uuid != "" {
+				uuids = append(uuids, DSYMUUID{UUID: uuid, Arch: archName(f.Cpu, f.SubCpu), DSYM: filepath.Base(dsymPath)})
+			}
+		}); err != nil {
+			return nil, fmt.Errorf("failed to read DWARF binary (%s): %s", binary, err)
+		}
+	}
+	return uuids, nil
+}
+
+// parseDSYMOnlyArchivePath returns the absolute path of the archive, which dSYMs are exported in the dSYM only mode.
+func parseDSYMOnlyArchivePath(pth string) (string, error) {
+	if pth = strings.TrimSpace(pth); pth == "" {
+		return "", nil
+	}
+	if filepath.Ext(pth) != xcarchiveExt {
+		return "", fmt.Errorf("%s is not an .xcarchive", pth)
+	}
+	return v1pathutil.AbsPath(pth)
+}
+
+type dsymExportOpts struct {
+	ExportAllDsyms bool
+	Compression    ArchiveCompression
+	OutputDir      string
+	// Name is the file name of the dSYM archive, without the extension
+	Name string
+	// UploadCommandArgs is the command called with the path of the dSYM archive, empty if the upload is disabled
+	UploadCommandArgs []string
+}
+
+type dsymExportResult struct {
+	AppDSYMCount       int
+	FrameworkDSYMCount int
+	// ZipPath is the path of the dSYM archive, empty if the archive has no dSYMs
+	ZipPath string
+}
+
+// exportDSYMs collects the app (and optionally the framework) dSYMs of the archive, exports them
+// with the UUID mapping of their binaries and calls the upload command with the dSYM archive.
+func (s XcodebuildArchiver) exportDSYMs(archive xcarchive.IosArchive, opts dsymExportOpts, outputPath func(string) (string, error)) (dsymExportResult, error) {
+	s.logger.Printf("Looking for app and framework dSYMs.")
+
+	appDSYMPaths, frameworkDSYMPaths, err := archive.FindDSYMs()
+	if err != nil {
+		return dsymExportResult{}, fmt.Errorf("failed to export dSYMs, error: %s", err)
+	}
+
+	result := dsymExportResult{AppDSYMCount: len(appDSYMPaths), FrameworkDSYMCount: len(frameworkDSYMPaths)}
+	s.logger.Printf("Found %d app dSYMs and %d framework dSYMs.", result.AppDSYMCount, result.FrameworkDSYMCount)
+
+	if result.AppDSYMCount == 0 && result.FrameworkDSYMCount == 0 {
+		return result, nil
+	}
+
+	dsymDir, err := v1pathutil.NormalizedOSTempDirPath("__dsyms__")
+	if err != nil {
+		return dsymExportResult{}, fmt.Errorf("failed to create tmp dir, error: %s", err)
+	}
+
+	dsymPaths := appDSYMPaths
+	if result.AppDSYMCount > 0 {
+		if err := ExportDSYMs(dsymDir, appDSYMPaths); err != nil {
+			return dsymExportResult{}, fmt.Errorf("failed to export dSYMs: %v", err)
+		}
+	} else {
+		s.logger.Warnf("No app dSYMs found to export")
+	}
+
+	if opts.ExportAllDsyms && result.FrameworkDSYMCount > 0 {
+		if err := ExportDSYMs(dsymDir, frameworkDSYMPaths); err != nil {
+			return dsymExportResult{}, fmt.Errorf("failed to export dSYMs: %v", err)
+		}
+		dsymPaths = append(dsymPaths, frameworkDSYMPaths...)
+	}
+
+	if err := ExportOutputDir(s.cmdFactory, dsymDir, dsymDir, bitriseDSYMDirPthEnvKey, s.logger); err != nil {
+		return dsymExportResult{}, fmt.Errorf("failed to export %s, error: %s", bitriseDSYMDirPthEnvKey, err)
+	}
+	s.logger.Donef("The dSYM dir path is now available in the Environment Variable: %s (value: %s)", bitriseDSYMDirPthEnvKey, dsymDir)
+
+	dsymZipPath := filepath.Join(opts.OutputDir, opts.Name+".dSYM"+opts.Compression.Extension())
+	if dsymZipPath, err = outputPath(dsymZipPath); err != nil {
+		return dsymExportResult{}, err
+	}
+
+	if err := ExportOutputDirAsArchive(s.cmdFactory, dsymDir, dsymZipPath, bitriseDSYMPthEnvKey, opts.Compression, s.logger); err != nil {
+		return dsymExportResult{}, fmt.Errorf("failed to export %s, error: %s", bitriseDSYMPthEnvKey, err)
+	}
+	s.logger.Donef("The dSYM zip path is now available in the Environment Variable: %s (value: %s)", bitriseDSYMPthEnvKey, dsymZipPath)
+	result.ZipPath = dsymZipPath
+
+	if uuidsPath, err := outputPath(filepath.Join(opts.OutputDir, opts.Name+".dSYM-uuids.json")); err != nil {
+		s.logger.Warnf("Failed to export the dSYM UUIDs: %s", err)
+	} else if err := s.exportDSYMUUIDs(dsymPaths, uuidsPath); err != nil {
+		s.logger.Warnf("Failed to export the dSYM UUIDs: %s", err)
+	}
+
+	if len(opts.UploadCommandArgs) > 0 {
+		if err := s.uploadDSYMs(opts.UploadCommandArgs, dsymZipPath); err != nil {
+			return dsymExportResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s XcodebuildArchiver) exportDSYMUUIDs(dsymPaths []string, uuidsPath string) error {
+	uuids := []DSYMUUID{}
+	for _, dsymPath := range dsymPaths {
+		dsymUUIDs, err := readDSYMUUIDs(dsymPath)
+		if err != nil {
+			return err
+		}
+		uuids = append(uuids, dsymUUIDs...)
+	}
+	sort.SliceStable(uuids, func(i, j int) bool {
+		return uuids[i].DSYM < uuids[j].DSYM
+	})
+
+	b, err := json.MarshalIndent(uuids, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ExportOutputFileContent(s.cmdFactory, string(b), uuidsPath, bitriseDSYMUUIDsPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseDSYMUUIDsPthEnvKey, err)
+	}
+	s.logger.Donef("The dSYM UUIDs path is now available in the Environment Variable: %s (value: %s)", bitriseDSYMUUIDsPthEnvKey, uuidsPath)
+	return nil
+}
+
+// uploadDSYMs calls the upload command with the path of the dSYM archive as its last argument.
+func (s XcodebuildArchiver) uploadDSYMs(commandArgs []string, dsymZipPath string) error {
+	args := append(append([]string{}, commandArgs[1:]...), dsymZipPath)
+	cmd := s.cmdFactory.Create(commandArgs[0], args, &command.Opts{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+
+	s.logger.Println()
+	s.logger.Infof("Uploading dSYMs")
+	s.logger.Printf("$ %s", printableCommand(commandArgs[0], args, s.sensitiveValues))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to upload dSYMs: %w", err)
+	}
+	return nil
+}
+
+// DSYMOnlyOpts ...
+type DSYMOnlyOpts struct {
+	ArchivePath           string
+	OutputDir             string
+	OutputOverwritePolicy string
+	ExportAllDsyms        bool
+	DSYMArchiveFormat     string
+	CompressionLevel      int
+	DeterministicArchives bool
+	DSYMUploadCommandArgs []string
+}
+
+// ExportArchiveDSYMs collects the dSYMs of an existing archive, without building the project or exporting an IPA.
+func (s XcodebuildArchiver) ExportArchiveDSYMs(opts DSYMOnlyOpts) (err error) {
+	endPhase := s.startPhase(phaseOutputs)
+	defer func() {
+		endPhase(err)
+	}()
+
+	s.logger.Println()
+	s.logger.TInfof("Exporting the dSYMs of: %s", opts.ArchivePath)
+
+	archive, err := s.newIosArchive(opts.ArchivePath, "")
+	if err != nil {
+		return fmt.Errorf("failed to parse archive (%s): %s", opts.ArchivePath, err)
+	}
+
+	compression := ArchiveCompression{Format: opts.DSYMArchiveFormat, Level: opts.CompressionLevel, Deterministic: opts.DeterministicArchives}
+	result, err := s.exportDSYMs(archive, dsymExportOpts{
+		ExportAllDsyms:    opts.ExportAllDsyms,
+		Compression:       compression,
+		OutputDir:         opts.OutputDir,
+		Name:              fileNameSafe(strings.TrimSuffix(filepath.Base(opts.ArchivePath), xcarchiveExt)),
+		UploadCommandArgs: opts.DSYMUploadCommandArgs,
+	}, func(pth string) (string, error) {
+		return resolveOutputPath(pth, opts.OutputOverwritePolicy)
+	})
+	if err != nil {
+		return err
+	}
+	if result.ZipPath == "" {
+		s.logger.Warnf("The archive contains no dSYMs")
+	}
+	return nil
+}
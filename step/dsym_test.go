@@ -0,0 +1,49 @@
+package step
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readDSYMUUIDs(t *testing.T) {
+	uuid := []byte{0x0a, 0x1b, 0x2c, 0x3d, 0x4e, 0x5f, 0x60, 0x71, 0x82, 0x93, 0xa4, 0xb5, 0xc6, 0xd7, 0xe8, 0xf9}
+
+	b := binary.LittleEndian.AppendUint32(nil, macho.Magic64)
+	b = binary.LittleEndian.AppendUint32(b, uint32(macho.CpuArm64))
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	b = binary.LittleEndian.AppendUint32(b, 0xa) // MH_DSYM
+	b = binary.LittleEndian.AppendUint32(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, 24)
+	b = binary.LittleEndian.AppendUint32(b, 0) // flags
+	b = binary.LittleEndian.AppendUint32(b, 0) // reserved
+	b = binary.LittleEndian.AppendUint32(b, loadCmdUUID)
+	b = binary.LittleEndian.AppendUint32(b, 24)
+	b = append(b, uuid...)
+
+	dsymPath := filepath.Join(t.TempDir(), "Sample App.app.dSYM")
+	dwarfDir := filepath.Join(dsymPath, "Contents", "Resources", "DWARF")
+	require.NoError(t, os.MkdirAll(dwarfDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dwarfDir, "Sample App"), b, 0644))
+
+	uuids, err := readDSYMUUIDs(dsymPath)
+	require.NoError(t, err)
+	require.Equal(t, []DSYMUUID{{UUID: "0A1B2C3D-4E5F-6071-8293-A4B5C6D7E8F9", Arch: "arm64", DSYM: "Sample App.app.dSYM"}}, uuids)
+}
+
+func Test_parseDSYMOnlyArchivePath(t *testing.T) {
+	pth, err := parseDSYMOnlyArchivePath("")
+	require.NoError(t, err)
+	require.Empty(t, pth)
+
+	pth, err = parseDSYMOnlyArchivePath("/Users/vagrant/Sample.xcarchive")
+	require.NoError(t, err)
+	require.Equal(t, "/Users/vagrant/Sample.xcarchive", pth)
+
+	_, err = parseDSYMOnlyArchivePath("/Users/vagrant/Sample.ipa")
+	require.EqualError(t, err, "/Users/vagrant/Sample.ipa is not an .xcarchive")
+}
@@ -0,0 +1,179 @@
+package step
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/xcodeproject/serialized"
+)
+
+const (
+	iCloudContainerEnvironmentKey            = "com.apple.developer.icloud-container-environment"
+	iCloudDevelopmentContainerIdentifiersKey = "com.apple.developer.icloud-container-development-container-identifiers"
+	iCloudServicesKey                        = "com.apple.developer.icloud-services"
+	ubiquityContainerIdentifiersKey          = "com.apple.developer.ubiquity-container-identifiers"
+	ubiquityKVStoreIdentifierKey             = "com.apple.developer.ubiquity-kvstore-identifier"
+
+	iCloudDevelopmentEnvironment = "Development"
+
+	signInWithAppleKey = "com.apple.developer.applesignin"
+	appGroupsKey       = "com.apple.security.application-groups"
+
+	networkExtensionKey = "com.apple.developer.networking.networkextension"
+	// systemExtensionSuffix marks the Network Extension types of the Developer ID signed (system extension) providers
+	systemExtensionSuffix = "-systemextension"
+	vpnAPIKey             = "com.apple.developer.networking.vpn.api"
+
+	nfcReaderSessionFormatsKey = "com.apple.developer.nfc.readersession.formats"
+	nfcNDEFFormat              = "NDEF"
+	nfcTagFormat               = "TAG"
+)
+
+// entitlementComparator returns the mismatches of the app's entitlement (under key) and the profile's entitlements.
+type entitlementComparator func(key string, profileEntitlements, appEntitlements autocodesign.Entitlements) []string
+
+// entitlementComparators compare the entitlements, whose profile value differs from the app's value,
+// the other entitlements need to be equal.
+var entitlementComparators = map[string]entitlementComparator{
+	autocodesign.ICloudIdentifiersEntitlementKey: compareICloudContainers,
+	iCloudDevelopmentContainerIdentifiersKey:     compareICloudContainers,
+	ubiquityContainerIdentifiersKey:              compareICloudContainers,
+	ubiquityKVStoreIdentifierKey:                 compareEntitlementValue,
+	iCloudContainerEnvironmentKey:                compareEntitlementValue,
+	iCloudServicesKey:                            compareEntitlementValues,
+	signInWithAppleKey:                           compareEntitlementValues,
+	appGroupsKey:                                 compareEntitlementValues,
+	networkExtensionKey:                          compareEntitlementValuesWith(grantsNetworkExtension),
+	vpnAPIKey:                                    compareEntitlementValues,
+	nfcReaderSessionFormatsKey:                   compareEntitlementValuesWith(grantsNFCFormat),
+}
+
+// entitlementMismatches returns the descriptions of the app's entitlements, which the profile doesn't grant.
+func entitlementMismatches(profileEntitlements, appEntitlements autocodesign.Entitlements) []string {
+	var keys []string
+	for key := range appEntitlements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []string
+	for _, key := range keys {
+		if compare, ok := entitlementComparators[key]; ok {
+			mismatches = append(mismatches, compare(key, profileEntitlements, appEntitlements)...)
+		} else if !reflect.DeepEqual(profileEntitlements[key], appEntitlements[key]) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: the profile's value (%v) differs from the app's value (%v)", key, profileEntitlements[key], appEntitlements[key]))
+		}
+	}
+	return mismatches
+}
+
+// compareICloudContainers requires every iCloud container of the app in the profile.
+// The apps using the Development iCloud environment may use the development containers of the profile too.
+func compareICloudContainers(key string, profileEntitlements, appEntitlements autocodesign.Entitlements) []string {
+	appContainers, err := serialized.Object(appEntitlements).StringSlice(key)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: invalid app value: %s", key, err)}
+	}
+
+	profileContainers := entitlementStrings(profileEntitlements, key)
+	environment, _ := serialized.Object(appEntitlements).String(iCloudContainerEnvironmentKey)
+	if environment == iCloudDevelopmentEnvironment && key != iCloudDevelopmentContainerIdentifiersKey {
+		profileContainers = append(profileContainers, entitlementStrings(profileEntitlements, iCloudDevelopmentContainerIdentifiersKey)...)
+	}
+
+	var mismatches []string
+	for _, container := range appContainers {
+		if !matchesAnyEntitlementValue(profileContainers, container) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: the profile misses the iCloud container %s", key, container))
+		}
+	}
+	return mismatches
+}
+
+// compareEntitlementValue requires the app's value among the profile's values, which may be wildcards (for example TEAMID.*).
+func compareEntitlementValue(key string, profileEntitlements, appEntitlements autocodesign.Entitlements) []string {
+	value, err := serialized.Object(appEntitlements).String(key)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: invalid app value: %s", key, err)}
+	}
+	if !matchesAnyEntitlementValue(entitlementStrings(profileEntitlements, key), value) {
+		return []string{fmt.Sprintf("%s: the profile misses %s", key, value)}
+	}
+	return nil
+}
+
+// compareEntitlementValues requires each of the app's values among the profile's values,
+// the profile may grant more values (for example App Groups) than the app uses.
+var compareEntitlementValues = compareEntitlementValuesWith(matchesAnyEntitlementValue)
+
+// compareEntitlementValuesWith returns a comparator, which requires each of the app's values granted by the profile's values.
+func compareEntitlementValuesWith(grants func(profileValues []string, value string) bool) entitlementComparator {
+	return func(key string, profileEntitlements, appEntitlements autocodesign.Entitlements) []string {
+		values, err := serialized.Object(appEntitlements).StringSlice(key)
+		if err != nil {
+			return []string{fmt.Sprintf("%s: invalid app value: %s", key, err)}
+		}
+
+		profileValues := entitlementStrings(profileEntitlements, key)
+		var missing []string
+		for _, value := range values {
+			if !grants(profileValues, value) {
+				missing = append(missing, value)
+			}
+		}
+		if len(missing) > 0 {
+			return []string{fmt.Sprintf("%s: the profile misses %s", key, strings.Join(missing, ", "))}
+		}
+		return nil
+	}
+}
+
+// grantsNetworkExtension returns true if the profile grants the Network Extension type,
+// the Developer ID profiles list the system extension variant (for example packet-tunnel-provider-systemextension) of the types.
+func grantsNetworkExtension(profileValues []string, value string) bool {
+	value = strings.TrimSuffix(value, systemExtensionSuffix)
+	for _, profileValue := range profileValues {
+		if strings.TrimSuffix(profileValue, systemExtensionSuffix) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// grantsNFCFormat returns true if the profile grants the NFC reader session format,
+// the TAG format includes the NDEF format, so the profiles granting TAG sign the apps reading NDEF too.
+func grantsNFCFormat(profileValues []string, value string) bool {
+	if value == nfcNDEFFormat && sliceutil.IsStringInSlice(nfcTagFormat, profileValues) {
+		return true
+	}
+	return sliceutil.IsStringInSlice(value, profileValues)
+}
+
+// entitlementStrings returns the string or string array value of the entitlement.
+func entitlementStrings(entitlements autocodesign.Entitlements, key string) []string {
+	if values, err := serialized.Object(entitlements).StringSlice(key); err == nil {
+		return values
+	}
+	if value, err := serialized.Object(entitlements).String(key); err == nil {
+		return []string{value}
+	}
+	return nil
+}
+
+// matchesAnyEntitlementValue returns true if any of the profile's values matches the value,
+// the profile's values may end with a * wildcard.
+func matchesAnyEntitlementValue(profileValues []string, value string) bool {
+	for _, profileValue := range profileValues {
+		if profileValue == value {
+			return true
+		}
+		if strings.HasSuffix(profileValue, "*") && strings.HasPrefix(value, strings.TrimSuffix(profileValue, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_entitlementMismatches_iCloud(t *testing.T) {
+	profileEntitlements := autocodesign.Entitlements{
+		autocodesign.ICloudIdentifiersEntitlementKey: []interface{}{"iCloud.io.bitrise.sample"},
+		iCloudDevelopmentContainerIdentifiersKey:     []interface{}{"iCloud.io.bitrise.sample", "iCloud.io.bitrise.sample.staging"},
+		ubiquityContainerIdentifiersKey:              []interface{}{"iCloud.io.bitrise.sample"},
+		ubiquityKVStoreIdentifierKey:                 "TEAM123.*",
+		iCloudContainerEnvironmentKey:                []interface{}{"Development", "Production"},
+		iCloudServicesKey:                            "*",
+	}
+
+	tests := []struct {
+		name            string
+		appEntitlements autocodesign.Entitlements
+		want            []string
+	}{
+		{
+			name: "matching containers, key-value store and services",
+			appEntitlements: autocodesign.Entitlements{
+				autocodesign.ICloudIdentifiersEntitlementKey: []interface{}{"iCloud.io.bitrise.sample"},
+				ubiquityContainerIdentifiersKey:              []interface{}{"iCloud.io.bitrise.sample"},
+				ubiquityKVStoreIdentifierKey:                 "TEAM123.io.bitrise.sample",
+				iCloudContainerEnvironmentKey:                "Production",
+				iCloudServicesKey:                            []interface{}{"CloudKit", "CloudDocuments"},
+			},
+		},
+		{
+			name: "development container in the Development environment",
+			appEntitlements: autocodesign.Entitlements{
+				autocodesign.ICloudIdentifiersEntitlementKey: []interface{}{"iCloud.io.bitrise.sample.staging"},
+				iCloudContainerEnvironmentKey:                "Development",
+			},
+		},
+		{
+			name: "development container in the Production environment",
+			appEntitlements: autocodesign.Entitlements{
+				autocodesign.ICloudIdentifiersEntitlementKey: []interface{}{"iCloud.io.bitrise.sample", "iCloud.io.bitrise.sample.staging"},
+				iCloudContainerEnvironmentKey:                "Production",
+			},
+			want: []string{"com.apple.developer.icloud-container-identifiers: the profile misses the iCloud container iCloud.io.bitrise.sample.staging"},
+		},
+		{
+			name: "missing ubiquity container and key-value store of an other team",
+			appEntitlements: autocodesign.Entitlements{
+				ubiquityContainerIdentifiersKey: []interface{}{"iCloud.io.bitrise.other"},
+				ubiquityKVStoreIdentifierKey:    "OTHER.io.bitrise.sample",
+			},
+			want: []string{
+				"com.apple.developer.ubiquity-container-identifiers: the profile misses the iCloud container iCloud.io.bitrise.other",
+				"com.apple.developer.ubiquity-kvstore-identifier: the profile misses OTHER.io.bitrise.sample",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, entitlementMismatches(profileEntitlements, tt.appEntitlements))
+		})
+	}
+}
+
+func Test_entitlementMismatches_setEntitlements(t *testing.T) {
+	profileEntitlements := autocodesign.Entitlements{
+		signInWithAppleKey: []interface{}{"Default"},
+		appGroupsKey:       []interface{}{"group.io.bitrise.sample", "group.io.bitrise.shared"},
+	}
+
+	require.Empty(t, entitlementMismatches(profileEntitlements, autocodesign.Entitlements{
+		signInWithAppleKey: []interface{}{"Default"},
+		appGroupsKey:       []interface{}{"group.io.bitrise.shared"},
+	}))
+	require.Equal(t, []string{
+		"com.apple.developer.applesignin: the profile misses Grouped",
+		"com.apple.security.application-groups: the profile misses group.io.bitrise.other",
+	}, entitlementMismatches(profileEntitlements, autocodesign.Entitlements{
+		signInWithAppleKey: []interface{}{"Default", "Grouped"},
+		appGroupsKey:       []interface{}{"group.io.bitrise.sample", "group.io.bitrise.other"},
+	}))
+}
+
+func Test_entitlementMismatches_capabilityVariants(t *testing.T) {
+	profileEntitlements := autocodesign.Entitlements{
+		networkExtensionKey:        []interface{}{"packet-tunnel-provider-systemextension", "content-filter-provider-systemextension"},
+		nfcReaderSessionFormatsKey: []interface{}{"TAG"},
+	}
+
+	require.Empty(t, entitlementMismatches(profileEntitlements, autocodesign.Entitlements{
+		networkExtensionKey:        []interface{}{"packet-tunnel-provider"},
+		nfcReaderSessionFormatsKey: []interface{}{"NDEF", "TAG"},
+	}))
+	require.Equal(t, []string{
+		"com.apple.developer.networking.networkextension: the profile misses dns-proxy",
+		"com.apple.developer.nfc.readersession.formats: the profile misses PACE",
+	}, entitlementMismatches(profileEntitlements, autocodesign.Entitlements{
+		networkExtensionKey:        []interface{}{"content-filter-provider", "dns-proxy"},
+		nfcReaderSessionFormatsKey: []interface{}{"TAG", "PACE"},
+	}))
+}
@@ -0,0 +1,176 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// entitlementsCacheKind is the directory of the entitlements snapshots in the previous build cache.
+const entitlementsCacheKind = "entitlements"
+
+// entitlementsSnapshot maps the bundle IDs of the archive to their entitlements.
+type entitlementsSnapshot map[string]map[string]interface{}
+
+// entitlementsDrift is the capability changes of a bundle since the previous build.
+type entitlementsDrift struct {
+	BundleID string
+	Added    []string
+	Removed  []string
+	Changed  []string
+	// NewBundle and RemovedBundle are set if the whole bundle was added to or removed from the archive.
+	NewBundle     bool
+	RemovedBundle bool
+}
+
+func (d entitlementsDrift) String() string {
+	switch {
+	case d.NewBundle:
+		return fmt.Sprintf("%s: new target (%s)", d.BundleID, strings.Join(d.Added, ", "))
+	case d.RemovedBundle:
+		return fmt.Sprintf("%s: removed target (%s)", d.BundleID, strings.Join(d.Removed, ", "))
+	}
+
+	var lines []string
+	for _, key := range d.Added {
+		lines = append(lines, "  + "+key)
+	}
+	for _, key := range d.Removed {
+		lines = append(lines, "  - "+key)
+	}
+	for _, key := range d.Changed {
+		lines = append(lines, "  ~ "+key)
+	}
+	return d.BundleID + ":\n" + strings.Join(lines, "\n")
+}
+
+func (s entitlementsSnapshot) bundleIDs() []string {
+	var bundleIDs []string
+	for bundleID := range s {
+		bundleIDs = append(bundleIDs, bundleID)
+	}
+	sort.Strings(bundleIDs)
+	return bundleIDs
+}
+
+func sortedEntitlementKeys(entitlements map[string]interface{}) []string {
+	var keys []string
+	for key := range entitlements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffEntitlements returns the capability changes per bundle ID, sorted by the bundle ID.
+func diffEntitlements(previous, current entitlementsSnapshot) []entitlementsDrift {
+	var drifts []entitlementsDrift
+	for _, bundleID := range current.bundleIDs() {
+		currentEntitlements := current[bundleID]
+		previousEntitlements, ok := previous[bundleID]
+		if !ok {
+			drifts = append(drifts, entitlementsDrift{BundleID: bundleID, Added: sortedEntitlementKeys(currentEntitlements), NewBundle: true})
+			continue
+		}
+
+		drift := entitlementsDrift{BundleID: bundleID}
+		for _, key := range sortedEntitlementKeys(currentEntitlements) {
+			previousValue, ok := previousEntitlements[key]
+			if !ok {
+				drift.Added = append(drift.Added, key)
+			} else if !reflect.DeepEqual(previousValue, currentEntitlements[key]) {
+				drift.Changed = append(drift.Changed, key)
+			}
+		}
+		for _, key := range sortedEntitlementKeys(previousEntitlements) {
+			if _, ok := currentEntitlements[key]; !ok {
+				drift.Removed = append(drift.Removed, key)
+			}
+		}
+		if len(drift.Added)+len(drift.Removed)+len(drift.Changed) > 0 {
+			drifts = append(drifts, drift)
+		}
+	}
+	for _, bundleID := range previous.bundleIDs() {
+		if _, ok := current[bundleID]; !ok {
+			drifts = append(drifts, entitlementsDrift{BundleID: bundleID, Removed: sortedEntitlementKeys(previous[bundleID]), RemovedBundle: true})
+		}
+	}
+	return drifts
+}
+
+// newEntitlementsSnapshot creates the snapshot of the archive entitlements,
+// the values are normalised by a JSON round trip so they compare equal to the stored snapshot.
+func newEntitlementsSnapshot(archive Archive) (entitlementsSnapshot, error) {
+	snapshot := entitlementsSnapshot{}
+	for bundleID, entitlements := range archive.BundleIDEntitlementsMap() {
+		snapshot[bundleID] = entitlements
+	}
+
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var normalised entitlementsSnapshot
+	if err := json.Unmarshal(content, &normalised); err != nil {
+		return nil, err
+	}
+	return normalised, nil
+}
+
+func readEntitlementsSnapshot(pth string) (entitlementsSnapshot, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot entitlementsSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// compareEntitlementsWithPreviousBuild prints the capabilities added or removed since the previous build per target,
+// and stores the current entitlements for the next build.
+func (s XcodebuildArchiver) compareEntitlementsWithPreviousBuild(archive Archive, artifactName string) error {
+	cacheDir, err := previousBuildDir(entitlementsCacheKind)
+	if err != nil {
+		return err
+	}
+	previousPath := filepath.Join(cacheDir, artifactName+".json")
+
+	current, err := newEntitlementsSnapshot(archive)
+	if err != nil {
+		return fmt.Errorf("failed to read entitlements: %w", err)
+	}
+
+	if previous, err := readEntitlementsSnapshot(previousPath); os.IsNotExist(err) {
+		s.logger.Printf("No entitlements of a previous build found")
+	} else if err != nil {
+		s.logger.Warnf("Failed to read the entitlements of the previous build: %s", err)
+	} else if drifts := diffEntitlements(previous, current); len(drifts) == 0 {
+		s.logger.Printf("Entitlements are the same as in the previous build")
+	} else {
+		s.logger.Warnf("Entitlements changed since the previous build:")
+		for _, drift := range drifts {
+			s.logger.Printf("%s", drift)
+		}
+	}
+
+	content, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(previousPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to store entitlements: %w", err)
+	}
+
+	return commitPreviousBuildDir(cacheDir)
+}
@@ -0,0 +1,50 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffEntitlements(t *testing.T) {
+	previous := entitlementsSnapshot{
+		"io.bitrise.sample": {
+			"aps-environment":                        "production",
+			"com.apple.developer.associated-domains": []interface{}{"applinks:bitrise.io"},
+			"com.apple.security.application-groups":  []interface{}{"group.io.bitrise.sample"},
+		},
+		"io.bitrise.sample.widget": {
+			"com.apple.security.application-groups": []interface{}{"group.io.bitrise.sample"},
+		},
+	}
+	current := entitlementsSnapshot{
+		"io.bitrise.sample": {
+			"aps-environment":                        "production",
+			"com.apple.developer.associated-domains": []interface{}{"applinks:bitrise.io", "applinks:app.bitrise.io"},
+			"com.apple.developer.healthkit":          true,
+		},
+		"io.bitrise.sample.clip": {
+			"com.apple.developer.parent-application-identifiers": []interface{}{"ABCD1234.io.bitrise.sample"},
+		},
+	}
+
+	drifts := diffEntitlements(previous, current)
+	require.Equal(t, []entitlementsDrift{
+		{
+			BundleID: "io.bitrise.sample",
+			Added:    []string{"com.apple.developer.healthkit"},
+			Removed:  []string{"com.apple.security.application-groups"},
+			Changed:  []string{"com.apple.developer.associated-domains"},
+		},
+		{BundleID: "io.bitrise.sample.clip", Added: []string{"com.apple.developer.parent-application-identifiers"}, NewBundle: true},
+		{BundleID: "io.bitrise.sample.widget", Removed: []string{"com.apple.security.application-groups"}, RemovedBundle: true},
+	}, drifts)
+
+	require.Equal(t, `io.bitrise.sample:
+  + com.apple.developer.healthkit
+  - com.apple.security.application-groups
+  ~ com.apple.developer.associated-domains`, drifts[0].String())
+	require.Equal(t, "io.bitrise.sample.clip: new target (com.apple.developer.parent-application-identifiers)", drifts[1].String())
+
+	require.Empty(t, diffEntitlements(current, current))
+}
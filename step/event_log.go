@@ -0,0 +1,161 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+)
+
+// Step event types
+const (
+	stepEventPhaseStart = "phase_start"
+	stepEventPhaseEnd   = "phase_end"
+	stepEventWarning    = "warning"
+	stepEventError      = "error"
+)
+
+// Step phases
+const (
+	phaseCodeSigning       = "code_signing"
+	phasePackageResolution = "resolve_packages"
+	phaseArchive           = "archive"
+	phaseSimulatorBuild    = "simulator_build"
+	phaseExport            = "export"
+	phaseOutputs           = "outputs"
+	phaseArtifactSigning   = "artifact_signing"
+)
+
+// StepEvent is a machine-readable log entry of the Step.
+type StepEvent struct {
+	Time            string  `json:"time"`
+	Type            string  `json:"type"`
+	Phase           string  `json:"phase,omitempty"`
+	Message         string  `json:"message,omitempty"`
+	Success         *bool   `json:"success,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+// EventLogger is a Logger, which also writes the warnings, errors and phase changes as JSON lines,
+// so log aggregation systems can index them.
+// It is safe for concurrent use, for example by the concurrent exports of the additional distribution methods.
+type EventLogger struct {
+	log.Logger
+	writer io.Writer
+	now    func() time.Time
+
+	mu    sync.Mutex
+	phase string
+}
+
+// NewEventLogger ...
+func NewEventLogger(logger log.Logger, writer io.Writer) *EventLogger {
+	return &EventLogger{
+		Logger: logger,
+		writer: writer,
+		now:    time.Now,
+	}
+}
+
+// Warnf ...
+func (l *EventLogger) Warnf(format string, v ...interface{}) {
+	l.Logger.Warnf(format, v...)
+	l.emitInPhase(StepEvent{Type: stepEventWarning, Message: fmt.Sprintf(format, v...)})
+}
+
+// TWarnf ...
+func (l *EventLogger) TWarnf(format string, v ...interface{}) {
+	l.Logger.TWarnf(format, v...)
+	l.emitInPhase(StepEvent{Type: stepEventWarning, Message: fmt.Sprintf(format, v...)})
+}
+
+// Errorf ...
+func (l *EventLogger) Errorf(format string, v ...interface{}) {
+	l.Logger.Errorf(format, v...)
+	l.emitInPhase(StepEvent{Type: stepEventError, Message: fmt.Sprintf(format, v...)})
+}
+
+// TErrorf ...
+func (l *EventLogger) TErrorf(format string, v ...interface{}) {
+	l.Logger.TErrorf(format, v...)
+	l.emitInPhase(StepEvent{Type: stepEventError, Message: fmt.Sprintf(format, v...)})
+}
+
+// StartPhase emits a phase start event and returns the function emitting the phase end event.
+// After a top-level phase ends, it is kept as the context of the errors logged afterwards,
+// after a nested phase ends, the parent phase becomes the context again.
+func (l *EventLogger) StartPhase(phase string) func(err error) {
+	l.mu.Lock()
+	parentPhase := l.phase
+	l.phase = phase
+	l.mu.Unlock()
+	started := l.now()
+	l.emit(StepEvent{Type: stepEventPhaseStart, Phase: phase})
+
+	return func(err error) {
+		success := err == nil
+		event := StepEvent{
+			Type:            stepEventPhaseEnd,
+			Phase:           phase,
+			Success:         &success,
+			DurationSeconds: l.now().Sub(started).Seconds(),
+		}
+		if err != nil {
+			event.Message = err.Error()
+		}
+		l.emit(event)
+
+		if parentPhase != "" {
+			l.mu.Lock()
+			l.phase = parentPhase
+			l.mu.Unlock()
+		}
+	}
+}
+
+// emitInPhase emits the event in the context of the current phase.
+func (l *EventLogger) emitInPhase(event StepEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	event.Phase = l.phase
+	l.write(event)
+}
+
+func (l *EventLogger) emit(event StepEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write(event)
+}
+
+func (l *EventLogger) write(event StepEvent) {
+	event.Time = l.now().UTC().Format(time.RFC3339)
+	b, err := json.Marshal(event)
+	if err != nil {
+		l.Logger.Debugf("Failed to serialize step event: %s", err)
+		return
+	}
+	if _, err := fmt.Fprintln(l.writer, string(b)); err != nil {
+		l.Logger.Debugf("Failed to write step event: %s", err)
+	}
+}
+
+// startPhase marks the start of a Step phase in the event log and in the trace (if enabled),
+// and returns the function marking the end of the phase.
+func (s XcodebuildArchiver) startPhase(phase string) func(err error) {
+	var endPhaseFuncs []func(error)
+	if eventLogger, ok := s.logger.(*EventLogger); ok {
+		endPhaseFuncs = append(endPhaseFuncs, eventLogger.StartPhase(phase))
+	}
+	if s.tracer != nil {
+		endPhaseFuncs = append(endPhaseFuncs, s.tracer.StartSpan(phase))
+	}
+
+	return func(err error) {
+		for _, endPhase := range endPhaseFuncs {
+			endPhase(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package step
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewEventLogger(log.NewLogger(), &buf)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := start
+	logger.now = func() time.Time { return now }
+
+	archiver := XcodebuildArchiver{logger: logger}
+
+	endArchivePhase := archiver.startPhase(phaseArchive)
+	endResolvePhase := archiver.startPhase(phasePackageResolution)
+	endResolvePhase(nil)
+	logger.Warnf("Deprecated %s", "API")
+	logger.Printf("not an event")
+	now = start.Add(90 * time.Second)
+	endArchivePhase(errors.New("exit status 65"))
+	logger.Errorf("Failed to execute Step main logic")
+
+	require.Equal(t, []string{
+		`{"time":"2024-05-01T10:00:00Z","type":"phase_start","phase":"archive"}`,
+		`{"time":"2024-05-01T10:00:00Z","type":"phase_start","phase":"resolve_packages"}`,
+		`{"time":"2024-05-01T10:00:00Z","type":"phase_end","phase":"resolve_packages","success":true}`,
+		`{"time":"2024-05-01T10:00:00Z","type":"warning","phase":"archive","message":"Deprecated API"}`,
+		`{"time":"2024-05-01T10:01:30Z","type":"phase_end","phase":"archive","message":"exit status 65","success":false,"duration_seconds":90}`,
+		`{"time":"2024-05-01T10:01:30Z","type":"error","phase":"archive","message":"Failed to execute Step main logic"}`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+}
+
+func TestEventLogger_concurrent(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewEventLogger(log.NewLogger(), &buf)
+	archiver := XcodebuildArchiver{logger: logger}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			endPhase := archiver.startPhase(phaseExport)
+			logger.Warnf("export %d", i)
+			endPhase(nil)
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3*8)
+	for _, line := range lines {
+		require.True(t, strings.HasPrefix(line, "{") && strings.HasSuffix(line, "}"), line)
+	}
+}
+
+func TestXcodebuildArchiver_startPhase_withoutEventLogger(t *testing.T) {
+	archiver := XcodebuildArchiver{logger: log.NewLogger()}
+	endPhase := archiver.startPhase(phaseExport)
+	require.NotPanics(t, func() { endPhase(nil) })
+}
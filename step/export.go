@@ -2,6 +2,7 @@ package step
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -12,12 +13,43 @@ import (
 	"github.com/bitrise-io/go-utils/v2/log"
 )
 
-func zip(cmdFactory command.Factory, sourceDir, destinationZipPth string, logger log.Logger) error {
+const (
+	archiveFormatZip  = "zip"
+	archiveFormatZstd = "zstd"
+	// defaultCompressionLevel is the default compression level of the zip command
+	defaultCompressionLevel = 6
+	// deterministicTimestamp is the modification time of every archived file in deterministic mode (touch -t format),
+	// 1980-01-01 is the earliest date the zip format can store
+	deterministicTimestamp = "198001010000.00"
+)
+
+// ArchiveCompression describes how a directory is exported as a single file: the format, the compression level and the archived contents.
+type ArchiveCompression struct {
+	// Format is zip or zstd (zstd compressed tar archive)
+	Format string
+	// Level is the compression level from 0 (no compression) to 9 (best compression)
+	Level int
+	// Deterministic archives store the files in a fixed order, with normalized timestamps and without extra attributes,
+	// so archiving identical directories produces byte-identical archives
+	Deterministic bool
+	// Excludes are glob patterns (relative to the archived directory) of the contents left out from the archive
+	Excludes []string
+}
+
+// Extension returns the file extension of the archive format.
+func (c ArchiveCompression) Extension() string {
+	if c.Format == archiveFormatZstd {
+		return ".tar.zst"
+	}
+	return ".zip"
+}
+
+func zip(cmdFactory command.Factory, sourceDir, destinationZipPth string, compressionLevel int, logger log.Logger) error {
 	logger.TPrintf("Will zip directory path: %s", sourceDir)
 
 	parentDir := filepath.Dir(sourceDir)
 	dirName := filepath.Base(sourceDir)
-	cmd := cmdFactory.Create("/usr/bin/zip", []string{"-rTy", destinationZipPth, dirName}, &command.Opts{Dir: parentDir})
+	cmd := cmdFactory.Create("/usr/bin/zip", []string{"-rTy", fmt.Sprintf("-%d", compressionLevel), destinationZipPth, dirName}, &command.Opts{Dir: parentDir})
 	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to zip dir: %s, output: %s, error: %s", sourceDir, out, err)
@@ -28,6 +60,138 @@ func zip(cmdFactory command.Factory, sourceDir, destinationZipPth string, logger
 	return nil
 }
 
+// deterministicZip zips the directory entries in sorted order, without the extra file attributes (uid/gid, extended timestamps).
+func deterministicZip(cmdFactory command.Factory, sourceDir, destinationZipPth string, compressionLevel int, logger log.Logger) error {
+	logger.TPrintf("Will zip directory path deterministically: %s", sourceDir)
+
+	entries, err := archiveEntries(sourceDir)
+	if err != nil {
+		return err
+	}
+
+	parentDir := filepath.Dir(sourceDir)
+	cmd := cmdFactory.Create("/usr/bin/zip", []string{"-TyX", fmt.Sprintf("-%d", compressionLevel), "-@", destinationZipPth}, &command.Opts{
+		Dir:   parentDir,
+		Stdin: strings.NewReader(strings.Join(entries, "\n") + "\n"),
+	})
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to zip dir: %s, output: %s, error: %s", sourceDir, out, err)
+	}
+
+	logger.TPrintf("Directory zipped.")
+
+	return nil
+}
+
+// tarZstd creates a zstd compressed tar archive of the directory, using all CPU cores for the compression.
+// In deterministic mode the entries are archived in sorted order, without extended attributes and macOS metadata.
+func tarZstd(cmdFactory command.Factory, sourceDir, destinationPth string, compressionLevel int, deterministic bool, logger log.Logger) error {
+	logger.TPrintf("Will compress directory path with zstd: %s", sourceDir)
+
+	parentDir := filepath.Dir(sourceDir)
+	dirName := filepath.Base(sourceDir)
+	compressProgram := fmt.Sprintf("zstd -T0 -%d", compressionLevel)
+	args := []string{"--use-compress-program", compressProgram, "-cf", destinationPth}
+	opts := &command.Opts{Dir: parentDir}
+	if deterministic {
+		entries, err := archiveEntries(sourceDir)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--no-xattrs", "--no-recursion", "-T", "-")
+		opts.Stdin = strings.NewReader(strings.Join(entries, "\n") + "\n")
+		// Prevents macOS tar from storing the extended attributes and ACLs in AppleDouble (._*) entries
+		opts.Env = []string{"COPYFILE_DISABLE=1"}
+	} else {
+		args = append(args, dirName)
+	}
+
+	cmd := cmdFactory.Create("tar", args, opts)
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to compress dir: %s, output: %s, error: %s", sourceDir, out, err)
+	}
+
+	logger.TPrintf("Directory compressed.")
+
+	return nil
+}
+
+// archiveEntries returns the paths of the directory and its contents relative to the directory's parent, in lexical order.
+// Symlinks are not followed.
+func archiveEntries(sourceDir string) ([]string, error) {
+	parentDir := filepath.Dir(sourceDir)
+	var entries []string
+	if err := filepath.Walk(sourceDir, func(pth string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(parentDir, pth)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, rel)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list the contents of %s: %s", sourceDir, err)
+	}
+	return entries, nil
+}
+
+// stageArchivedDir copies the directory into the staging directory, removes the excluded contents
+// and optionally sets the modification time of the remaining contents (including symlinks) to a fixed date.
+// The source directory is left untouched.
+func stageArchivedDir(cmdFactory command.Factory, sourceDir, stagingDir string, excludes []string, normalizeTimestamps bool) (string, error) {
+	if err := v1command.CopyDir(sourceDir, stagingDir, false); err != nil {
+		return "", fmt.Errorf("failed to copy (%s) to (%s): %s", sourceDir, stagingDir, err)
+	}
+	stagedDir := filepath.Join(stagingDir, filepath.Base(sourceDir))
+
+	for _, pattern := range excludes {
+		matches, err := filepath.Glob(filepath.Join(escapeGlobPath(stagedDir), pattern))
+		if err != nil {
+			return "", fmt.Errorf("invalid exclude pattern (%s): %s", pattern, err)
+		}
+		for _, match := range matches {
+			if err := os.RemoveAll(match); err != nil {
+				return "", fmt.Errorf("failed to remove excluded path (%s): %s", match, err)
+			}
+		}
+	}
+
+	if normalizeTimestamps {
+		cmd := cmdFactory.Create("find", []string{stagedDir, "-exec", "touch", "-h", "-t", deterministicTimestamp, "{}", "+"}, nil)
+		if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to normalize file timestamps: %s, output: %s", err, out)
+		}
+	}
+
+	return stagedDir, nil
+}
+
+// parseArchiveExcludes parses the newline separated exclude patterns, the patterns are relative to the archived directory.
+func parseArchiveExcludes(content string) ([]string, error) {
+	var excludes []string
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := filepath.Clean(line)
+		if filepath.IsAbs(pattern) || pattern == "." || pattern == ".." || strings.HasPrefix(pattern, "../") {
+			return nil, fmt.Errorf("line %d (%s) is not a path inside the archived directory", i+1, line)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("line %d (%s) is not a valid glob pattern: %s", i+1, line, err)
+		}
+
+		excludes = append(excludes, pattern)
+	}
+	return excludes, nil
+}
+
 func exportEnvironmentWithEnvman(cmdFactory command.Factory, keyStr, valueStr string) error {
 	cmd := cmdFactory.Create("envman", []string{"add", "--key", keyStr}, &command.Opts{Stdin: strings.NewReader(valueStr)})
 	return cmd.Run()
@@ -70,19 +234,43 @@ func ExportOutputFileContent(cmdFactory command.Factory, content, destinationPth
 
 // ExportOutputDirAsZip ...
 func ExportOutputDirAsZip(cmdFactory command.Factory, sourceDirPth, destinationPth, envKey string, logger log.Logger) error {
+	return ExportOutputDirAsArchive(cmdFactory, sourceDirPth, destinationPth, envKey, ArchiveCompression{Format: archiveFormatZip, Level: defaultCompressionLevel}, logger)
+}
+
+// ExportOutputDirAsArchive ...
+func ExportOutputDirAsArchive(cmdFactory command.Factory, sourceDirPth, destinationPth, envKey string, compression ArchiveCompression, logger log.Logger) error {
 	tmpDir, err := pathutil.NormalizedOSTempDirPath("__export_tmp_dir__")
 	if err != nil {
 		return err
 	}
 
 	base := filepath.Base(sourceDirPth)
-	tmpZipFilePth := filepath.Join(tmpDir, base+".zip")
+	tmpArchiveFilePth := filepath.Join(tmpDir, base+compression.Extension())
 
-	if err := zip(cmdFactory, sourceDirPth, tmpZipFilePth, logger); err != nil {
+	archivedDirPth := sourceDirPth
+	if compression.Deterministic || len(compression.Excludes) > 0 {
+		stagingDir := filepath.Join(tmpDir, "staging")
+		defer func() {
+			_ = os.RemoveAll(stagingDir)
+		}()
+		if archivedDirPth, err = stageArchivedDir(cmdFactory, sourceDirPth, stagingDir, compression.Excludes, compression.Deterministic); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case compression.Format == archiveFormatZstd:
+		err = tarZstd(cmdFactory, archivedDirPth, tmpArchiveFilePth, compression.Level, compression.Deterministic, logger)
+	case compression.Deterministic:
+		err = deterministicZip(cmdFactory, archivedDirPth, tmpArchiveFilePth, compression.Level, logger)
+	default:
+		err = zip(cmdFactory, archivedDirPth, tmpArchiveFilePth, compression.Level, logger)
+	}
+	if err != nil {
 		return err
 	}
 
-	return ExportOutputFile(cmdFactory, tmpZipFilePth, destinationPth, envKey)
+	return ExportOutputFile(cmdFactory, tmpArchiveFilePth, destinationPth, envKey)
 }
 
 // ExportDSYMs ...
@@ -0,0 +1,137 @@
+package step
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	// Encryption usage input values
+	encryptionUsageDetect = "detect"
+	encryptionUsageYes    = "yes"
+	encryptionUsageNo     = "no"
+
+	usesNonExemptEncryptionKey     = "ITSAppUsesNonExemptEncryption"
+	encryptionComplianceCodeKey    = "ITSEncryptionExportComplianceCode"
+	usesNonExemptEncryptionSetting = "INFOPLIST_KEY_" + usesNonExemptEncryptionKey
+
+	// Export compliance statuses
+	exportComplianceExempt     = "exempt"
+	exportComplianceNonExempt  = "non-exempt"
+	exportComplianceUndeclared = "undeclared"
+)
+
+// encryptionSymbols are symbol names referenced by binaries using encryption APIs other than the OS provided HTTPS/TLS stack.
+var encryptionSymbols = []string{
+	"_CCCrypt",
+	"_CCCryptorCreate",
+	"_SecKeyCreateEncryptedData",
+	"_SecKeyEncrypt",
+	"_EVP_EncryptInit",
+	"_AES_encrypt",
+	"CryptoKit",
+}
+
+// encryptionFrameworks are embedded frameworks implementing encryption.
+var encryptionFrameworks = []string{"OpenSSL", "BoringSSL", "openssl_grpc", "SQLCipher", "CryptoSwift"}
+
+// exportCompliance describes the export compliance posture of the archived application.
+type exportCompliance struct {
+	// Declared is true if the Info.plist contains the ITSAppUsesNonExemptEncryption key
+	Declared                bool
+	UsesNonExemptEncryption bool
+	ComplianceCode          string
+	// EncryptionIndicators are the encryption related symbols and frameworks found in the app
+	EncryptionIndicators []string
+}
+
+// Status ...
+func (c exportCompliance) Status() string {
+	switch {
+	case !c.Declared:
+		return exportComplianceUndeclared
+	case c.UsesNonExemptEncryption:
+		return exportComplianceNonExempt
+	default:
+		return exportComplianceExempt
+	}
+}
+
+func detectExportCompliance(app xcarchive.IosApplication) (exportCompliance, error) {
+	compliance := exportCompliance{}
+	compliance.UsesNonExemptEncryption, compliance.Declared = app.InfoPlist.GetBool(usesNonExemptEncryptionKey)
+	compliance.ComplianceCode, _ = app.InfoPlist.GetString(encryptionComplianceCodeKey)
+
+	if executable, ok := app.InfoPlist.GetString("CFBundleExecutable"); ok {
+		binary, err := os.ReadFile(filepath.Join(app.Path, executable))
+		if err != nil {
+			return exportCompliance{}, fmt.Errorf("failed to read app executable: %s", err)
+		}
+		compliance.EncryptionIndicators = append(compliance.EncryptionIndicators, findEncryptionSymbols(binary)...)
+	}
+
+	for _, framework := range encryptionFrameworks {
+		frameworkPath := filepath.Join(app.Path, "Frameworks", framework+".framework")
+		if _, err := os.Stat(frameworkPath); err == nil {
+			compliance.EncryptionIndicators = append(compliance.EncryptionIndicators, framework+".framework")
+		}
+	}
+
+	return compliance, nil
+}
+
+func findEncryptionSymbols(binary []byte) []string {
+	var found []string
+	for _, symbol := range encryptionSymbols {
+		if bytes.Contains(binary, []byte(symbol)) {
+			found = append(found, symbol)
+		}
+	}
+	return found
+}
+
+// encryptionUsageBuildSettings returns the build setting injecting the ITSAppUsesNonExemptEncryption key
+// into the generated Info.plist (GENERATE_INFOPLIST_FILE = YES).
+func encryptionUsageBuildSettings(encryptionUsage string) []string {
+	switch encryptionUsage {
+	case encryptionUsageYes:
+		return []string{usesNonExemptEncryptionSetting + "=YES"}
+	case encryptionUsageNo:
+		return []string{usesNonExemptEncryptionSetting + "=NO"}
+	default:
+		return nil
+	}
+}
+
+func (s XcodebuildArchiver) exportExportCompliance(app xcarchive.IosApplication) error {
+	compliance, err := detectExportCompliance(app)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("Export compliance: %s", compliance.Status())
+	if len(compliance.EncryptionIndicators) > 0 {
+		s.logger.Printf("Encryption indicators found: %s", strings.Join(compliance.EncryptionIndicators, ", "))
+	}
+
+	switch {
+	case !compliance.Declared:
+		s.logger.Warnf("%s is not set in the app's Info.plist, App Store Connect will hold the build until the export compliance questions are answered.", usesNonExemptEncryptionKey)
+	case !compliance.UsesNonExemptEncryption && len(compliance.EncryptionIndicators) > 0:
+		s.logger.Warnf("%s is set to NO, but the app seems to use encryption APIs, make sure the usage is exempt from export regulations.", usesNonExemptEncryptionKey)
+	case compliance.UsesNonExemptEncryption && compliance.ComplianceCode == "":
+		s.logger.Warnf("%s is set to YES, but %s is missing, App Store Connect will ask for the export compliance documentation.", usesNonExemptEncryptionKey, encryptionComplianceCodeKey)
+	}
+
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseExportComplianceEnvKey, compliance.Status()); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseExportComplianceEnvKey, err)
+	}
+	s.logger.Donef("The export compliance status is now available in the Environment Variable: %s (value: %s)", bitriseExportComplianceEnvKey, compliance.Status())
+
+	return nil
+}
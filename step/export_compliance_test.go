@@ -0,0 +1,56 @@
+package step
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_detectExportCompliance(t *testing.T) {
+	tests := []struct {
+		name           string
+		infoPlist      plistutil.PlistData
+		binary         string
+		wantStatus     string
+		wantIndicators []string
+	}{
+		{
+			name:       "undeclared",
+			infoPlist:  plistutil.PlistData{},
+			binary:     "_objc_msgSend",
+			wantStatus: exportComplianceUndeclared,
+		},
+		{
+			name:           "declared exempt with encryption indicators",
+			infoPlist:      plistutil.PlistData{"ITSAppUsesNonExemptEncryption": false},
+			binary:         "_objc_msgSend\x00_CCCrypt\x00",
+			wantStatus:     exportComplianceExempt,
+			wantIndicators: []string{"_CCCrypt"},
+		},
+		{
+			name:       "declared non-exempt",
+			infoPlist:  plistutil.PlistData{"ITSAppUsesNonExemptEncryption": true},
+			binary:     "",
+			wantStatus: exportComplianceNonExempt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appPath := filepath.Join(t.TempDir(), "Sample.app")
+			writeTestFile(t, filepath.Join(appPath, "Sample"), tt.binary)
+			tt.infoPlist["CFBundleExecutable"] = "Sample"
+
+			app := xcarchive.IosApplication{
+				IosBaseApplication: xcarchive.IosBaseApplication{Path: appPath, InfoPlist: tt.infoPlist},
+			}
+
+			got, err := detectExportCompliance(app)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, got.Status())
+			require.Equal(t, tt.wantIndicators, got.EncryptionIndicators)
+		})
+	}
+}
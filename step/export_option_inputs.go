@@ -0,0 +1,34 @@
+package step
+
+import "github.com/bitrise-io/go-xcode/exportoptions"
+
+// exportOptionInputWarnings returns a warning for each disabled bitcode and symbol export option input,
+// which has no effect with the given distribution methods.
+// auto-detect may resolve to any distribution method, so the inputs are considered valid for it.
+func exportOptionInputWarnings(exportMethods []string, uploadBitcode, uploadSymbols, compileBitcode bool) []string {
+	var appStore, nonAppStore bool
+	for _, method := range exportMethods {
+		switch {
+		case method == "auto-detect":
+			appStore, nonAppStore = true, true
+		case exportoptions.Method(method).IsAppStore():
+			appStore = true
+		default:
+			nonAppStore = true
+		}
+	}
+
+	var warnings []string
+	if !appStore {
+		if !uploadBitcode {
+			warnings = append(warnings, "UploadBitcode is valid only for Distribution Method app-store.")
+		}
+		if !uploadSymbols {
+			warnings = append(warnings, "UploadSymbols is valid only for Distribution Method app-store.")
+		}
+	}
+	if !nonAppStore && !compileBitcode {
+		warnings = append(warnings, "CompileBitcode is not valid for Distribution Method app-store.")
+	}
+	return warnings
+}
@@ -0,0 +1,54 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exportOptionInputWarnings(t *testing.T) {
+	tests := []struct {
+		name           string
+		exportMethods  []string
+		uploadBitcode  bool
+		uploadSymbols  bool
+		compileBitcode bool
+		want           []string
+	}{
+		{
+			name:           "defaults",
+			exportMethods:  []string{"development"},
+			uploadBitcode:  true,
+			uploadSymbols:  true,
+			compileBitcode: true,
+		},
+		{
+			name:          "app-store options for non app-store export",
+			exportMethods: []string{"ad-hoc", "enterprise"},
+			want: []string{
+				"UploadBitcode is valid only for Distribution Method app-store.",
+				"UploadSymbols is valid only for Distribution Method app-store.",
+			},
+		},
+		{
+			name:          "additional app-store export",
+			exportMethods: []string{"development", "app-store"},
+		},
+		{
+			name:          "non app-store option for app-store export",
+			exportMethods: []string{"app-store"},
+			uploadBitcode: true,
+			want:          []string{"CompileBitcode is not valid for Distribution Method app-store."},
+		},
+		{
+			name:          "auto-detect",
+			exportMethods: []string{"auto-detect"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exportOptionInputWarnings(tt.exportMethods, tt.uploadBitcode, tt.uploadSymbols, tt.compileBitcode)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	v1command "github.com/bitrise-io/go-utils/command"
+	"howett.net/plist"
+)
+
+// exportOptionsCacheKind is the directory of the export options in the previous build cache.
+const exportOptionsCacheKind = "export_options"
+
+// exportOptionsChange is a changed key of the export options, the nested dictionary keys are joined with a dot.
+type exportOptionsChange struct {
+	Key      string
+	Previous interface{}
+	Current  interface{}
+}
+
+func (c exportOptionsChange) String() string {
+	switch {
+	case c.Previous == nil:
+		return fmt.Sprintf("+ %s: %v", c.Key, c.Current)
+	case c.Current == nil:
+		return fmt.Sprintf("- %s: %v", c.Key, c.Previous)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Key, c.Previous, c.Current)
+	}
+}
+
+func flattenExportOptions(prefix string, options map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range options {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenExportOptions(key, nested, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
+
+// diffExportOptions returns the changed keys of the export options, sorted by the key.
+func diffExportOptions(previous, current map[string]interface{}) []exportOptionsChange {
+	previousFlat, currentFlat := map[string]interface{}{}, map[string]interface{}{}
+	flattenExportOptions("", previous, previousFlat)
+	flattenExportOptions("", current, currentFlat)
+
+	var changes []exportOptionsChange
+	for key, value := range currentFlat {
+		if previousValue, ok := previousFlat[key]; !ok || !reflect.DeepEqual(previousValue, value) {
+			changes = append(changes, exportOptionsChange{Key: key, Previous: previousValue, Current: value})
+		}
+	}
+	for key, value := range previousFlat {
+		if _, ok := currentFlat[key]; !ok {
+			changes = append(changes, exportOptionsChange{Key: key, Previous: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func readExportOptions(pth string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var options map[string]interface{}
+	if _, err := plist.Unmarshal(content, &options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// compareExportOptionsWithPreviousBuild prints the changes of the export options since the previous build,
+// and stores the current export options for the next build.
+func (s XcodebuildArchiver) compareExportOptionsWithPreviousBuild(exportOptionsPath, artifactName string) error {
+	cacheDir, err := previousBuildDir(exportOptionsCacheKind)
+	if err != nil {
+		return err
+	}
+	previousPath := filepath.Join(cacheDir, artifactName+".plist")
+
+	current, err := readExportOptions(exportOptionsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read export options: %w", err)
+	}
+
+	if previous, err := readExportOptions(previousPath); os.IsNotExist(err) {
+		s.logger.Printf("No export options of a previous build found")
+	} else if err != nil {
+		s.logger.Warnf("Failed to read the export options of the previous build: %s", err)
+	} else if changes := diffExportOptions(previous, current); len(changes) == 0 {
+		s.logger.Printf("Export options are the same as in the previous build")
+	} else {
+		s.logger.Warnf("Export options changed since the previous build:")
+		for _, change := range changes {
+			s.logger.Printf("%s", change)
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	if err := v1command.CopyFile(exportOptionsPath, previousPath); err != nil {
+		return fmt.Errorf("failed to store export options: %w", err)
+	}
+
+	return commitPreviousBuildDir(cacheDir)
+}
@@ -0,0 +1,33 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffExportOptions(t *testing.T) {
+	previous := map[string]interface{}{
+		"method":               "app-store",
+		"uploadBitcode":        false,
+		"provisioningProfiles": map[string]interface{}{"io.bitrise.sample": "Sample AppStore"},
+	}
+	current := map[string]interface{}{
+		"method":               "app-store",
+		"teamID":               "ABCD1234",
+		"provisioningProfiles": map[string]interface{}{"io.bitrise.sample": "Sample AppStore 2"},
+	}
+
+	changes := diffExportOptions(previous, current)
+	require.Equal(t, []exportOptionsChange{
+		{Key: "provisioningProfiles.io.bitrise.sample", Previous: "Sample AppStore", Current: "Sample AppStore 2"},
+		{Key: "teamID", Current: "ABCD1234"},
+		{Key: "uploadBitcode", Previous: false},
+	}, changes)
+
+	require.Equal(t, "~ provisioningProfiles.io.bitrise.sample: Sample AppStore -> Sample AppStore 2", changes[0].String())
+	require.Equal(t, "+ teamID: ABCD1234", changes[1].String())
+	require.Equal(t, "- uploadBitcode: false", changes[2].String())
+
+	require.Empty(t, diffExportOptions(current, current))
+}
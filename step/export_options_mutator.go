@@ -0,0 +1,196 @@
+package step
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/exportoptionsgenerator"
+	"howett.net/plist"
+)
+
+const (
+	// mutatorStageArchiveInfo is the argument of the external mutators adjusting the archive analysis results
+	mutatorStageArchiveInfo = "archive-info"
+	// mutatorStageExportOptions is the argument of the external mutators adjusting the generated export options
+	mutatorStageExportOptions = "export-options"
+)
+
+// ArchiveAnalysis is the result of the archive analysis, the export options are generated from it.
+type ArchiveAnalysis struct {
+	AppBundleID            string                            `json:"app_bundle_id"`
+	AppClipBundleID        string                            `json:"app_clip_bundle_id,omitempty"`
+	EntitlementsByBundleID map[string]map[string]interface{} `json:"entitlements_by_bundle_id"`
+	// ExportMethod is the distribution type of the archived app's provisioning profile
+	ExportMethod string `json:"export_method"`
+	XcodeManaged bool   `json:"xcode_managed"`
+}
+
+// ExportOptionsMutator adjusts the archive analysis results and the export options generated from them,
+// to extend the signing logic of the Step.
+type ExportOptionsMutator interface {
+	Name() string
+	// MutateArchiveAnalysis is called before generating the export options.
+	MutateArchiveAnalysis(analysis ArchiveAnalysis) (ArchiveAnalysis, error)
+	// MutateExportOptions is called with the generated export options, the returned options are passed to xcodebuild -exportArchive.
+	MutateExportOptions(exportOptions map[string]interface{}, analysis ArchiveAnalysis) (map[string]interface{}, error)
+}
+
+// RegisterExportOptionsMutator adds a mutator, the mutators are called in the order of the registration.
+func (s *XcodebuildArchiver) RegisterExportOptionsMutator(mutator ExportOptionsMutator) {
+	s.exportOptionsMutators = append(s.exportOptionsMutators, mutator)
+}
+
+func newArchiveAnalysis(info exportoptionsgenerator.ArchiveInfo, exportMethod exportoptions.Method, xcodeManaged bool) ArchiveAnalysis {
+	entitlements := map[string]map[string]interface{}{}
+	for bundleID, bundleEntitlements := range info.EntitlementsByBundleID {
+		entitlements[bundleID] = bundleEntitlements
+	}
+	return ArchiveAnalysis{
+		AppBundleID:            info.AppBundleID,
+		AppClipBundleID:        info.AppClipBundleID,
+		EntitlementsByBundleID: entitlements,
+		ExportMethod:           string(exportMethod),
+		XcodeManaged:           xcodeManaged,
+	}
+}
+
+func (a ArchiveAnalysis) archiveInfo() exportoptionsgenerator.ArchiveInfo {
+	entitlements := map[string]plistutil.PlistData{}
+	for bundleID, bundleEntitlements := range a.EntitlementsByBundleID {
+		entitlements[bundleID] = bundleEntitlements
+	}
+	return exportoptionsgenerator.ArchiveInfo{
+		AppBundleID:            a.AppBundleID,
+		AppClipBundleID:        a.AppClipBundleID,
+		EntitlementsByBundleID: entitlements,
+	}
+}
+
+func (s XcodebuildArchiver) mutateArchiveAnalysis(analysis ArchiveAnalysis) (ArchiveAnalysis, error) {
+	for _, mutator := range s.exportOptionsMutators {
+		mutated, err := mutator.MutateArchiveAnalysis(analysis)
+		if err != nil {
+			return ArchiveAnalysis{}, fmt.Errorf("export options mutator %s failed to adjust the archive analysis: %w", mutator.Name(), err)
+		}
+		analysis = mutated
+	}
+	return analysis, nil
+}
+
+func (s XcodebuildArchiver) mutateExportOptions(exportOptions map[string]interface{}, analysis ArchiveAnalysis) (map[string]interface{}, error) {
+	for _, mutator := range s.exportOptionsMutators {
+		mutated, err := mutator.MutateExportOptions(exportOptions, analysis)
+		if err != nil {
+			return nil, fmt.Errorf("export options mutator %s failed to adjust the export options: %w", mutator.Name(), err)
+		}
+		exportOptions = mutated
+	}
+	return exportOptions, nil
+}
+
+// exportOptionsContent returns the export options in the plist format.
+func exportOptionsContent(exportOptions map[string]interface{}) (string, error) {
+	content, err := plist.MarshalIndent(exportOptions, plist.XMLFormat, "\t")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize export options: %s", err)
+	}
+	return string(content), nil
+}
+
+// parseExportOptionsMutators parses the newline separated list of external mutator executable paths.
+func parseExportOptionsMutators(content string) ([]string, error) {
+	var pths []string
+	for _, pth := range matrixLines(content) {
+		info, err := os.Stat(pth)
+		if err != nil {
+			return nil, fmt.Errorf("export options mutator not found: %s", pth)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return nil, fmt.Errorf("export options mutator is not executable: %s", pth)
+		}
+		pths = append(pths, pth)
+	}
+	return pths, nil
+}
+
+// externalMutatorMessage is the JSON document written to the standard input of the external mutators,
+// and expected on their standard output.
+type externalMutatorMessage struct {
+	Archive       *ArchiveAnalysis       `json:"archive,omitempty"`
+	ExportOptions map[string]interface{} `json:"export_options,omitempty"`
+}
+
+// ExternalExportOptionsMutator is an ExportOptionsMutator executable.
+//
+// The executable is called with the stage (`archive-info` or `export-options`) as its only argument,
+// it receives a JSON document with the `archive` analysis (and the `export_options` in the export-options stage) on its standard input,
+// and it should write the same document with the adjusted values to its standard output.
+// A non-zero exit code fails the export.
+type ExternalExportOptionsMutator struct {
+	path       string
+	cmdFactory command.Factory
+}
+
+// NewExternalExportOptionsMutator ...
+func NewExternalExportOptionsMutator(path string, cmdFactory command.Factory) ExternalExportOptionsMutator {
+	return ExternalExportOptionsMutator{path: path, cmdFactory: cmdFactory}
+}
+
+// Name ...
+func (m ExternalExportOptionsMutator) Name() string {
+	return filepath.Base(m.path)
+}
+
+// MutateArchiveAnalysis ...
+func (m ExternalExportOptionsMutator) MutateArchiveAnalysis(analysis ArchiveAnalysis) (ArchiveAnalysis, error) {
+	response, err := m.run(mutatorStageArchiveInfo, externalMutatorMessage{Archive: &analysis})
+	if err != nil {
+		return ArchiveAnalysis{}, err
+	}
+	if response.Archive == nil {
+		return ArchiveAnalysis{}, fmt.Errorf("missing archive in the response")
+	}
+	return *response.Archive, nil
+}
+
+// MutateExportOptions ...
+func (m ExternalExportOptionsMutator) MutateExportOptions(exportOptions map[string]interface{}, analysis ArchiveAnalysis) (map[string]interface{}, error) {
+	response, err := m.run(mutatorStageExportOptions, externalMutatorMessage{Archive: &analysis, ExportOptions: exportOptions})
+	if err != nil {
+		return nil, err
+	}
+	if response.ExportOptions == nil {
+		return nil, fmt.Errorf("missing export_options in the response")
+	}
+	return response.ExportOptions, nil
+}
+
+func (m ExternalExportOptionsMutator) run(stage string, request externalMutatorMessage) (externalMutatorMessage, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return externalMutatorMessage{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := m.cmdFactory.Create(m.path, []string{stage}, &command.Opts{
+		Stdin:  bytes.NewReader(input),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err := cmd.Run(); err != nil {
+		return externalMutatorMessage{}, fmt.Errorf("%s, output: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response externalMutatorMessage
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return externalMutatorMessage{}, fmt.Errorf("invalid response: %s", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,107 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/exportoptionsgenerator"
+	"github.com/stretchr/testify/require"
+)
+
+type teamIDMutator struct {
+	teamID string
+}
+
+func (m teamIDMutator) Name() string {
+	return "team-id"
+}
+
+func (m teamIDMutator) MutateArchiveAnalysis(analysis ArchiveAnalysis) (ArchiveAnalysis, error) {
+	analysis.XcodeManaged = false
+	return analysis, nil
+}
+
+func (m teamIDMutator) MutateExportOptions(exportOptions map[string]interface{}, _ ArchiveAnalysis) (map[string]interface{}, error) {
+	exportOptions[exportoptions.TeamIDKey] = m.teamID
+	return exportOptions, nil
+}
+
+func writeMutator(t *testing.T, script string) string {
+	pth := filepath.Join(t.TempDir(), "mutator")
+	require.NoError(t, os.WriteFile(pth, []byte("#!/bin/sh\n"+script), 0755))
+	return pth
+}
+
+func Test_parseExportOptionsMutators(t *testing.T) {
+	executable := writeMutator(t, "exit 0")
+	nonExecutable := filepath.Join(t.TempDir(), "mutator.json")
+	require.NoError(t, os.WriteFile(nonExecutable, []byte("{}"), 0644))
+
+	pths, err := parseExportOptionsMutators("\n" + executable + "\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{executable}, pths)
+
+	_, err = parseExportOptionsMutators(nonExecutable)
+	require.Error(t, err)
+
+	_, err = parseExportOptionsMutators(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func Test_newArchiveAnalysis(t *testing.T) {
+	info := exportoptionsgenerator.ArchiveInfo{
+		AppBundleID:            "io.bitrise.sample",
+		EntitlementsByBundleID: map[string]plistutil.PlistData{"io.bitrise.sample": {"aps-environment": "production"}},
+	}
+
+	analysis := newArchiveAnalysis(info, exportoptions.MethodAppStore, true)
+	require.Equal(t, ArchiveAnalysis{
+		AppBundleID:            "io.bitrise.sample",
+		EntitlementsByBundleID: map[string]map[string]interface{}{"io.bitrise.sample": {"aps-environment": "production"}},
+		ExportMethod:           "app-store",
+		XcodeManaged:           true,
+	}, analysis)
+	require.Equal(t, info, analysis.archiveInfo())
+}
+
+func TestXcodebuildArchiver_mutateExportOptions(t *testing.T) {
+	archiver := XcodebuildArchiver{}
+	archiver.RegisterExportOptionsMutator(teamIDMutator{teamID: "ABCD1234"})
+
+	analysis, err := archiver.mutateArchiveAnalysis(ArchiveAnalysis{AppBundleID: "io.bitrise.sample", XcodeManaged: true})
+	require.NoError(t, err)
+	require.False(t, analysis.XcodeManaged)
+
+	exportOptions, err := archiver.mutateExportOptions(map[string]interface{}{exportoptions.MethodKey: "app-store"}, analysis)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{exportoptions.MethodKey: "app-store", exportoptions.TeamIDKey: "ABCD1234"}, exportOptions)
+}
+
+func TestExternalExportOptionsMutator(t *testing.T) {
+	cmdFactory := command.NewFactory(env.NewRepository())
+	pth := writeMutator(t, `cat > /dev/null
+case "$1" in
+  archive-info) echo '{"archive": {"app_bundle_id": "io.bitrise.other", "export_method": "ad-hoc"}}' ;;
+  export-options) echo '{"export_options": {"method": "ad-hoc", "teamID": "ABCD1234"}}' ;;
+esac
+`)
+	mutator := NewExternalExportOptionsMutator(pth, cmdFactory)
+	require.Equal(t, "mutator", mutator.Name())
+
+	analysis, err := mutator.MutateArchiveAnalysis(ArchiveAnalysis{AppBundleID: "io.bitrise.sample"})
+	require.NoError(t, err)
+	require.Equal(t, ArchiveAnalysis{AppBundleID: "io.bitrise.other", ExportMethod: "ad-hoc"}, analysis)
+
+	exportOptions, err := mutator.MutateExportOptions(map[string]interface{}{"method": "app-store"}, analysis)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"method": "ad-hoc", "teamID": "ABCD1234"}, exportOptions)
+
+	failing := NewExternalExportOptionsMutator(writeMutator(t, "echo 'unknown team' >&2; exit 1"), cmdFactory)
+	_, err = failing.MutateExportOptions(map[string]interface{}{"method": "app-store"}, analysis)
+	require.ErrorContains(t, err, "unknown team")
+}
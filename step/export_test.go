@@ -0,0 +1,121 @@
+package step
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveCompression_Extension(t *testing.T) {
+	require.Equal(t, ".zip", ArchiveCompression{Format: archiveFormatZip}.Extension())
+	require.Equal(t, ".tar.zst", ArchiveCompression{Format: archiveFormatZstd}.Extension())
+	require.Equal(t, ".zip", ArchiveCompression{}.Extension())
+}
+
+func Test_archiveEntries(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Sample.xcarchive")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dSYMs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Info.plist"), nil, 0644))
+	require.NoError(t, os.Symlink("Info.plist", filepath.Join(dir, "Link.plist")))
+
+	entries, err := archiveEntries(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Sample.xcarchive",
+		"Sample.xcarchive/Info.plist",
+		"Sample.xcarchive/Link.plist",
+		"Sample.xcarchive/dSYMs",
+	}, entries)
+}
+
+func Test_deterministicZip(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not available")
+	}
+
+	cmdFactory := command.NewFactory(env.NewRepository())
+	logger := log.NewLogger()
+
+	createSource := func(modTime time.Time, files ...string) string {
+		dir := filepath.Join(t.TempDir(), "Sample.app.dSYM")
+		for _, file := range files {
+			pth := filepath.Join(dir, file)
+			require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+			require.NoError(t, os.WriteFile(pth, []byte(file), 0644))
+			require.NoError(t, os.Chtimes(pth, modTime, modTime))
+		}
+		return dir
+	}
+
+	archive := func(sourceDir string) []byte {
+		stagedDir, err := stageArchivedDir(cmdFactory, sourceDir, t.TempDir(), nil, true)
+		require.NoError(t, err)
+
+		zipPath := filepath.Join(t.TempDir(), "Sample.app.dSYM.zip")
+		require.NoError(t, deterministicZip(cmdFactory, stagedDir, zipPath, defaultCompressionLevel, logger))
+
+		content, err := os.ReadFile(zipPath)
+		require.NoError(t, err)
+		return content
+	}
+
+	first := createSource(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "Contents/Info.plist", "Contents/Resources/DWARF/Sample")
+	second := createSource(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "Contents/Resources/DWARF/Sample", "Contents/Info.plist")
+
+	require.Equal(t, archive(first), archive(second))
+
+	info, err := os.Stat(filepath.Join(first, "Contents", "Info.plist"))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), info.ModTime().UTC(), "the source directory should be left untouched")
+}
+
+func Test_parseArchiveExcludes(t *testing.T) {
+	excludes, err := parseArchiveExcludes("SwiftSupport\n\n# dSYMs are exported separately\ndSYMs/\nProducts/Applications/*.app/Frameworks/*.framework/Headers\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{"SwiftSupport", "dSYMs", "Products/Applications/*.app/Frameworks/*.framework/Headers"}, excludes)
+
+	_, err = parseArchiveExcludes("../Sample.xcarchive")
+	require.Error(t, err)
+
+	_, err = parseArchiveExcludes("/tmp")
+	require.Error(t, err)
+
+	_, err = parseArchiveExcludes("dSYMs/[")
+	require.Error(t, err)
+}
+
+func Test_stageArchivedDir_excludes(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not available")
+	}
+
+	sourceDir := filepath.Join(t.TempDir(), "Sample.xcarchive")
+	for _, file := range []string{"Info.plist", "SwiftSupport/iphoneos/libswiftCore.dylib", "dSYMs/Sample.app.dSYM/Contents/Info.plist", "Products/Applications/Sample.app/Sample"} {
+		pth := filepath.Join(sourceDir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+		require.NoError(t, os.WriteFile(pth, nil, 0644))
+	}
+
+	stagedDir, err := stageArchivedDir(command.NewFactory(env.NewRepository()), sourceDir, t.TempDir(), []string{"SwiftSupport", "dSYMs/*.dSYM"}, false)
+	require.NoError(t, err)
+
+	entries, err := archiveEntries(stagedDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Sample.xcarchive",
+		"Sample.xcarchive/Info.plist",
+		"Sample.xcarchive/Products",
+		"Sample.xcarchive/Products/Applications",
+		"Sample.xcarchive/Products/Applications/Sample.app",
+		"Sample.xcarchive/Products/Applications/Sample.app/Sample",
+		"Sample.xcarchive/dSYMs",
+	}, entries)
+	require.DirExists(t, filepath.Join(sourceDir, "SwiftSupport"))
+}
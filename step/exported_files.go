@@ -0,0 +1,81 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	artifactTypeIPA          = "ipa"
+	artifactTypeXCArchiveZip = "xcarchive-zip"
+	artifactTypeDSYM         = "dsym"
+	// artifactTypeSimulatorAppZip is the zipped .app built in simulator build mode
+	artifactTypeSimulatorAppZip = "simulator-app-zip"
+
+	// exportedFilePathsSeparator separates the paths of the BITRISE_EXPORTED_FILE_PATHS list, like the other Bitrise path list outputs
+	exportedFilePathsSeparator = "|"
+)
+
+// exportedFilesManifest enumerates every artifact produced by the Step.
+type exportedFilesManifest struct {
+	Artifacts []exportedFilesManifestEntry `json:"artifacts"`
+}
+
+type exportedFilesManifestEntry struct {
+	Path          string `json:"path"`
+	Type          string `json:"type"`
+	ExportMethod  string `json:"export_method,omitempty"`
+	Variant       string `json:"variant,omitempty"`
+	Scheme        string `json:"scheme,omitempty"`
+	Configuration string `json:"configuration,omitempty"`
+}
+
+func newExportedFilesManifest(artifacts []exportedArtifact) exportedFilesManifest {
+	manifest := exportedFilesManifest{Artifacts: []exportedFilesManifestEntry{}}
+	for _, artifact := range artifacts {
+		manifest.Artifacts = append(manifest.Artifacts, exportedFilesManifestEntry{
+			Path:          artifact.Path,
+			Type:          artifact.Type,
+			ExportMethod:  artifact.ExportMethod,
+			Variant:       artifact.Variant,
+			Scheme:        artifact.Scheme,
+			Configuration: artifact.Configuration,
+		})
+	}
+	return manifest
+}
+
+func exportedFilePaths(artifacts []exportedArtifact) string {
+	var pths []string
+	for _, artifact := range artifacts {
+		pths = append(pths, artifact.Path)
+	}
+	return strings.Join(pths, exportedFilePathsSeparator)
+}
+
+// exportExportedFiles exports the path list and the manifest of the exported artifacts.
+func (s XcodebuildArchiver) exportExportedFiles(artifacts []exportedArtifact, outputDir, artifactName string, outputPath func(string) (string, error)) error {
+	pths := exportedFilePaths(artifacts)
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseExportedFilePathsEnvKey, pths); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseExportedFilePathsEnvKey, err)
+	}
+	s.logger.Donef("The exported file paths are now available in the Environment Variable: %s (value: %s)", bitriseExportedFilePathsEnvKey, pths)
+
+	content, err := json.MarshalIndent(newExportedFilesManifest(artifacts), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	manifestPath, err := outputPath(filepath.Join(outputDir, artifactName+".exported-files.json"))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, string(content), manifestPath, bitriseExportedFilesManifestPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseExportedFilesManifestPthEnvKey, err)
+	}
+	s.logger.Donef("The exported files manifest path is now available in the Environment Variable: %s (value: %s)", bitriseExportedFilesManifestPthEnvKey, manifestPath)
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package step
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exportedFilePaths(t *testing.T) {
+	artifacts := []exportedArtifact{
+		{Path: "/deploy/Sample.xcarchive.zip", Type: artifactTypeXCArchiveZip},
+		{Path: "/deploy/Sample.ipa", Type: artifactTypeIPA},
+	}
+	require.Equal(t, "/deploy/Sample.xcarchive.zip|/deploy/Sample.ipa", exportedFilePaths(artifacts))
+}
+
+func Test_newExportedFilesManifest(t *testing.T) {
+	manifest := newExportedFilesManifest([]exportedArtifact{
+		{Path: "/deploy/Sample.dSYM.zip", Type: artifactTypeDSYM, ChecksumEnvKey: bitriseDSYMSHA256EnvKey},
+		{Path: "/deploy/Sample.ipa", Type: artifactTypeIPA, ExportMethod: "ad-hoc", Variant: "Sample iPhone"},
+	})
+
+	content, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"artifacts": [
+		{"path": "/deploy/Sample.dSYM.zip", "type": "dsym"},
+		{"path": "/deploy/Sample.ipa", "type": "ipa", "export_method": "ad-hoc", "variant": "Sample iPhone"}
+	]}`, string(content))
+}
@@ -0,0 +1,101 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// frameworkMinOSIssue is an embedded framework requiring a newer OS than the app's deployment target.
+type frameworkMinOSIssue struct {
+	Framework string
+	Arch      string
+	MinOS     uint32
+}
+
+// parseMachOVersion encodes a dotted version (for example 15.0 or 12.4.1) the way Mach-O load commands store it.
+func parseMachOVersion(version string) (uint32, error) {
+	components := strings.Split(version, ".")
+	if len(components) > 3 {
+		return 0, fmt.Errorf("invalid version: %s", version)
+	}
+
+	// major: 16 bits, minor and patch: 8 bits each
+	bitSizes := []int{16, 8, 8}
+	var encoded uint32
+	for i, bitSize := range bitSizes {
+		var value uint64
+		if i < len(components) {
+			var err error
+			if value, err = strconv.ParseUint(components[i], 10, bitSize); err != nil {
+				return 0, fmt.Errorf("invalid version: %s", version)
+			}
+		}
+		encoded = encoded<<8 | uint32(value)
+	}
+	return encoded, nil
+}
+
+func formatMachOVersion(version uint32) string {
+	major, minor, patch := version>>16, (version>>8)&0xff, version&0xff
+	if patch == 0 {
+		return fmt.Sprintf("%d.%d", major, minor)
+	}
+	return fmt.Sprintf("%d.%d.%d", major, minor, patch)
+}
+
+// findFrameworkMinOSIssues returns the framework slices with a higher minimum OS version than the app's deployment target.
+func findFrameworkMinOSIssues(binaryPaths []string, appMinOS uint32) ([]frameworkMinOSIssue, error) {
+	var issues []frameworkMinOSIssue
+	for _, pth := range binaryPaths {
+		slices, err := readBinarySlices(pth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Mach-O binary (%s): %s", pth, err)
+		}
+
+		for _, slice := range slices {
+			if slice.IsSimulator() || slice.MinOS <= appMinOS {
+				continue
+			}
+			issues = append(issues, frameworkMinOSIssue{
+				Framework: filepath.Base(filepath.Dir(pth)),
+				Arch:      slice.Arch,
+				MinOS:     slice.MinOS,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// checkFrameworkMinOSVersions reports the embedded frameworks, which would crash on the app's oldest supported OS.
+func (s XcodebuildArchiver) checkFrameworkMinOSVersions(archive Archive) {
+	minimumOSVersion := archive.MinimumOSVersion()
+	if minimumOSVersion == "" {
+		s.logger.Debugf("MinimumOSVersion not found in the app's Info.plist, skipping the framework minimum OS version check")
+		return
+	}
+
+	appMinOS, err := parseMachOVersion(minimumOSVersion)
+	if err != nil {
+		s.logger.Warnf("Failed to parse the app's MinimumOSVersion: %s", err)
+		return
+	}
+
+	binaries, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		s.logger.Warnf("Failed to list embedded frameworks: %s", err)
+		return
+	}
+
+	issues, err := findFrameworkMinOSIssues(binaries, appMinOS)
+	if err != nil {
+		s.logger.Warnf("Failed to check the minimum OS version of the embedded frameworks: %s", err)
+		return
+	}
+
+	for _, issue := range issues {
+		s.logger.Warnf("%s (%s) requires OS version %s, but the app supports OS version %s: the app would crash on launch on older OS versions",
+			issue.Framework, issue.Arch, formatMachOVersion(issue.MinOS), minimumOSVersion)
+	}
+}
@@ -0,0 +1,37 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMachOVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint32
+		wantErr bool
+	}{
+		{version: "15", want: 0x000f0000},
+		{version: "15.0", want: 0x000f0000},
+		{version: "12.4.1", want: 0x000c0401},
+		{version: "17.a", wantErr: true},
+		{version: "1.2.3.4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := parseMachOVersion(tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_formatMachOVersion(t *testing.T) {
+	require.Equal(t, "15.0", formatMachOVersion(0x000f0000))
+	require.Equal(t, "12.4.1", formatMachOVersion(0x000c0401))
+}
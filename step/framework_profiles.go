@@ -0,0 +1,153 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const embeddedProfileFileName = "embedded.mobileprovision"
+
+// frameworksWithProfiles returns the embedded frameworks of the application, which contain a provisioning profile.
+// Frameworks are not provisioned, the profile is copied into them by misconfigured build phases or third party build scripts.
+func frameworksWithProfiles(appPath string) ([]string, error) {
+	binaries, err := frameworkBinaries(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var frameworks []string
+	for _, binary := range binaries {
+		framework := filepath.Dir(binary)
+		if _, err := os.Stat(filepath.Join(framework, embeddedProfileFileName)); err == nil {
+			frameworks = append(frameworks, framework)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return frameworks, nil
+}
+
+// resignFrameworkArgs returns the codesign arguments, which re-sign the framework with the identity
+// keeping its identifier and flags, as removing a file invalidates the framework's code signature.
+func resignFrameworkArgs(framework, identity string) []string {
+	return []string{"--force", "--sign", identity, "--preserve-metadata=identifier,flags", "--timestamp=none", framework}
+}
+
+// archiveSigningIdentity returns the identity the archived application is signed with, which re-signs the modified frameworks.
+func archiveSigningIdentity(archive Archive) (string, error) {
+	properties, ok := archive.ApplicationProperties()
+	if !ok || properties.SigningIdentity == "" {
+		return "", fmt.Errorf("the archive's signing identity not found, the frameworks can't be re-signed")
+	}
+	return properties.SigningIdentity, nil
+}
+
+// resignBundleArgs returns the codesign arguments, which re-sign the application or extension bundle with the identity
+// keeping its identifier, entitlements and flags.
+func resignBundleArgs(bundle, identity string) []string {
+	return []string{"--force", "--sign", identity, "--preserve-metadata=identifier,entitlements,flags", "--timestamp=none", bundle}
+}
+
+// containsBundle returns true if any of the bundles is embedded into the bundle at bundlePath.
+func containsBundle(bundlePath string, bundles []string) bool {
+	for _, bundle := range bundles {
+		if strings.HasPrefix(bundle, bundlePath+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// embeddingBundles returns the bundles embedding any of the modified paths, directly or through other embedding bundles.
+// The embedded bundles (extensions, watch app, App Clip) are listed after the bundles embedding them,
+// so they are returned in reverse order: the innermost bundle first, as it is signed before its parent.
+func embeddingBundles(bundles []ArchiveBundle, modified []string) []string {
+	modified = append([]string{}, modified...)
+	var embedding []string
+	for i := len(bundles) - 1; i >= 0; i-- {
+		if containsBundle(bundles[i].Path, modified) {
+			embedding = append(embedding, bundles[i].Path)
+			modified = append(modified, bundles[i].Path)
+		}
+	}
+	return embedding
+}
+
+// resignEmbeddingBundles re-signs the bundles embedding the modified frameworks, as the code signature of a bundle
+// seals the signatures of its nested code.
+func (s XcodebuildArchiver) resignEmbeddingBundles(archive Archive, modified []string, identity string) error {
+	for _, bundle := range embeddingBundles(archive.Bundles(), modified) {
+		cmd := s.cmdFactory.Create("codesign", resignBundleArgs(bundle, identity), nil)
+		s.logger.Printf("$ %s", cmd.PrintableCommandArgs())
+		if output, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+		}
+	}
+	return nil
+}
+
+func (s XcodebuildArchiver) resignFramework(framework, identity string) error {
+	cmd := s.cmdFactory.Create("codesign", resignFrameworkArgs(framework, identity), nil)
+	s.logger.Printf("$ %s", cmd.PrintableCommandArgs())
+	if output, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+	}
+	return nil
+}
+
+// stripFrameworkProfiles removes the provisioning profiles of the embedded frameworks and re-signs the frameworks
+// and the bundles embedding them with the archive's signing identity.
+func (s XcodebuildArchiver) stripFrameworkProfiles(archive Archive, frameworks []string) error {
+	identity, err := archiveSigningIdentity(archive)
+	if err != nil {
+		return err
+	}
+
+	for _, framework := range frameworks {
+		if err := os.Remove(filepath.Join(framework, embeddedProfileFileName)); err != nil {
+			return err
+		}
+		if err := s.resignFramework(framework, identity); err != nil {
+			return err
+		}
+	}
+	return s.resignEmbeddingBundles(archive, frameworks, identity)
+}
+
+// cleanupFrameworkProfiles checks the embedded frameworks of the archive for provisioning profiles,
+// which App Store Connect rejects. The profiles are removed before app-store exports if strip is set, otherwise reported.
+func (s XcodebuildArchiver) cleanupFrameworkProfiles(archive Archive, strip bool, exportMethods ...string) error {
+	frameworks, err := frameworksWithProfiles(archive.Application.Path)
+	if err != nil {
+		s.logger.Warnf("Failed to list embedded frameworks: %s", err)
+		return nil
+	}
+	if len(frameworks) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, framework := range frameworks {
+		names = append(names, filepath.Base(framework))
+	}
+
+	if !exportsForAppStore(archive, exportMethods...) {
+		s.logger.Debugf("Embedded frameworks with a provisioning profile: %s", strings.Join(names, ", "))
+		return nil
+	}
+
+	if !strip {
+		s.logger.Warnf("Embedded frameworks contain a provisioning profile, App Store Connect will reject the upload: %s", strings.Join(names, ", "))
+		s.logger.Warnf("Enable the Strip framework provisioning profiles input to remove them before the export.")
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Removing provisioning profiles of embedded frameworks: %s", strings.Join(names, ", "))
+	if err := s.stripFrameworkProfiles(archive, frameworks); err != nil {
+		return fmt.Errorf("failed to remove the provisioning profiles of embedded frameworks: %w", err)
+	}
+	return nil
+}
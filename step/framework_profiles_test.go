@@ -0,0 +1,62 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_frameworksWithProfiles(t *testing.T) {
+	appPath := filepath.Join(t.TempDir(), "Sample.app")
+	provisioned := filepath.Join(appPath, "Frameworks", "Provisioned.framework")
+	clean := filepath.Join(appPath, "Frameworks", "Clean.framework")
+	require.NoError(t, os.MkdirAll(provisioned, 0755))
+	require.NoError(t, os.MkdirAll(clean, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(provisioned, embeddedProfileFileName), nil, 0644))
+
+	frameworks, err := frameworksWithProfiles(appPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{provisioned}, frameworks)
+}
+
+func Test_resignFrameworkArgs(t *testing.T) {
+	require.Equal(t,
+		[]string{"--force", "--sign", "Apple Distribution: Bitrise (ABCD1234)", "--preserve-metadata=identifier,flags", "--timestamp=none", "Sample.app/Frameworks/Sample.framework"},
+		resignFrameworkArgs("Sample.app/Frameworks/Sample.framework", "Apple Distribution: Bitrise (ABCD1234)"),
+	)
+}
+
+func Test_containsBundle(t *testing.T) {
+	appPath := filepath.Join("Products", "Applications", "Sample.app")
+	extensionPath := filepath.Join(appPath, "PlugIns", "Widget.appex")
+
+	require.True(t, containsBundle(appPath, []string{extensionPath}))
+	require.False(t, containsBundle(extensionPath, []string{appPath}))
+	require.False(t, containsBundle(appPath, []string{filepath.Join("Products", "Applications", "Sample.app2")}))
+}
+
+func Test_embeddingBundles(t *testing.T) {
+	appPath := filepath.Join("Products", "Applications", "Sample.app")
+	extensionPath := filepath.Join(appPath, "PlugIns", "Widget.appex")
+	watchAppPath := filepath.Join(appPath, "Watch", "Watch.app")
+	watchExtensionPath := filepath.Join(watchAppPath, "PlugIns", "WatchExtension.appex")
+	bundles := []ArchiveBundle{
+		{IosBaseApplication: xcarchive.IosBaseApplication{Path: appPath}, Kind: bundleKindApplication},
+		{IosBaseApplication: xcarchive.IosBaseApplication{Path: extensionPath}, Kind: bundleKindExtension},
+		{IosBaseApplication: xcarchive.IosBaseApplication{Path: watchAppPath}, Kind: bundleKindWatchApplication},
+		{IosBaseApplication: xcarchive.IosBaseApplication{Path: watchExtensionPath}, Kind: bundleKindWatchExtension},
+	}
+
+	require.Equal(t,
+		[]string{watchExtensionPath, watchAppPath, appPath},
+		embeddingBundles(bundles, []string{filepath.Join(watchExtensionPath, "Frameworks", "Sample.framework")}),
+	)
+	require.Equal(t,
+		[]string{appPath},
+		embeddingBundles(bundles, []string{filepath.Join(appPath, "Frameworks", "Sample.framework")}),
+	)
+	require.Empty(t, embeddingBundles(bundles, nil))
+}
@@ -0,0 +1,170 @@
+package step
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// disallowedFrameworkDirs are the build time only directories of a framework, which are not needed in the app bundle.
+var disallowedFrameworkDirs = []string{"Headers", "PrivateHeaders", "Modules"}
+
+const swiftModuleExt = ".swiftmodule"
+
+// frameworkVersionDirs returns the version directories (Versions/A) of a versioned (macOS) framework,
+// the top-level directories of these frameworks are symlinks into the current version.
+func frameworkVersionDirs(framework string) ([]string, error) {
+	versions, err := filepath.Glob(filepath.Join(escapeGlobPath(framework), "Versions", "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, version := range versions {
+		info, err := os.Lstat(version)
+		if err != nil {
+			return nil, err
+		}
+		// Versions/Current is a symlink to the current version
+		if info.IsDir() {
+			dirs = append(dirs, version)
+		}
+	}
+	return dirs, nil
+}
+
+// disallowedFrameworkContents returns the headers, module maps and Swift module directories of the framework,
+// including the ones of every version of a versioned framework and the top-level symlinks pointing to them.
+func disallowedFrameworkContents(framework string) ([]string, error) {
+	versionDirs, err := frameworkVersionDirs(framework)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []string
+	for _, root := range append([]string{framework}, versionDirs...) {
+		for _, dir := range disallowedFrameworkDirs {
+			pth := filepath.Join(root, dir)
+			if _, err := os.Lstat(pth); err == nil {
+				contents = append(contents, pth)
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	if err := filepath.WalkDir(framework, func(pth string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		for _, content := range contents {
+			if pth == content {
+				// Swift modules in the removed directories are removed with their parent
+				return filepath.SkipDir
+			}
+		}
+		if strings.EqualFold(filepath.Ext(pth), swiftModuleExt) {
+			contents = append(contents, pth)
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(contents)
+	return contents, nil
+}
+
+// pathSize returns the total size of the regular files under the path.
+func pathSize(pth string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(pth, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// sanitizeFrameworks removes the headers, module maps and Swift modules of the embedded frameworks of the archived bundles
+// (the application and its extensions, watch app and App Clip) and re-signs the modified frameworks
+// and the bundles embedding them with the archive's signing identity.
+func (s XcodebuildArchiver) sanitizeFrameworks(archive Archive) error {
+	var frameworks []string
+	for _, bundle := range archive.Bundles() {
+		binaries, err := frameworkBinaries(bundle.Path)
+		if err != nil {
+			return fmt.Errorf("failed to list embedded frameworks: %w", err)
+		}
+		for _, binary := range binaries {
+			frameworks = append(frameworks, filepath.Dir(binary))
+		}
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Removing headers and modules of embedded frameworks")
+
+	var identity string
+	var removedSize int64
+	var modifiedFrameworks []string
+	for _, framework := range frameworks {
+		contents, err := disallowedFrameworkContents(framework)
+		if err != nil {
+			return err
+		}
+		if len(contents) == 0 {
+			continue
+		}
+
+		if identity == "" {
+			if identity, err = archiveSigningIdentity(archive); err != nil {
+				return err
+			}
+		}
+
+		for _, content := range contents {
+			size, err := pathSize(content)
+			if err != nil {
+				return err
+			}
+			if err := os.RemoveAll(content); err != nil {
+				return fmt.Errorf("failed to remove %s: %s", content, err)
+			}
+			removedSize += size
+			s.logger.Printf("Removed %s", filepath.Join(filepath.Base(framework), strings.TrimPrefix(content, framework+string(filepath.Separator))))
+		}
+
+		if err := s.resignFramework(framework, identity); err != nil {
+			return err
+		}
+		modifiedFrameworks = append(modifiedFrameworks, framework)
+	}
+
+	if len(modifiedFrameworks) > 0 {
+		if err := s.resignEmbeddingBundles(archive, modifiedFrameworks, identity); err != nil {
+			return err
+		}
+	}
+
+	if removedSize == 0 {
+		s.logger.Donef("The embedded frameworks contain no headers or modules")
+		return nil
+	}
+	s.logger.Donef("Removed %.1f MB from the embedded frameworks", float64(removedSize)/bytesInMB)
+	return nil
+}
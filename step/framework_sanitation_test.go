@@ -0,0 +1,51 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_disallowedFrameworkContents(t *testing.T) {
+	framework := filepath.Join(t.TempDir(), "Sample.framework")
+	for _, dir := range []string{
+		"Headers",
+		filepath.Join("Modules", "Sample.swiftmodule"),
+		filepath.Join("Resources", "Legacy.swiftmodule"),
+		"Assets.car.d",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(framework, dir), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(framework, "Headers", "Sample.h"), []byte("#import <UIKit/UIKit.h>"), 0644))
+
+	contents, err := disallowedFrameworkContents(framework)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(framework, "Headers"),
+		filepath.Join(framework, "Modules"),
+		filepath.Join(framework, "Resources", "Legacy.swiftmodule"),
+	}, contents)
+
+	size, err := pathSize(filepath.Join(framework, "Headers"))
+	require.NoError(t, err)
+	require.Equal(t, int64(len("#import <UIKit/UIKit.h>")), size)
+}
+
+func Test_disallowedFrameworkContents_versioned(t *testing.T) {
+	framework := filepath.Join(t.TempDir(), "Sample.framework")
+	versionDir := filepath.Join(framework, "Versions", "A")
+	require.NoError(t, os.MkdirAll(filepath.Join(versionDir, "Headers"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(versionDir, "Resources"), 0755))
+	require.NoError(t, os.Symlink("A", filepath.Join(framework, "Versions", "Current")))
+	require.NoError(t, os.Symlink(filepath.Join("Versions", "Current", "Headers"), filepath.Join(framework, "Headers")))
+	require.NoError(t, os.Symlink(filepath.Join("Versions", "Current", "Resources"), filepath.Join(framework, "Resources")))
+
+	contents, err := disallowedFrameworkContents(framework)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(framework, "Headers"),
+		filepath.Join(versionDir, "Headers"),
+	}, contents)
+}
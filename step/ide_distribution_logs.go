@@ -0,0 +1,52 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+)
+
+// xcLoggingBaseDirEnvKey overrides the directory, where Xcode creates the IDEDistribution logs (*.xcdistributionlogs).
+// By default they are created in the user's temporary directory under /var/folders.
+const xcLoggingBaseDirEnvKey = "_XCLoggingBaseDir"
+
+// prepareXCLoggingBaseDir makes Xcode create the IDEDistribution logs of the exports in a temporary directory of this build,
+// so that the logs of other builds and users in the shared temporary directories are never picked up.
+// A logging base directory set by the user is kept.
+func prepareXCLoggingBaseDir() (string, error) {
+	if dir := os.Getenv(xcLoggingBaseDirEnvKey); dir != "" {
+		return dir, nil
+	}
+	dir, err := v1pathutil.NormalizedOSTempDirPath("xcdistributionlogs")
+	if err != nil {
+		return "", err
+	}
+	return dir, os.Setenv(xcLoggingBaseDirEnvKey, dir)
+}
+
+// findIDEDistributionLogsSince returns the most recent IDEDistribution logs directory of the given directories,
+// created after the given time, empty if not found.
+func findIDEDistributionLogsSince(dirs []string, since time.Time) string {
+	var (
+		latestPath    string
+		latestModTime time.Time
+	)
+	for _, dir := range dirs {
+		logsDirs, err := filepath.Glob(filepath.Join(escapeGlobPath(dir), "*.xcdistributionlogs"))
+		if err != nil {
+			continue
+		}
+		for _, logsDir := range logsDirs {
+			info, err := os.Stat(logsDir)
+			if err != nil || !info.IsDir() || info.ModTime().Before(since) {
+				continue
+			}
+			if latestPath == "" || info.ModTime().After(latestModTime) {
+				latestPath, latestModTime = logsDir, info.ModTime()
+			}
+		}
+	}
+	return latestPath
+}
@@ -0,0 +1,44 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findIDEDistributionLogsSince(t *testing.T) {
+	baseDir := t.TempDir()
+	otherDir := t.TempDir()
+	now := time.Now()
+
+	oldLogs := filepath.Join(baseDir, "Sample_2024-01-01_10-00-00.xcdistributionlogs")
+	newLogs := filepath.Join(otherDir, "Sample_2024-01-02_10-00-00.xcdistributionlogs")
+	newerLogs := filepath.Join(baseDir, "Sample_2024-01-02_10-00-05.xcdistributionlogs")
+	for _, dir := range []string{oldLogs, newLogs, newerLogs} {
+		require.NoError(t, os.Mkdir(dir, 0755))
+	}
+	require.NoError(t, os.Chtimes(oldLogs, now.Add(-time.Hour), now.Add(-time.Hour)))
+	require.NoError(t, os.Chtimes(newLogs, now.Add(time.Second), now.Add(time.Second)))
+	require.NoError(t, os.Chtimes(newerLogs, now.Add(2*time.Second), now.Add(2*time.Second)))
+
+	require.Equal(t, newerLogs, findIDEDistributionLogsSince([]string{baseDir, otherDir}, now))
+	require.Equal(t, newLogs, findIDEDistributionLogsSince([]string{otherDir}, now))
+	require.Empty(t, findIDEDistributionLogsSince([]string{baseDir}, now.Add(time.Minute)))
+}
+
+func Test_prepareXCLoggingBaseDir(t *testing.T) {
+	userDir := t.TempDir()
+	t.Setenv(xcLoggingBaseDirEnvKey, userDir)
+	dir, err := prepareXCLoggingBaseDir()
+	require.NoError(t, err)
+	require.Equal(t, userDir, dir)
+
+	t.Setenv(xcLoggingBaseDirEnvKey, "")
+	dir, err = prepareXCLoggingBaseDir()
+	require.NoError(t, err)
+	require.DirExists(t, dir)
+	require.Equal(t, dir, os.Getenv(xcLoggingBaseDirEnvKey))
+}
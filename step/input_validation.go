@@ -0,0 +1,249 @@
+package step
+
+import (
+	"fmt"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"howett.net/plist"
+)
+
+// InputValidationError lists every issue of the Step inputs, so they can be fixed at once instead of one by one.
+type InputValidationError struct {
+	Issues []string
+}
+
+// Error returns the numbered list of the issues.
+func (e InputValidationError) Error() string {
+	title := fmt.Sprintf("%d issues found with the Step inputs:", len(e.Issues))
+	if len(e.Issues) == 1 {
+		title = "1 issue found with the Step inputs:"
+	}
+
+	lines := []string{title}
+	for i, issue := range e.Issues {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, issue))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inputIssues collects the issues found while parsing and validating the inputs.
+type inputIssues []string
+
+func (i *inputIssues) add(input string, err error) {
+	*i = append(*i, fmt.Sprintf("issue with input %s: %s", input, err))
+}
+
+func (i *inputIssues) addf(format string, v ...interface{}) {
+	*i = append(*i, fmt.Sprintf(format, v...))
+}
+
+func (i inputIssues) err() error {
+	if len(i) == 0 {
+		return nil
+	}
+	return InputValidationError{Issues: i}
+}
+
+// stepconfParseIssues returns the issues of the stepconf parse error, which lists the fields failed to parse
+// (`- Field: value: reason` lines), followed by the parsed config.
+func stepconfParseIssues(err error) inputIssues {
+	var issues inputIssues
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line == "" {
+			// the list of the failed fields ends before the printed config
+			break
+		}
+		if field, ok := strings.CutPrefix(line, "- "); ok {
+			issues = append(issues, "issue with input "+field)
+		}
+	}
+	if len(issues) == 0 {
+		issues.addf("issue with input: %s", err)
+	}
+	return issues
+}
+
+// inputRule is a constraint of the parsed inputs: a value requirement, a dependency or a mutual exclusion between the inputs.
+// It returns the issue of the config, nil if the config satisfies the rule.
+type inputRule func(config Config) error
+
+// xcodebuildOptionRule excludes an xcodebuild option from the XcodebuildOptions, if the input setting the same option is set.
+func xcodebuildOptionRule(option, input, inputTitle string, isSet func(config Config) bool) inputRule {
+	return func(config Config) error {
+		if sliceutil.IsStringInSlice(option, config.XcodebuildAdditionalOptions) && isSet(config) {
+			return fmt.Errorf("`%s` option found in XcodebuildOptions (`xcodebuild_options`), please clear %s (`%s`) input as only one can be set", option, inputTitle, input)
+		}
+		return nil
+	}
+}
+
+// apiKeyInputsSet returns the number of the set App Store Connect API key connection override inputs.
+func apiKeyInputsSet(config Config) int {
+	count := 0
+	for _, value := range []string{string(config.APIKeyPath), config.APIKeyID, config.APIKeyIssuerID} {
+		if value != "" {
+			count++
+		}
+	}
+	return count
+}
+
+var inputRules = []inputRule{
+	func(config Config) error {
+		if config.MinDaysProfileValid < 0 {
+			return fmt.Errorf("issue with input MinDaysProfileValid: should be at least 0, got: %d", config.MinDaysProfileValid)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.DSYMOnlyArchivePath != "" && config.CompareArchives != "" {
+			return fmt.Errorf("issue with input DSYMOnlyArchivePath: can't be set together with CompareArchives")
+		}
+		return nil
+	},
+	xcodebuildOptionRule("-xcconfig", "xcconfig_content", "Build settings (xcconfig)", func(config Config) bool { return config.XcconfigContent != "" }),
+	xcodebuildOptionRule("-destination", "destination", "Destination", func(config Config) bool { return config.Destination != "" }),
+	xcodebuildOptionRule("-derivedDataPath", "derived_data_path", "DerivedData path", func(config Config) bool { return config.DerivedDataPath != "" }),
+	xcodebuildOptionRule("-archivePath", "archive_path", "Archive path", func(config Config) bool { return config.ArchivePath != "" }),
+	func(config Config) error {
+		if config.CacheLevel == cacheLevelDerivedData && config.DerivedDataPath == "" {
+			return fmt.Errorf("issue with input DerivedDataPath: required when CacheLevel is set to %s", cacheLevelDerivedData)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.ArtifactSigningMethod != artifactSigningNone && config.ArtifactSigningKey == "" {
+			return fmt.Errorf("issue with input ArtifactSigningKey: required when ArtifactSigningMethod is set to %s", config.ArtifactSigningMethod)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.LogFormatter == CustomFormatterTool && strings.TrimSpace(config.LogFormatterCommand) == "" {
+			return fmt.Errorf("issue with input LogFormatterCommand: required when LogFormatter is set to %s", CustomFormatterTool)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.XcprettyReports != "" && config.LogFormatter != XcprettyTool {
+			return fmt.Errorf("issue with input XcprettyReports: only available if LogFormatter is set to %s", XcprettyTool)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if !config.EmbedODRAssetPacksInBundle && config.ODRAssetPacksBaseURL == "" {
+			return fmt.Errorf("issue with input ODRAssetPacksBaseURL: required when EmbedODRAssetPacksInBundle is set to no")
+		}
+		if config.EmbedODRAssetPacksInBundle && config.ODRAssetPacksBaseURL != "" {
+			return fmt.Errorf("issue with input ODRAssetPacksBaseURL: only used if EmbedODRAssetPacksInBundle is set to no")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.MaxWarnings < -1 {
+			return fmt.Errorf("issue with input MaxWarnings: should be -1 (no limit) or greater")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.PackageResolutionRetries < 0 {
+			return fmt.Errorf("issue with input PackageResolutionRetries: should not be negative")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if sliceutil.IsStringInSlice("-scmProvider", config.XcodebuildAdditionalOptions) && config.PackageSCMProvider == packageSCMProviderSystem {
+			return fmt.Errorf("`-scmProvider` option found in XcodebuildOptions (`xcodebuild_options`), please set Package source control provider (`package_scm_provider`) input to %s", packageSCMProviderXcode)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.PackageHostToken != "" && config.PackageHost == "" {
+			return fmt.Errorf("issue with input PackageHost: required when PackageHostToken is set")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.BuildNumberMode == buildNumberModeSet && strings.TrimSpace(config.BuildNumber) == "" {
+			return fmt.Errorf("issue with input BuildNumber: required when BuildNumberMode is set to %s", buildNumberModeSet)
+		}
+		return nil
+	},
+	func(config Config) error {
+		// the build_settings tool passes the build number only to the archive command, an incremented build number would not be saved
+		// and every build of the same commit would get the same build number
+		if config.BuildNumberMode == buildNumberModeIncrement && config.BuildNumberTool == buildNumberToolBuildSettings {
+			return fmt.Errorf("issue with input BuildNumberMode: %s requires the BuildNumberTool to be set to %s, the %s tool does not save the build number", buildNumberModeIncrement, buildNumberToolAgvtool, buildNumberToolBuildSettings)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.ExportOptionsPlistContent == "" {
+			return nil
+		}
+		var options map[string]interface{}
+		if _, err := plist.Unmarshal([]byte(config.ExportOptionsPlistContent), &options); err != nil {
+			return fmt.Errorf("issue with input ExportOptionsPlistContent: %s", err)
+		}
+		if len(config.AdditionalExportMethodList) > 0 {
+			return fmt.Errorf("issue with input AdditionalExportMethods: the export options are generated for each distribution method, please clear ExportOptionsPlistContent")
+		}
+		return nil
+	},
+	func(config Config) error {
+		// the API key inputs override the Bitrise Apple Service connection of the api-key code signing
+		if config.CodeSigningAuthSource == codeSignSourceAppleID && apiKeyInputsSet(config) > 0 {
+			return fmt.Errorf("issue with input APIKeyPath: the App Store Connect API key inputs (`api_key_path`, `api_key_id`, `api_key_issuer_id`) can't be used with %s automatic code signing, please clear them or set Automatic code signing method (`automatic_code_signing`) to %s", codeSignSourceAppleID, codeSignSourceAPIKey)
+		}
+		if set := apiKeyInputsSet(config); config.CodeSigningAuthSource != codeSignSourceOff && set > 0 && set < 3 {
+			return fmt.Errorf("issue with input APIKeyPath: `api_key_path`, `api_key_id` and `api_key_issuer_id` should be set together to override the Bitrise Apple Service connection")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if (config.AppleID == "") != (config.AppleIDPassword == "") {
+			return fmt.Errorf("issue with input AppleID: `apple_id` and `apple_id_password` should be set together")
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.TestDeviceListPath == "" || config.CodeSigningAuthSource == codeSignSourceOff {
+			return nil
+		}
+		if exist, err := v1pathutil.IsPathExists(config.TestDeviceListPath); err != nil {
+			return fmt.Errorf("issue with input TestDeviceListPath: %s", err)
+		} else if !exist {
+			return fmt.Errorf("issue with input TestDeviceListPath: file not found: %s", config.TestDeviceListPath)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.SigningAssetBundlePath == "" {
+			return nil
+		}
+		if exist, err := v1pathutil.IsPathExists(config.SigningAssetBundlePath); err != nil {
+			return fmt.Errorf("issue with input SigningAssetBundlePath: %s", err)
+		} else if !exist {
+			return fmt.Errorf("issue with input SigningAssetBundlePath: file not found: %s", config.SigningAssetBundlePath)
+		}
+		return nil
+	},
+	func(config Config) error {
+		if config.ReadOnlyAppStoreConnect && config.RegisterTestDevices {
+			return fmt.Errorf("issue with input RegisterTestDevices: the test devices can't be registered in read-only App Store Connect mode (`read_only_app_store_connect`), please set Register test devices (`register_test_devices`) to no")
+		}
+		return nil
+	},
+}
+
+// validateInputRules returns the issues of the rules violated by the config.
+func validateInputRules(config Config, rules []inputRule) inputIssues {
+	var issues inputIssues
+	for _, rule := range rules {
+		if err := rule(config); err != nil {
+			issues = append(issues, err.Error())
+		}
+	}
+	return issues
+}
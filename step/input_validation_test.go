@@ -0,0 +1,105 @@
+package step
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputValidationError_Error(t *testing.T) {
+	require.Equal(t, "1 issue found with the Step inputs:\n1. issue with input Scheme: required variable is not present",
+		InputValidationError{Issues: []string{"issue with input Scheme: required variable is not present"}}.Error())
+
+	var issues inputIssues
+	require.NoError(t, issues.err())
+
+	issues.add("Platform", errors.New("unknown platform"))
+	issues.addf("provided XcodebuildOptions (%s) are not valid CLI parameters", "'-quiet")
+	require.EqualError(t, issues.err(), "2 issues found with the Step inputs:\n"+
+		"1. issue with input Platform: unknown platform\n"+
+		"2. provided XcodebuildOptions ('-quiet) are not valid CLI parameters")
+}
+
+func Test_stepconfParseIssues(t *testing.T) {
+	err := errors.New("failed to parse config:\n- Scheme: required variable is not present\n- ExportMethod: invalid option: release\n\nConfig:\n- Scheme: ")
+	require.Equal(t, inputIssues{
+		"issue with input Scheme: required variable is not present",
+		"issue with input ExportMethod: invalid option: release",
+	}, stepconfParseIssues(err))
+
+	require.Equal(t, inputIssues{"issue with input: unexpected error"}, stepconfParseIssues(errors.New("unexpected error")))
+}
+
+func Test_validateInputRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs Inputs
+		want   int
+	}{
+		{
+			name:   "valid inputs",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true},
+			want:   0,
+		},
+		{
+			name:   "apple-id auth with API key",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAppleID, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, APIKeyID: "ABCD1234"},
+			want:   1,
+		},
+		{
+			name:   "partial API key",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, APIKeyPath: "key.p8", APIKeyID: "ABCD1234"},
+			want:   1,
+		},
+		{
+			name:   "custom log formatter without command",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, LogFormatter: CustomFormatterTool},
+			want:   1,
+		},
+		{
+			name:   "hosted asset packs without base URL",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone},
+			want:   1,
+		},
+		{
+			name:   "embedded asset packs with base URL",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, ODRAssetPacksBaseURL: "https://example.com/odr"},
+			want:   1,
+		},
+		{
+			name: "missing test device list and dependent inputs",
+			inputs: Inputs{
+				CodeSigningAuthSource:      codeSignSourceAPIKey,
+				ArtifactSigningMethod:      artifactSigningNone,
+				EmbedODRAssetPacksInBundle: true,
+				TestDeviceListPath:         filepath.Join(t.TempDir(), "devices.txt"),
+				CacheLevel:                 cacheLevelDerivedData,
+				MaxWarnings:                -2,
+			},
+			want: 3,
+		},
+		{
+			name:   "test device registration in read-only App Store Connect mode",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, ReadOnlyAppStoreConnect: true, RegisterTestDevices: true},
+			want:   1,
+		},
+		{
+			name:   "build number increment with build settings",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceAPIKey, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, BuildNumberMode: buildNumberModeIncrement, BuildNumberTool: buildNumberToolBuildSettings},
+			want:   1,
+		},
+		{
+			name:   "missing signing asset bundle",
+			inputs: Inputs{CodeSigningAuthSource: codeSignSourceOff, ArtifactSigningMethod: artifactSigningNone, EmbedODRAssetPacksInBundle: true, SigningAssetBundlePath: filepath.Join(t.TempDir(), "signing-assets.zip")},
+			want:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := validateInputRules(Config{Inputs: tt.inputs}, inputRules)
+			require.Len(t, issues, tt.want, issues)
+		})
+	}
+}
@@ -1,17 +1,17 @@
 package step
 
 import (
-	"strings"
+	"path/filepath"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/bitrise-io/go-xcode/v2/xcodecommand"
 	"github.com/bitrise-io/go-xcode/xcodebuild"
 )
 
-func runIPAExportCommand(xcodeCommandRunner xcodecommand.Runner, logFormatter string, exportCmd *xcodebuild.ExportCommandModel, logger log.Logger) (string, error) {
+func runIPAExportCommand(xcodeCommandRunner xcodecommand.Runner, logFormatter string, exportCmd *xcodebuild.ExportCommandModel, sensitiveValues []string, logger log.Logger) (string, error) {
 	// Log the full command with arguments
 	cmdArgs := exportCmd.CommandArgs()
-	logger.Printf("Running xcodebuild command: xcodebuild %s", strings.Join(cmdArgs, " "))
+	logger.Printf("Running xcodebuild command: %s", printableCommand("xcodebuild", cmdArgs, sensitiveValues))
 	
 	output, err := xcodeCommandRunner.Run("", cmdArgs, []string{})
 	if logFormatter == XcodebuildTool {
@@ -19,6 +19,47 @@ func runIPAExportCommand(xcodeCommandRunner xcodecommand.Runner, logFormatter st
 		// The export log is short, so we print it in entirety.
 		logger.Printf("%s", output.RawOut)
 	}
+	if err != nil {
+		err = newXcodebuildError("-exportArchive", output.ExitCode, string(output.RawOut), err)
+	}
 
 	return string(output.RawOut), err
 }
+
+// checkMessagesApplicationSupport warns if an App Store IPA of an app built around a Messages application stub
+// (for example an iMessage sticker pack) is missing the MessagesApplicationSupport directory.
+func checkMessagesApplicationSupport(archive Archive, exportOptionsPath, ipaExportDir string, logger log.Logger) error {
+	hasStub, err := archive.HasMessagesApplicationStub()
+	if err != nil {
+		return err
+	}
+	if !hasStub {
+		return nil
+	}
+
+	method, err := exportMethodFromExportOptions(exportOptionsPath)
+	if err != nil {
+		return err
+	}
+	if !method.IsAppStore() {
+		return nil
+	}
+
+	ipaPaths, err := filepath.Glob(filepath.Join(escapeGlobPath(ipaExportDir), "*.ipa"))
+	if err != nil {
+		return err
+	}
+
+	for _, ipaPath := range ipaPaths {
+		found, err := ipaContainsMessagesApplicationSupport(ipaPath)
+		if err != nil {
+			return err
+		}
+		if !found {
+			logger.Warnf("The archive contains a %s, but the exported IPA (%s) is missing the %s directory.", messagesApplicationStubDir, filepath.Base(ipaPath), messagesApplicationSupportDir)
+			logger.Warnf("App Store Connect will reject the upload, make sure the app is archived with the Messages application stub.")
+		}
+	}
+
+	return nil
+}
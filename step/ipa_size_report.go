@@ -0,0 +1,143 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const bytesInMB = 1024 * 1024
+
+// ipaSizeReport is the size breakdown of an exported IPA.
+type ipaSizeReport struct {
+	// IPASize is the (compressed) size of the .ipa file
+	IPASize int64 `json:"ipa_size"`
+	// UncompressedSize is the sum of the uncompressed file sizes
+	UncompressedSize int64             `json:"uncompressed_size"`
+	AssetCatalogSize int64             `json:"asset_catalog_size"`
+	FrameworkSizes   map[string]int64  `json:"framework_sizes"`
+	LargestFiles     []ipaFileSizeInfo `json:"largest_files"`
+}
+
+type ipaFileSizeInfo struct {
+	Path           string `json:"path"`
+	Size           int64  `json:"size"`
+	CompressedSize int64  `json:"compressed_size"`
+}
+
+// newIPASizeReport reads the IPA's zip directory (without extracting it) and collects the topFilesCount largest files,
+// the size of the embedded frameworks and the compiled asset catalogs.
+func newIPASizeReport(ipaPath string, topFilesCount int) (ipaSizeReport, error) {
+	info, err := os.Stat(ipaPath)
+	if err != nil {
+		return ipaSizeReport{}, err
+	}
+
+	reader, err := archivezip.OpenReader(ipaPath)
+	if err != nil {
+		return ipaSizeReport{}, fmt.Errorf("failed to open ipa (%s): %s", ipaPath, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	report := ipaSizeReport{
+		IPASize:        info.Size(),
+		FrameworkSizes: map[string]int64{},
+		LargestFiles:   []ipaFileSizeInfo{},
+	}
+
+	var files []ipaFileSizeInfo
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		size := int64(file.UncompressedSize64)
+		report.UncompressedSize += size
+		files = append(files, ipaFileSizeInfo{Path: file.Name, Size: size, CompressedSize: int64(file.CompressedSize64)})
+
+		if path.Base(file.Name) == "Assets.car" {
+			report.AssetCatalogSize += size
+		}
+		if framework := frameworkOfIPAFile(file.Name); framework != "" {
+			report.FrameworkSizes[framework] += size
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	if len(files) > topFilesCount {
+		files = files[:topFilesCount]
+	}
+	report.LargestFiles = append(report.LargestFiles, files...)
+
+	return report, nil
+}
+
+// frameworkOfIPAFile returns the name of the embedded framework (for example Lottie.framework) containing the file.
+func frameworkOfIPAFile(name string) string {
+	components := strings.Split(name, "/")
+	for i, component := range components {
+		if component == "Frameworks" && i+1 < len(components)-1 && strings.HasSuffix(components[i+1], ".framework") {
+			return components[i+1]
+		}
+	}
+	return ""
+}
+
+// checkThresholds returns an error if the IPA or the uncompressed app exceeds the given size limits (in MB, 0 means no limit).
+func (r ipaSizeReport) checkThresholds(maxIPASizeMB, maxAppSizeMB int) error {
+	if maxIPASizeMB > 0 && r.IPASize > int64(maxIPASizeMB)*bytesInMB {
+		return fmt.Errorf("the IPA size (%.1f MB) exceeds the limit of %d MB", float64(r.IPASize)/bytesInMB, maxIPASizeMB)
+	}
+	if maxAppSizeMB > 0 && r.UncompressedSize > int64(maxAppSizeMB)*bytesInMB {
+		return fmt.Errorf("the uncompressed app size (%.1f MB) exceeds the limit of %d MB", float64(r.UncompressedSize)/bytesInMB, maxAppSizeMB)
+	}
+	return nil
+}
+
+type ipaSizeReportOpts struct {
+	IPAPath       string
+	TopFilesCount int
+	MaxIPASizeMB  int
+	MaxAppSizeMB  int
+	OutputDir     string
+	ArtifactName  string
+	OutputPath    func(string) (string, error)
+}
+
+func (s XcodebuildArchiver) exportIPASizeReport(opts ipaSizeReportOpts) error {
+	report, err := newIPASizeReport(opts.IPAPath, opts.TopFilesCount)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("IPA size: %.1f MB (uncompressed: %.1f MB, asset catalogs: %.1f MB)", float64(report.IPASize)/bytesInMB, float64(report.UncompressedSize)/bytesInMB, float64(report.AssetCatalogSize)/bytesInMB)
+	s.logger.Printf("Largest files:")
+	for _, file := range report.LargestFiles {
+		s.logger.Printf("- %s: %.1f MB", file.Path, float64(file.Size)/bytesInMB)
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	reportPath, err := opts.OutputPath(filepath.Join(opts.OutputDir, opts.ArtifactName+".size-report.json"))
+	if err != nil {
+		s.logger.Warnf("Failed to export %s, error: %s", bitriseIPASizeReportPthEnvKey, err)
+	} else if err := ExportOutputFileContent(s.cmdFactory, string(b), reportPath, bitriseIPASizeReportPthEnvKey); err != nil {
+		s.logger.Warnf("Failed to export %s, error: %s", bitriseIPASizeReportPthEnvKey, err)
+	} else {
+		s.logger.Donef("The IPA size report path is now available in the Environment Variable: %s (value: %s)", bitriseIPASizeReportPthEnvKey, reportPath)
+	}
+
+	return report.checkThresholds(opts.MaxIPASizeMB, opts.MaxAppSizeMB)
+}
@@ -0,0 +1,45 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newIPASizeReport(t *testing.T) {
+	ipaPath := filepath.Join(t.TempDir(), "Sample.ipa")
+	f, err := os.Create(ipaPath)
+	require.NoError(t, err)
+	w := archivezip.NewWriter(f)
+	for name, size := range map[string]int{
+		"Payload/Sample.app/Sample":                                 300,
+		"Payload/Sample.app/Assets.car":                             200,
+		"Payload/Sample.app/Frameworks/Lottie.framework/Lottie":     100,
+		"Payload/Sample.app/Frameworks/Lottie.framework/Info.plist": 10,
+		"Payload/Sample.app/Info.plist":                             5,
+	} {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(strings.Repeat("a", size)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	report, err := newIPASizeReport(ipaPath, 2)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(615), report.UncompressedSize)
+	require.Equal(t, int64(200), report.AssetCatalogSize)
+	require.Equal(t, map[string]int64{"Lottie.framework": 110}, report.FrameworkSizes)
+	require.Len(t, report.LargestFiles, 2)
+	require.Equal(t, "Payload/Sample.app/Sample", report.LargestFiles[0].Path)
+	require.Equal(t, "Payload/Sample.app/Assets.car", report.LargestFiles[1].Path)
+
+	require.NoError(t, report.checkThresholds(0, 0))
+	require.Error(t, ipaSizeReport{UncompressedSize: 2 * bytesInMB}.checkThresholds(0, 1))
+}
@@ -0,0 +1,106 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keychainMinLockTimeout is the auto-lock timeout the keychain is extended to, so it doesn't lock during the build.
+const keychainMinLockTimeout = 2 * time.Hour
+
+var keychainTimeoutPattern = regexp.MustCompile(`timeout=(\d+)s`)
+
+// keychainLockedOutputs are the outputs of the security command of a locked keychain,
+// these are the "User interaction is not allowed" codesign failures.
+var keychainLockedOutputs = []string{
+	"User interaction is not allowed",
+	"errSecInteractionNotAllowed",
+}
+
+// parseKeychainLockTimeout returns the auto-lock timeout from the `security show-keychain-info` output,
+// 0 if the keychain doesn't lock after a timeout.
+func parseKeychainLockTimeout(output string) time.Duration {
+	if strings.Contains(output, "no-timeout") {
+		return 0
+	}
+	match := keychainTimeoutPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func isKeychainLockedOutput(output string) bool {
+	for _, lockedOutput := range keychainLockedOutputs {
+		if strings.Contains(output, lockedOutput) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveKeychainPath returns the keychain file, login.keychain is stored as login.keychain-db since macOS Sierra.
+func resolveKeychainPath(pth string) (string, bool) {
+	for _, candidate := range []string{pth, pth + "-db"} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// prepareKeychain unlocks the keychain used for code signing and extends its auto-lock timeout for the build duration.
+func (s XcodebuildArchiver) prepareKeychain(pth, password string) error {
+	keychainPath, ok := resolveKeychainPath(pth)
+	if !ok {
+		s.logger.Debugf("Keychain not found at %s, skipping keychain unlock", pth)
+		return nil
+	}
+
+	s.logger.Infof("Checking keychain: %s", keychainPath)
+
+	output, err := s.runSecurity("show-keychain-info", keychainPath)
+	if err != nil && !isKeychainLockedOutput(output) {
+		return fmt.Errorf("failed to read keychain settings: %s", output)
+	}
+
+	if err != nil {
+		if password == "" {
+			return fmt.Errorf("keychain is locked and the keychain password is not provided")
+		}
+
+		s.logger.Printf("Keychain is locked, unlocking it")
+		if output, err := s.runSecurity("unlock-keychain", "-p", password, keychainPath); err != nil {
+			return fmt.Errorf("failed to unlock keychain: %s", output)
+		}
+		if output, err = s.runSecurity("show-keychain-info", keychainPath); err != nil {
+			return fmt.Errorf("failed to read keychain settings: %s", output)
+		}
+	}
+
+	timeout := parseKeychainLockTimeout(output)
+	if timeout == 0 || timeout >= keychainMinLockTimeout {
+		s.logger.Printf("Keychain is unlocked")
+		return nil
+	}
+
+	s.logger.Printf("Keychain auto-lock timeout (%s) is shorter than %s, extending it", timeout, keychainMinLockTimeout)
+	seconds := strconv.Itoa(int(keychainMinLockTimeout.Seconds()))
+	if output, err := s.runSecurity("set-keychain-settings", "-lut", seconds, keychainPath); err != nil {
+		return fmt.Errorf("failed to set keychain auto-lock timeout: %s", output)
+	}
+	return nil
+}
+
+func (s XcodebuildArchiver) runSecurity(args ...string) (string, error) {
+	cmd := s.cmdFactory.Create("security", args, nil)
+	return cmd.RunAndReturnTrimmedCombinedOutput()
+}
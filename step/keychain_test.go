@@ -0,0 +1,32 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseKeychainLockTimeout(t *testing.T) {
+	require.Equal(t, 300*time.Second, parseKeychainLockTimeout(`Keychain "/Users/vagrant/Library/Keychains/login.keychain-db" lock-on-sleep timeout=300s`))
+	require.Equal(t, time.Duration(0), parseKeychainLockTimeout(`Keychain "/Users/vagrant/Library/Keychains/login.keychain-db" no-timeout`))
+}
+
+func Test_isKeychainLockedOutput(t *testing.T) {
+	require.True(t, isKeychainLockedOutput("security: SecKeychainCopySettings login.keychain-db: User interaction is not allowed."))
+	require.False(t, isKeychainLockedOutput("security: SecKeychainCopySettings login.keychain-db: The specified keychain could not be found."))
+}
+
+func Test_resolveKeychainPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "login.keychain-db"), nil, 0600))
+
+	pth, ok := resolveKeychainPath(filepath.Join(dir, "login.keychain"))
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(dir, "login.keychain-db"), pth)
+
+	_, ok = resolveKeychainPath(filepath.Join(dir, "build.keychain"))
+	require.False(t, ok)
+}
@@ -0,0 +1,169 @@
+package step
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"howett.net/plist"
+)
+
+const (
+	displayNameKey             = "CFBundleDisplayName"
+	usageDescriptionKeySuffix  = "UsageDescription"
+	localizedInfoPlistFileName = "InfoPlist.strings"
+	localizationDirExt         = ".lproj"
+)
+
+// BundleLocalization lists the user facing Info.plist strings of a bundle and their translations.
+type BundleLocalization struct {
+	BundleID string `json:"bundle_id"`
+	Kind     string `json:"kind"`
+	// Base are the strings of the Info.plist
+	Base map[string]string `json:"base"`
+	// Localizations are the strings of the <language>.lproj/InfoPlist.strings files, by language
+	Localizations map[string]map[string]string `json:"localizations"`
+	// Missing are the Info.plist strings without a translation, by language
+	Missing map[string][]string `json:"missing"`
+}
+
+// LocalizationReport collects the display name and permission prompt (usage description) strings of the archived bundles.
+type LocalizationReport struct {
+	Bundles []BundleLocalization `json:"bundles"`
+}
+
+// isLocalizedInfoPlistKey returns true for the Info.plist keys displayed to the users.
+func isLocalizedInfoPlistKey(key string) bool {
+	return key == displayNameKey || strings.HasSuffix(key, usageDescriptionKeySuffix)
+}
+
+func localizedInfoPlistStrings(values map[string]interface{}) map[string]string {
+	strs := map[string]string{}
+	for key, value := range values {
+		if str, ok := value.(string); ok && isLocalizedInfoPlistKey(key) {
+			strs[key] = str
+		}
+	}
+	return strs
+}
+
+// readStringsFile parses a compiled .strings file, which is either a binary property list
+// or a text strings file, encoded in UTF-8 or UTF-16 (with a byte order mark).
+func readStringsFile(pth string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	var byteOrder binary.ByteOrder
+	if bytes.HasPrefix(b, []byte{0xff, 0xfe}) {
+		byteOrder = binary.LittleEndian
+	} else if bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
+		byteOrder = binary.BigEndian
+	}
+	if byteOrder != nil {
+		units := make([]uint16, (len(b)-2)/2)
+		for i := range units {
+			units[i] = byteOrder.Uint16(b[2+2*i:])
+		}
+		b = []byte(string(utf16.Decode(units)))
+	}
+
+	var strs map[string]interface{}
+	if _, err := plist.Unmarshal(b, &strs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", pth, err)
+	}
+	return strs, nil
+}
+
+// newBundleLocalization reads the Info.plist strings of the bundle and their translations.
+func newBundleLocalization(bundlePath, bundleID, kind string, infoPlist plistutil.PlistData) (BundleLocalization, error) {
+	localization := BundleLocalization{
+		BundleID:      bundleID,
+		Kind:          kind,
+		Base:          localizedInfoPlistStrings(infoPlist),
+		Localizations: map[string]map[string]string{},
+		Missing:       map[string][]string{},
+	}
+
+	stringsPaths, err := filepath.Glob(filepath.Join(escapeGlobPath(bundlePath), "*"+localizationDirExt, localizedInfoPlistFileName))
+	if err != nil {
+		return BundleLocalization{}, err
+	}
+	for _, pth := range stringsPaths {
+		language := strings.TrimSuffix(filepath.Base(filepath.Dir(pth)), localizationDirExt)
+		values, err := readStringsFile(pth)
+		if err != nil {
+			return BundleLocalization{}, err
+		}
+
+		translations := localizedInfoPlistStrings(values)
+		localization.Localizations[language] = translations
+
+		var missing []string
+		for key := range localization.Base {
+			if _, ok := translations[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			localization.Missing[language] = missing
+		}
+	}
+	return localization, nil
+}
+
+// NewLocalizationReport collects the localized Info.plist strings of the archived application and its embedded bundles.
+func NewLocalizationReport(archive Archive) (LocalizationReport, error) {
+	report := LocalizationReport{Bundles: []BundleLocalization{}}
+	for _, bundle := range archive.Bundles() {
+		localization, err := newBundleLocalization(bundle.Path, bundle.BundleIdentifier(), bundle.Kind, bundle.InfoPlist)
+		if err != nil {
+			return LocalizationReport{}, err
+		}
+		report.Bundles = append(report.Bundles, localization)
+	}
+	return report, nil
+}
+
+func (s XcodebuildArchiver) exportLocalizationReport(archive Archive, outputDir, artifactName string, outputPath func(string) (string, error)) error {
+	report, err := NewLocalizationReport(archive)
+	if err != nil {
+		return err
+	}
+
+	for _, bundle := range report.Bundles {
+		var languages []string
+		for language := range bundle.Missing {
+			languages = append(languages, language)
+		}
+		sort.Strings(languages)
+		for _, language := range languages {
+			s.logger.Warnf("%s (%s) misses %s translations: %s", bundle.BundleID, bundle.Kind, language, strings.Join(bundle.Missing[language], ", "))
+		}
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	reportPath, err := outputPath(filepath.Join(outputDir, artifactName+".localization-report.json"))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, string(b), reportPath, bitriseLocalizationReportPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseLocalizationReportPthEnvKey, err)
+	}
+	s.logger.Donef("The localization report path is now available in the Environment Variable: %s (value: %s)", bitriseLocalizationReportPthEnvKey, reportPath)
+
+	return nil
+}
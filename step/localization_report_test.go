@@ -0,0 +1,61 @@
+package step
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/stretchr/testify/require"
+	"howett.net/plist"
+)
+
+func writeUTF16StringsFile(t *testing.T, pth, content string) {
+	b := []byte{0xff, 0xfe}
+	for _, unit := range utf16.Encode([]rune(content)) {
+		b = binary.LittleEndian.AppendUint16(b, unit)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+	require.NoError(t, os.WriteFile(pth, b, 0644))
+}
+
+func Test_newBundleLocalization(t *testing.T) {
+	appPath := t.TempDir()
+
+	writeUTF16StringsFile(t, filepath.Join(appPath, "de.lproj", localizedInfoPlistFileName), `/* Kamera */
+"NSCameraUsageDescription" = "Für Fotos";
+"CFBundleDisplayName" = "Beispiel";`)
+
+	binaryStrings, err := plist.Marshal(map[string]string{"CFBundleDisplayName": "Exemple"}, plist.BinaryFormat)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(appPath, "fr.lproj"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(appPath, "fr.lproj", localizedInfoPlistFileName), binaryStrings, 0644))
+
+	localization, err := newBundleLocalization(appPath, "io.bitrise.sample", bundleKindApplication, plistutil.PlistData{
+		"CFBundleDisplayName":          "Sample",
+		"CFBundleIdentifier":           "io.bitrise.sample",
+		"NSCameraUsageDescription":     "For photos",
+		"NFCReaderUsageDescription":    "For tags",
+		"UIRequiredDeviceCapabilities": []interface{}{"arm64"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, BundleLocalization{
+		BundleID: "io.bitrise.sample",
+		Kind:     bundleKindApplication,
+		Base: map[string]string{
+			"CFBundleDisplayName":       "Sample",
+			"NSCameraUsageDescription":  "For photos",
+			"NFCReaderUsageDescription": "For tags",
+		},
+		Localizations: map[string]map[string]string{
+			"de": {"CFBundleDisplayName": "Beispiel", "NSCameraUsageDescription": "Für Fotos"},
+			"fr": {"CFBundleDisplayName": "Exemple"},
+		},
+		Missing: map[string][]string{
+			"de": {"NFCReaderUsageDescription"},
+			"fr": {"NFCReaderUsageDescription", "NSCameraUsageDescription"},
+		},
+	}, localization)
+}
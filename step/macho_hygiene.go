@@ -0,0 +1,149 @@
+package step
+
+import (
+	"debug/macho"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+const (
+	loadCmdEncryptionInfo   = 0x21
+	loadCmdEncryptionInfo64 = 0x2c
+
+	// machoStabMask matches the debugging (N_STAB) symbol table entries
+	machoStabMask = 0xe0
+)
+
+// machoHygieneIssue is a finding of the static analysis of an archived binary.
+type machoHygieneIssue struct {
+	BinaryPath string
+	Arch       string
+	Message    string
+}
+
+func (i machoHygieneIssue) String() string {
+	return fmt.Sprintf("%s (%s): %s", i.BinaryPath, i.Arch, i.Message)
+}
+
+// machoHygieneIssues inspects the architecture slices of the binary for encryption info anomalies,
+// embedded DWARF debug info, unsafe RPATH entries and unstripped symbol tables.
+func machoHygieneIssues(pth string, isMainExecutable bool) ([]machoHygieneIssue, error) {
+	var issues []machoHygieneIssue
+	if err := forEachMachOSlice(pth, func(f *macho.File) {
+		arch := archName(f.Cpu, f.SubCpu)
+		for _, message := range machoSliceHygieneIssues(f, isMainExecutable) {
+			issues = append(issues, machoHygieneIssue{BinaryPath: pth, Arch: arch, Message: message})
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func machoSliceHygieneIssues(f *macho.File, isMainExecutable bool) []string {
+	var issues []string
+
+	hasEncryptionInfo := false
+	for _, load := range f.Loads {
+		if rpath, ok := load.(*macho.Rpath); ok && !isSafeRpath(rpath.Path) {
+			issues = append(issues, fmt.Sprintf("unsafe RPATH entry: %s", rpath.Path))
+			continue
+		}
+
+		raw := load.Raw()
+		if len(raw) < 20 {
+			continue
+		}
+		if cmd := f.ByteOrder.Uint32(raw); cmd == loadCmdEncryptionInfo || cmd == loadCmdEncryptionInfo64 {
+			hasEncryptionInfo = true
+			if cryptID := f.ByteOrder.Uint32(raw[16:]); cryptID != 0 {
+				issues = append(issues, fmt.Sprintf("the binary is already encrypted (cryptid: %d)", cryptID))
+			}
+		}
+	}
+	if isMainExecutable && !hasEncryptionInfo {
+		issues = append(issues, "LC_ENCRYPTION_INFO load command not found")
+	}
+
+	if f.Segment("__DWARF") != nil || f.Section("__debug_info") != nil {
+		issues = append(issues, "contains embedded DWARF debug info")
+	}
+
+	if f.Dysymtab != nil && f.Dysymtab.Nlocalsym > 0 {
+		issues = append(issues, fmt.Sprintf("symbol table is not stripped (%d local symbols)", f.Dysymtab.Nlocalsym))
+	} else if f.Symtab != nil {
+		for _, symbol := range f.Symtab.Syms {
+			if symbol.Type&machoStabMask != 0 {
+				issues = append(issues, "symbol table contains debugging symbols")
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
+// isSafeRpath returns true for the bundle relative and system library search paths.
+func isSafeRpath(pth string) bool {
+	return strings.HasPrefix(pth, "@executable_path") || strings.HasPrefix(pth, "@loader_path") || strings.HasPrefix(pth, "/usr/lib/")
+}
+
+func bundleExecutable(bundlePath string, infoPlist plistutil.PlistData) (string, bool) {
+	executable, ok := infoPlist.GetString("CFBundleExecutable")
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(bundlePath, executable), true
+}
+
+// checkMachOHygiene runs the static analysis on the executables of the archived application, its extensions
+// and embedded frameworks. The findings are reported as warnings.
+func (s XcodebuildArchiver) checkMachOHygiene(archive Archive) {
+	s.logger.Println()
+	s.logger.Infof("Checking the archived binaries")
+
+	mainExecutable, ok := bundleExecutable(archive.Application.Path, archive.Application.InfoPlist)
+	if !ok {
+		s.logger.Warnf("CFBundleExecutable not found in the app's Info.plist")
+		return
+	}
+
+	var executables []string
+	for _, extension := range archive.Extensions() {
+		if executable, ok := bundleExecutable(extension.Path, extension.InfoPlist); ok {
+			executables = append(executables, executable)
+		}
+	}
+
+	frameworks, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		s.logger.Warnf("Failed to list embedded frameworks: %s", err)
+	}
+	executables = append(executables, frameworks...)
+
+	var issues []machoHygieneIssue
+	for _, executable := range append([]string{mainExecutable}, executables...) {
+		binaryIssues, err := machoHygieneIssues(executable, executable == mainExecutable)
+		if err != nil {
+			s.logger.Warnf("Failed to read Mach-O binary (%s): %s", executable, err)
+			continue
+		}
+		issues = append(issues, binaryIssues...)
+	}
+
+	if len(issues) == 0 {
+		s.logger.Donef("No issues found in the archived binaries")
+		return
+	}
+
+	for _, issue := range issues {
+		relPath, err := filepath.Rel(archive.Application.Path, issue.BinaryPath)
+		if err == nil {
+			issue.BinaryPath = relPath
+		}
+		s.logger.Warnf("%s", issue)
+	}
+}
@@ -0,0 +1,30 @@
+package step
+
+import (
+	"debug/macho"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_machoHygieneIssues(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "MyApp")
+	require.NoError(t, os.WriteFile(pth, testMachO(macho.CpuArm64, 0, map[string]uint64{"__DWARF": 4096}), 0600))
+
+	issues, err := machoHygieneIssues(pth, true)
+	require.NoError(t, err)
+	require.Equal(t, []machoHygieneIssue{
+		{BinaryPath: pth, Arch: "arm64", Message: "LC_ENCRYPTION_INFO load command not found"},
+		{BinaryPath: pth, Arch: "arm64", Message: "contains embedded DWARF debug info"},
+	}, issues)
+}
+
+func Test_isSafeRpath(t *testing.T) {
+	require.True(t, isSafeRpath("@executable_path/Frameworks"))
+	require.True(t, isSafeRpath("@loader_path/Frameworks"))
+	require.True(t, isSafeRpath("/usr/lib/swift"))
+	require.False(t, isSafeRpath("/Users/vagrant/Library/Developer/Xcode/DerivedData"))
+	require.False(t, isSafeRpath("Frameworks"))
+}
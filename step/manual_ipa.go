@@ -0,0 +1,92 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1command "github.com/bitrise-io/go-utils/command"
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	payloadDirName      = "Payload"
+	swiftSupportDirName = "SwiftSupport"
+)
+
+// checkManualIPAPackaging returns an error if the archived app can't be distributed with the given export method
+// without re-signing: the App Store requires the processing of xcodebuild -exportArchive,
+// and the other methods need the app to be signed with a profile of the same distribution type.
+func checkManualIPAPackaging(exportMethod, archiveExportMethod exportoptions.Method) error {
+	if exportMethod.IsAppStore() {
+		return fmt.Errorf("App Store IPAs can't be packaged manually")
+	}
+	if exportoptions.UpgradeToXcode15_3MethodName(exportMethod) != exportoptions.UpgradeToXcode15_3MethodName(archiveExportMethod) {
+		return fmt.Errorf("the archived app is signed for %s distribution, but %s distribution was requested", archiveExportMethod, exportMethod)
+	}
+	return nil
+}
+
+// verifyCodeSignature checks the code signature of the bundle and its nested code.
+func verifyCodeSignature(cmdFactory command.Factory, bundlePath string) error {
+	cmd := cmdFactory.Create("codesign", []string{"--verify", "--deep", "--strict", bundlePath}, nil)
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("invalid code signature of %s: %s, output: %s", filepath.Base(bundlePath), err, out)
+	}
+	return nil
+}
+
+// packageIPAManually zips the archived app into an IPA (Payload/<app>.app), including the archive's SwiftSupport directory,
+// and returns the directory of the IPA.
+func (s XcodebuildArchiver) packageIPAManually(archive xcarchive.IosArchive, exportMethod exportoptions.Method) (string, error) {
+	if err := checkManualIPAPackaging(exportMethod, archive.Application.ProvisioningProfile.ExportType); err != nil {
+		return "", err
+	}
+	if err := verifyCodeSignature(s.cmdFactory, archive.Application.Path); err != nil {
+		return "", err
+	}
+
+	tmpDir, err := v1pathutil.NormalizedOSTempDirPath("manualIPAPackaging")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir, error: %s", err)
+	}
+
+	contentDir := filepath.Join(tmpDir, "content")
+	payloadDir := filepath.Join(contentDir, payloadDirName)
+	if err := os.MkdirAll(payloadDir, 0755); err != nil {
+		return "", err
+	}
+	if err := v1command.CopyDir(archive.Application.Path, payloadDir, false); err != nil {
+		return "", fmt.Errorf("failed to copy the app into the Payload directory: %s", err)
+	}
+
+	entries := []string{payloadDirName}
+	swiftSupportDir := filepath.Join(archive.Path, swiftSupportDirName)
+	if exist, err := v1pathutil.IsDirExists(swiftSupportDir); err != nil {
+		return "", err
+	} else if exist {
+		if err := v1command.CopyDir(swiftSupportDir, contentDir, false); err != nil {
+			return "", fmt.Errorf("failed to copy the SwiftSupport directory: %s", err)
+		}
+		entries = append(entries, swiftSupportDirName)
+	}
+
+	ipaDir := filepath.Join(tmpDir, "exported")
+	if err := os.MkdirAll(ipaDir, 0755); err != nil {
+		return "", err
+	}
+	ipaName := strings.TrimSuffix(filepath.Base(archive.Application.Path), ".app") + ".ipa"
+	ipaPath := filepath.Join(ipaDir, ipaName)
+
+	args := append([]string{"-qry", ipaPath}, entries...)
+	cmd := s.cmdFactory.Create("/usr/bin/zip", args, &command.Opts{Dir: contentDir})
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to zip the IPA: %s, output: %s", err, out)
+	}
+
+	return ipaDir, nil
+}
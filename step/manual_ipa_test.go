@@ -0,0 +1,32 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkManualIPAPackaging(t *testing.T) {
+	tests := []struct {
+		name                string
+		exportMethod        exportoptions.Method
+		archiveExportMethod exportoptions.Method
+		wantErr             bool
+	}{
+		{name: "same method", exportMethod: exportoptions.MethodAdHoc, archiveExportMethod: exportoptions.MethodAdHoc},
+		{name: "Xcode 15.3 method name", exportMethod: exportoptions.MethodDebugging, archiveExportMethod: exportoptions.MethodDevelopment},
+		{name: "App Store", exportMethod: exportoptions.MethodAppStoreConnect, archiveExportMethod: exportoptions.MethodAppStore, wantErr: true},
+		{name: "re-signing required", exportMethod: exportoptions.MethodAdHoc, archiveExportMethod: exportoptions.MethodDevelopment, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkManualIPAPackaging(tt.exportMethod, tt.archiveExportMethod)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package step
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	v1command "github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+)
+
+// AdditionalIPAExport is an IPA exported from the archive with an additional distribution method.
+type AdditionalIPAExport struct {
+	ExportMethod               string
+	ExportOptionsPath          string
+	IPAExportDir               string
+	XcodebuildExportArchiveLog string
+}
+
+// parseExportMethods parses the newline separated list of distribution methods,
+// the main distribution method can't be listed again.
+func parseExportMethods(content, mainExportMethod string) ([]string, error) {
+	var methods []string
+	for _, method := range matrixLines(content) {
+		if !sliceutil.IsStringInSlice(method, exportMethods) {
+			return nil, fmt.Errorf("invalid distribution method: %s, available: %s", method, strings.Join(exportMethods, ", "))
+		}
+		if method == mainExportMethod || sliceutil.IsStringInSlice(method, methods) {
+			return nil, fmt.Errorf("duplicated distribution method: %s", method)
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// exportAdditionalMethods exports the archive with each distribution method.
+// The xcodebuild -exportArchive invocations are independent, so they run concurrently, at most concurrency at a time.
+// The results keep the order of the methods, failed exports are left out.
+func (s XcodebuildArchiver) exportAdditionalMethods(opts xcodeIPAExportOpts, methods []string, concurrency int) ([]AdditionalIPAExport, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]AdditionalIPAExport, len(methods))
+	errs := make([]error, len(methods))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i, method := range methods {
+		wg.Add(1)
+		go func(i int, method string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			methodOpts := opts
+			methodOpts.ExportMethod = method
+			methodOpts.ConcurrentExport = concurrency > 1
+			exportOut, err := s.xcodeIPAExport(methodOpts)
+			results[i] = AdditionalIPAExport{
+				ExportMethod:               method,
+				ExportOptionsPath:          exportOut.ExportOptionsPath,
+				IPAExportDir:               exportOut.IPAExportDir,
+				XcodebuildExportArchiveLog: exportOut.XcodebuildExportArchiveLog,
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", method, err)
+			}
+		}(i, method)
+	}
+	wg.Wait()
+
+	var exports []AdditionalIPAExport
+	for i, result := range results {
+		if errs[i] == nil {
+			exports = append(exports, result)
+		}
+	}
+	return exports, errors.Join(errs...)
+}
+
+// prepareAdditionalCodesigning ensures the code signing assets of the additional distribution methods,
+// one method at a time, before their concurrent export.
+func (s XcodebuildArchiver) prepareAdditionalCodesigning(managers []*codesign.Manager) error {
+	if len(managers) == 0 {
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Preparing code signing assets (certificates, profiles) of the additional distribution methods")
+
+	unlockProfiles, err := s.lockProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to lock provisioning profiles: %w", err)
+	}
+	defer unlockProfiles()
+
+	endPhase := s.startPhase(phaseCodeSigning)
+	for _, manager := range managers {
+		// the xcodebuild authentication of the main distribution method is used by every export
+		if _, err := manager.PrepareCodesigning(); err != nil {
+			endPhase(err)
+			return fmt.Errorf("failed to manage code signing: %s", err)
+		}
+	}
+	endPhase(nil)
+	return nil
+}
+
+// additionalExportsLog returns the xcodebuild -exportArchive logs of the additional exports, each preceded by its distribution method.
+func additionalExportsLog(exports []AdditionalIPAExport) string {
+	var log string
+	for _, export := range exports {
+		log += fmt.Sprintf("\n\n=== %s ===\n\n%s", export.ExportMethod, export.XcodebuildExportArchiveLog)
+	}
+	return log
+}
+
+// findIPAFiles returns the .ipa files of the export dir.
+func findIPAFiles(exportDir string) ([]string, error) {
+	var ipaFiles []string
+	if err := filepath.Walk(exportDir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(pth) == ".ipa" {
+			ipaFiles = append(ipaFiles, pth)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to search for .ipa file, error: %s", err)
+	}
+	if len(ipaFiles) == 0 {
+		return nil, fmt.Errorf("no .ipa file found at export dir: %s", exportDir)
+	}
+	return ipaFiles, nil
+}
+
+// exportAdditionalIPAs copies the first IPA of each additional export into the output dir,
+// named after the distribution method if the name would match the main IPA's name.
+func (s XcodebuildArchiver) exportAdditionalIPAs(exports []AdditionalIPAExport, opts ExportOpts, nameValues artifactNameValues, ipaName string, outputPath func(string) (string, error)) ([]exportedArtifact, error) {
+	var artifacts []exportedArtifact
+	for _, export := range exports {
+		ipaFiles, err := findIPAFiles(export.IPAExportDir)
+		if err != nil {
+			return artifacts, fmt.Errorf("%s: %w", export.ExportMethod, err)
+		}
+
+		methodValues := nameValues
+		methodValues.ExportMethod = export.ExportMethod
+		if method, err := exportMethodFromExportOptions(export.ExportOptionsPath); err == nil {
+			methodValues.ExportMethod = string(method)
+		}
+		name := artifactFileName(".ipa", methodValues, opts.IPANameTemplate, opts.ArtifactNameTemplate)
+		if name == ipaName {
+			name += "-" + export.ExportMethod
+		}
+
+		pth, err := outputPath(filepath.Join(opts.OutputDir, name+".ipa"))
+		if err != nil {
+			return artifacts, err
+		}
+		if err := v1command.CopyFile(ipaFiles[0], pth); err != nil {
+			return artifacts, fmt.Errorf("failed to copy (%s) -> (%s), error: %s", ipaFiles[0], pth, err)
+		}
+		s.logger.Donef("The %s ipa is exported to: %s", export.ExportMethod, pth)
+
+		artifacts = append(artifacts, exportedArtifact{
+			Path:         pth,
+			Type:         artifactTypeIPA,
+			ExportMethod: methodValues.ExportMethod,
+		})
+	}
+	return artifacts, nil
+}
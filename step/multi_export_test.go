@@ -0,0 +1,68 @@
+package step
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseExportMethods(t *testing.T) {
+	methods, err := parseExportMethods("ad-hoc\n\n development \n", "app-store")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ad-hoc", "development"}, methods)
+
+	methods, err = parseExportMethods("", "app-store")
+	require.NoError(t, err)
+	require.Empty(t, methods)
+
+	_, err = parseExportMethods("release-testing", "app-store")
+	require.Error(t, err)
+
+	_, err = parseExportMethods("ad-hoc\napp-store", "app-store")
+	require.Error(t, err)
+
+	_, err = parseExportMethods("ad-hoc\nad-hoc", "app-store")
+	require.Error(t, err)
+}
+
+func Test_additionalExportsLog(t *testing.T) {
+	log := additionalExportsLog([]AdditionalIPAExport{
+		{ExportMethod: "ad-hoc", XcodebuildExportArchiveLog: "** EXPORT SUCCEEDED **"},
+		{ExportMethod: "development", XcodebuildExportArchiveLog: "** EXPORT FAILED **"},
+	})
+	require.Equal(t, "\n\n=== ad-hoc ===\n\n** EXPORT SUCCEEDED **\n\n=== development ===\n\n** EXPORT FAILED **", log)
+}
+
+func TestXcodebuildArchiver_exportAdditionalIPAs(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not available")
+	}
+
+	exportDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(exportDir, "Sample.ipa"), []byte("ipa"), 0644))
+	outputDir := t.TempDir()
+
+	archiver := XcodebuildArchiver{logger: log.NewLogger()}
+	outputPath := func(pth string) (string, error) {
+		return pth, nil
+	}
+	artifacts, err := archiver.exportAdditionalIPAs(
+		[]AdditionalIPAExport{{ExportMethod: "ad-hoc", IPAExportDir: exportDir}},
+		ExportOpts{OutputDir: outputDir, IPANameTemplate: "{product}"},
+		artifactNameValues{ArtifactName: "Sample"},
+		"Sample",
+		outputPath,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []exportedArtifact{
+		{Path: filepath.Join(outputDir, "Sample-ad-hoc.ipa"), Type: artifactTypeIPA, ExportMethod: "ad-hoc"},
+	}, artifacts)
+	require.FileExists(t, filepath.Join(outputDir, "Sample-ad-hoc.ipa"))
+
+	_, err = archiver.exportAdditionalIPAs([]AdditionalIPAExport{{ExportMethod: "ad-hoc", IPAExportDir: t.TempDir()}}, ExportOpts{OutputDir: outputDir}, artifactNameValues{}, "Sample", outputPath)
+	require.Error(t, err)
+}
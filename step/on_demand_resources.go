@@ -0,0 +1,127 @@
+package step
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+)
+
+const (
+	// onDemandResourcesPlist is written into the app bundle if the app uses On-Demand Resources
+	onDemandResourcesPlist = "OnDemandResources.plist"
+	// onDemandResourcesDir holds the asset packs, next to the app in the archive and next to the IPA in the export dir
+	onDemandResourcesDir = "OnDemandResources"
+	assetPackExt         = ".assetpack"
+)
+
+// OnDemandResourcesOpts are the On-Demand Resources related export options, used by the non app-store distribution methods.
+type OnDemandResourcesOpts struct {
+	// EmbedAssetPacksInBundle embeds the asset packs in the app bundle instead of hosting them
+	EmbedAssetPacksInBundle bool
+	// AssetPacksBaseURL is the URL the asset packs are downloaded from, if they are not embedded in the app bundle
+	AssetPacksBaseURL string
+}
+
+// parseOnDemandResourcesBaseURL validates the base URL of the hosted asset packs, an empty URL is valid.
+func parseOnDemandResourcesBaseURL(baseURL string) (string, error) {
+	if baseURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("should be an absolute http(s) URL: %s", baseURL)
+	}
+	return baseURL, nil
+}
+
+// UsesOnDemandResources returns true if the main application was built with On-Demand Resources.
+func (a Archive) UsesOnDemandResources() bool {
+	exists, err := v1pathutil.IsPathExists(filepath.Join(a.Application.Path, onDemandResourcesPlist))
+	return err == nil && exists
+}
+
+// OnDemandResourcesAssetPacks returns the asset packs of the archive, both the embedded and the hosted ones.
+func (a Archive) OnDemandResourcesAssetPacks() ([]string, error) {
+	productsDir := filepath.Join(a.Path, "Products")
+
+	var assetPacks []string
+	if err := filepath.Walk(productsDir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && filepath.Ext(pth) == assetPackExt {
+			assetPacks = append(assetPacks, pth)
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to search for asset packs: %s", err)
+	}
+	sort.Strings(assetPacks)
+	return assetPacks, nil
+}
+
+// applyOnDemandResourcesOptions sets the On-Demand Resources export options, the App Store always hosts the asset packs.
+func applyOnDemandResourcesOptions(exportOpts exportoptions.ExportOptions, opts OnDemandResourcesOpts) exportoptions.ExportOptions {
+	options, ok := exportOpts.(exportoptions.NonAppStoreOptionsModel)
+	if !ok {
+		return exportOpts
+	}
+	options.EmbedOnDemandResourcesAssetPacksInBundle = opts.EmbedAssetPacksInBundle
+	options.OnDemandResourcesAssetPacksBaseURL = opts.AssetPacksBaseURL
+	return options
+}
+
+// printOnDemandResources lists the asset packs of the archive and how they are exported.
+func (s XcodebuildArchiver) printOnDemandResources(archive Archive, exportMethod string, opts OnDemandResourcesOpts) {
+	if !archive.UsesOnDemandResources() {
+		return
+	}
+
+	assetPacks, err := archive.OnDemandResourcesAssetPacks()
+	if err != nil {
+		s.logger.Warnf("Failed to list the On-Demand Resources asset packs: %s", err)
+		return
+	}
+
+	s.logger.Println()
+	s.logger.Printf("On-Demand Resources asset packs found in the archive:")
+	for _, assetPack := range assetPacks {
+		s.logger.Printf("- %s", filepath.Base(assetPack))
+	}
+
+	if exportoptions.Method(exportMethod).IsAppStore() {
+		return
+	}
+	if opts.EmbedAssetPacksInBundle {
+		s.logger.Printf("The asset packs are embedded in the app bundle")
+	} else {
+		s.logger.Printf("The asset packs are exported next to the IPA, they should be hosted at: %s", opts.AssetPacksBaseURL)
+	}
+}
+
+// exportOnDemandResources exports the zipped asset packs of the export dir, if they are not embedded in the IPA.
+func (s XcodebuildArchiver) exportOnDemandResources(ipaExportDir, outputDir, ipaName string, outputPath func(string) (string, error)) error {
+	assetPacksDir := filepath.Join(ipaExportDir, onDemandResourcesDir)
+	if exists, err := v1pathutil.IsDirExists(assetPacksDir); err != nil || !exists {
+		return err
+	}
+
+	zipPath, err := outputPath(filepath.Join(outputDir, ipaName+".OnDemandResources.zip"))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputDirAsZip(s.cmdFactory, assetPacksDir, zipPath, bitriseOnDemandResourcesZipPthEnvKey, s.logger); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseOnDemandResourcesZipPthEnvKey, err)
+	}
+	s.logger.Donef("The On-Demand Resources asset packs zip path is now available in the Environment Variable: %s (value: %s)", bitriseOnDemandResourcesZipPthEnvKey, zipPath)
+	return nil
+}
@@ -0,0 +1,63 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseOnDemandResourcesBaseURL(t *testing.T) {
+	baseURL, err := parseOnDemandResourcesBaseURL("")
+	require.NoError(t, err)
+	require.Equal(t, "", baseURL)
+
+	baseURL, err = parseOnDemandResourcesBaseURL("https://example.com/odr")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/odr", baseURL)
+
+	_, err = parseOnDemandResourcesBaseURL("example.com/odr")
+	require.EqualError(t, err, "should be an absolute http(s) URL: example.com/odr")
+}
+
+func Test_applyOnDemandResourcesOptions(t *testing.T) {
+	opts := OnDemandResourcesOpts{AssetPacksBaseURL: "https://example.com/odr"}
+
+	adHocOptions := applyOnDemandResourcesOptions(exportoptions.NewNonAppStoreOptions(exportoptions.MethodAdHoc), opts).Hash()
+	require.Equal(t, false, adHocOptions[exportoptions.EmbedOnDemandResourcesAssetPacksInBundleKey])
+	require.Equal(t, "https://example.com/odr", adHocOptions[exportoptions.OnDemandResourcesAssetPacksBaseURLKey])
+
+	appStoreOptions := applyOnDemandResourcesOptions(exportoptions.NewAppStoreOptions(), opts).Hash()
+	require.NotContains(t, appStoreOptions, exportoptions.EmbedOnDemandResourcesAssetPacksInBundleKey)
+	require.NotContains(t, appStoreOptions, exportoptions.OnDemandResourcesAssetPacksBaseURLKey)
+}
+
+func TestArchive_OnDemandResourcesAssetPacks(t *testing.T) {
+	archivePath := t.TempDir()
+	appPath := filepath.Join(archivePath, "Products", "Applications", "Sample.app")
+	for _, dir := range []string{
+		filepath.Join(appPath, onDemandResourcesDir, "io.bitrise.sample.tag2.assetpack"),
+		filepath.Join(archivePath, "Products", onDemandResourcesDir, "io.bitrise.sample.tag1.assetpack", "Contents"),
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(appPath, onDemandResourcesPlist), []byte{}, 0644))
+
+	archive := NewArchive(xcarchive.IosArchive{
+		Path: archivePath,
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: xcarchive.IosBaseApplication{Path: appPath},
+		},
+	})
+	require.True(t, archive.UsesOnDemandResources())
+
+	assetPacks, err := archive.OnDemandResourcesAssetPacks()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(appPath, onDemandResourcesDir, "io.bitrise.sample.tag2.assetpack"),
+		filepath.Join(archivePath, "Products", onDemandResourcesDir, "io.bitrise.sample.tag1.assetpack"),
+	}, assetPacks)
+}
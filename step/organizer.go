@@ -0,0 +1,62 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1command "github.com/bitrise-io/go-utils/command"
+)
+
+// organizerArchivesDir is the directory (relative to the home directory), where Xcode stores the archives listed in the Organizer.
+const organizerArchivesDir = "Library/Developer/Xcode/Archives"
+
+// organizerArchivePath returns the path Xcode would store the archive at: the archives are grouped by the
+// creation day (<yyyy-MM-dd>/) and named after the archive and its creation time (<name> <M-d-yy, h.mm a>.xcarchive).
+// A numbered suffix is added if an archive with the same name exists.
+func organizerArchivePath(archivesDir, name string, creationDate time.Time) (string, error) {
+	dayDir := filepath.Join(archivesDir, creationDate.Format("2006-01-02"))
+	baseName := fmt.Sprintf("%s %s", fileNameSafe(name), creationDate.Format("1-2-06, 3.04 PM"))
+
+	pth := filepath.Join(dayDir, baseName+xcarchiveExt)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(pth); os.IsNotExist(err) {
+			return pth, nil
+		} else if err != nil {
+			return "", err
+		}
+		pth = filepath.Join(dayDir, fmt.Sprintf("%s %d%s", baseName, i, xcarchiveExt))
+	}
+}
+
+// copyArchiveToOrganizer copies the archive into the user's Xcode archives directory, so that it is listed in the Organizer.
+func (s XcodebuildArchiver) copyArchiveToOrganizer(archive Archive) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	name := archive.SchemeName()
+	if name == "" {
+		name = applicationName(archive.Application)
+	}
+	creationDate, ok := archive.CreationDate()
+	if !ok {
+		creationDate = time.Now()
+	}
+
+	pth, err := organizerArchivePath(filepath.Join(homeDir, organizerArchivesDir), name, creationDate.Local())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pth, 0755); err != nil {
+		return err
+	}
+	if err := v1command.CopyDir(archive.Path, pth, true); err != nil {
+		return fmt.Errorf("failed to copy (%s) to (%s): %s", archive.Path, pth, err)
+	}
+
+	s.logger.Donef("The xcarchive is copied to the Xcode Organizer: %s", pth)
+	return nil
+}
@@ -0,0 +1,24 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_organizerArchivePath(t *testing.T) {
+	archivesDir := t.TempDir()
+	creationDate := time.Date(2024, 5, 1, 14, 5, 30, 0, time.UTC)
+
+	pth, err := organizerArchivePath(archivesDir, "Sample App", creationDate)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(archivesDir, "2024-05-01", "Sample App 5-1-24, 2.05 PM.xcarchive"), pth)
+
+	require.NoError(t, os.MkdirAll(pth, 0755))
+	pth, err = organizerArchivePath(archivesDir, "Sample App", creationDate)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(archivesDir, "2024-05-01", "Sample App 5-1-24, 2.05 PM 2.xcarchive"), pth)
+}
@@ -0,0 +1,83 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	outputOverwritePolicyOverwrite    = "overwrite"
+	outputOverwritePolicyFail         = "fail"
+	outputOverwritePolicyUniqueSuffix = "unique-suffix"
+)
+
+// outputExtensions are the multi-part extensions of the exported files, the unique suffix is inserted before them.
+var outputExtensions = []string{
+	".xcarchive.zip",
+	".dSYM.zip",
+	".dSYM.tar.zst",
+	".build-logs.zip",
+	".xcdistributionlogs.zip",
+	".icon.png",
+	".simulator.app.zip",
+	".watch.app.zip",
+	".app.zip",
+}
+
+// artifactOutputFileName prefixes the file name with the artifact name,
+// so the outputs of the scheme matrix entries don't overwrite each other.
+func artifactOutputFileName(artifactName, fileName string) string {
+	return artifactName + "-" + fileName
+}
+
+// splitOutputExtension splits the file name into the name and the (possibly multi-part) extension.
+func splitOutputExtension(fileName string) (string, string) {
+	for _, ext := range outputExtensions {
+		if strings.HasSuffix(fileName, ext) && len(fileName) > len(ext) {
+			return strings.TrimSuffix(fileName, ext), ext
+		}
+	}
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext), ext
+}
+
+// uniqueOutputPath returns the first non-existing path of the <name>-<n><ext> form.
+func uniqueOutputPath(pth string) (string, error) {
+	dir := filepath.Dir(pth)
+	name, ext := splitOutputExtension(filepath.Base(pth))
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, name+"-"+strconv.Itoa(i)+ext)
+		if exist, err := v1pathutil.IsPathExists(candidate); err != nil {
+			return "", fmt.Errorf("failed to check if path (%s) exist, error: %s", candidate, err)
+		} else if !exist {
+			return candidate, nil
+		}
+	}
+}
+
+// resolveOutputPath applies the overwrite policy to an output path, and returns the path the output should be written to.
+func resolveOutputPath(pth, policy string) (string, error) {
+	exist, err := v1pathutil.IsPathExists(pth)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if path (%s) exist, error: %s", pth, err)
+	} else if !exist {
+		return pth, nil
+	}
+
+	switch policy {
+	case outputOverwritePolicyFail:
+		return "", fmt.Errorf("output path (%s) already exists, remove it or change the output overwrite policy", pth)
+	case outputOverwritePolicyUniqueSuffix:
+		return uniqueOutputPath(pth)
+	default:
+		if err := os.RemoveAll(pth); err != nil {
+			return "", fmt.Errorf("failed to remove path (%s), error: %s", pth, err)
+		}
+		return pth, nil
+	}
+}
@@ -0,0 +1,55 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitOutputExtension(t *testing.T) {
+	tests := []struct {
+		fileName string
+		wantName string
+		wantExt  string
+	}{
+		{fileName: "Sample.ipa", wantName: "Sample", wantExt: ".ipa"},
+		{fileName: "Sample.xcarchive.zip", wantName: "Sample", wantExt: ".xcarchive.zip"},
+		{fileName: "My.App.dSYM.tar.zst", wantName: "My.App", wantExt: ".dSYM.tar.zst"},
+		{fileName: artifactOutputFileName("Sample", "xcodebuild.xcdistributionlogs.zip"), wantName: "Sample-xcodebuild", wantExt: ".xcdistributionlogs.zip"},
+		{fileName: "Sample", wantName: "Sample", wantExt: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			name, ext := splitOutputExtension(tt.fileName)
+			require.Equal(t, tt.wantName, name)
+			require.Equal(t, tt.wantExt, ext)
+		})
+	}
+}
+
+func Test_resolveOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "Sample.xcarchive.zip")
+	require.NoError(t, os.WriteFile(existing, []byte("previous build"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Sample-1.xcarchive.zip"), []byte("previous build"), 0644))
+
+	missing := filepath.Join(dir, "Sample.ipa")
+	pth, err := resolveOutputPath(missing, outputOverwritePolicyFail)
+	require.NoError(t, err)
+	require.Equal(t, missing, pth)
+
+	_, err = resolveOutputPath(existing, outputOverwritePolicyFail)
+	require.Error(t, err)
+
+	pth, err = resolveOutputPath(existing, outputOverwritePolicyUniqueSuffix)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "Sample-2.xcarchive.zip"), pth)
+	require.FileExists(t, existing)
+
+	pth, err = resolveOutputPath(existing, outputOverwritePolicyOverwrite)
+	require.NoError(t, err)
+	require.Equal(t, existing, pth)
+	require.NoFileExists(t, existing)
+}
@@ -40,6 +40,14 @@ func parsePlatform(platform string) (Platform, error) {
 	}
 }
 
+// genericDestination returns the xcodebuild generic destination specifier of the platform.
+func genericDestination(platform Platform) string {
+	if platform == osX {
+		return "generic/platform=macOS"
+	}
+	return "generic/platform=" + string(platform)
+}
+
 func OpenArchivableProject(pth, schemeName, configurationName string) (*xcodeproj.XcodeProj, *xcscheme.Scheme, string, error) {
 	scheme, schemeContainerDir, err := schemeint.Scheme(pth, schemeName)
 	if err != nil {
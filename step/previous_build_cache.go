@@ -0,0 +1,31 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	stepcache "github.com/bitrise-io/go-steputils/cache"
+)
+
+// previousBuildCacheDir stores the build reports compared with the next build, it is included in the Bitrise build cache.
+const previousBuildCacheDir = ".steps-xcode-archive"
+
+// previousBuildDir returns the directory of the given report kind in the previous build cache.
+func previousBuildDir(kind string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, previousBuildCacheDir, kind), nil
+}
+
+// commitPreviousBuildDir marks the directory for caching, so it is available for the next build.
+func commitPreviousBuildDir(dir string) error {
+	cache := stepcache.New()
+	cache.IncludePath(dir)
+	if err := cache.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache, error: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+const privacyManifestFileName = "PrivacyInfo.xcprivacy"
+
+// sdksRequiringPrivacyManifest is Apple's list of commonly used third-party SDKs,
+// which need to ship a privacy manifest when included in an app:
+// https://developer.apple.com/support/third-party-SDK-requirements/
+var sdksRequiringPrivacyManifest = []string{
+	"Abseil", "AFNetworking", "Alamofire", "AppAuth", "BoringSSL", "openssl_grpc", "Capacitor", "Charts",
+	"connectivity_plus", "Cordova", "device_info_plus", "DKImagePickerController", "DKPhotoGallery",
+	"FBAEMKit", "FBLPromises", "FBSDKCoreKit", "FBSDKCoreKit_Basics", "FBSDKLoginKit", "FBSDKShareKit",
+	"file_picker", "FirebaseABTesting", "FirebaseAuth", "FirebaseCore", "FirebaseCoreDiagnostics",
+	"FirebaseCoreExtension", "FirebaseCoreInternal", "FirebaseCrashlytics", "FirebaseDynamicLinks",
+	"FirebaseFirestore", "FirebaseInstallations", "FirebaseMessaging", "FirebaseRemoteConfig", "Flutter",
+	"flutter_inappwebview", "flutter_local_notifications", "fluttertoast", "FMDB", "geolocator_apple",
+	"GoogleDataTransport", "GoogleSignIn", "GoogleToolboxForMac", "GoogleUtilities", "grpcpp", "GTMAppAuth",
+	"GTMSessionFetcher", "hermes", "image_picker_ios", "IQKeyboardManager", "IQKeyboardManagerSwift",
+	"Kingfisher", "leveldb", "Lottie", "MBProgressHUD", "nanopb", "OneSignal", "OneSignalCore",
+	"OneSignalExtension", "OneSignalOutcomes", "OpenSSL", "OrderedSet", "package_info", "package_info_plus",
+	"path_provider", "path_provider_ios", "Promises", "Protobuf", "Reachability", "RealmSwift", "RxCocoa",
+	"RxRelay", "RxSwift", "SDWebImage", "share_plus", "shared_preferences_ios", "SnapKit", "sqflite",
+	"Starscream", "SVProgressHUD", "SwiftyGif", "SwiftyJSON", "Toast", "UnityFramework", "url_launcher",
+	"url_launcher_ios", "video_player_avfoundation", "wakelock", "webview_flutter_wkwebview",
+}
+
+// PrivacyReport aggregates the privacy manifests found in the archived application.
+type PrivacyReport struct {
+	// Manifests are the privacy manifest paths, relative to the application bundle
+	Manifests          []string            `json:"manifests"`
+	Tracking           bool                `json:"tracking"`
+	TrackingDomains    []string            `json:"tracking_domains"`
+	CollectedDataTypes []string            `json:"collected_data_types"`
+	AccessedAPITypes   map[string][]string `json:"accessed_api_types"`
+	// FrameworksMissingManifest are the embedded frameworks on Apple's list of commonly used SDKs without a privacy manifest
+	FrameworksMissingManifest []string `json:"frameworks_missing_manifest"`
+}
+
+// NewPrivacyReport scans the application bundle (including its extensions and embedded frameworks) for privacy manifests.
+func NewPrivacyReport(appPath string) (PrivacyReport, error) {
+	report := PrivacyReport{
+		Manifests:                 []string{},
+		TrackingDomains:           []string{},
+		CollectedDataTypes:        []string{},
+		AccessedAPITypes:          map[string][]string{},
+		FrameworksMissingManifest: []string{},
+	}
+
+	if err := filepath.Walk(appPath, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != privacyManifestFileName {
+			return nil
+		}
+
+		manifest, err := plistutil.NewPlistDataFromFile(pth)
+		if err != nil {
+			return fmt.Errorf("failed to parse privacy manifest (%s): %s", pth, err)
+		}
+
+		relPath, err := filepath.Rel(appPath, pth)
+		if err != nil {
+			return err
+		}
+		report.Manifests = append(report.Manifests, relPath)
+		report.add(manifest)
+
+		return nil
+	}); err != nil {
+		return PrivacyReport{}, err
+	}
+
+	frameworks, err := findBundles(appPath, "Frameworks", ".framework")
+	if err != nil {
+		return PrivacyReport{}, err
+	}
+	for _, framework := range frameworks {
+		name := strings.TrimSuffix(filepath.Base(framework), ".framework")
+		if !sliceutil.IsStringInSlice(name, sdksRequiringPrivacyManifest) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(appPath, framework)
+		if err != nil {
+			return PrivacyReport{}, err
+		}
+
+		hasManifest := false
+		for _, manifest := range report.Manifests {
+			if strings.HasPrefix(manifest, relPath+string(filepath.Separator)) {
+				hasManifest = true
+				break
+			}
+		}
+		if !hasManifest {
+			report.FrameworksMissingManifest = append(report.FrameworksMissingManifest, name)
+		}
+	}
+
+	sort.Strings(report.TrackingDomains)
+	sort.Strings(report.CollectedDataTypes)
+
+	return report, nil
+}
+
+func (r *PrivacyReport) add(manifest plistutil.PlistData) {
+	if tracking, ok := manifest.GetBool("NSPrivacyTracking"); ok && tracking {
+		r.Tracking = true
+	}
+
+	if domains, ok := manifest.GetStringArray("NSPrivacyTrackingDomains"); ok {
+		for _, domain := range domains {
+			if !sliceutil.IsStringInSlice(domain, r.TrackingDomains) {
+				r.TrackingDomains = append(r.TrackingDomains, domain)
+			}
+		}
+	}
+
+	if dataTypes, ok := manifest.GetMapStringInterfaceArray("NSPrivacyCollectedDataTypes"); ok {
+		for _, dataType := range dataTypes {
+			name, ok := dataType.GetString("NSPrivacyCollectedDataType")
+			if ok && !sliceutil.IsStringInSlice(name, r.CollectedDataTypes) {
+				r.CollectedDataTypes = append(r.CollectedDataTypes, name)
+			}
+		}
+	}
+
+	if apiTypes, ok := manifest.GetMapStringInterfaceArray("NSPrivacyAccessedAPITypes"); ok {
+		for _, apiType := range apiTypes {
+			name, ok := apiType.GetString("NSPrivacyAccessedAPIType")
+			if !ok {
+				continue
+			}
+
+			reasons := r.AccessedAPITypes[name]
+			if reasons == nil {
+				reasons = []string{}
+			}
+			newReasons, _ := apiType.GetStringArray("NSPrivacyAccessedAPITypeReasons")
+			for _, reason := range newReasons {
+				if !sliceutil.IsStringInSlice(reason, reasons) {
+					reasons = append(reasons, reason)
+				}
+			}
+			sort.Strings(reasons)
+			r.AccessedAPITypes[name] = reasons
+		}
+	}
+}
+
+// JSON ...
+func (r PrivacyReport) JSON() (string, error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (s XcodebuildArchiver) exportPrivacyReport(appPath, outputDir, artifactName string, outputPath func(string) (string, error)) error {
+	report, err := NewPrivacyReport(appPath)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("Found %d privacy manifests.", len(report.Manifests))
+	for _, framework := range report.FrameworksMissingManifest {
+		s.logger.Warnf("%s.framework is on Apple's list of commonly used third-party SDKs, but it does not contain a privacy manifest (%s)", framework, privacyManifestFileName)
+	}
+
+	content, err := report.JSON()
+	if err != nil {
+		return err
+	}
+
+	reportPath, err := outputPath(filepath.Join(outputDir, artifactName+".privacy-report.json"))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, content, reportPath, bitrisePrivacyReportPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitrisePrivacyReportPthEnvKey, err)
+	}
+	s.logger.Donef("The privacy report path is now available in the Environment Variable: %s (value: %s)", bitrisePrivacyReportPthEnvKey, reportPath)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAppPrivacyManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSPrivacyTracking</key>
+	<false/>
+	<key>NSPrivacyCollectedDataTypes</key>
+	<array>
+		<dict>
+			<key>NSPrivacyCollectedDataType</key>
+			<string>NSPrivacyCollectedDataTypeEmailAddress</string>
+		</dict>
+	</array>
+	<key>NSPrivacyAccessedAPITypes</key>
+	<array>
+		<dict>
+			<key>NSPrivacyAccessedAPIType</key>
+			<string>NSPrivacyAccessedAPICategoryUserDefaults</string>
+			<key>NSPrivacyAccessedAPITypeReasons</key>
+			<array>
+				<string>CA92.1</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+const testFrameworkPrivacyManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSPrivacyTracking</key>
+	<true/>
+	<key>NSPrivacyTrackingDomains</key>
+	<array>
+		<string>tracking.example.com</string>
+	</array>
+	<key>NSPrivacyAccessedAPITypes</key>
+	<array>
+		<dict>
+			<key>NSPrivacyAccessedAPIType</key>
+			<string>NSPrivacyAccessedAPICategoryUserDefaults</string>
+			<key>NSPrivacyAccessedAPITypeReasons</key>
+			<array>
+				<string>1C8F.1</string>
+				<string>CA92.1</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+func TestNewPrivacyReport(t *testing.T) {
+	appPath := filepath.Join(t.TempDir(), "Sample.app")
+	writeTestFile(t, filepath.Join(appPath, "PrivacyInfo.xcprivacy"), testAppPrivacyManifest)
+	writeTestFile(t, filepath.Join(appPath, "Frameworks", "Alamofire.framework", "Alamofire_Privacy.bundle", "PrivacyInfo.xcprivacy"), testFrameworkPrivacyManifest)
+	writeTestFile(t, filepath.Join(appPath, "Frameworks", "SnapKit.framework", "SnapKit"), "")
+	writeTestFile(t, filepath.Join(appPath, "Frameworks", "InHouse.framework", "InHouse"), "")
+
+	report, err := NewPrivacyReport(appPath)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"PrivacyInfo.xcprivacy",
+		filepath.Join("Frameworks", "Alamofire.framework", "Alamofire_Privacy.bundle", "PrivacyInfo.xcprivacy"),
+	}, report.Manifests)
+	require.True(t, report.Tracking)
+	require.Equal(t, []string{"tracking.example.com"}, report.TrackingDomains)
+	require.Equal(t, []string{"NSPrivacyCollectedDataTypeEmailAddress"}, report.CollectedDataTypes)
+	require.Equal(t, map[string][]string{"NSPrivacyAccessedAPICategoryUserDefaults": {"1C8F.1", "CA92.1"}}, report.AccessedAPITypes)
+	require.Equal(t, []string{"SnapKit"}, report.FrameworksMissingManifest)
+}
+
+func writeTestFile(t *testing.T, pth, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+	require.NoError(t, os.WriteFile(pth, []byte(content), 0644))
+}
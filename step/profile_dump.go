@@ -0,0 +1,170 @@
+package step
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-xcode/profileutil"
+)
+
+// profileDirs are the installed provisioning profile directories, Xcode 16 moved them under the Xcode user data.
+var profileDirs = []string{
+	"Library/MobileDevice/Provisioning Profiles",
+	"Library/Developer/Xcode/UserData/Provisioning Profiles",
+}
+
+// codeSigningFailurePatterns identify the code signing failures in the errors and the xcodebuild logs.
+var codeSigningFailurePatterns = []string{
+	"code signing",
+	"codesign",
+	"provisioning profile",
+	"signing certificate",
+	"no profiles for",
+	"no account for team",
+	"failed to manage code signing",
+}
+
+// profileDump is the decoded summary of an installed provisioning profile.
+type profileDump struct {
+	Path                 string                 `json:"path"`
+	UUID                 string                 `json:"uuid,omitempty"`
+	Name                 string                 `json:"name,omitempty"`
+	AppID                string                 `json:"app_id,omitempty"`
+	TeamID               string                 `json:"team_id,omitempty"`
+	TeamName             string                 `json:"team_name,omitempty"`
+	DistributionType     string                 `json:"distribution_type,omitempty"`
+	Platform             string                 `json:"platform,omitempty"`
+	XcodeManaged         bool                   `json:"xcode_managed"`
+	Entitlements         map[string]interface{} `json:"entitlements,omitempty"`
+	DevicesCount         int                    `json:"devices_count"`
+	ProvisionsAllDevices bool                   `json:"provisions_all_devices"`
+	Certificates         []certificateDump      `json:"certificates,omitempty"`
+	CreationDate         *time.Time             `json:"creation_date,omitempty"`
+	ExpirationDate       *time.Time             `json:"expiration_date,omitempty"`
+	Expired              bool                   `json:"expired"`
+	// Error is set if the profile can't be decoded
+	Error string `json:"error,omitempty"`
+}
+
+type certificateDump struct {
+	CommonName     string    `json:"common_name"`
+	Serial         string    `json:"serial"`
+	TeamID         string    `json:"team_id"`
+	ExpirationDate time.Time `json:"expiration_date"`
+}
+
+// isCodeSigningFailure returns true if the error or the xcodebuild logs of the failed build refer to code signing.
+func isCodeSigningFailure(err error, logs ...string) bool {
+	texts := append([]string{err.Error()}, logs...)
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, pattern := range codeSigningFailurePatterns {
+			if strings.Contains(text, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newProfileDump(pth string, info profileutil.ProvisioningProfileInfoModel, now time.Time) profileDump {
+	dump := profileDump{
+		Path:                 pth,
+		UUID:                 info.UUID,
+		Name:                 info.Name,
+		AppID:                info.BundleID,
+		TeamID:               info.TeamID,
+		TeamName:             info.TeamName,
+		DistributionType:     string(info.ExportType),
+		Platform:             string(info.Type),
+		XcodeManaged:         info.IsXcodeManaged(),
+		Entitlements:         info.Entitlements,
+		DevicesCount:         len(info.ProvisionedDevices),
+		ProvisionsAllDevices: info.ProvisionsAllDevices,
+		CreationDate:         &info.CreationDate,
+		ExpirationDate:       &info.ExpirationDate,
+		Expired:              info.ExpirationDate.Before(now),
+	}
+	for _, certificate := range info.DeveloperCertificates {
+		dump.Certificates = append(dump.Certificates, certificateDump{
+			CommonName:     certificate.CommonName,
+			Serial:         certificate.Serial,
+			TeamID:         certificate.TeamID,
+			ExpirationDate: certificate.EndDate,
+		})
+	}
+	return dump
+}
+
+// dumpInstalledProfiles decodes every provisioning profile of the profile directories under the home directory.
+func dumpInstalledProfiles(homeDir string, now time.Time) ([]profileDump, error) {
+	dumps := []profileDump{}
+	for _, dir := range profileDirs {
+		for _, ext := range []string{"*.mobileprovision", "*.provisionprofile"} {
+			pths, err := filepath.Glob(filepath.Join(escapeGlobPath(filepath.Join(homeDir, dir)), ext))
+			if err != nil {
+				return nil, err
+			}
+			for _, pth := range pths {
+				info, err := profileutil.NewProvisioningProfileInfoFromFile(pth)
+				if err != nil {
+					dumps = append(dumps, profileDump{Path: pth, Error: err.Error()})
+					continue
+				}
+				dumps = append(dumps, newProfileDump(pth, info, now))
+			}
+		}
+	}
+	sort.Slice(dumps, func(i, j int) bool {
+		return dumps[i].Path < dumps[j].Path
+	})
+	return dumps, nil
+}
+
+// ExportProfileDump writes the summaries of the installed provisioning profiles to a JSON file if the run failed with a code signing error,
+// to debug code signing failures without accessing the build machine.
+func (s XcodebuildArchiver) ExportProfileDump(runErr error, result RunResult, outputDir, outputOverwritePolicy string) {
+	if runErr == nil || !isCodeSigningFailure(runErr, result.XcodebuildArchiveLog, result.XcodebuildExportArchiveLog) {
+		return
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Dumping installed provisioning profiles")
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		s.logger.Warnf("Failed to dump provisioning profiles: %s", err)
+		return
+	}
+	dumps, err := dumpInstalledProfiles(homeDir, time.Now())
+	if err != nil {
+		s.logger.Warnf("Failed to dump provisioning profiles: %s", err)
+		return
+	}
+	s.logger.Printf("%d provisioning profiles found", len(dumps))
+
+	content, err := json.MarshalIndent(dumps, "", "  ")
+	if err != nil {
+		s.logger.Warnf("Failed to dump provisioning profiles: %s", err)
+		return
+	}
+
+	name := "provisioning_profiles.json"
+	if result.ArtifactName != "" {
+		name = result.ArtifactName + ".profiles.json"
+	}
+	dumpPath, err := resolveOutputPath(filepath.Join(outputDir, name), outputOverwritePolicy)
+	if err != nil {
+		s.logger.Warnf("Failed to export %s, error: %s", bitriseProfileDumpPthEnvKey, err)
+		return
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, string(content), dumpPath, bitriseProfileDumpPthEnvKey); err != nil {
+		s.logger.Warnf("Failed to export %s, error: %s", bitriseProfileDumpPthEnvKey, err)
+		return
+	}
+	s.logger.Donef("The provisioning profile dump path is now available in the Environment Variable: %s (value: %s)", bitriseProfileDumpPthEnvKey, dumpPath)
+}
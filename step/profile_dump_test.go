@@ -0,0 +1,35 @@
+package step
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isCodeSigningFailure(t *testing.T) {
+	require.True(t, isCodeSigningFailure(errors.New("failed to manage code signing: no valid certificate")))
+	require.True(t, isCodeSigningFailure(errors.New("archive failed"), `error: No profiles for 'io.bitrise.sample' were found`))
+	require.False(t, isCodeSigningFailure(errors.New("archive failed"), "error: cannot find 'Foo' in scope"))
+}
+
+func Test_dumpInstalledProfiles(t *testing.T) {
+	homeDir := t.TempDir()
+	dumps, err := dumpInstalledProfiles(homeDir, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, dumps)
+
+	profilesDir := filepath.Join(homeDir, "Library/MobileDevice/Provisioning Profiles")
+	require.NoError(t, os.MkdirAll(profilesDir, 0755))
+	pth := filepath.Join(profilesDir, "invalid.mobileprovision")
+	require.NoError(t, os.WriteFile(pth, []byte("not a profile"), 0644))
+
+	dumps, err = dumpInstalledProfiles(homeDir, time.Now())
+	require.NoError(t, err)
+	require.Len(t, dumps, 1)
+	require.Equal(t, pth, dumps[0].Path)
+	require.NotEmpty(t, dumps[0].Error)
+}
@@ -0,0 +1,427 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient/appstoreconnect"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/localcodesignasset"
+	"github.com/fullsailor/pkcs7"
+	"howett.net/plist"
+)
+
+const (
+	// macCatalystVariant is the xcodebuild destination variant of the iOS apps built for the Mac
+	macCatalystVariant = "variant=Mac Catalyst"
+	// macCatalystBundleIDPrefix is the prefix of the Mac Catalyst bundle IDs derived from the iOS bundle IDs
+	macCatalystBundleIDPrefix = "maccatalyst."
+)
+
+// profilePlatformsByPlatform maps the app's platform to the values of the profile's Platform array, which sign the app.
+// The visionOS profiles list xrOS or visionOS, next to iOS if the profile signs the iOS apps too.
+var profilePlatformsByPlatform = map[autocodesign.Platform][]string{
+	autocodesign.IOS:                {string(profileutil.ProfileTypeIos)},
+	autocodesign.TVOS:               {string(profileutil.ProfileTypeTvOs)},
+	autocodesign.MacOS:              {string(profileutil.ProfileTypeMacOs)},
+	autocodesign.Platform(visionOS): {"xros", "visionos"},
+}
+
+// isMacCatalystDestination returns true if the xcodebuild destination builds the iOS app for the Mac.
+func isMacCatalystDestination(destination string) bool {
+	for _, specifier := range strings.Split(destination, ",") {
+		if strings.EqualFold(strings.TrimSpace(specifier), macCatalystVariant) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMacCatalystBuild returns true if the Destination input or a -destination of the additional xcodebuild options
+// builds the iOS app for the Mac.
+func isMacCatalystBuild(destination string, additionalOptions []string) bool {
+	if isMacCatalystDestination(destination) {
+		return true
+	}
+	for i, option := range additionalOptions {
+		if option == "-destination" && i+1 < len(additionalOptions) && isMacCatalystDestination(additionalOptions[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// installedProfile is an installed provisioning profile.
+type installedProfile struct {
+	Info profileutil.ProvisioningProfileInfoModel
+	// Platforms are the lowercased values of the profile's Platform array, for example ios, osx or tvos
+	Platforms []string
+	Content   []byte
+}
+
+func readInstalledProfile(pth string) (installedProfile, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return installedProfile{}, err
+	}
+	profile, err := profileutil.ProvisioningProfileFromContent(content)
+	if err != nil {
+		return installedProfile{}, err
+	}
+
+	var data plistutil.PlistData
+	if _, err := plist.Unmarshal(profile.Content, &data); err != nil {
+		return installedProfile{}, err
+	}
+	platforms, _ := data.GetStringArray("Platform")
+	for i, platform := range platforms {
+		platforms[i] = strings.ToLower(platform)
+	}
+
+	info, err := profileutil.NewProvisioningProfileInfo(*profile)
+	if err != nil && len(platforms) > 0 && sliceutil.IsStringInSlice(platforms[0], profilePlatformsByPlatform[autocodesign.Platform(visionOS)]) {
+		// profileutil knows only the iOS, tvOS and macOS profiles, the visionOS profiles are read as iOS profiles
+		info, err = visionOSProfileInfo(data)
+	}
+	if err != nil {
+		return installedProfile{}, err
+	}
+	return installedProfile{Info: info, Platforms: platforms, Content: content}, nil
+}
+
+func visionOSProfileInfo(data plistutil.PlistData) (profileutil.ProvisioningProfileInfoModel, error) {
+	iOSData := plistutil.PlistData{}
+	for key, value := range data {
+		iOSData[key] = value
+	}
+	iOSData["Platform"] = []interface{}{string(autocodesign.IOS)}
+
+	iOSContent, err := plist.Marshal(map[string]interface{}(iOSData), plist.XMLFormat)
+	if err != nil {
+		return profileutil.ProvisioningProfileInfoModel{}, err
+	}
+	return profileutil.NewProvisioningProfileInfo(pkcs7.PKCS7{Content: iOSContent})
+}
+
+// listInstalledProfiles returns the iOS, tvOS, visionOS and macOS provisioning profiles of the profile directories under the home directory,
+// the profiles failed to read are skipped.
+func listInstalledProfiles(homeDir string, logger log.Logger) ([]installedProfile, error) {
+	var profiles []installedProfile
+	for _, dir := range profileDirs {
+		for _, ext := range []string{"*.mobileprovision", "*.provisionprofile"} {
+			pths, err := filepath.Glob(filepath.Join(escapeGlobPath(filepath.Join(homeDir, dir)), ext))
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(pths)
+			for _, pth := range pths {
+				profile, err := readInstalledProfile(pth)
+				if err != nil {
+					logger.Debugf("Skipping provisioning profile (%s): %s", pth, err)
+					continue
+				}
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// profileLookup finds the installed provisioning profiles of the automatic code signing.
+// It replaces the lookup of the localcodesignasset package, which matches the profile's type to the app's platform
+// and so misses the Mac Catalyst (macOS) profiles of the iOS apps.
+type profileLookup struct {
+	listProfiles func() ([]installedProfile, error)
+	// macCatalyst is true if the iOS app is built for the Mac, so it is signed with Mac Catalyst (macOS) profiles
+	macCatalyst bool
+	// readOnly is true in read-only App Store Connect mode, where the missing profiles can't be generated
+	readOnly bool
+	logger   log.Logger
+}
+
+func newProfileLookup(macCatalyst, readOnly bool, logger log.Logger) profileLookup {
+	return profileLookup{
+		listProfiles: func() ([]installedProfile, error) {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			return listInstalledProfiles(homeDir, logger)
+		},
+		macCatalyst: macCatalyst,
+		readOnly:    readOnly,
+		logger:      logger,
+	}
+}
+
+// FindCodesignAssets returns the installed profiles of the app layout's targets, and the app layout of the targets without a profile
+// (nil if every target has a profile).
+func (l profileLookup) FindCodesignAssets(appLayout autocodesign.AppLayout, distrType autocodesign.DistributionType, certsByType map[appstoreconnect.CertificateType][]autocodesign.Certificate, deviceIDs []string, minProfileDaysValid int) (*autocodesign.AppCodesignAssets, *autocodesign.AppLayout, error) {
+	profiles, err := l.listProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certs := certsByType[autocodesign.CertificateTypeByDistribution[distrType]]
+	var certSerials []string
+	for _, cert := range certs {
+		certSerials = append(certSerials, cert.CertificateInfo.Serial)
+	}
+
+	criteria := profileCriteria{
+		Platform:            appLayout.Platform,
+		MacCatalyst:         l.macCatalyst,
+		DistributionType:    distrType,
+		MinProfileDaysValid: minProfileDaysValid,
+		CertificateSerials:  certSerials,
+		DeviceUDIDs:         deviceIDs,
+	}
+
+	var asset *autocodesign.AppCodesignAssets
+	addProfile := func(bundleID string, profile installedProfile, entitlements autocodesign.Entitlements, uiTest bool) {
+		l.logger.Printf("Installed profile selected for %s: %s (%s)", bundleID, profile.Info.Name, profile.Info.UUID)
+		for _, reason := range profileChoiceExplanation(profile, entitlements, criteria, certs, time.Now()) {
+			l.logger.Printf("- %s", reason)
+		}
+
+		if asset == nil {
+			asset = &autocodesign.AppCodesignAssets{}
+		}
+		profiles := &asset.ArchivableTargetProfilesByBundleID
+		if uiTest {
+			profiles = &asset.UITestTargetProfilesByBundleID
+		}
+		if *profiles == nil {
+			*profiles = map[string]autocodesign.Profile{}
+		}
+		(*profiles)[bundleID] = localcodesignasset.NewProfile(profile.Info, profile.Content)
+	}
+
+	remaining := autocodesign.AppLayout{
+		Platform:                               appLayout.Platform,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{},
+		UITestTargetBundleIDs:                  appLayout.UITestTargetBundleIDs,
+	}
+	var bundleIDs []string
+	for bundleID := range appLayout.EntitlementsByArchivableTargetBundleID {
+		bundleIDs = append(bundleIDs, bundleID)
+	}
+	sort.Strings(bundleIDs)
+	for _, bundleID := range bundleIDs {
+		entitlements := appLayout.EntitlementsByArchivableTargetBundleID[bundleID]
+		profile := l.findInstalledProfile(profiles, bundleID, entitlements, criteria)
+		if profile == nil {
+			remaining.EntitlementsByArchivableTargetBundleID[bundleID] = entitlements
+			continue
+		}
+		addProfile(bundleID, *profile, entitlements, false)
+	}
+
+	if distrType == autocodesign.Development {
+		remaining.UITestTargetBundleIDs = nil
+		for _, bundleID := range appLayout.UITestTargetBundleIDs {
+			wildcardBundleID, err := autocodesign.CreateWildcardBundleID(bundleID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not create wildcard bundle id: %s", err)
+			}
+
+			// Capabilities are not supported for UITest targets.
+			profile := l.findInstalledProfile(profiles, wildcardBundleID, nil, criteria)
+			if profile == nil {
+				remaining.UITestTargetBundleIDs = append(remaining.UITestTargetBundleIDs, bundleID)
+				continue
+			}
+			addProfile(bundleID, *profile, nil, true)
+		}
+	}
+
+	if asset != nil {
+		// every profile requires a certificate, so the certificate of the found profiles is available
+		certificate, err := autocodesign.SelectCertificate(certsByType, distrType)
+		if err != nil {
+			return nil, nil, err
+		}
+		asset.Certificate = certificate.CertificateInfo
+	}
+
+	if len(remaining.EntitlementsByArchivableTargetBundleID) == 0 && len(remaining.UITestTargetBundleIDs) == 0 {
+		return asset, nil, nil
+	}
+	if l.readOnly {
+		return nil, nil, missingProfilesError(remaining, distrType)
+	}
+	return asset, &remaining, nil
+}
+
+// missingProfilesError fails the read-only App Store Connect mode, instead of generating the missing profiles.
+func missingProfilesError(remaining autocodesign.AppLayout, distrType autocodesign.DistributionType) error {
+	var bundleIDs []string
+	for bundleID := range remaining.EntitlementsByArchivableTargetBundleID {
+		bundleIDs = append(bundleIDs, bundleID)
+	}
+	sort.Strings(bundleIDs)
+	bundleIDs = append(bundleIDs, remaining.UITestTargetBundleIDs...)
+	return fmt.Errorf("read-only App Store Connect mode: no installed %s provisioning profile found for %s, install the profiles (or provide them in fallback_provisioning_profile_url_list), as they can't be generated", distrType, strings.Join(bundleIDs, ", "))
+}
+
+// profileCriteria are the requirements of the profiles, which are common for every target of the app.
+type profileCriteria struct {
+	Platform            autocodesign.Platform
+	MacCatalyst         bool
+	DistributionType    autocodesign.DistributionType
+	MinProfileDaysValid int
+	CertificateSerials  []string
+	DeviceUDIDs         []string
+}
+
+// findInstalledProfile returns the first profile matching the criteria and granting the entitlements of the bundle ID.
+// If no profile is found, the entitlement mismatches of the otherwise matching profiles are reported.
+func (l profileLookup) findInstalledProfile(profiles []installedProfile, bundleID string, entitlements autocodesign.Entitlements, criteria profileCriteria) *installedProfile {
+	mismatchesByProfile := map[string][]string{}
+	var rejectedProfiles []string
+	for _, profile := range profiles {
+		if !isInstalledProfileMatching(profile, bundleID, criteria) {
+			continue
+		}
+		mismatches := entitlementMismatches(autocodesign.Entitlements(profile.Info.Entitlements), entitlements)
+		if len(mismatches) == 0 {
+			return &profile
+		}
+		name := fmt.Sprintf("%s (%s)", profile.Info.Name, profile.Info.UUID)
+		rejectedProfiles = append(rejectedProfiles, name)
+		mismatchesByProfile[name] = mismatches
+	}
+
+	if len(rejectedProfiles) > 0 {
+		l.logger.Warnf("The installed profiles of %s don't match the app's entitlements:", bundleID)
+		for _, name := range rejectedProfiles {
+			l.logger.Printf("- %s:", name)
+			for _, mismatch := range mismatchesByProfile[name] {
+				l.logger.Printf("  - %s", mismatch)
+			}
+		}
+	}
+	return nil
+}
+
+func isInstalledProfileMatching(profile installedProfile, bundleID string, criteria profileCriteria) bool {
+	info := profile.Info
+
+	minExpiration := time.Now().AddDate(0, 0, criteria.MinProfileDaysValid)
+	if !minExpiration.Before(info.ExpirationDate) {
+		return false
+	}
+	if autocodesign.DistributionType(info.ExportType) != criteria.DistributionType {
+		return false
+	}
+	if !hasMatchingProfileBundleID(info.BundleID, bundleID, criteria.MacCatalyst) {
+		return false
+	}
+	if !hasMatchingProfilePlatform(profile.Platforms, criteria.Platform, criteria.MacCatalyst) {
+		return false
+	}
+	if !hasProfileCertificates(info, criteria.CertificateSerials) {
+		return false
+	}
+	if !provisionsProfileDevices(info, criteria.DeviceUDIDs) {
+		return false
+	}
+	// Bitrise-managed automatic code signing enforces manual code signing, so the Xcode managed profiles are dropped
+	return !info.IsXcodeManaged()
+}
+
+// hasMatchingProfileBundleID returns true if the profile is for the bundle ID,
+// Mac Catalyst apps may be signed with the profile of the derived maccatalyst. prefixed bundle ID too.
+func hasMatchingProfileBundleID(profileBundleID, bundleID string, macCatalyst bool) bool {
+	if profileBundleID == bundleID {
+		return true
+	}
+	return macCatalyst && profileBundleID == macCatalystBundleIDPrefix+bundleID
+}
+
+// hasMatchingProfilePlatform returns true if the profile's Platform array contains the app's platform.
+// The Mac Catalyst apps are iOS apps, signed with macOS profiles.
+func hasMatchingProfilePlatform(profilePlatforms []string, platform autocodesign.Platform, macCatalyst bool) bool {
+	if macCatalyst {
+		platform = autocodesign.MacOS
+	}
+	wants, ok := profilePlatformsByPlatform[platform]
+	if !ok {
+		wants = []string{strings.ToLower(string(platform))}
+	}
+	for _, want := range wants {
+		if sliceutil.IsStringInSlice(want, profilePlatforms) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProfileCertificates(info profileutil.ProvisioningProfileInfoModel, certificateSerials []string) bool {
+	var profileSerials []string
+	for _, certificate := range info.DeveloperCertificates {
+		profileSerials = append(profileSerials, certificate.Serial)
+	}
+	for _, serial := range certificateSerials {
+		if !sliceutil.IsStringInSlice(serial, profileSerials) {
+			return false
+		}
+	}
+	return true
+}
+
+// profileChoiceExplanation returns why the profile was chosen: the granted entitlements, the signing certificates,
+// the device coverage and the expiry of the profile.
+func profileChoiceExplanation(profile installedProfile, entitlements autocodesign.Entitlements, criteria profileCriteria, certificates []autocodesign.Certificate, now time.Time) []string {
+	info := profile.Info
+	var reasons []string
+
+	if len(entitlements) == 0 {
+		reasons = append(reasons, "entitlements: the target has no entitlements")
+	} else {
+		reasons = append(reasons, fmt.Sprintf("entitlements: grants %s", strings.Join(sortedEntitlementKeys(entitlements), ", ")))
+	}
+
+	var certificateNames []string
+	for _, certificate := range certificates {
+		if hasProfileCertificates(info, []string{certificate.CertificateInfo.Serial}) {
+			certificateNames = append(certificateNames, fmt.Sprintf("%s (serial: %s)", certificate.CertificateInfo.CommonName, certificate.CertificateInfo.Serial))
+		}
+	}
+	reasons = append(reasons, fmt.Sprintf("certificate: %s", strings.Join(certificateNames, ", ")))
+
+	switch {
+	case !autocodesign.DistributionTypeRequiresDeviceList([]autocodesign.DistributionType{criteria.DistributionType}):
+		reasons = append(reasons, fmt.Sprintf("devices: not required for %s distribution", criteria.DistributionType))
+	case info.ProvisionsAllDevices:
+		reasons = append(reasons, "devices: provisions all devices")
+	case len(criteria.DeviceUDIDs) == 0:
+		reasons = append(reasons, fmt.Sprintf("devices: %d devices provisioned, no test device required", len(info.ProvisionedDevices)))
+	default:
+		reasons = append(reasons, fmt.Sprintf("devices: provisions all the %d test devices (%d devices provisioned)", len(criteria.DeviceUDIDs), len(info.ProvisionedDevices)))
+	}
+
+	daysLeft := int(info.ExpirationDate.Sub(now).Hours() / 24)
+	reasons = append(reasons, fmt.Sprintf("expiry: %s (%d days left, minimum validity: %d days)", info.ExpirationDate.Format("2006-01-02"), daysLeft, criteria.MinProfileDaysValid))
+	return reasons
+}
+
+func provisionsProfileDevices(info profileutil.ProvisioningProfileInfoModel, deviceUDIDs []string) bool {
+	if info.ProvisionsAllDevices || len(deviceUDIDs) == 0 {
+		return true
+	}
+	for _, udid := range deviceUDIDs {
+		if !sliceutil.IsStringInSlice(udid, info.ProvisionedDevices) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package step
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/certificateutil"
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient/appstoreconnect"
+	"github.com/stretchr/testify/require"
+)
+
+func testInstalledProfile(name, bundleID string, platforms ...string) installedProfile {
+	return installedProfile{
+		Info: profileutil.ProvisioningProfileInfoModel{
+			Name:                  name,
+			BundleID:              bundleID,
+			ExportType:            exportoptions.MethodAppStore,
+			ExpirationDate:        time.Now().AddDate(0, 6, 0),
+			DeveloperCertificates: []certificateutil.CertificateInfoModel{{Serial: "serial"}},
+			Entitlements:          map[string]interface{}{},
+		},
+		Platforms: platforms,
+	}
+}
+
+func Test_isMacCatalystDestination(t *testing.T) {
+	require.True(t, isMacCatalystDestination("generic/platform=macOS,variant=Mac Catalyst"))
+	require.True(t, isMacCatalystDestination("platform=macOS, variant=Mac Catalyst"))
+	require.False(t, isMacCatalystDestination("generic/platform=iOS"))
+	require.False(t, isMacCatalystDestination(""))
+}
+
+func Test_isMacCatalystBuild(t *testing.T) {
+	require.True(t, isMacCatalystBuild("generic/platform=macOS,variant=Mac Catalyst", nil))
+	require.True(t, isMacCatalystBuild("", []string{"-quiet", "-destination", "generic/platform=macOS,variant=Mac Catalyst"}))
+	require.False(t, isMacCatalystBuild("generic/platform=iOS", []string{"-destination", "generic/platform=iOS"}))
+	require.False(t, isMacCatalystBuild("", []string{"-destination"}))
+}
+
+func Test_hasMatchingProfilePlatform(t *testing.T) {
+	require.True(t, hasMatchingProfilePlatform([]string{"ios", "xros", "visionos"}, autocodesign.IOS, false))
+	require.False(t, hasMatchingProfilePlatform([]string{"osx"}, autocodesign.IOS, false))
+	require.True(t, hasMatchingProfilePlatform([]string{"osx"}, autocodesign.IOS, true))
+	require.False(t, hasMatchingProfilePlatform([]string{"ios"}, autocodesign.IOS, true))
+	require.True(t, hasMatchingProfilePlatform([]string{"osx"}, autocodesign.MacOS, false))
+	require.True(t, hasMatchingProfilePlatform([]string{"tvos"}, autocodesign.TVOS, false))
+	require.False(t, hasMatchingProfilePlatform([]string{"ios"}, autocodesign.TVOS, false))
+	require.True(t, hasMatchingProfilePlatform([]string{"xros"}, autocodesign.Platform(visionOS), false))
+	require.True(t, hasMatchingProfilePlatform([]string{"ios", "visionos"}, autocodesign.Platform(visionOS), false))
+	require.False(t, hasMatchingProfilePlatform([]string{"ios"}, autocodesign.Platform(visionOS), false))
+}
+
+func Test_visionOSProfileInfo(t *testing.T) {
+	info, err := visionOSProfileInfo(plistutil.PlistData{
+		"Name":                        "visionOS App Store",
+		"Platform":                    []interface{}{"xrOS", "visionOS"},
+		"ApplicationIdentifierPrefix": []interface{}{"TEAM123"},
+		"ProvisionsAllDevices":        false,
+		"Entitlements":                map[string]interface{}{"application-identifier": "TEAM123.io.bitrise.sample"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "visionOS App Store", info.Name)
+	require.Equal(t, "io.bitrise.sample", info.BundleID)
+	require.Equal(t, profileutil.ProfileTypeIos, info.Type)
+}
+
+func Test_profileLookup_FindCodesignAssets_macCatalyst(t *testing.T) {
+	profiles := []installedProfile{
+		testInstalledProfile("iOS App Store", "io.bitrise.sample", "ios"),
+		testInstalledProfile("Mac Catalyst App Store", "maccatalyst.io.bitrise.sample", "osx"),
+	}
+	certsByType := map[appstoreconnect.CertificateType][]autocodesign.Certificate{
+		appstoreconnect.IOSDistribution: {{CertificateInfo: certificateutil.CertificateInfoModel{Serial: "serial"}}},
+	}
+	appLayout := autocodesign.AppLayout{
+		Platform:                               autocodesign.IOS,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{"io.bitrise.sample": {}},
+	}
+
+	for _, tt := range []struct {
+		macCatalyst bool
+		want        string
+	}{
+		{macCatalyst: false, want: "iOS App Store"},
+		{macCatalyst: true, want: "Mac Catalyst App Store"},
+	} {
+		lookup := profileLookup{
+			listProfiles: func() ([]installedProfile, error) { return profiles, nil },
+			macCatalyst:  tt.macCatalyst,
+			logger:       log.NewLogger(),
+		}
+		asset, missing, err := lookup.FindCodesignAssets(appLayout, autocodesign.AppStore, certsByType, nil, 0)
+		require.NoError(t, err)
+		require.Nil(t, missing)
+		require.Equal(t, tt.want, asset.ArchivableTargetProfilesByBundleID["io.bitrise.sample"].Attributes().Name)
+	}
+}
+
+func Test_profileChoiceExplanation(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	profile := testInstalledProfile("Ad Hoc", "io.bitrise.sample", "ios")
+	profile.Info.ExportType = exportoptions.MethodAdHoc
+	profile.Info.ExpirationDate = now.AddDate(0, 0, 45)
+	profile.Info.ProvisionedDevices = []string{"udid-1", "udid-2", "udid-3"}
+	certificates := []autocodesign.Certificate{
+		{CertificateInfo: certificateutil.CertificateInfoModel{CommonName: "Apple Distribution: Bitrise", Serial: "serial"}},
+		{CertificateInfo: certificateutil.CertificateInfoModel{CommonName: "Apple Distribution: Other", Serial: "other"}},
+	}
+	criteria := profileCriteria{DistributionType: autocodesign.AdHoc, MinProfileDaysValid: 30, DeviceUDIDs: []string{"udid-1", "udid-2"}}
+
+	got := profileChoiceExplanation(profile, autocodesign.Entitlements{"aps-environment": "production", appGroupsKey: []interface{}{"group.io.bitrise.sample"}}, criteria, certificates, now)
+	require.Equal(t, []string{
+		"entitlements: grants aps-environment, com.apple.security.application-groups",
+		"certificate: Apple Distribution: Bitrise (serial: serial)",
+		"devices: provisions all the 2 test devices (3 devices provisioned)",
+		"expiry: 2026-11-15 (45 days left, minimum validity: 30 days)",
+	}, got)
+}
+
+func Test_profileLookup_FindCodesignAssets_readOnly(t *testing.T) {
+	certsByType := map[appstoreconnect.CertificateType][]autocodesign.Certificate{
+		appstoreconnect.IOSDistribution: {{CertificateInfo: certificateutil.CertificateInfoModel{Serial: "serial"}}},
+	}
+	appLayout := autocodesign.AppLayout{
+		Platform: autocodesign.IOS,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{
+			"io.bitrise.sample":        {},
+			"io.bitrise.sample.widget": {},
+		},
+	}
+	lookup := profileLookup{
+		listProfiles: func() ([]installedProfile, error) {
+			return []installedProfile{testInstalledProfile("App Store", "io.bitrise.sample", "ios")}, nil
+		},
+		readOnly: true,
+		logger:   log.NewLogger(),
+	}
+
+	_, _, err := lookup.FindCodesignAssets(appLayout, autocodesign.AppStore, certsByType, nil, 0)
+	require.EqualError(t, err, "read-only App Store Connect mode: no installed app-store provisioning profile found for io.bitrise.sample.widget, install the profiles (or provide them in fallback_provisioning_profile_url_list), as they can't be generated")
+}
@@ -0,0 +1,54 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+// exportOptionsProvisioningProfiles returns the bundle ID - provisioning profile (name or UUID) mapping of the export options.
+// The mapping is empty if the profiles are selected by Xcode (automatic signing).
+func exportOptionsProvisioningProfiles(exportOptionsPath string) (map[string]string, error) {
+	exportOptions, err := plistutil.NewPlistDataFromFile(exportOptionsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export options: %s", err)
+	}
+
+	profiles := map[string]string{}
+	mapping, ok := exportOptions.GetMapStringInterface(exportoptions.ProvisioningProfilesKey)
+	if !ok {
+		return profiles, nil
+	}
+	for bundleID := range mapping {
+		profile, ok := mapping.GetString(bundleID)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry for %s: %v", exportoptions.ProvisioningProfilesKey, bundleID, mapping[bundleID])
+		}
+		profiles[bundleID] = profile
+	}
+	return profiles, nil
+}
+
+// exportProvisioningProfileMap exports the provisioning profiles of the export options as a JSON object,
+// so that the following steps can record which profile signed which target.
+func (s XcodebuildArchiver) exportProvisioningProfileMap(exportOptionsPath string) error {
+	profiles, err := exportOptionsProvisioningProfiles(exportOptionsPath)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		s.logger.Printf("The export options contain no provisioning profiles, they are selected by Xcode")
+	}
+
+	content, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseProvisioningProfilesEnvKey, string(content)); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseProvisioningProfilesEnvKey, err)
+	}
+	s.logger.Donef("The provisioning profiles are now available in the Environment Variable: %s (value: %s)", bitriseProvisioningProfilesEnvKey, content)
+	return nil
+}
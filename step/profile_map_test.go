@@ -0,0 +1,55 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exportOptionsProvisioningProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	manualPath := filepath.Join(dir, "manual.plist")
+	require.NoError(t, os.WriteFile(manualPath, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>method</key>
+	<string>app-store</string>
+	<key>provisioningProfiles</key>
+	<dict>
+		<key>io.bitrise.sample</key>
+		<string>Sample App Store</string>
+		<key>io.bitrise.sample.widget</key>
+		<string>Sample Widget App Store</string>
+	</dict>
+	<key>signingStyle</key>
+	<string>manual</string>
+</dict>
+</plist>`), 0600))
+
+	profiles, err := exportOptionsProvisioningProfiles(manualPath)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"io.bitrise.sample":        "Sample App Store",
+		"io.bitrise.sample.widget": "Sample Widget App Store",
+	}, profiles)
+
+	automaticPath := filepath.Join(dir, "automatic.plist")
+	require.NoError(t, os.WriteFile(automaticPath, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>method</key>
+	<string>development</string>
+	<key>signingStyle</key>
+	<string>automatic</string>
+</dict>
+</plist>`), 0600))
+
+	profiles, err = exportOptionsProvisioningProfiles(automaticPath)
+	require.NoError(t, err)
+	require.Empty(t, profiles)
+}
@@ -0,0 +1,41 @@
+package step
+
+import (
+	"time"
+
+	"github.com/bitrise-io/go-xcode/profileutil"
+)
+
+// profilesByBundleID maps the provisioning profiles embedded into the IPA by their bundle IDs.
+func profilesByBundleID(profilesByPath map[string]profileutil.ProvisioningProfileInfoModel) map[string]profileutil.ProvisioningProfileInfoModel {
+	profiles := map[string]profileutil.ProvisioningProfileInfoModel{}
+	for _, profile := range profilesByPath {
+		profiles[profile.BundleID] = profile
+	}
+	return profiles
+}
+
+// profilesBelowMinValidity returns the provisioning profiles, which expire within the minimum validity days.
+func profilesBelowMinValidity(profiles []buildSummaryProfile, minValidityDays int, now time.Time) []buildSummaryProfile {
+	var expiring []buildSummaryProfile
+	for _, profile := range profiles {
+		if profile.remainingDays(now) < minValidityDays {
+			expiring = append(expiring, profile)
+		}
+	}
+	return expiring
+}
+
+// printProfileValidity prints the expiration of the provisioning profiles signing the exported app (or the archive),
+// and warns about the ones expiring within the minimum validity days.
+func (s XcodebuildArchiver) printProfileValidity(profiles []buildSummaryProfile, minValidityDays int, now time.Time) {
+	s.logger.Println()
+	s.logger.Infof("Provisioning profile validity:")
+	for _, profile := range profiles {
+		s.logger.Printf("- %s (%s): expires on %s", profile.Name, profile.BundleID, profile.expiry(now, minValidityDays))
+	}
+
+	for _, profile := range profilesBelowMinValidity(profiles, minValidityDays, now) {
+		s.logger.Warnf("The provisioning profile %s (%s) expires within the minimum validity (%d days), renew it", profile.Name, profile.BundleID, minValidityDays)
+	}
+}
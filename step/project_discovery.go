@@ -0,0 +1,110 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-utils/v2/log"
+)
+
+const (
+	xcodeProjectExt   = ".xcodeproj"
+	xcodeWorkspaceExt = ".xcworkspace"
+
+	// projectDiscoveryMaxDepth limits the directory levels searched below the root directory
+	projectDiscoveryMaxDepth = 3
+)
+
+// projectDiscoverySkippedDirs are dependency and build directories, which may contain projects not meant to be archived
+var projectDiscoverySkippedDirs = []string{"Pods", "Carthage", ".build", "SourcePackages", "DerivedData", "node_modules", ".git"}
+
+// isProjectPath ...
+func isProjectPath(pth string) bool {
+	ext := filepath.Ext(pth)
+	return ext == xcodeProjectExt || ext == xcodeWorkspaceExt
+}
+
+// findProjects returns the workspaces and projects found in the directory,
+// ordered by preference: workspaces before projects, shallower paths first.
+func findProjects(dir string) ([]string, error) {
+	var projects []string
+	if err := filepath.WalkDir(dir, func(pth string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || pth == dir {
+			return nil
+		}
+
+		if isProjectPath(pth) {
+			projects = append(projects, pth)
+			// the project.xcworkspace embedded into projects is not a candidate
+			return filepath.SkipDir
+		}
+
+		relPath, err := filepath.Rel(dir, pth)
+		if err != nil {
+			return err
+		}
+		if sliceutil.IsStringInSlice(entry.Name(), projectDiscoverySkippedDirs) || strings.Count(relPath, string(filepath.Separator)) >= projectDiscoveryMaxDepth-1 {
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		iIsWorkspace, jIsWorkspace := filepath.Ext(projects[i]) == xcodeWorkspaceExt, filepath.Ext(projects[j]) == xcodeWorkspaceExt
+		if iIsWorkspace != jIsWorkspace {
+			return iIsWorkspace
+		}
+		return strings.Count(projects[i], string(filepath.Separator)) < strings.Count(projects[j], string(filepath.Separator))
+	})
+	return projects, nil
+}
+
+// discoverProjectPath returns the given project or workspace path,
+// or finds one if the path is a directory or empty (meaning the working directory).
+func discoverProjectPath(projectPath string, logger log.Logger) (string, error) {
+	if isProjectPath(projectPath) {
+		if _, err := os.Stat(projectPath); err != nil {
+			return "", fmt.Errorf("project (%s) does not exist: %w", projectPath, err)
+		}
+		return projectPath, nil
+	}
+
+	dir := projectPath
+	if dir == "" {
+		dir = "."
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("should be and .xcodeproj or .xcworkspace path")
+	}
+
+	logger.Printf("Looking for a workspace or project in: %s", dir)
+	projects, err := findProjects(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to search for projects in %s: %w", dir, err)
+	}
+	if len(projects) == 0 {
+		return "", fmt.Errorf("should be and .xcodeproj or .xcworkspace path, no workspace or project found in %s", dir)
+	}
+
+	selected := projects[0]
+	if len(projects) > 1 {
+		next := projects[1]
+		sameKind := filepath.Ext(next) == filepath.Ext(selected)
+		sameDepth := strings.Count(next, string(filepath.Separator)) == strings.Count(selected, string(filepath.Separator))
+		if sameKind && sameDepth {
+			return "", fmt.Errorf("multiple candidates found in %s, please set the Project path (`project_path`) input to one of them: %s", dir, strings.Join(projects, ", "))
+		}
+	}
+
+	logger.Donef("Using: %s", selected)
+	return selected, nil
+}
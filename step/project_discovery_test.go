@@ -0,0 +1,60 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_discoverProjectPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		projects []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "workspace preferred over project",
+			projects: []string{"MyApp.xcodeproj", "MyApp.xcworkspace", "Pods/Pods.xcodeproj"},
+			want:     "MyApp.xcworkspace",
+		},
+		{
+			name:     "nested project",
+			projects: []string{"ios/MyApp.xcodeproj", "ios/.build/checkouts/Package/Package.xcodeproj"},
+			want:     "ios/MyApp.xcodeproj",
+		},
+		{
+			name:     "embedded project workspace is ignored",
+			projects: []string{"MyApp.xcodeproj/project.xcworkspace"},
+			want:     "MyApp.xcodeproj",
+		},
+		{
+			name:     "ambiguous projects",
+			projects: []string{"MyApp.xcodeproj", "Other.xcodeproj"},
+			wantErr:  true,
+		},
+		{
+			name:    "no project",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, project := range tt.projects {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, project), 0700))
+			}
+
+			got, err := discoverProjectPath(dir, log.NewLogger())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(dir, tt.want), got)
+		})
+	}
+}
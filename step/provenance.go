@@ -0,0 +1,185 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/env"
+)
+
+const (
+	inTotoStatementType     = "https://in-toto.io/Statement/v1"
+	slsaProvenancePredicate = "https://slsa.dev/provenance/v1"
+	provenanceBuildType     = "https://github.com/bitrise-steplib/steps-xcode-archive"
+	defaultBuilderID        = "https://bitrise.io"
+)
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type slsaResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]string        `json:"externalParameters"`
+	InternalParameters   map[string]string        `json:"internalParameters,omitempty"`
+	ResolvedDependencies []slsaResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	Metadata struct {
+		InvocationID string `json:"invocationId,omitempty"`
+		StartedOn    string `json:"startedOn,omitempty"`
+		FinishedOn   string `json:"finishedOn,omitempty"`
+	} `json:"metadata"`
+}
+
+type slsaProvenanceStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     struct {
+		BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+		RunDetails      slsaRunDetails      `json:"runDetails"`
+	} `json:"predicate"`
+}
+
+// ProvenanceGenerator creates SLSA provenance statements of the exported artifacts.
+type ProvenanceGenerator struct {
+	externalParameters map[string]string
+	startedOn          time.Time
+	now                func() time.Time
+
+	repositoryURL string
+	commit        string
+	branch        string
+	buildURL      string
+	builderID     string
+	internal      map[string]string
+}
+
+// NewProvenanceGeneratorFromEnv returns a ProvenanceGenerator describing the current Bitrise build,
+// with the given Step inputs as the external parameters of the build.
+func NewProvenanceGeneratorFromEnv(envRepository env.Repository, externalParameters map[string]string, startedOn time.Time) *ProvenanceGenerator {
+	builderID := defaultBuilderID
+	if appURL := envRepository.Get("BITRISE_APP_URL"); appURL != "" {
+		builderID = appURL
+	}
+
+	internal := map[string]string{}
+	for key, envKey := range map[string]string{
+		"workflow": "BITRISE_TRIGGERED_WORKFLOW_ID",
+		"stack":    "BITRISE_STACK_ID",
+	} {
+		if value := envRepository.Get(envKey); value != "" {
+			internal[key] = value
+		}
+	}
+
+	return &ProvenanceGenerator{
+		externalParameters: externalParameters,
+		startedOn:          startedOn,
+		now:                time.Now,
+		repositoryURL:      envRepository.Get("GIT_REPOSITORY_URL"),
+		commit:             envRepository.Get("BITRISE_GIT_COMMIT"),
+		branch:             envRepository.Get("BITRISE_GIT_BRANCH"),
+		buildURL:           envRepository.Get("BITRISE_BUILD_URL"),
+		builderID:          builderID,
+		internal:           internal,
+	}
+}
+
+// sourceURI returns the VCS URI of the built revision in the git+<repository>@<ref> format, for example git+https://github.com/org/repo@refs/heads/main.
+func sourceURI(repositoryURL, branch string) string {
+	uri := repositoryURL
+	if !strings.HasPrefix(uri, "git+") {
+		uri = "git+" + uri
+	}
+	if branch != "" {
+		uri += "@refs/heads/" + branch
+	}
+	return uri
+}
+
+// Statement returns the provenance statement of the given artifacts, the checksums are the artifacts' SHA-256 digests.
+func (g ProvenanceGenerator) Statement(artifacts []exportedArtifact, checksums []string, xcodeBuild string) ([]byte, error) {
+	var statement slsaProvenanceStatement
+	statement.Type = inTotoStatementType
+	statement.PredicateType = slsaProvenancePredicate
+	for i, artifact := range artifacts {
+		statement.Subject = append(statement.Subject, inTotoSubject{
+			Name:   filepath.Base(artifact.Path),
+			Digest: map[string]string{"sha256": checksums[i]},
+		})
+	}
+
+	internal := map[string]string{}
+	for key, value := range g.internal {
+		internal[key] = value
+	}
+	if xcodeBuild != "" {
+		internal["xcode_build"] = xcodeBuild
+	}
+
+	definition := slsaBuildDefinition{
+		BuildType:          provenanceBuildType,
+		ExternalParameters: g.externalParameters,
+		InternalParameters: internal,
+	}
+	if g.repositoryURL != "" {
+		source := slsaResourceDescriptor{URI: sourceURI(g.repositoryURL, g.branch)}
+		if g.commit != "" {
+			source.Digest = map[string]string{"gitCommit": g.commit}
+		}
+		definition.ResolvedDependencies = append(definition.ResolvedDependencies, source)
+	}
+	statement.Predicate.BuildDefinition = definition
+
+	statement.Predicate.RunDetails.Builder.ID = g.builderID
+	statement.Predicate.RunDetails.Metadata.InvocationID = g.buildURL
+	if !g.startedOn.IsZero() {
+		statement.Predicate.RunDetails.Metadata.StartedOn = g.startedOn.UTC().Format(time.RFC3339)
+	}
+	statement.Predicate.RunDetails.Metadata.FinishedOn = g.now().UTC().Format(time.RFC3339)
+
+	return json.MarshalIndent(statement, "", "  ")
+}
+
+// exportProvenance writes the provenance statement of the artifacts into the output dir and returns its path.
+func (s XcodebuildArchiver) exportProvenance(generator ProvenanceGenerator, artifacts []exportedArtifact, xcodeBuild, outputDir, artifactName string, outputPath func(string) (string, error)) (string, error) {
+	var checksums []string
+	for _, artifact := range artifacts {
+		checksum, err := sha256OfFile(artifact.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to calculate checksum of %s: %s", artifact.Path, err)
+		}
+		checksums = append(checksums, checksum)
+	}
+
+	content, err := generator.Statement(artifacts, checksums, xcodeBuild)
+	if err != nil {
+		return "", err
+	}
+
+	provenancePath, err := outputPath(filepath.Join(outputDir, artifactName+".provenance.json"))
+	if err != nil {
+		return "", err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, string(content), provenancePath, bitriseProvenancePthEnvKey); err != nil {
+		return "", fmt.Errorf("failed to export %s, error: %s", bitriseProvenancePthEnvKey, err)
+	}
+	s.logger.Donef("The provenance statement path is now available in the Environment Variable: %s (value: %s)", bitriseProvenancePthEnvKey, provenancePath)
+
+	return provenancePath, nil
+}
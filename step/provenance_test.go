@@ -0,0 +1,60 @@
+package step
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenanceGenerator_Statement(t *testing.T) {
+	generator := NewProvenanceGeneratorFromEnv(testEnvRepository{
+		"GIT_REPOSITORY_URL":            "https://github.com/bitrise-io/sample-app.git",
+		"BITRISE_GIT_BRANCH":            "main",
+		"BITRISE_GIT_COMMIT":            "8b1a9953c4611296a827abf8c47804d7e6c49c6b",
+		"BITRISE_BUILD_URL":             "https://app.bitrise.io/build/build-slug",
+		"BITRISE_APP_URL":               "https://app.bitrise.io/app/app-slug",
+		"BITRISE_TRIGGERED_WORKFLOW_ID": "release",
+	}, map[string]string{"scheme": "Sample"}, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	generator.now = func() time.Time { return time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC) }
+
+	content, err := generator.Statement([]exportedArtifact{{Path: "/deploy/Sample.ipa"}}, []string{"abc123"}, "15C500b")
+	require.NoError(t, err)
+
+	var statement map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &statement))
+	require.Equal(t, map[string]interface{}{
+		"_type":         inTotoStatementType,
+		"predicateType": slsaProvenancePredicate,
+		"subject": []interface{}{
+			map[string]interface{}{"name": "Sample.ipa", "digest": map[string]interface{}{"sha256": "abc123"}},
+		},
+		"predicate": map[string]interface{}{
+			"buildDefinition": map[string]interface{}{
+				"buildType":          provenanceBuildType,
+				"externalParameters": map[string]interface{}{"scheme": "Sample"},
+				"internalParameters": map[string]interface{}{"workflow": "release", "xcode_build": "15C500b"},
+				"resolvedDependencies": []interface{}{
+					map[string]interface{}{
+						"uri":    "git+https://github.com/bitrise-io/sample-app.git@refs/heads/main",
+						"digest": map[string]interface{}{"gitCommit": "8b1a9953c4611296a827abf8c47804d7e6c49c6b"},
+					},
+				},
+			},
+			"runDetails": map[string]interface{}{
+				"builder": map[string]interface{}{"id": "https://app.bitrise.io/app/app-slug"},
+				"metadata": map[string]interface{}{
+					"invocationId": "https://app.bitrise.io/build/build-slug",
+					"startedOn":    "2024-05-01T10:00:00Z",
+					"finishedOn":   "2024-05-01T10:05:00Z",
+				},
+			},
+		},
+	}, statement)
+}
+
+func Test_sourceURI(t *testing.T) {
+	require.Equal(t, "git+git@github.com:org/repo.git", sourceURI("git@github.com:org/repo.git", ""))
+	require.Equal(t, "git+https://github.com/org/repo@refs/heads/main", sourceURI("git+https://github.com/org/repo", "main"))
+}
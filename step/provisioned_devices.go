@@ -0,0 +1,154 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/fullsailor/pkcs7"
+)
+
+// provisionedDevicesSeparator separates the UDIDs of the BITRISE_PROVISIONED_DEVICES output.
+const provisionedDevicesSeparator = "|"
+
+// deviceProvisioningExportMethods are the distribution methods, which install only on the devices listed in the profiles.
+var deviceProvisioningExportMethods = []exportoptions.Method{exportoptions.MethodAdHoc, exportoptions.MethodDevelopment}
+
+// ipaProvisioningProfiles returns the provisioning profiles embedded into the application bundles of the IPA,
+// mapped by their path in the IPA.
+func ipaProvisioningProfiles(ipaPath string) (map[string]profileutil.ProvisioningProfileInfoModel, error) {
+	reader, err := archivezip.OpenReader(ipaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ipa (%s): %s", ipaPath, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	profiles := map[string]profileutil.ProvisioningProfileInfoModel{}
+	for _, file := range reader.File {
+		if !strings.HasPrefix(file.Name, payloadDirName+"/") || path.Base(file.Name) != embeddedProfileFileName {
+			continue
+		}
+		if strings.Contains(file.Name, ".framework/") {
+			continue
+		}
+
+		profile, err := readZippedProvisioningProfile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provisioning profile (%s): %s", file.Name, err)
+		}
+		profiles[file.Name] = profile
+	}
+	return profiles, nil
+}
+
+func readZippedProvisioningProfile(file *archivezip.File) (profileutil.ProvisioningProfileInfoModel, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return profileutil.ProvisioningProfileInfoModel{}, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return profileutil.ProvisioningProfileInfoModel{}, err
+	}
+	profile, err := pkcs7.Parse(content)
+	if err != nil {
+		return profileutil.ProvisioningProfileInfoModel{}, err
+	}
+	return profileutil.NewProvisioningProfileInfo(*profile)
+}
+
+// provisionedDevices returns the UDIDs of the devices covered by every profile, the build installs only on these devices.
+// Profiles provisioning all devices (enterprise) don't restrict the list, allDevices is true if every profile is such.
+func provisionedDevices(profiles []profileutil.ProvisioningProfileInfoModel) (devices []string, allDevices bool) {
+	counts := map[string]int{}
+	var restricting int
+	for _, profile := range profiles {
+		if profile.ProvisionsAllDevices {
+			continue
+		}
+		restricting++
+		seen := map[string]bool{}
+		for _, device := range profile.ProvisionedDevices {
+			if !seen[device] {
+				seen[device] = true
+				counts[device]++
+			}
+		}
+	}
+	if restricting == 0 {
+		return nil, len(profiles) > 0
+	}
+
+	devices = []string{}
+	for device, count := range counts {
+		if count == restricting {
+			devices = append(devices, device)
+		}
+	}
+	sort.Strings(devices)
+	return devices, false
+}
+
+// exportProvisionedDevices exports the UDIDs and the count of the devices the IPA can be installed on,
+// for the distribution methods limited to the devices of the provisioning profiles.
+// profilesByPath are the provisioning profiles embedded into the IPA.
+func (s XcodebuildArchiver) exportProvisionedDevices(profilesByPath map[string]profileutil.ProvisioningProfileInfoModel, exportMethod string) error {
+	isDeviceProvisioned := false
+	for _, method := range deviceProvisioningExportMethods {
+		if exportMethod == string(method) {
+			isDeviceProvisioned = true
+		}
+	}
+	if !isDeviceProvisioned {
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Provisioned devices:")
+
+	var pths []string
+	for pth := range profilesByPath {
+		pths = append(pths, pth)
+	}
+	sort.Strings(pths)
+	var profiles []profileutil.ProvisioningProfileInfoModel
+	for _, pth := range pths {
+		profile := profilesByPath[pth]
+		profiles = append(profiles, profile)
+		s.logger.Printf("- %s (%s): %d devices", profile.Name, strings.TrimPrefix(path.Dir(pth), payloadDirName+"/"), len(profile.ProvisionedDevices))
+	}
+
+	devices, allDevices := provisionedDevices(profiles)
+	if allDevices {
+		s.logger.Printf("The provisioning profiles provision all devices")
+		return nil
+	}
+	if len(devices) == 0 {
+		s.logger.Warnf("No device is covered by every provisioning profile of the IPA, it can't be installed on any device")
+	}
+
+	count := strconv.Itoa(len(devices))
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseProvisionedDeviceCountEnvKey, count); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseProvisionedDeviceCountEnvKey, err)
+	}
+	s.logger.Donef("The provisioned device count is now available in the Environment Variable: %s (value: %s)", bitriseProvisionedDeviceCountEnvKey, count)
+
+	udids := strings.Join(devices, provisionedDevicesSeparator)
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseProvisionedDevicesEnvKey, udids); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseProvisionedDevicesEnvKey, err)
+	}
+	s.logger.Donef("The provisioned device UDIDs are now available in the Environment Variable: %s (value: %s)", bitriseProvisionedDevicesEnvKey, udids)
+	return nil
+}
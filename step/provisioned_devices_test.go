@@ -0,0 +1,30 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/profileutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provisionedDevices(t *testing.T) {
+	app := profileutil.ProvisioningProfileInfoModel{ProvisionedDevices: []string{"udid-3", "udid-1", "udid-2"}}
+	extension := profileutil.ProvisioningProfileInfoModel{ProvisionedDevices: []string{"udid-2", "udid-1", "udid-4"}}
+	enterprise := profileutil.ProvisioningProfileInfoModel{ProvisionsAllDevices: true}
+
+	devices, allDevices := provisionedDevices([]profileutil.ProvisioningProfileInfoModel{app})
+	require.Equal(t, []string{"udid-1", "udid-2", "udid-3"}, devices)
+	require.False(t, allDevices)
+
+	devices, allDevices = provisionedDevices([]profileutil.ProvisioningProfileInfoModel{app, extension, enterprise})
+	require.Equal(t, []string{"udid-1", "udid-2"}, devices)
+	require.False(t, allDevices)
+
+	devices, allDevices = provisionedDevices([]profileutil.ProvisioningProfileInfoModel{app, {}})
+	require.Equal(t, []string{}, devices)
+	require.False(t, allDevices)
+
+	devices, allDevices = provisionedDevices([]profileutil.ProvisioningProfileInfoModel{enterprise})
+	require.Nil(t, devices)
+	require.True(t, allDevices)
+}
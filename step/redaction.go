@@ -0,0 +1,76 @@
+package step
+
+import (
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveCommandOptions are the command line options followed by a value, which should not be printed in the logs.
+var sensitiveCommandOptions = []string{
+	"-authenticationKeyPath",
+	"-authenticationKeyID",
+	"-authenticationKeyIssuerID",
+}
+
+// SensitiveValues returns the values of the sensitive inputs, which should not be printed in the logs.
+func (c Config) SensitiveValues() []string {
+	values := strings.Split(string(c.CertificatePassphraseList), "|")
+	values = append(values,
+		string(c.KeychainPassword),
+		string(c.APIKeyPath),
+		string(c.AppleIDPassword),
+		string(c.AppleIDAppSpecificPassword),
+		string(c.AppleIDSession),
+		string(c.PackageHostToken),
+		string(c.ArtifactSigningKeyPassphrase),
+	)
+
+	var sensitiveValues []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			sensitiveValues = append(sensitiveValues, value)
+		}
+	}
+	return sensitiveValues
+}
+
+// RedactedXcodebuildAdditionalOptions returns the additional xcodebuild options with the sensitive values redacted,
+// which are safe to write into the outputs.
+func (c Config) RedactedXcodebuildAdditionalOptions() []string {
+	return redactCommandArgs(c.XcodebuildAdditionalOptions, c.SensitiveValues())
+}
+
+// redactCommandArgs replaces the values of the sensitive options and every occurrence of the sensitive values in the arguments.
+func redactCommandArgs(args []string, sensitiveValues []string) []string {
+	redacted := make([]string, 0, len(args))
+	for i, arg := range args {
+		if i > 0 && sliceutil.IsStringInSlice(args[i-1], sensitiveCommandOptions) {
+			redacted = append(redacted, redactedValue)
+			continue
+		}
+
+		for _, value := range sensitiveValues {
+			if value == "" {
+				continue
+			}
+			arg = strings.ReplaceAll(arg, value, redactedValue)
+		}
+		redacted = append(redacted, arg)
+	}
+	return redacted
+}
+
+// printableCommand returns the command, which is safe to print in the logs.
+func printableCommand(name string, args []string, sensitiveValues []string) string {
+	printableArgs := []string{name}
+	for _, arg := range redactCommandArgs(args, sensitiveValues) {
+		if arg != redactedValue {
+			arg = quoteCommandArg(arg)
+		}
+		printableArgs = append(printableArgs, arg)
+	}
+	return strings.Join(printableArgs, " ")
+}
@@ -0,0 +1,88 @@
+package step
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/projectmanager"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+)
+
+// carPlayEntitlementPrefix is the prefix of the CarPlay entitlements (for example com.apple.developer.carplay-audio).
+const carPlayEntitlementPrefix = "com.apple.developer.carplay-"
+
+// restrictedEntitlementNames maps the entitlements, which require Apple's approval, to their capability name.
+// The App Store Connect API can't generate profiles with these entitlements.
+var restrictedEntitlementNames = map[string]string{
+	"com.apple.developer.usernotifications.critical-alerts": "Critical Alerts",
+	"com.apple.developer.contacts.notes":                    "Contact Notes Field Access",
+	"com.apple.developer.exposure-notification":             "Exposure Notification",
+}
+
+func restrictedEntitlementName(key string) (string, bool) {
+	if strings.HasPrefix(key, carPlayEntitlementPrefix) {
+		return "CarPlay", true
+	}
+	name, ok := restrictedEntitlementNames[key]
+	return name, ok
+}
+
+// restrictedEntitlements returns the restricted entitlements of the targets, sorted by the bundle ID.
+func restrictedEntitlements(entitlementsByBundleID map[string]autocodesign.Entitlements) []string {
+	var restricted []string
+	for bundleID, entitlements := range entitlementsByBundleID {
+		for key := range entitlements {
+			if name, ok := restrictedEntitlementName(key); ok {
+				restricted = append(restricted, fmt.Sprintf("%s: %s (%s)", bundleID, name, key))
+			}
+		}
+	}
+	sort.Strings(restricted)
+	return restricted
+}
+
+// usesBitriseManagedSigning returns true if the code signing assets are managed by Bitrise, instead of xcodebuild
+// (-allowProvisioningUpdates), following the code signing strategy selection of the codesign package.
+func usesBitriseManagedSigning(credentials devportalservice.Credentials, opts codesign.Opts, isSigningManagedAutomatically func() (bool, error)) bool {
+	if credentials.AppleID != nil || credentials.APIKey == nil {
+		return true
+	}
+	if !opts.ShouldConsiderXcodeSigning || opts.XcodeMajorVersion < 13 || credentials.APIKey.EnterpriseAccount || opts.MinDaysProfileValidity > 0 {
+		return true
+	}
+	managed, err := isSigningManagedAutomatically()
+	return err != nil || !managed
+}
+
+// checkRestrictedEntitlements fails if the archived targets use restricted entitlements,
+// as the Bitrise-managed code signing can't generate their profiles.
+func (s XcodebuildArchiveConfigParser) checkRestrictedEntitlements(config Config) error {
+	helper, err := projectmanager.NewProjectHelper(config.ProjectPath, config.Scheme, config.Configuration)
+	if err != nil {
+		s.logger.Warnf("Failed to check the restricted entitlements: %s", err)
+		return nil
+	}
+	entitlementsByBundleID, err := helper.ArchivableTargetBundleIDToEntitlements()
+	if err != nil {
+		s.logger.Warnf("Failed to check the restricted entitlements: %s", err)
+		return nil
+	}
+
+	restricted := restrictedEntitlements(entitlementsByBundleID)
+	if len(restricted) == 0 {
+		return nil
+	}
+
+	s.logger.Errorf("The app uses entitlements, which require Apple's approval:")
+	for _, entitlement := range restricted {
+		s.logger.Printf("- %s", entitlement)
+	}
+	s.logger.Printf("The App Store Connect API can't generate provisioning profiles with these entitlements, so Bitrise-managed code signing can't sign the app.")
+	s.logger.Printf("Request the entitlements from Apple, then either:")
+	s.logger.Printf("- generate the provisioning profiles on the Apple Developer Portal (Additional Capabilities tab), install them with the Certificate and profile installer Step and disable automatic code signing (automatic_code_signing: off), or")
+	s.logger.Printf("- enable Automatically manage signing in Xcode and use an App Store Connect API key connection, so that xcodebuild generates the profiles")
+	return fmt.Errorf("the app uses restricted entitlements, which Bitrise-managed code signing can't provide")
+}
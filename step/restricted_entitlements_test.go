@@ -0,0 +1,44 @@
+package step
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_restrictedEntitlements(t *testing.T) {
+	got := restrictedEntitlements(map[string]autocodesign.Entitlements{
+		"io.bitrise.sample": {
+			"com.apple.developer.carplay-audio":                     true,
+			"com.apple.developer.usernotifications.critical-alerts": true,
+			"aps-environment": "production",
+		},
+		"io.bitrise.sample.widget": {
+			"com.apple.security.application-groups": []interface{}{"group.io.bitrise.sample"},
+		},
+	})
+	require.Equal(t, []string{
+		"io.bitrise.sample: CarPlay (com.apple.developer.carplay-audio)",
+		"io.bitrise.sample: Critical Alerts (com.apple.developer.usernotifications.critical-alerts)",
+	}, got)
+}
+
+func Test_usesBitriseManagedSigning(t *testing.T) {
+	apiKey := devportalservice.Credentials{APIKey: &devportalservice.APIKeyConnection{}}
+	opts := codesign.Opts{ShouldConsiderXcodeSigning: true, XcodeMajorVersion: 15}
+	managed := func() (bool, error) { return true, nil }
+	manual := func() (bool, error) { return false, nil }
+	failing := func() (bool, error) { return false, errors.New("failed to read build settings") }
+
+	require.False(t, usesBitriseManagedSigning(apiKey, opts, managed))
+	require.True(t, usesBitriseManagedSigning(apiKey, opts, manual))
+	require.True(t, usesBitriseManagedSigning(apiKey, opts, failing))
+	require.True(t, usesBitriseManagedSigning(devportalservice.Credentials{AppleID: &devportalservice.AppleID{}}, opts, managed))
+
+	opts.MinDaysProfileValidity = 30
+	require.True(t, usesBitriseManagedSigning(apiKey, opts, managed))
+}
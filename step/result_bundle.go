@@ -0,0 +1,201 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	resultBundlePathOption = "-resultBundlePath"
+	// resultBundleMinXcodeMajorVersion is the first Xcode version writing result bundles for the archive action
+	resultBundleMinXcodeMajorVersion = 11
+	// buildResultsMinXcodeMajorVersion is the first Xcode version with the `xcresulttool get build-results` command,
+	// older versions only support the (later deprecated) object graph output
+	buildResultsMinXcodeMajorVersion = 16
+
+	deprecationIssueType = "Deprecation"
+)
+
+// BuildIssue is a compiler warning or error read from the archive's result bundle.
+type BuildIssue struct {
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+	Location string `json:"location,omitempty"`
+}
+
+// IsDeprecation returns true for the usages of deprecated APIs.
+func (i BuildIssue) IsDeprecation() bool {
+	return i.Type == deprecationIssueType || strings.Contains(i.Message, "deprecated")
+}
+
+// BuildIssues are the warnings and errors of the archive action.
+type BuildIssues struct {
+	Warnings []BuildIssue `json:"warnings"`
+	Errors   []BuildIssue `json:"errors"`
+}
+
+// DeprecationWarnings ...
+func (i BuildIssues) DeprecationWarnings() []BuildIssue {
+	var warnings []BuildIssue
+	for _, warning := range i.Warnings {
+		if warning.IsDeprecation() {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// resultBundlePathFromOptions returns the result bundle path set in the additional xcodebuild options.
+func resultBundlePathFromOptions(options []string) string {
+	for i, option := range options {
+		if option == resultBundlePathOption && i+1 < len(options) {
+			return options[i+1]
+		}
+	}
+	return ""
+}
+
+func (s XcodebuildArchiver) readResultBundleIssues(resultBundlePath string, xcodeMajorVersion int) (BuildIssues, error) {
+	args := []string{"xcresulttool", "get", "--format", "json", "--path", resultBundlePath}
+	if xcodeMajorVersion >= buildResultsMinXcodeMajorVersion {
+		args = []string{"xcresulttool", "get", "build-results", "--path", resultBundlePath}
+	}
+
+	cmd := s.cmdFactory.Create("xcrun", args, nil)
+	out, err := cmd.RunAndReturnTrimmedOutput()
+	if err != nil {
+		return BuildIssues{}, fmt.Errorf("%s failed: %s", cmd.PrintableCommandArgs(), err)
+	}
+
+	if xcodeMajorVersion >= buildResultsMinXcodeMajorVersion {
+		return parseBuildResultsIssues([]byte(out))
+	}
+	return parseLegacyResultBundleIssues([]byte(out))
+}
+
+// parseBuildResultsIssues parses the output of `xcresulttool get build-results`.
+func parseBuildResultsIssues(content []byte) (BuildIssues, error) {
+	type issue struct {
+		IssueType string `json:"issueType"`
+		Message   string `json:"message"`
+		SourceURL string `json:"sourceURL"`
+	}
+	var buildResults struct {
+		Warnings []issue `json:"warnings"`
+		Errors   []issue `json:"errors"`
+	}
+	if err := json.Unmarshal(content, &buildResults); err != nil {
+		return BuildIssues{}, fmt.Errorf("failed to parse build results: %s", err)
+	}
+
+	convert := func(issues []issue) []BuildIssue {
+		var buildIssues []BuildIssue
+		for _, issue := range issues {
+			buildIssues = append(buildIssues, BuildIssue{Type: issue.IssueType, Message: issue.Message, Location: issueLocation(issue.SourceURL)})
+		}
+		return buildIssues
+	}
+
+	return BuildIssues{Warnings: convert(buildResults.Warnings), Errors: convert(buildResults.Errors)}, nil
+}
+
+// parseLegacyResultBundleIssues parses the ActionsInvocationRecord printed by `xcresulttool get --format json`.
+func parseLegacyResultBundleIssues(content []byte) (BuildIssues, error) {
+	type value struct {
+		Value string `json:"_value"`
+	}
+	type issueSummaries struct {
+		Values []struct {
+			IssueType value `json:"issueType"`
+			Message   value `json:"message"`
+			Location  struct {
+				URL value `json:"url"`
+			} `json:"documentLocationInCreatingWorkspace"`
+		} `json:"_values"`
+	}
+	var record struct {
+		Issues struct {
+			WarningSummaries issueSummaries `json:"warningSummaries"`
+			ErrorSummaries   issueSummaries `json:"errorSummaries"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(content, &record); err != nil {
+		return BuildIssues{}, fmt.Errorf("failed to parse result bundle: %s", err)
+	}
+
+	convert := func(summaries issueSummaries) []BuildIssue {
+		var buildIssues []BuildIssue
+		for _, summary := range summaries.Values {
+			buildIssues = append(buildIssues, BuildIssue{Type: summary.IssueType.Value, Message: summary.Message.Value, Location: issueLocation(summary.Location.URL.Value)})
+		}
+		return buildIssues
+	}
+
+	return BuildIssues{Warnings: convert(record.Issues.WarningSummaries), Errors: convert(record.Issues.ErrorSummaries)}, nil
+}
+
+// issueLocation converts the document location URL of an issue (for example file:///App/View.swift#StartingLineNumber=11&...)
+// to a path:line location.
+func issueLocation(documentURL string) string {
+	if documentURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(documentURL)
+	if err != nil || u.Path == "" {
+		return documentURL
+	}
+
+	fragment, err := url.ParseQuery(u.Fragment)
+	if err != nil {
+		return u.Path
+	}
+	// The line numbers are zero-based in the result bundle
+	line, err := strconv.Atoi(fragment.Get("StartingLineNumber"))
+	if err != nil {
+		return u.Path
+	}
+	return fmt.Sprintf("%s:%d", u.Path, line+1)
+}
+
+func (s XcodebuildArchiver) exportBuildIssues(issues BuildIssues, outputDir, artifactName string, outputPath func(string) (string, error)) error {
+	deprecationWarnings := issues.DeprecationWarnings()
+	s.logger.Printf("Build issues: %d errors, %d warnings (%d deprecations).", len(issues.Errors), len(issues.Warnings), len(deprecationWarnings))
+	for _, buildError := range issues.Errors {
+		s.logger.Printf("- error: %s (%s)", buildError.Message, buildError.Location)
+	}
+
+	counts := []struct {
+		key   string
+		value int
+	}{
+		{bitriseXcodebuildErrorCountEnvKey, len(issues.Errors)},
+		{bitriseXcodebuildWarningCountEnvKey, len(issues.Warnings)},
+		{bitriseXcodebuildDeprecationCountEnvKey, len(deprecationWarnings)},
+	}
+	for _, count := range counts {
+		if err := exportEnvironmentWithEnvman(s.cmdFactory, count.key, strconv.Itoa(count.value)); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", count.key, err)
+		}
+	}
+
+	content, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	issuesPath, err := outputPath(filepath.Join(outputDir, artifactName+".build-issues.json"))
+	if err != nil {
+		return err
+	}
+	if err := ExportOutputFileContent(s.cmdFactory, string(content), issuesPath, bitriseXcodebuildIssuesPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseXcodebuildIssuesPthEnvKey, err)
+	}
+	s.logger.Donef("The build issues are now available in the Environment Variable: %s (value: %s)", bitriseXcodebuildIssuesPthEnvKey, issuesPath)
+
+	return nil
+}
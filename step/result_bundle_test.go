@@ -0,0 +1,85 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseLegacyResultBundleIssues(t *testing.T) {
+	content := `{
+  "_type": {"_name": "ActionsInvocationRecord"},
+  "issues": {
+    "_type": {"_name": "ResultIssueSummaries"},
+    "warningSummaries": {
+      "_type": {"_name": "Array"},
+      "_values": [
+        {
+          "_type": {"_name": "IssueSummary"},
+          "issueType": {"_type": {"_name": "String"}, "_value": "Deprecation"},
+          "message": {"_type": {"_name": "String"}, "_value": "'UIWebView' was deprecated in iOS 12.0"},
+          "documentLocationInCreatingWorkspace": {
+            "url": {"_type": {"_name": "String"}, "_value": "file:///Users/vagrant/git/App/WebView.swift#CharacterRangeLen=0&EndingLineNumber=11&StartingLineNumber=11"}
+          }
+        },
+        {
+          "_type": {"_name": "IssueSummary"},
+          "issueType": {"_type": {"_name": "String"}, "_value": "Swift Compiler Warning"},
+          "message": {"_type": {"_name": "String"}, "_value": "variable 'x' was never used"}
+        }
+      ]
+    },
+    "errorSummaries": {
+      "_type": {"_name": "Array"},
+      "_values": [
+        {
+          "_type": {"_name": "IssueSummary"},
+          "issueType": {"_type": {"_name": "String"}, "_value": "Swift Compiler Error"},
+          "message": {"_type": {"_name": "String"}, "_value": "cannot find 'y' in scope"}
+        }
+      ]
+    }
+  }
+}`
+
+	issues, err := parseLegacyResultBundleIssues([]byte(content))
+	require.NoError(t, err)
+	require.Equal(t, BuildIssues{
+		Warnings: []BuildIssue{
+			{Type: "Deprecation", Message: "'UIWebView' was deprecated in iOS 12.0", Location: "/Users/vagrant/git/App/WebView.swift:12"},
+			{Type: "Swift Compiler Warning", Message: "variable 'x' was never used"},
+		},
+		Errors: []BuildIssue{
+			{Type: "Swift Compiler Error", Message: "cannot find 'y' in scope"},
+		},
+	}, issues)
+	require.Len(t, issues.DeprecationWarnings(), 1)
+}
+
+func Test_parseBuildResultsIssues(t *testing.T) {
+	content := `{
+  "actionTitle": "Archive \"App\"",
+  "status": "succeeded",
+  "warningCount": 2,
+  "errorCount": 0,
+  "warnings": [
+    {"issueType": "Swift Compiler Warning", "message": "'init()' is deprecated: use init(configuration:)", "targetName": "App", "sourceURL": "file:///App/Model.swift#StartingLineNumber=4&EndingLineNumber=4"},
+    {"issueType": "Swift Compiler Warning", "message": "immutable value 'a' was never used", "targetName": "App"}
+  ],
+  "errors": []
+}`
+
+	issues, err := parseBuildResultsIssues([]byte(content))
+	require.NoError(t, err)
+	require.Equal(t, []BuildIssue{
+		{Type: "Swift Compiler Warning", Message: "'init()' is deprecated: use init(configuration:)", Location: "/App/Model.swift:5"},
+		{Type: "Swift Compiler Warning", Message: "immutable value 'a' was never used"},
+	}, issues.Warnings)
+	require.Empty(t, issues.Errors)
+	require.Len(t, issues.DeprecationWarnings(), 1)
+}
+
+func Test_resultBundlePathFromOptions(t *testing.T) {
+	require.Equal(t, "out.xcresult", resultBundlePathFromOptions([]string{"-quiet", "-resultBundlePath", "out.xcresult"}))
+	require.Equal(t, "", resultBundlePathFromOptions([]string{"-quiet", "-resultBundlePath"}))
+}
@@ -0,0 +1,420 @@
+package step
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+const (
+	// SBOM formats
+	sbomFormatNone      = "none"
+	sbomFormatCycloneDX = "cyclonedx"
+	sbomFormatSPDX      = "spdx"
+
+	sbomToolName = "steps-xcode-archive"
+)
+
+// sbomComponent is a framework, bundle or Swift package shipped with the application.
+type sbomComponent struct {
+	Type     string
+	Name     string
+	Version  string
+	BundleID string
+	SHA256   string
+	// Location is the repository URL of Swift packages
+	Location   string
+	PackageURL string
+}
+
+// collectBundleComponents lists the embedded frameworks, extensions and resource bundles of the application.
+func collectBundleComponents(appPath string) ([]sbomComponent, error) {
+	patterns := []struct {
+		subdir        string
+		ext           string
+		componentType string
+	}{
+		{"Frameworks", ".framework", "framework"},
+		{"PlugIns", ".appex", "application"},
+		{".", ".bundle", "library"},
+	}
+
+	var components []sbomComponent
+	for _, p := range patterns {
+		pths, err := findBundles(appPath, p.subdir, p.ext)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pth := range pths {
+			component, err := newBundleComponent(pth, p.componentType)
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, component)
+		}
+	}
+
+	return components, nil
+}
+
+func newBundleComponent(bundlePath, componentType string) (sbomComponent, error) {
+	component := sbomComponent{
+		Type: componentType,
+		Name: strings.TrimSuffix(filepath.Base(bundlePath), filepath.Ext(bundlePath)),
+	}
+
+	infoPlistPath := filepath.Join(bundlePath, "Info.plist")
+	if exist, err := v1pathutil.IsPathExists(infoPlistPath); err != nil {
+		return sbomComponent{}, err
+	} else if !exist {
+		return component, nil
+	}
+
+	infoPlist, err := plistutil.NewPlistDataFromFile(infoPlistPath)
+	if err != nil {
+		return sbomComponent{}, fmt.Errorf("failed to read %s: %s", infoPlistPath, err)
+	}
+	component.Version, _ = infoPlist.GetString("CFBundleShortVersionString")
+	component.BundleID, _ = infoPlist.GetString("CFBundleIdentifier")
+
+	if executable, ok := infoPlist.GetString("CFBundleExecutable"); ok {
+		hash, err := fileSHA256(filepath.Join(bundlePath, executable))
+		if err != nil {
+			return sbomComponent{}, err
+		}
+		component.SHA256 = hash
+	}
+
+	return component, nil
+}
+
+func fileSHA256(pth string) (string, error) {
+	f, err := os.Open(pth)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// findPackageResolved returns the Package.resolved file of the project or workspace, or an empty string if it does not exist.
+func findPackageResolved(projectPath string) (string, error) {
+	candidates := []string{
+		filepath.Join(projectPath, "xcshareddata", "swiftpm", "Package.resolved"),
+		filepath.Join(projectPath, "project.xcworkspace", "xcshareddata", "swiftpm", "Package.resolved"),
+	}
+	for _, candidate := range candidates {
+		if exist, err := v1pathutil.IsPathExists(candidate); err != nil {
+			return "", err
+		} else if exist {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// packageResolved covers both the version 1 and the version 2 and 3 formats of Package.resolved.
+type packageResolved struct {
+	Object struct {
+		Pins []packageResolvedPin `json:"pins"`
+	} `json:"object"`
+	Pins []packageResolvedPin `json:"pins"`
+}
+
+type packageResolvedPin struct {
+	// Version 1
+	Package       string `json:"package"`
+	RepositoryURL string `json:"repositoryURL"`
+	// Version 2 and 3
+	Identity string `json:"identity"`
+	Location string `json:"location"`
+
+	State struct {
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+		Version  string `json:"version"`
+	} `json:"state"`
+}
+
+func readSwiftPackageComponents(packageResolvedPath string) ([]sbomComponent, error) {
+	b, err := os.ReadFile(packageResolvedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved packageResolved
+	if err := json.Unmarshal(b, &resolved); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", packageResolvedPath, err)
+	}
+
+	pins := append(resolved.Object.Pins, resolved.Pins...)
+	var components []sbomComponent
+	for _, pin := range pins {
+		name, location := pin.Identity, pin.Location
+		if name == "" {
+			name, location = pin.Package, pin.RepositoryURL
+		}
+
+		version := pin.State.Version
+		if version == "" {
+			version = pin.State.Revision
+		}
+
+		components = append(components, sbomComponent{
+			Type:       "library",
+			Name:       name,
+			Version:    version,
+			Location:   location,
+			PackageURL: swiftPackageURL(location, version),
+		})
+	}
+
+	return components, nil
+}
+
+var repositoryURLSchemeRegexp = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://)?([^@/]+@)?`)
+
+// swiftPackageURL returns the purl of a Swift package, for example: pkg:swift/github.com/Alamofire/Alamofire@5.8.1
+func swiftPackageURL(location, version string) string {
+	if location == "" {
+		return ""
+	}
+
+	pth := repositoryURLSchemeRegexp.ReplaceAllString(location, "")
+	pth = strings.Replace(pth, ":", "/", 1)
+	pth = strings.TrimSuffix(strings.TrimSuffix(pth, "/"), ".git")
+
+	return fmt.Sprintf("pkg:swift/%s@%s", pth, version)
+}
+
+type sbomApplication struct {
+	Name     string
+	Version  string
+	BundleID string
+}
+
+func cycloneDXDocument(app sbomApplication, components []sbomComponent, timestamp time.Time) ([]byte, error) {
+	type hash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	type property struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type component struct {
+		Type       string     `json:"type"`
+		BOMRef     string     `json:"bom-ref"`
+		Name       string     `json:"name"`
+		Version    string     `json:"version,omitempty"`
+		PackageURL string     `json:"purl,omitempty"`
+		Hashes     []hash     `json:"hashes,omitempty"`
+		Properties []property `json:"properties,omitempty"`
+	}
+
+	newComponent := func(c sbomComponent, index int) component {
+		out := component{
+			Type:       c.Type,
+			BOMRef:     fmt.Sprintf("component-%d", index),
+			Name:       c.Name,
+			Version:    c.Version,
+			PackageURL: c.PackageURL,
+		}
+		if c.SHA256 != "" {
+			out.Hashes = []hash{{Alg: "SHA-256", Content: c.SHA256}}
+		}
+		if c.BundleID != "" {
+			out.Properties = append(out.Properties, property{Name: "bundleIdentifier", Value: c.BundleID})
+		}
+		if c.Location != "" {
+			out.Properties = append(out.Properties, property{Name: "repositoryURL", Value: c.Location})
+		}
+		return out
+	}
+
+	document := struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+		Version     int    `json:"version"`
+		Metadata    struct {
+			Timestamp string `json:"timestamp"`
+			Tools     []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			Component component `json:"component"`
+		} `json:"metadata"`
+		Components []component `json:"components"`
+	}{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Components:  []component{},
+	}
+	document.Metadata.Timestamp = timestamp.UTC().Format(time.RFC3339)
+	document.Metadata.Tools = []struct {
+		Name string `json:"name"`
+	}{{Name: sbomToolName}}
+	document.Metadata.Component = newComponent(sbomComponent{Type: "application", Name: app.Name, Version: app.Version, BundleID: app.BundleID}, 0)
+
+	for i, c := range components {
+		document.Components = append(document.Components, newComponent(c, i+1))
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func spdxDocument(app sbomApplication, components []sbomComponent, timestamp time.Time) ([]byte, error) {
+	type checksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+	type externalRef struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	}
+	type spdxPackage struct {
+		Name             string        `json:"name"`
+		SPDXID           string        `json:"SPDXID"`
+		VersionInfo      string        `json:"versionInfo,omitempty"`
+		DownloadLocation string        `json:"downloadLocation"`
+		FilesAnalyzed    bool          `json:"filesAnalyzed"`
+		Checksums        []checksum    `json:"checksums,omitempty"`
+		ExternalRefs     []externalRef `json:"externalRefs,omitempty"`
+		Comment          string        `json:"comment,omitempty"`
+	}
+	type relationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+
+	newPackage := func(c sbomComponent, id string) spdxPackage {
+		out := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           id,
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+		}
+		if c.Location != "" {
+			out.DownloadLocation = c.Location
+		}
+		if c.SHA256 != "" {
+			out.Checksums = []checksum{{Algorithm: "SHA256", ChecksumValue: c.SHA256}}
+		}
+		if c.PackageURL != "" {
+			out.ExternalRefs = []externalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PackageURL}}
+		}
+		if c.BundleID != "" {
+			out.Comment = "Bundle identifier: " + c.BundleID
+		}
+		return out
+	}
+
+	appID := "SPDXRef-Application"
+	packages := []spdxPackage{newPackage(sbomComponent{Name: app.Name, Version: app.Version, BundleID: app.BundleID}, appID)}
+	relationships := []relationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: appID}}
+	for i, c := range components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		packages = append(packages, newPackage(c, id))
+		relationships = append(relationships, relationship{SPDXElementID: appID, RelationshipType: "CONTAINS", RelatedSPDXElement: id})
+	}
+
+	document := struct {
+		SPDXVersion       string `json:"spdxVersion"`
+		DataLicense       string `json:"dataLicense"`
+		SPDXID            string `json:"SPDXID"`
+		Name              string `json:"name"`
+		DocumentNamespace string `json:"documentNamespace"`
+		CreationInfo      struct {
+			Created  string   `json:"created"`
+			Creators []string `json:"creators"`
+		} `json:"creationInfo"`
+		Packages      []spdxPackage  `json:"packages"`
+		Relationships []relationship `json:"relationships"`
+	}{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              app.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s-%d", app.BundleID, app.Version, timestamp.Unix()),
+		Packages:          packages,
+		Relationships:     relationships,
+	}
+	document.CreationInfo.Created = timestamp.UTC().Format(time.RFC3339)
+	document.CreationInfo.Creators = []string{"Tool: " + sbomToolName}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func (s XcodebuildArchiver) exportSBOM(archive Archive, projectPath, format, outputDir, artifactName string, outputPath func(string) (string, error)) error {
+	components, err := collectBundleComponents(archive.Application.Path)
+	if err != nil {
+		return fmt.Errorf("failed to collect embedded bundles: %s", err)
+	}
+
+	packageResolvedPath, err := findPackageResolved(projectPath)
+	if err != nil {
+		return err
+	}
+	if packageResolvedPath != "" {
+		packages, err := readSwiftPackageComponents(packageResolvedPath)
+		if err != nil {
+			return err
+		}
+		components = append(components, packages...)
+	}
+
+	app := sbomApplication{
+		Name:     artifactName,
+		Version:  archive.Version(),
+		BundleID: archive.Application.BundleIdentifier(),
+	}
+
+	var content []byte
+	var sbomPath string
+	switch format {
+	case sbomFormatCycloneDX:
+		content, err = cycloneDXDocument(app, components, time.Now())
+		sbomPath = filepath.Join(outputDir, artifactName+".cdx.json")
+	case sbomFormatSPDX:
+		content, err = spdxDocument(app, components, time.Now())
+		sbomPath = filepath.Join(outputDir, artifactName+".spdx.json")
+	default:
+		return fmt.Errorf("unknown SBOM format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.Printf("SBOM contains %d components.", len(components))
+
+	if sbomPath, err = outputPath(sbomPath); err != nil {
+		return err
+	}
+
+	if err := ExportOutputFileContent(s.cmdFactory, string(content), sbomPath, bitriseSBOMPthEnvKey); err != nil {
+		return fmt.Errorf("failed to export %s, error: %s", bitriseSBOMPthEnvKey, err)
+	}
+	s.logger.Donef("The SBOM path is now available in the Environment Variable: %s (value: %s)", bitriseSBOMPthEnvKey, sbomPath)
+
+	return nil
+}
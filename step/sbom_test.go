@@ -0,0 +1,70 @@
+package step
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readSwiftPackageComponents(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []sbomComponent
+	}{
+		{
+			name: "version 1",
+			content: `{"object": {"pins": [{"package": "Alamofire", "repositoryURL": "https://github.com/Alamofire/Alamofire.git",
+				"state": {"branch": null, "revision": "f455c2975872ccd2d9c81594c658af65716e9b9a", "version": "5.8.1"}}]}, "version": 1}`,
+			want: []sbomComponent{{
+				Type:       "library",
+				Name:       "Alamofire",
+				Version:    "5.8.1",
+				Location:   "https://github.com/Alamofire/Alamofire.git",
+				PackageURL: "pkg:swift/github.com/Alamofire/Alamofire@5.8.1",
+			}},
+		},
+		{
+			name: "version 2 with branch pin",
+			content: `{"pins": [{"identity": "swift-log", "kind": "remoteSourceControl", "location": "git@github.com:apple/swift-log.git",
+				"state": {"branch": "main", "revision": "e97a6fcb1ab07462881ac165fdbb37f067e205d5"}}], "version": 2}`,
+			want: []sbomComponent{{
+				Type:       "library",
+				Name:       "swift-log",
+				Version:    "e97a6fcb1ab07462881ac165fdbb37f067e205d5",
+				Location:   "git@github.com:apple/swift-log.git",
+				PackageURL: "pkg:swift/github.com/apple/swift-log@e97a6fcb1ab07462881ac165fdbb37f067e205d5",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "Package.resolved")
+			writeTestFile(t, pth, tt.content)
+
+			got, err := readSwiftPackageComponents(pth)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_cycloneDXDocument(t *testing.T) {
+	app := sbomApplication{Name: "Sample", Version: "1.0", BundleID: "io.bitrise.sample"}
+	components := []sbomComponent{{Type: "framework", Name: "Lottie", Version: "4.3.0", BundleID: "com.airbnb.Lottie", SHA256: "abc"}}
+
+	b, err := cycloneDXDocument(app, components, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &document))
+	require.Equal(t, "CycloneDX", document["bomFormat"])
+	require.Len(t, document["components"], 1)
+
+	component := document["components"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "Lottie", component["name"])
+	require.Equal(t, []interface{}{map[string]interface{}{"alg": "SHA-256", "content": "abc"}}, component["hashes"])
+}
@@ -0,0 +1,165 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcscheme"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcworkspace"
+	"golang.org/x/text/unicode/norm"
+)
+
+const shareSchemeSuggestion = `Xcode stores schemes in the user's data directory (xcuserdata) by default, which is usually not committed.
+Mark the scheme as Shared in Xcode (Product > Scheme > Manage Schemes...) and commit the xcshareddata directory.`
+
+// projectScheme is a scheme and the project or workspace containing it.
+type projectScheme struct {
+	xcscheme.Scheme
+	Container string
+}
+
+// IsShared returns true if the scheme is available in a clean checkout of the repository.
+func (s projectScheme) IsShared() bool {
+	if s.Scheme.IsShared {
+		return true
+	}
+	exist, err := v1pathutil.IsPathExists(filepath.Join(s.Container, "xcshareddata", "xcschemes", s.Name+".xcscheme"))
+	return err == nil && exist
+}
+
+// IsArchivable ...
+func (s projectScheme) IsArchivable() bool {
+	_, ok := s.AppBuildActionEntry()
+	return ok
+}
+
+// listProjectSchemes returns the schemes considered by Xcode, when opening the given project or workspace.
+func listProjectSchemes(projectPath string) ([]projectScheme, error) {
+	var schemes []projectScheme
+	if xcodeproj.IsXcodeProj(projectPath) {
+		project, err := xcodeproj.Open(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		projectSchemes, err := project.Schemes()
+		if err != nil {
+			return nil, err
+		}
+		for _, scheme := range projectSchemes {
+			schemes = append(schemes, projectScheme{Scheme: scheme, Container: project.Path})
+		}
+		return schemes, nil
+	}
+
+	workspace, err := xcworkspace.Open(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	schemesByContainer, err := workspace.Schemes()
+	if err != nil {
+		return nil, err
+	}
+	for container, containerSchemes := range schemesByContainer {
+		for _, scheme := range containerSchemes {
+			schemes = append(schemes, projectScheme{Scheme: scheme, Container: container})
+		}
+	}
+	sort.Slice(schemes, func(i, j int) bool {
+		return schemes[i].Name < schemes[j].Name
+	})
+	return schemes, nil
+}
+
+// schemeContainers returns the project or workspace and (for workspaces) the embedded project paths.
+func schemeContainers(projectPath string) []string {
+	containers := []string{projectPath}
+	if xcodeproj.IsXcodeProj(projectPath) {
+		return containers
+	}
+
+	workspace, err := xcworkspace.Open(projectPath)
+	if err != nil {
+		return containers
+	}
+	projectLocations, err := workspace.ProjectFileLocations()
+	if err != nil {
+		return containers
+	}
+	return append(containers, projectLocations...)
+}
+
+// findUserScheme returns the path of a scheme, which exists only in a user's (not shared) scheme directory.
+func findUserScheme(projectPath, name string) (string, bool) {
+	for _, container := range schemeContainers(projectPath) {
+		pattern := filepath.Join(escapeGlobPath(container), "xcuserdata", "*.xcuserdatad", "xcschemes", escapeGlobPath(name)+".xcscheme")
+		if matches, err := filepath.Glob(pattern); err == nil && len(matches) > 0 {
+			return matches[0], true
+		}
+	}
+	return "", false
+}
+
+// selectDefaultScheme picks the only shared archivable scheme, or the one named after the project or workspace.
+func selectDefaultScheme(projectPath string, schemes []projectScheme) (string, error) {
+	var candidates []string
+	for _, scheme := range schemes {
+		if scheme.IsShared() && scheme.IsArchivable() {
+			candidates = append(candidates, scheme.Name)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no shared scheme with an archivable application found in %s\n%s", projectPath, shareSchemeSuggestion)
+	case 1:
+		return candidates[0], nil
+	}
+
+	projectName := strings.TrimSuffix(filepath.Base(projectPath), filepath.Ext(projectPath))
+	for _, candidate := range candidates {
+		if candidate == projectName {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("multiple shared schemes found in %s, please set the Scheme (`scheme`) input to one of them: %s", projectPath, strings.Join(candidates, ", "))
+}
+
+// resolveScheme validates the given scheme, or selects a default one if no scheme is given.
+func resolveScheme(projectPath, scheme string, logger log.Logger) (string, error) {
+	schemes, err := listProjectSchemes(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the schemes of %s: %w", projectPath, err)
+	}
+
+	if scheme == "" {
+		logger.Printf("Scheme is not set, looking for shared schemes")
+		selected, err := selectDefaultScheme(projectPath, schemes)
+		if err != nil {
+			return "", err
+		}
+		logger.Donef("Using scheme: %s", selected)
+		return selected, nil
+	}
+
+	for _, s := range schemes {
+		if norm.NFC.String(s.Name) == norm.NFC.String(scheme) {
+			return scheme, nil
+		}
+	}
+
+	if userSchemePath, ok := findUserScheme(projectPath, scheme); ok {
+		return "", fmt.Errorf("scheme %s is not shared, it only exists in a user's scheme directory: %s\n%s", scheme, userSchemePath, shareSchemeSuggestion)
+	}
+
+	var names []string
+	for _, s := range schemes {
+		names = append(names, s.Name)
+	}
+	return "", fmt.Errorf("scheme %s not found in %s, available schemes: %s", scheme, projectPath, strings.Join(names, ", "))
+}
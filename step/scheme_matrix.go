@@ -0,0 +1,137 @@
+package step
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/v2/codesign"
+)
+
+// schemeMatrixSeparator separates the fields of an additional scheme or configuration entry
+const schemeMatrixSeparator = "|"
+
+// exportMethods are the accepted values of the distribution method input
+var exportMethods = []string{"app-store", "ad-hoc", "enterprise", "development"}
+
+// SchemeMatrixEntry is a scheme and configuration archived and exported by the Step.
+type SchemeMatrixEntry struct {
+	Scheme        string
+	Configuration string
+	ExportMethod  string
+	// ArtifactNameSuffix distinguishes the artifacts of the additional entries, empty for the Scheme input
+	ArtifactNameSuffix string
+	// CodesignManager prepares the code signing of the entry, nil if automatic code signing is "off"
+	CodesignManager *codesign.Manager
+	// SigningRepairer repairs the code signing assets after a signing failure of the export, nil if disabled
+	SigningRepairer *SigningRepairer
+	// AdditionalCodesignManagers prepare the code signing of the additional distribution methods, nil if automatic code signing is "off"
+	AdditionalCodesignManagers []*codesign.Manager
+}
+
+// parseSchemeMatrix parses the newline separated list of `scheme` or `scheme|configuration` entries.
+func parseSchemeMatrix(content string) ([]SchemeMatrixEntry, error) {
+	var entries []SchemeMatrixEntry
+	for _, line := range matrixLines(content) {
+		scheme, configuration, _ := strings.Cut(line, schemeMatrixSeparator)
+		entry := SchemeMatrixEntry{
+			Scheme:        strings.TrimSpace(scheme),
+			Configuration: strings.TrimSpace(configuration),
+		}
+		if entry.Scheme == "" {
+			return nil, fmt.Errorf("missing scheme in entry: %s", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseConfigurationMatrix parses the newline separated list of `configuration` or `configuration|distribution_method` entries.
+func parseConfigurationMatrix(content string) ([]SchemeMatrixEntry, error) {
+	var entries []SchemeMatrixEntry
+	for _, line := range matrixLines(content) {
+		configuration, exportMethod, _ := strings.Cut(line, schemeMatrixSeparator)
+		entry := SchemeMatrixEntry{
+			Configuration: strings.TrimSpace(configuration),
+			ExportMethod:  strings.TrimSpace(exportMethod),
+		}
+		if entry.Configuration == "" {
+			return nil, fmt.Errorf("missing configuration in entry: %s", line)
+		}
+		if entry.ExportMethod != "" && !sliceutil.IsStringInSlice(entry.ExportMethod, exportMethods) {
+			return nil, fmt.Errorf("invalid distribution method (%s) in entry: %s, available: %s", entry.ExportMethod, line, strings.Join(exportMethods, ", "))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func matrixLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// newSchemeMatrix returns the entries archived by the Step: the Scheme input first,
+// followed by its additional configurations and the additional schemes.
+func newSchemeMatrix(primary SchemeMatrixEntry, configurations, schemes []SchemeMatrixEntry) ([]SchemeMatrixEntry, error) {
+	matrix := []SchemeMatrixEntry{primary}
+
+	for _, entry := range configurations {
+		entry.Scheme = primary.Scheme
+		entry.ArtifactNameSuffix = "-" + entry.Configuration
+		if entry.ExportMethod != "" {
+			entry.ArtifactNameSuffix += "-" + entry.ExportMethod
+		} else {
+			entry.ExportMethod = primary.ExportMethod
+		}
+		matrix = append(matrix, entry)
+	}
+
+	for _, entry := range schemes {
+		entry.ArtifactNameSuffix = "-" + entry.Scheme
+		if entry.Configuration != "" {
+			entry.ArtifactNameSuffix += "-" + entry.Configuration
+		}
+		entry.ExportMethod = primary.ExportMethod
+		matrix = append(matrix, entry)
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range matrix {
+		key := strings.Join([]string{entry.Scheme, entry.Configuration, entry.ExportMethod}, schemeMatrixSeparator)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicated entry: scheme %s, configuration %s, distribution method %s", entry.Scheme, entry.Configuration, entry.ExportMethod)
+		}
+		seen[key] = true
+	}
+
+	return matrix, nil
+}
+
+// ExportedFiles collects the artifacts of the subsequent ExportOutput calls of a multi-scheme run,
+// so the exported files outputs list the artifacts of every scheme.
+type ExportedFiles struct {
+	artifacts []exportedArtifact
+}
+
+// add records the artifacts of the scheme and configuration and returns the artifacts of every scheme exported so far.
+// Only the artifacts of the current scheme keep their checksum and signature Environment Variables,
+// as those are overwritten by each scheme.
+func (f *ExportedFiles) add(scheme, configuration string, artifacts []exportedArtifact) []exportedArtifact {
+	all := append([]exportedArtifact{}, f.artifacts...)
+	for _, artifact := range artifacts {
+		artifact.Scheme = scheme
+		artifact.Configuration = configuration
+		all = append(all, artifact)
+
+		artifact.ChecksumEnvKey = ""
+		artifact.SignatureEnvKey = ""
+		f.artifacts = append(f.artifacts, artifact)
+	}
+	return all
+}
@@ -0,0 +1,127 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseSchemeMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []SchemeMatrixEntry
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "schemes with and without configuration",
+			content: "Widgets\n\n  Admin | Release-Internal  \n",
+			want: []SchemeMatrixEntry{
+				{Scheme: "Widgets"},
+				{Scheme: "Admin", Configuration: "Release-Internal"},
+			},
+		},
+		{
+			name:    "missing scheme",
+			content: "|Release",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSchemeMatrix(tt.content)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseConfigurationMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []SchemeMatrixEntry
+		wantErr bool
+	}{
+		{
+			name:    "configurations with and without distribution method",
+			content: "Debug|development\nStaging\n",
+			want: []SchemeMatrixEntry{
+				{Configuration: "Debug", ExportMethod: "development"},
+				{Configuration: "Staging"},
+			},
+		},
+		{
+			name:    "missing configuration",
+			content: "|development",
+			wantErr: true,
+		},
+		{
+			name:    "invalid distribution method",
+			content: "Debug|debugging",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfigurationMatrix(tt.content)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newSchemeMatrix(t *testing.T) {
+	primary := SchemeMatrixEntry{Scheme: "App", Configuration: "Release", ExportMethod: "app-store"}
+
+	matrix, err := newSchemeMatrix(primary,
+		[]SchemeMatrixEntry{{Configuration: "Debug", ExportMethod: "development"}, {Configuration: "Staging"}},
+		[]SchemeMatrixEntry{{Scheme: "Widgets"}, {Scheme: "Admin", Configuration: "Debug"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []SchemeMatrixEntry{
+		{Scheme: "App", Configuration: "Release", ExportMethod: "app-store"},
+		{Scheme: "App", Configuration: "Debug", ExportMethod: "development", ArtifactNameSuffix: "-Debug-development"},
+		{Scheme: "App", Configuration: "Staging", ExportMethod: "app-store", ArtifactNameSuffix: "-Staging"},
+		{Scheme: "Widgets", ExportMethod: "app-store", ArtifactNameSuffix: "-Widgets"},
+		{Scheme: "Admin", Configuration: "Debug", ExportMethod: "app-store", ArtifactNameSuffix: "-Admin-Debug"},
+	}, matrix)
+
+	_, err = newSchemeMatrix(primary, []SchemeMatrixEntry{{Configuration: "Release"}}, nil)
+	require.Error(t, err)
+
+	_, err = newSchemeMatrix(primary, nil, []SchemeMatrixEntry{{Scheme: "Widgets"}, {Scheme: "Widgets"}})
+	require.Error(t, err)
+}
+
+func TestExportedFiles_add(t *testing.T) {
+	var files ExportedFiles
+
+	first := files.add("App", "Release", []exportedArtifact{
+		{Path: "/out/App.ipa", Type: artifactTypeIPA, ChecksumEnvKey: bitriseIPASHA256EnvKey, SignatureEnvKey: bitriseIPASignaturePthEnvKey},
+	})
+	require.Equal(t, []exportedArtifact{
+		{Path: "/out/App.ipa", Type: artifactTypeIPA, Scheme: "App", Configuration: "Release", ChecksumEnvKey: bitriseIPASHA256EnvKey, SignatureEnvKey: bitriseIPASignaturePthEnvKey},
+	}, first)
+
+	second := files.add("Widgets", "Release", []exportedArtifact{
+		{Path: "/out/Widgets.ipa", Type: artifactTypeIPA, ChecksumEnvKey: bitriseIPASHA256EnvKey},
+	})
+	require.Equal(t, []exportedArtifact{
+		{Path: "/out/App.ipa", Type: artifactTypeIPA, Scheme: "App", Configuration: "Release"},
+		{Path: "/out/Widgets.ipa", Type: artifactTypeIPA, Scheme: "Widgets", Configuration: "Release", ChecksumEnvKey: bitriseIPASHA256EnvKey},
+	}, second)
+}
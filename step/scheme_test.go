@@ -0,0 +1,76 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcscheme"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheme(name string, isShared, isArchivable bool) projectScheme {
+	scheme := xcscheme.Scheme{Name: name, IsShared: isShared}
+	if isArchivable {
+		scheme.BuildAction.BuildActionEntries = []xcscheme.BuildActionEntry{{
+			BuildForArchiving:  "YES",
+			BuildableReference: xcscheme.BuildableReference{BlueprintIdentifier: "ID", BuildableName: name + ".app"},
+		}}
+	}
+	return projectScheme{Scheme: scheme, Container: "/tmp/MyApp.xcodeproj"}
+}
+
+func Test_selectDefaultScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemes []projectScheme
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "single shared archivable scheme",
+			schemes: []projectScheme{newTestScheme("MyApp", true, true), newTestScheme("MyFramework", true, false), newTestScheme("Local", false, true)},
+			want:    "MyApp",
+		},
+		{
+			name:    "scheme named after the project",
+			schemes: []projectScheme{newTestScheme("Beta", true, true), newTestScheme("MyApp", true, true)},
+			want:    "MyApp",
+		},
+		{
+			name:    "multiple candidates",
+			schemes: []projectScheme{newTestScheme("Beta", true, true), newTestScheme("Production", true, true)},
+			wantErr: "multiple shared schemes found in /tmp/MyApp.xcodeproj, please set the Scheme (`scheme`) input to one of them: Beta, Production",
+		},
+		{
+			name:    "no shared scheme",
+			schemes: []projectScheme{newTestScheme("Local", false, true)},
+			wantErr: "no shared scheme with an archivable application found in /tmp/MyApp.xcodeproj\n" + shareSchemeSuggestion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectDefaultScheme("/tmp/MyApp.xcodeproj", tt.schemes)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_findUserScheme(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "MyApp.xcodeproj")
+	userSchemePath := filepath.Join(projectPath, "xcuserdata", "developer.xcuserdatad", "xcschemes", "MyApp.xcscheme")
+	require.NoError(t, os.MkdirAll(filepath.Dir(userSchemePath), 0700))
+	require.NoError(t, os.WriteFile(userSchemePath, []byte(""), 0600))
+
+	pth, ok := findUserScheme(projectPath, "MyApp")
+	require.True(t, ok)
+	require.Equal(t, userSchemePath, pth)
+
+	_, ok = findUserScheme(projectPath, "Other")
+	require.False(t, ok)
+}
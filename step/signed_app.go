@@ -0,0 +1,84 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	artifactTypeAppZip      = "app-zip"
+	artifactTypeWatchAppZip = "watch-app-zip"
+)
+
+// signedAppBundles returns the application bundle of the unzipped IPA and the Watch application bundles embedded in it.
+func signedAppBundles(unzippedIPADir string) (string, []string, error) {
+	apps, err := filepath.Glob(filepath.Join(escapeGlobPath(unzippedIPADir), payloadDirName, "*.app"))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(apps) == 0 {
+		return "", nil, fmt.Errorf("no application found in the IPA's %s directory", payloadDirName)
+	}
+
+	watchApps, err := filepath.Glob(filepath.Join(escapeGlobPath(apps[0]), "Watch", "*.app"))
+	if err != nil {
+		return "", nil, err
+	}
+	return apps[0], watchApps, nil
+}
+
+// exportSignedApps exports the zipped application (and Watch application) bundles of the IPA, which are signed for the distribution,
+// unlike the ones in the archive.
+func (s XcodebuildArchiver) exportSignedApps(ipaPath, outputDir, name string, outputPath func(string) (string, error)) ([]exportedArtifact, error) {
+	tmpDir, err := v1pathutil.NormalizedOSTempDirPath("__signed_app__")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tmp dir, error: %s", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	cmd := s.cmdFactory.Create("unzip", []string{"-q", ipaPath, "-d", tmpDir}, nil)
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to unzip the IPA: %s, output: %s", err, out)
+	}
+
+	appPath, watchAppPaths, err := signedAppBundles(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []exportedArtifact
+
+	appZipPath, err := outputPath(filepath.Join(outputDir, name+".app.zip"))
+	if err != nil {
+		return nil, err
+	}
+	if err := ExportOutputDirAsZip(s.cmdFactory, appPath, appZipPath, bitriseSignedAppZipPthEnvKey, s.logger); err != nil {
+		return nil, fmt.Errorf("failed to export %s, error: %s", bitriseSignedAppZipPthEnvKey, err)
+	}
+	s.logger.Donef("The signed app zip path is now available in the Environment Variable: %s (value: %s)", bitriseSignedAppZipPthEnvKey, appZipPath)
+	artifacts = append(artifacts, exportedArtifact{Path: appZipPath, Type: artifactTypeAppZip})
+
+	if len(watchAppPaths) == 0 {
+		return artifacts, nil
+	}
+	if len(watchAppPaths) > 1 {
+		s.logger.Warnf("More than 1 Watch app found, exporting the first one: %s", filepath.Base(watchAppPaths[0]))
+	}
+
+	watchAppZipPath, err := outputPath(filepath.Join(outputDir, name+".watch.app.zip"))
+	if err != nil {
+		return nil, err
+	}
+	if err := ExportOutputDirAsZip(s.cmdFactory, watchAppPaths[0], watchAppZipPath, bitriseSignedWatchAppZipPthEnvKey, s.logger); err != nil {
+		return nil, fmt.Errorf("failed to export %s, error: %s", bitriseSignedWatchAppZipPthEnvKey, err)
+	}
+	s.logger.Donef("The signed Watch app zip path is now available in the Environment Variable: %s (value: %s)", bitriseSignedWatchAppZipPthEnvKey, watchAppZipPath)
+	artifacts = append(artifacts, exportedArtifact{Path: watchAppZipPath, Type: artifactTypeWatchAppZip})
+
+	return artifacts, nil
+}
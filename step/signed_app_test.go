@@ -0,0 +1,34 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_signedAppBundles(t *testing.T) {
+	dir := t.TempDir()
+	appPath := filepath.Join(dir, "Payload", "Sample.app")
+	watchAppPath := filepath.Join(appPath, "Watch", "SampleWatch.app")
+	require.NoError(t, os.MkdirAll(watchAppPath, 0755))
+
+	gotApp, gotWatchApps, err := signedAppBundles(dir)
+	require.NoError(t, err)
+	require.Equal(t, appPath, gotApp)
+	require.Equal(t, []string{watchAppPath}, gotWatchApps)
+
+	dir = filepath.Join(t.TempDir(), "[Release] Sample's Ünïcode-App")
+	appPath = filepath.Join(dir, "Payload", "Sample Ápp.app")
+	watchAppPath = filepath.Join(appPath, "Watch", "Sample Wätch.app")
+	require.NoError(t, os.MkdirAll(watchAppPath, 0755))
+
+	gotApp, gotWatchApps, err = signedAppBundles(dir)
+	require.NoError(t, err)
+	require.Equal(t, appPath, gotApp)
+	require.Equal(t, []string{watchAppPath}, gotWatchApps)
+
+	_, _, err = signedAppBundles(t.TempDir())
+	require.Error(t, err)
+}
@@ -0,0 +1,301 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/profileutil"
+)
+
+const (
+	// signingAssetBundleManifestName is the manifest of the signing asset bundle, listing its profiles and certificates
+	signingAssetBundleManifestName = "manifest.json"
+	// signingAssetBundleProfilesDir is the directory of the provisioning profiles in the signing asset bundle
+	signingAssetBundleProfilesDir = "profiles"
+)
+
+// signingAssetBundleManifest describes the provisioning profiles of the bundle, and references their signing certificates.
+// The certificates (and their private keys) are not bundled, they need to be installed in the keychain.
+type signingAssetBundleManifest struct {
+	Profiles     []signingAssetBundleProfile     `json:"profiles"`
+	Certificates []signingAssetBundleCertificate `json:"certificates"`
+}
+
+type signingAssetBundleProfile struct {
+	File           string    `json:"file"`
+	UUID           string    `json:"uuid"`
+	Name           string    `json:"name"`
+	BundleID       string    `json:"bundle_id"`
+	TeamID         string    `json:"team_id"`
+	ExportType     string    `json:"export_type"`
+	ExpirationDate time.Time `json:"expiration_date"`
+	// CertificateFingerprints are the SHA1 fingerprints of the certificates, which sign with the profile
+	CertificateFingerprints []string `json:"certificate_fingerprints"`
+}
+
+type signingAssetBundleCertificate struct {
+	CommonName      string    `json:"common_name"`
+	Serial          string    `json:"serial"`
+	SHA1Fingerprint string    `json:"sha1_fingerprint"`
+	TeamID          string    `json:"team_id"`
+	EndDate         time.Time `json:"end_date"`
+}
+
+// newSigningAssetBundleManifest returns the manifest of the profiles, listed in the order of the profiles.
+func newSigningAssetBundleManifest(profiles []installedProfile) signingAssetBundleManifest {
+	manifest := signingAssetBundleManifest{Profiles: []signingAssetBundleProfile{}, Certificates: []signingAssetBundleCertificate{}}
+	seenCertificates := map[string]bool{}
+	for _, profile := range profiles {
+		info := profile.Info
+		bundleProfile := signingAssetBundleProfile{
+			File:           path.Join(signingAssetBundleProfilesDir, signingAssetBundleProfileFileName(info)),
+			UUID:           info.UUID,
+			Name:           info.Name,
+			BundleID:       info.BundleID,
+			TeamID:         info.TeamID,
+			ExportType:     string(info.ExportType),
+			ExpirationDate: info.ExpirationDate,
+		}
+		for _, certificate := range info.DeveloperCertificates {
+			bundleProfile.CertificateFingerprints = append(bundleProfile.CertificateFingerprints, certificate.SHA1Fingerprint)
+			if seenCertificates[certificate.SHA1Fingerprint] {
+				continue
+			}
+			seenCertificates[certificate.SHA1Fingerprint] = true
+			manifest.Certificates = append(manifest.Certificates, signingAssetBundleCertificate{
+				CommonName:      certificate.CommonName,
+				Serial:          certificate.Serial,
+				SHA1Fingerprint: certificate.SHA1Fingerprint,
+				TeamID:          certificate.TeamID,
+				EndDate:         certificate.EndDate,
+			})
+		}
+		manifest.Profiles = append(manifest.Profiles, bundleProfile)
+	}
+	return manifest
+}
+
+func signingAssetBundleProfileFileName(info profileutil.ProvisioningProfileInfoModel) string {
+	if info.Type == profileutil.ProfileTypeMacOs {
+		return info.UUID + ".provisionprofile"
+	}
+	return info.UUID + ".mobileprovision"
+}
+
+// writeSigningAssetBundle writes the profiles and their manifest into the signing asset bundle (zip) at pth.
+func writeSigningAssetBundle(pth string, profiles []installedProfile) (err error) {
+	file, err := os.Create(pth)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Info.BundleID != profiles[j].Info.BundleID {
+			return profiles[i].Info.BundleID < profiles[j].Info.BundleID
+		}
+		return profiles[i].Info.UUID < profiles[j].Info.UUID
+	})
+
+	writer := archivezip.NewWriter(file)
+	manifest := newSigningAssetBundleManifest(profiles)
+	for i, profile := range profiles {
+		entry, err := writer.Create(manifest.Profiles[i].File)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(profile.Content); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	entry, err := writer.Create(signingAssetBundleManifestName)
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(content); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// exportSigningAssetBundle packages the installed provisioning profiles of the UUIDs into the signing asset bundle,
+// so that later builds can sign without querying the Apple Developer Portal.
+func (s XcodebuildArchiver) exportSigningAssetBundle(pth string, profileUUIDs []string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	installed, err := listInstalledProfiles(homeDir, s.logger)
+	if err != nil {
+		return err
+	}
+
+	var profiles []installedProfile
+	found := map[string]bool{}
+	for _, profile := range installed {
+		if sliceutil.IsStringInSlice(profile.Info.UUID, profileUUIDs) && !found[profile.Info.UUID] {
+			found[profile.Info.UUID] = true
+			profiles = append(profiles, profile)
+		}
+	}
+	for _, uuid := range profileUUIDs {
+		if !found[uuid] {
+			s.logger.Warnf("The provisioning profile (%s) is not installed, it is missing from the signing asset bundle", uuid)
+		}
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("none of the used provisioning profiles is installed")
+	}
+
+	if err := writeSigningAssetBundle(pth, profiles); err != nil {
+		return fmt.Errorf("failed to write signing asset bundle: %w", err)
+	}
+	if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseSigningAssetBundlePthEnvKey, pth); err != nil {
+		return fmt.Errorf("failed to export %s: %w", bitriseSigningAssetBundlePthEnvKey, err)
+	}
+	s.logger.Donef("The signing asset bundle (%d profiles) is now available in the Environment Variable: %s (value: %s)", len(profiles), bitriseSigningAssetBundlePthEnvKey, pth)
+	return nil
+}
+
+// readSigningAssetBundle returns the manifest and the profile contents (by the manifest's file paths) of the signing asset bundle.
+func readSigningAssetBundle(pth string) (signingAssetBundleManifest, map[string][]byte, error) {
+	reader, err := archivezip.OpenReader(pth)
+	if err != nil {
+		return signingAssetBundleManifest{}, nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	var manifest signingAssetBundleManifest
+	var manifestFound bool
+	contents := map[string][]byte{}
+	for _, file := range reader.File {
+		content, err := readZippedFile(file)
+		if err != nil {
+			return signingAssetBundleManifest{}, nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if file.Name == signingAssetBundleManifestName {
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				return signingAssetBundleManifest{}, nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+			manifestFound = true
+			continue
+		}
+		contents[file.Name] = content
+	}
+	if !manifestFound {
+		return signingAssetBundleManifest{}, nil, fmt.Errorf("%s not found in the signing asset bundle", signingAssetBundleManifestName)
+	}
+	for _, profile := range manifest.Profiles {
+		if _, ok := contents[profile.File]; !ok {
+			return signingAssetBundleManifest{}, nil, fmt.Errorf("the profile %s (%s) not found in the signing asset bundle", profile.Name, profile.File)
+		}
+	}
+	return manifest, contents, nil
+}
+
+func readZippedFile(file *archivezip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	return io.ReadAll(rc)
+}
+
+// signingIdentityPattern matches the SHA1 fingerprints in the security find-identity output,
+// for example: 1) 0123456789ABCDEF0123456789ABCDEF01234567 "Apple Distribution: Bitrise (TEAM123)"
+var signingIdentityPattern = regexp.MustCompile(`^\s*\d+\)\s+([0-9A-Fa-f]{40})\s`)
+
+func parseSigningIdentityFingerprints(output string) []string {
+	var fingerprints []string
+	for _, line := range strings.Split(output, "\n") {
+		if match := signingIdentityPattern.FindStringSubmatch(line); match != nil {
+			fingerprints = append(fingerprints, strings.ToUpper(match[1]))
+		}
+	}
+	return fingerprints
+}
+
+// missingSigningCertificates returns the profiles of the manifest, none of whose certificates are installed.
+func missingSigningCertificates(manifest signingAssetBundleManifest, installedFingerprints []string) []string {
+	var missing []string
+	for _, profile := range manifest.Profiles {
+		installed := false
+		for _, fingerprint := range profile.CertificateFingerprints {
+			if sliceutil.IsStringInSlice(strings.ToUpper(fingerprint), installedFingerprints) {
+				installed = true
+				break
+			}
+		}
+		if !installed {
+			missing = append(missing, fmt.Sprintf("%s (%s)", profile.Name, profile.BundleID))
+		}
+	}
+	return missing
+}
+
+// installSigningAssetBundle installs the provisioning profiles of the signing asset bundle,
+// and checks that their signing certificates are installed in the keychain.
+func (s XcodebuildArchiveConfigParser) installSigningAssetBundle(pth, keychainPath string) error {
+	manifest, contents, err := readSigningAssetBundle(pth)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"find-identity", "-v", "-p", "codesigning"}
+	if keychainPath != "" {
+		args = append(args, keychainPath)
+	}
+	cmd := s.cmdFactory.Create("security", args, nil)
+	output, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+	}
+	if missing := missingSigningCertificates(manifest, parseSigningIdentityFingerprints(output)); len(missing) > 0 {
+		return fmt.Errorf("the signing certificates of the profiles are not installed in the keychain: %s", strings.Join(missing, ", "))
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	for _, dir := range profileDirs {
+		if err := os.MkdirAll(filepath.Join(homeDir, dir), 0700); err != nil {
+			return err
+		}
+		for _, profile := range manifest.Profiles {
+			if err := os.WriteFile(filepath.Join(homeDir, dir, path.Base(profile.File)), contents[profile.File], 0600); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.logger.Printf("Installed the provisioning profiles of the signing asset bundle:")
+	for _, profile := range manifest.Profiles {
+		s.logger.Printf("- %s (%s, %s), expires: %s", profile.Name, profile.BundleID, profile.ExportType, profile.ExpirationDate.Format("2006-01-02"))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package step
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/certificateutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeSigningAssetBundle(t *testing.T) {
+	app := testInstalledProfile("App Store", "io.bitrise.sample", "ios")
+	app.Info.UUID = "app-uuid"
+	app.Info.DeveloperCertificates = []certificateutil.CertificateInfoModel{{CommonName: "Apple Distribution", SHA1Fingerprint: "AAAA"}}
+	app.Content = []byte("app profile")
+	widget := testInstalledProfile("Widget App Store", "io.bitrise.sample.widget", "ios")
+	widget.Info.UUID = "widget-uuid"
+	widget.Info.DeveloperCertificates = app.Info.DeveloperCertificates
+	widget.Content = []byte("widget profile")
+
+	pth := filepath.Join(t.TempDir(), "signing-assets.zip")
+	require.NoError(t, writeSigningAssetBundle(pth, []installedProfile{widget, app}))
+
+	manifest, contents, err := readSigningAssetBundle(pth)
+	require.NoError(t, err)
+	require.Len(t, manifest.Profiles, 2)
+	require.Equal(t, "io.bitrise.sample", manifest.Profiles[0].BundleID)
+	require.Equal(t, "profiles/app-uuid.mobileprovision", manifest.Profiles[0].File)
+	require.Equal(t, []string{"AAAA"}, manifest.Profiles[1].CertificateFingerprints)
+	require.Len(t, manifest.Certificates, 1)
+	require.Equal(t, []byte("widget profile"), contents[manifest.Profiles[1].File])
+}
+
+func Test_missingSigningCertificates(t *testing.T) {
+	output := `  1) 0123456789abcdef0123456789abcdef01234567 "Apple Distribution: Bitrise (TEAM123)"
+     1 valid identities found`
+	fingerprints := parseSigningIdentityFingerprints(output)
+	require.Equal(t, []string{"0123456789ABCDEF0123456789ABCDEF01234567"}, fingerprints)
+
+	manifest := signingAssetBundleManifest{Profiles: []signingAssetBundleProfile{
+		{Name: "App Store", BundleID: "io.bitrise.sample", CertificateFingerprints: []string{"0123456789abcdef0123456789abcdef01234567"}},
+		{Name: "Development", BundleID: "io.bitrise.sample", CertificateFingerprints: []string{"FFFF"}},
+	}}
+	require.Equal(t, []string{"Development (io.bitrise.sample)"}, missingSigningCertificates(manifest, fingerprints))
+}
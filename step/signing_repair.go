@@ -0,0 +1,191 @@
+package step
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient/appstoreconnect"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	signingErrorProfile     = "profile"
+	signingErrorCertificate = "certificate"
+)
+
+// signingErrorPatterns classify the signing errors of the xcodebuild -exportArchive log, the profile patterns are checked first.
+var signingErrorPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{signingErrorProfile, regexp.MustCompile(`(?i)no (".*" )?profiles? for|requires a provisioning profile|provisioning profile .* (doesn't|does not) (include|match|support)|provisioning profile .* (has expired|is invalid)`)},
+	{signingErrorCertificate, regexp.MustCompile(`(?i)no signing certificate|certificate .*(has been revoked|is revoked|has expired|is not valid)|(doesn't|does not) include signing certificate`)},
+}
+
+// quotedBundleIDPattern matches the quoted identifiers of the error lines, for example 'io.bitrise.sample'.
+var quotedBundleIDPattern = regexp.MustCompile(`['"]([A-Za-z0-9\-.]+)['"]`)
+
+// signingError is a classified signing failure of the IPA export.
+type signingError struct {
+	Kind string
+	// BundleIDs are the affected bundle IDs of the archive
+	BundleIDs []string
+}
+
+// classifySigningError returns the signing failure of the export log, and the affected bundle IDs of the archive.
+// If the error lines do not name a bundle ID of the archive (for example a revoked certificate), every bundle ID is affected.
+func classifySigningError(exportLog string, archiveBundleIDs []string) (signingError, bool) {
+	var (
+		kind      string
+		bundleIDs = map[string]bool{}
+	)
+	for _, line := range xcodebuildErrorExcerpt(exportLog, len(strings.Split(exportLog, "\n"))) {
+		lineKind := ""
+		for _, p := range signingErrorPatterns {
+			if p.pattern.MatchString(line) {
+				lineKind = p.kind
+				break
+			}
+		}
+		if lineKind == "" {
+			continue
+		}
+		if kind == "" || lineKind == signingErrorCertificate {
+			kind = lineKind
+		}
+
+		for _, match := range quotedBundleIDPattern.FindAllStringSubmatch(line, -1) {
+			for _, bundleID := range archiveBundleIDs {
+				if match[1] == bundleID {
+					bundleIDs[bundleID] = true
+				}
+			}
+		}
+	}
+	if kind == "" {
+		return signingError{}, false
+	}
+
+	affected := archiveBundleIDs
+	if kind == signingErrorProfile && len(bundleIDs) > 0 {
+		affected = nil
+		for bundleID := range bundleIDs {
+			affected = append(affected, bundleID)
+		}
+	}
+	affected = append([]string{}, affected...)
+	sort.Strings(affected)
+	return signingError{Kind: kind, BundleIDs: affected}, true
+}
+
+// filterAppLayout returns the app layout of the given bundle IDs.
+func filterAppLayout(appLayout autocodesign.AppLayout, bundleIDs []string) autocodesign.AppLayout {
+	filtered := autocodesign.AppLayout{
+		Platform:                               appLayout.Platform,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{},
+	}
+	for _, bundleID := range bundleIDs {
+		if entitlements, ok := appLayout.EntitlementsByArchivableTargetBundleID[bundleID]; ok {
+			filtered.EntitlementsByArchivableTargetBundleID[bundleID] = entitlements
+		}
+	}
+	return filtered
+}
+
+// missingLocalCodesignAssets reports every bundle ID as missing, so the profiles are ensured on the Developer Portal
+// instead of reusing the installed profiles the export failed with.
+type missingLocalCodesignAssets struct{}
+
+func (missingLocalCodesignAssets) FindCodesignAssets(appLayout autocodesign.AppLayout, _ autocodesign.DistributionType, _ map[appstoreconnect.CertificateType][]autocodesign.Certificate, _ []string, _ int) (*autocodesign.AppCodesignAssets, *autocodesign.AppLayout, error) {
+	return nil, &appLayout, nil
+}
+
+// SigningRepairer re-runs the code signing asset management of the automatic code signing for the given bundle IDs of an archive.
+type SigningRepairer struct {
+	credentials            devportalservice.Credentials
+	teamID                 string
+	distributionType       autocodesign.DistributionType
+	minProfileValidityDays int
+	verboseLog             bool
+	testDevices            []devportalservice.TestDevice
+
+	devPortalClientFactory devportalclient.Factory
+	certDownloader         autocodesign.CertificateProvider
+	assetWriter            autocodesign.AssetWriter
+}
+
+// Repair ensures the certificates and profiles of the given bundle IDs on the Developer Portal, and installs them.
+func (r SigningRepairer) Repair(archive xcarchive.IosArchive, bundleIDs []string) error {
+	appLayout, err := archive.GetAppLayout(false)
+	if err != nil {
+		return fmt.Errorf("failed to read the app layout of the archive: %w", err)
+	}
+	appLayout = filterAppLayout(appLayout, bundleIDs)
+
+	certificates, err := r.certDownloader.GetCertificates()
+	if err != nil {
+		return fmt.Errorf("failed to download certificates: %w", err)
+	}
+	typeToLocalCerts, err := autocodesign.GetValidLocalCertificates(certificates)
+	if err != nil {
+		return err
+	}
+
+	devPortalClient, err := r.devPortalClientFactory.Create(r.credentials, r.teamID)
+	if err != nil {
+		return err
+	}
+	if err := devPortalClient.Login(); err != nil {
+		return fmt.Errorf("Developer Portal client login failed: %w", err)
+	}
+
+	manager := autocodesign.NewCodesignAssetManager(devPortalClient, r.assetWriter, missingLocalCodesignAssets{})
+	if _, err := manager.EnsureCodesignAssets(appLayout, autocodesign.CodesignAssetsOpts{
+		DistributionType:        r.distributionType,
+		TypeToLocalCertificates: typeToLocalCerts,
+		BitriseTestDevices:      r.testDevices,
+		MinProfileValidityDays:  r.minProfileValidityDays,
+		VerboseLog:              r.verboseLog,
+	}); err != nil {
+		return fmt.Errorf("failed to ensure code signing assets: %w", err)
+	}
+	return nil
+}
+
+// repairSigning repairs the code signing assets of the bundles affected by the signing failure of the export,
+// it returns true if the export should be retried.
+func (s XcodebuildArchiver) repairSigning(repairer *SigningRepairer, archive xcarchive.IosArchive, exportLog string) bool {
+	if repairer == nil {
+		return false
+	}
+
+	var bundleIDs []string
+	for _, bundle := range NewArchive(archive).Bundles() {
+		bundleIDs = append(bundleIDs, bundle.BundleIdentifier())
+	}
+	signingErr, ok := classifySigningError(exportLog, bundleIDs)
+	if !ok {
+		return false
+	}
+
+	s.logger.Println()
+	s.logger.Warnf("The export failed with a %s signing error, repairing the code signing assets of: %s", signingErr.Kind, strings.Join(signingErr.BundleIDs, ", "))
+
+	unlockProfiles, err := s.lockProfiles()
+	if err != nil {
+		s.logger.Warnf("Failed to lock provisioning profiles: %s", err)
+		return false
+	}
+	defer unlockProfiles()
+
+	if err := repairer.Repair(archive, signingErr.BundleIDs); err != nil {
+		s.logger.Warnf("Failed to repair the code signing assets: %s", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_classifySigningError(t *testing.T) {
+	bundleIDs := []string{"io.bitrise.sample", "io.bitrise.sample.widget", "io.bitrise.sample.clip"}
+
+	tests := []struct {
+		name      string
+		exportLog string
+		want      signingError
+		wantOK    bool
+	}{
+		{
+			name:      "missing profile of an extension",
+			exportLog: `error: exportArchive: No "iOS App Store" profiles for team 'ABCD1234' matching 'io.bitrise.sample.widget' are installed.`,
+			want:      signingError{Kind: signingErrorProfile, BundleIDs: []string{"io.bitrise.sample.widget"}},
+			wantOK:    true,
+		},
+		{
+			name:      "missing profile without bundle ID",
+			exportLog: `error: exportArchive: "Sample.app" requires a provisioning profile.`,
+			want:      signingError{Kind: signingErrorProfile, BundleIDs: []string{"io.bitrise.sample", "io.bitrise.sample.clip", "io.bitrise.sample.widget"}},
+			wantOK:    true,
+		},
+		{
+			name: "revoked certificate",
+			exportLog: `error: exportArchive: No profiles for 'io.bitrise.sample.clip' were found
+error: exportArchive: Certificate "Apple Distribution: Bitrise" has been revoked`,
+			want:   signingError{Kind: signingErrorCertificate, BundleIDs: []string{"io.bitrise.sample", "io.bitrise.sample.clip", "io.bitrise.sample.widget"}},
+			wantOK: true,
+		},
+		{
+			name:      "not a signing error",
+			exportLog: `error: exportArchive: The data couldn't be read because it isn't in the correct format.`,
+			wantOK:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := classifySigningError(tt.exportLog, bundleIDs)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_filterAppLayout(t *testing.T) {
+	appLayout := autocodesign.AppLayout{
+		Platform: autocodesign.IOS,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{
+			"io.bitrise.sample":        {"aps-environment": "production"},
+			"io.bitrise.sample.widget": {},
+		},
+	}
+
+	require.Equal(t, autocodesign.AppLayout{
+		Platform: autocodesign.IOS,
+		EntitlementsByArchivableTargetBundleID: map[string]autocodesign.Entitlements{
+			"io.bitrise.sample.widget": {},
+		},
+	}, filterAppLayout(appLayout, []string{"io.bitrise.sample.widget", "io.bitrise.other"}))
+}
@@ -0,0 +1,189 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-io/go-xcode/v2/exportoptionsgenerator"
+	"github.com/bitrise-io/go-xcode/xcodebuild"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+)
+
+const (
+	buildModeArchive   = "archive"
+	buildModeSimulator = "simulator"
+)
+
+// simulatorSDKs are the simulator SDK names of the platforms.
+var simulatorSDKs = map[Platform]string{
+	iOS:      "iphonesimulator",
+	tvOS:     "appletvsimulator",
+	watchOS:  "watchsimulator",
+	visionOS: "xrsimulator",
+}
+
+// simulatorDestination returns the xcodebuild generic destination specifier of the platform's simulator.
+func simulatorDestination(platform Platform) (string, error) {
+	if _, ok := simulatorSDKs[platform]; !ok {
+		return "", fmt.Errorf("%s platform has no simulator", platform)
+	}
+	return "generic/platform=" + string(platform) + " Simulator", nil
+}
+
+type simulatorBuildOpts struct {
+	ProjectPath         string
+	Scheme              string
+	Configuration       string
+	DestinationPlatform Platform
+	// Destination overrides the generic simulator destination of the platform
+	Destination string
+	DryRun      bool
+
+	PerformCleanAction bool
+	AdditionalOptions  []string
+	BuildSettings      []string
+	DerivedDataPath    string
+}
+
+type simulatorBuildResult struct {
+	AppPath       string
+	Configuration string
+	XcodebuildLog string
+}
+
+// buildForSimulator builds the scheme for the simulator without code signing, and returns the built .app path.
+func (s XcodebuildArchiver) buildForSimulator(opts simulatorBuildOpts) (simulatorBuildResult, error) {
+	out := simulatorBuildResult{}
+
+	s.logger.TInfof("Opening xcode project at path: %s for scheme: %s", opts.ProjectPath, opts.Scheme)
+
+	xcodeProj, scheme, configuration, err := OpenArchivableProject(opts.ProjectPath, opts.Scheme, opts.Configuration)
+	if err != nil {
+		return out, fmt.Errorf("failed to open project: %s: %s", opts.ProjectPath, err)
+	}
+	out.Configuration = configuration
+
+	platform := opts.DestinationPlatform
+	if platform == detectPlatform {
+		s.logger.TInfof("Platform is set to 'automatic', detecting platform from the project.")
+		if platform, err = BuildableTargetPlatform(xcodeProj, scheme, configuration, opts.AdditionalOptions, s.buildSettingsProvider, s.logger); err != nil {
+			return out, fmt.Errorf("failed to read project platform: %s: %s", opts.ProjectPath, err)
+		}
+	}
+	destination := opts.Destination
+	if destination == "" {
+		if destination, err = simulatorDestination(platform); err != nil {
+			return out, err
+		}
+	}
+
+	mainTarget, err := exportoptionsgenerator.ArchivableApplicationTarget(xcodeProj, scheme)
+	if err != nil {
+		return out, fmt.Errorf("failed to read main application target: %s", err)
+	}
+
+	actions := []string{"build"}
+	if opts.PerformCleanAction {
+		actions = []string{"clean", "build"}
+	}
+
+	buildCmd := xcodebuild.NewCommandBuilder(opts.ProjectPath, actions...)
+	buildCmd.SetScheme(opts.Scheme)
+	buildCmd.SetConfiguration(configuration)
+	buildCmd.SetDisableCodesign(true)
+
+	var customOptions []string
+	if !sliceutil.IsStringInSlice("-destination", opts.AdditionalOptions) {
+		customOptions = append(customOptions, "-destination", destination)
+	}
+	customOptions = append(customOptions, opts.AdditionalOptions...)
+	customOptions = append(customOptions, derivedDataOptions(opts.DerivedDataPath)...)
+	customOptions = append(customOptions, opts.BuildSettings...)
+	buildCmd.SetCustomOptions(customOptions)
+
+	if opts.DryRun {
+		s.logger.Printf("Planned xcodebuild command: %s", printableCommand("xcodebuild", buildCmd.CommandArgs(), s.sensitiveValues))
+		return out, nil
+	}
+
+	s.logger.Println()
+	s.logger.TInfof("Building the scheme for the simulator ...")
+
+	xcodebuildLog, err := runArchiveCommand(s.xcodeCommandRunner, s.logFormatter, nil, buildCmd, s.sensitiveValues, s.logger)
+	out.XcodebuildLog = xcodebuildLog
+	if err != nil {
+		return out, fmt.Errorf("failed to build the scheme for the simulator: %w", err)
+	}
+
+	appPath, err := s.simulatorAppPath(xcodeProj, mainTarget.Name, configuration, simulatorSDKs[platform], opts.DerivedDataPath)
+	if err != nil {
+		return out, fmt.Errorf("failed to find the built app: %w", err)
+	}
+	out.AppPath = appPath
+
+	return out, nil
+}
+
+// simulatorAppPath returns the path of the application target's product built for the simulator SDK.
+func (s XcodebuildArchiver) simulatorAppPath(xcodeProj *xcodeproj.XcodeProj, target, configuration, sdk, derivedDataPath string) (string, error) {
+	customOptions := append([]string{"-sdk", sdk}, derivedDataOptions(derivedDataPath)...)
+	settings, err := s.buildSettingsProvider.TargetBuildSettings(xcodeProj, target, configuration, customOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build settings: %s", err)
+	}
+
+	targetBuildDir, err := settings.String("TARGET_BUILD_DIR")
+	if err != nil {
+		return "", fmt.Errorf("failed to read TARGET_BUILD_DIR build setting: %s", err)
+	}
+	wrapperName, err := settings.String("WRAPPER_NAME")
+	if err != nil {
+		return "", fmt.Errorf("failed to read WRAPPER_NAME build setting: %s", err)
+	}
+
+	return filepath.Join(targetBuildDir, wrapperName), nil
+}
+
+func (s XcodebuildArchiver) runSimulatorBuild(opts RunOpts, out RunResult) (RunResult, error) {
+	endPhase := s.startPhase(phaseSimulatorBuild)
+	buildOut, err := s.buildForSimulator(simulatorBuildOpts{
+		ProjectPath:         opts.ProjectPath,
+		Scheme:              opts.Scheme,
+		Configuration:       opts.Configuration,
+		DestinationPlatform: opts.DestinationPlatform,
+		Destination:         opts.Destination,
+		DryRun:              opts.DryRun,
+
+		PerformCleanAction: opts.PerformCleanAction,
+		AdditionalOptions:  opts.XcodebuildAdditionalOptions,
+		BuildSettings:      opts.BuildSettingOverrides,
+		DerivedDataPath:    opts.DerivedDataPath,
+	})
+	endPhase(err)
+	// the build log is exported as the archive log
+	out.XcodebuildArchiveLog = buildOut.XcodebuildLog
+	out.Configuration = buildOut.Configuration
+	out.SimulatorAppPath = buildOut.AppPath
+
+	return out, err
+}
+
+// exportSimulatorApp exports the simulator .app path and its zip, and returns the zip path.
+func (s XcodebuildArchiver) exportSimulatorApp(appPath, outputDir, zipName string, outputPath func(string) (string, error)) (string, error) {
+	if err := ExportOutputDir(s.cmdFactory, appPath, appPath, bitriseSimulatorAppDirPthEnvKey, s.logger); err != nil {
+		return "", fmt.Errorf("failed to export %s, error: %s", bitriseSimulatorAppDirPthEnvKey, err)
+	}
+	s.logger.Donef("The simulator app path is now available in the Environment Variable: %s (value: %s)", bitriseSimulatorAppDirPthEnvKey, appPath)
+
+	zipPath, err := outputPath(filepath.Join(outputDir, zipName+".simulator.app.zip"))
+	if err != nil {
+		return "", err
+	}
+	if err := ExportOutputDirAsZip(s.cmdFactory, appPath, zipPath, bitriseSimulatorAppZipPthEnvKey, s.logger); err != nil {
+		return "", fmt.Errorf("failed to export %s, error: %s", bitriseSimulatorAppZipPthEnvKey, err)
+	}
+	s.logger.Donef("The simulator app zip path is now available in the Environment Variable: %s (value: %s)", bitriseSimulatorAppZipPthEnvKey, zipPath)
+
+	return zipPath, nil
+}
@@ -0,0 +1,37 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/xcodeproject/serialized"
+	"github.com/bitrise-io/go-xcode/xcodeproject/xcodeproj"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_simulatorDestination(t *testing.T) {
+	destination, err := simulatorDestination(iOS)
+	require.NoError(t, err)
+	require.Equal(t, "generic/platform=iOS Simulator", destination)
+
+	destination, err = simulatorDestination(visionOS)
+	require.NoError(t, err)
+	require.Equal(t, "generic/platform=visionOS Simulator", destination)
+
+	_, err = simulatorDestination(osX)
+	require.Error(t, err)
+}
+
+func TestXcodebuildArchiver_simulatorAppPath(t *testing.T) {
+	xcodeProj := &xcodeproj.XcodeProj{Path: "/project/Sample.xcodeproj"}
+	provider := &MockTargetBuildSettingsProvider{}
+	provider.On("TargetBuildSettings", xcodeProj, "Sample", "Debug").Return(serialized.Object{
+		"TARGET_BUILD_DIR": "/DerivedData/Build/Products/Debug-iphonesimulator",
+		"WRAPPER_NAME":     "Sample.app",
+	}, nil)
+
+	archiver := XcodebuildArchiver{buildSettingsProvider: provider}
+	appPath, err := archiver.simulatorAppPath(xcodeProj, "Sample", "Debug", simulatorSDKs[iOS], "")
+	require.NoError(t, err)
+	require.Equal(t, "/DerivedData/Build/Products/Debug-iphonesimulator/Sample.app", appPath)
+	provider.AssertExpectations(t)
+}
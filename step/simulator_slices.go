@@ -0,0 +1,124 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/sliceutil"
+)
+
+const (
+	simulatorSliceActionWarn  = "warn"
+	simulatorSliceActionFail  = "fail"
+	simulatorSliceActionStrip = "strip"
+)
+
+// frameworkSliceIssue is an embedded framework binary, which would be rejected by App Store Connect.
+type frameworkSliceIssue struct {
+	BinaryPath     string
+	SimulatorArchs []string
+	DeviceArchs    []string
+}
+
+// Strippable returns true if the simulator slices can be removed while keeping a device slice.
+func (i frameworkSliceIssue) Strippable() bool {
+	return len(i.DeviceArchs) > 0
+}
+
+func (i frameworkSliceIssue) String() string {
+	name := filepath.Base(filepath.Dir(i.BinaryPath))
+	if len(i.DeviceArchs) == 0 {
+		return fmt.Sprintf("%s has no device architecture (architectures: %s)", name, strings.Join(i.SimulatorArchs, ", "))
+	}
+	return fmt.Sprintf("%s contains simulator slices: %s", name, strings.Join(i.SimulatorArchs, ", "))
+}
+
+// findFrameworkSliceIssues returns the binaries containing simulator slices or missing a device slice.
+func findFrameworkSliceIssues(binaryPaths []string) ([]frameworkSliceIssue, error) {
+	var issues []frameworkSliceIssue
+	for _, pth := range binaryPaths {
+		slices, err := readBinarySlices(pth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Mach-O binary (%s): %s", pth, err)
+		}
+
+		issue := frameworkSliceIssue{BinaryPath: pth}
+		for _, slice := range slices {
+			if slice.IsSimulator() {
+				if !sliceutil.IsStringInSlice(slice.Arch, issue.SimulatorArchs) {
+					issue.SimulatorArchs = append(issue.SimulatorArchs, slice.Arch)
+				}
+			} else {
+				issue.DeviceArchs = append(issue.DeviceArchs, slice.Arch)
+			}
+		}
+
+		if len(issue.SimulatorArchs) > 0 || len(issue.DeviceArchs) == 0 {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func (s XcodebuildArchiver) stripSimulatorSlices(issue frameworkSliceIssue) error {
+	args := []string{issue.BinaryPath}
+	for _, arch := range issue.SimulatorArchs {
+		args = append(args, "-remove", arch)
+	}
+	args = append(args, "-output", issue.BinaryPath)
+
+	cmd := s.cmdFactory.Create("lipo", args, nil)
+	s.logger.Printf("$ %s", cmd.PrintableCommandArgs())
+	if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, out)
+	}
+	return nil
+}
+
+// checkSimulatorSlices scans the embedded frameworks of the archived application for simulator slices
+// and missing device architectures, as App Store Connect only reports them after the upload.
+func (s XcodebuildArchiver) checkSimulatorSlices(archive Archive, action string) error {
+	if platform := archive.Platform(); platform == "" || platform == "macosx" || strings.HasSuffix(platform, "simulator") {
+		s.logger.Debugf("Skipping the embedded framework architecture check for platform: %s", platform)
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Checking the architectures of the embedded frameworks")
+
+	binaries, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		return fmt.Errorf("failed to list embedded frameworks: %w", err)
+	}
+
+	issues, err := findFrameworkSliceIssues(binaries)
+	if err != nil {
+		s.logger.Warnf("Failed to check the embedded frameworks: %s", err)
+		return nil
+	}
+	if len(issues) == 0 {
+		s.logger.Donef("No simulator slices found in the embedded frameworks")
+		return nil
+	}
+
+	var failures []string
+	for _, issue := range issues {
+		switch {
+		case action == simulatorSliceActionStrip && issue.Strippable():
+			s.logger.Printf("Stripping simulator slices (%s) from %s", strings.Join(issue.SimulatorArchs, ", "), issue.BinaryPath)
+			if err := s.stripSimulatorSlices(issue); err != nil {
+				failures = append(failures, fmt.Sprintf("failed to strip %s: %s", filepath.Base(issue.BinaryPath), err))
+			}
+		case action == simulatorSliceActionWarn:
+			s.logger.Warnf("%s", issue)
+		default:
+			failures = append(failures, issue.String())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("embedded frameworks would be rejected by App Store Connect:\n%s", strings.Join(failures, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package step
+
+import (
+	"debug/macho"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findFrameworkSliceIssues(t *testing.T) {
+	dir := t.TempDir()
+	devicePth := filepath.Join(dir, "Device.framework", "Device")
+	simulatorPth := filepath.Join(dir, "Simulator.framework", "Simulator")
+	for pth, cpu := range map[string]macho.Cpu{devicePth: macho.CpuArm64, simulatorPth: macho.CpuAmd64} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0700))
+		require.NoError(t, os.WriteFile(pth, testMachO(cpu, 0, map[string]uint64{"__TEXT": 4096}), 0600))
+	}
+
+	issues, err := findFrameworkSliceIssues([]string{devicePth, simulatorPth})
+	require.NoError(t, err)
+	require.Equal(t, []frameworkSliceIssue{{BinaryPath: simulatorPth, SimulatorArchs: []string{"x86_64"}}}, issues)
+	require.False(t, issues[0].Strippable())
+	require.Equal(t, "Simulator.framework has no device architecture (architectures: x86_64)", issues[0].String())
+}
+
+func Test_binarySlice_IsSimulator(t *testing.T) {
+	require.True(t, binarySlice{Arch: "x86_64"}.IsSimulator())
+	require.True(t, binarySlice{Arch: "arm64", Platform: machoPlatformIOSSimulator}.IsSimulator())
+	require.False(t, binarySlice{Arch: "arm64", Platform: 2}.IsSimulator())
+	require.False(t, binarySlice{Arch: "arm64"}.IsSimulator())
+}
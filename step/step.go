@@ -7,15 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"howett.net/plist"
-
 	"github.com/bitrise-io/go-steputils/v2/stepconf"
 	v1command "github.com/bitrise-io/go-utils/command"
 	v1fileutil "github.com/bitrise-io/go-utils/fileutil"
 	logv1 "github.com/bitrise-io/go-utils/log"
 	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
 	"github.com/bitrise-io/go-utils/retry"
-	"github.com/bitrise-io/go-utils/sliceutil"
 	"github.com/bitrise-io/go-utils/v2/command"
 	"github.com/bitrise-io/go-utils/v2/fileutil"
 	"github.com/bitrise-io/go-utils/v2/log"
@@ -48,48 +45,142 @@ const (
 	bitriseDSYMPthEnvKey         = "BITRISE_DSYM_PATH"
 	bitriseIPAPthEnvKey          = "BITRISE_IPA_PATH"
 
+	bitriseOnDemandResourcesZipPthEnvKey = "BITRISE_ON_DEMAND_RESOURCES_ZIP_PATH"
+	bitriseDSYMUUIDsPthEnvKey            = "BITRISE_DSYM_UUIDS_PATH"
+
 	// Deployed logs
 	xcodebuildArchiveLogPathEnvKey       = "BITRISE_XCODEBUILD_ARCHIVE_LOG_PATH"
 	xcodebuildExportArchiveLogPathEnvKey = "BITRISE_XCODEBUILD_EXPORT_ARCHIVE_LOG_PATH"
 	bitriseIDEDistributionLogsPthEnvKey  = "BITRISE_IDEDISTRIBUTION_LOGS_PATH"
+	bitriseBuildLogsZipPthEnvKey         = "BITRISE_BUILD_LOGS_ZIP_PATH"
 	xcodebuildArchiveLogFilename         = "xcodebuild-archive.log"
 	xcodebuildExportArchiveLogFilename   = "xcodebuild-export-archive.log"
+	bitriseXcprettyHTMLReportPthEnvKey   = "BITRISE_XCPRETTY_HTML_REPORT_PATH"
+	bitriseXcprettyJUnitReportPthEnvKey  = "BITRISE_XCPRETTY_JUNIT_REPORT_PATH"
 
 	// Env Outputs
-	bitriseAppDirPthEnvKey    = "BITRISE_APP_DIR_PATH"
-	bitriseDSYMDirPthEnvKey   = "BITRISE_DSYM_DIR_PATH"
-	bitriseXCArchivePthEnvKey = "BITRISE_XCARCHIVE_PATH"
+	bitriseAppDirPthEnvKey      = "BITRISE_APP_DIR_PATH"
+	bitriseDSYMDirPthEnvKey     = "BITRISE_DSYM_DIR_PATH"
+	bitriseXCArchivePthEnvKey   = "BITRISE_XCARCHIVE_PATH"
+	bitriseAppIconPthEnvKey     = "BITRISE_APP_ICON_PATH"
+	bitriseDerivedDataPthEnvKey = "BITRISE_DERIVED_DATA_PATH"
+
+	// Simulator build mode outputs
+	bitriseSimulatorAppDirPthEnvKey = "BITRISE_SIMULATOR_APP_DIR_PATH"
+	bitriseSimulatorAppZipPthEnvKey = "BITRISE_SIMULATOR_APP_ZIP_PATH"
+
+	// Signed application bundles, extracted from the exported IPA
+	bitriseSignedAppZipPthEnvKey      = "BITRISE_SIGNED_APP_ZIP_PATH"
+	bitriseSignedWatchAppZipPthEnvKey = "BITRISE_SIGNED_WATCH_APP_ZIP_PATH"
+
+	// Reports
+	bitrisePrivacyReportPthEnvKey      = "BITRISE_PRIVACY_REPORT_PATH"
+	bitriseLocalizationReportPthEnvKey = "BITRISE_LOCALIZATION_REPORT_PATH"
+	bitriseExportComplianceEnvKey      = "BITRISE_EXPORT_COMPLIANCE"
+	bitriseSBOMPthEnvKey               = "BITRISE_SBOM_PATH"
+	bitriseIPASizeReportPthEnvKey      = "BITRISE_IPA_SIZE_REPORT_PATH"
+	bitriseChecksumsPthEnvKey          = "BITRISE_CHECKSUMS_PATH"
+	bitriseProvenancePthEnvKey         = "BITRISE_PROVENANCE_PATH"
+	bitriseExportOptionsPthEnvKey      = "BITRISE_EXPORT_OPTIONS_PATH"
+	bitriseProfileDumpPthEnvKey        = "BITRISE_PROFILE_DUMP_PATH"
+	bitriseProvisioningProfilesEnvKey  = "BITRISE_PROVISIONING_PROFILES"
+	bitriseSigningAssetBundlePthEnvKey = "BITRISE_SIGNING_ASSET_BUNDLE_PATH"
+
+	// Provisioned device outputs, for the ad-hoc and development exports
+	bitriseProvisionedDevicesEnvKey     = "BITRISE_PROVISIONED_DEVICES"
+	bitriseProvisionedDeviceCountEnvKey = "BITRISE_PROVISIONED_DEVICE_COUNT"
+
+	// Exported files, listing every artifact (for example the thinned .ipa variants)
+	bitriseExportedFilePathsEnvKey        = "BITRISE_EXPORTED_FILE_PATHS"
+	bitriseExportedFilesManifestPthEnvKey = "BITRISE_EXPORTED_FILES_MANIFEST_PATH"
+
+	// Checksum outputs
+	bitriseIPASHA256EnvKey          = "BITRISE_IPA_SHA256"
+	bitriseDSYMSHA256EnvKey         = "BITRISE_DSYM_SHA256"
+	bitriseXCArchiveZipSHA256EnvKey = "BITRISE_XCARCHIVE_ZIP_SHA256"
+
+	// Signature outputs
+	bitriseIPASignaturePthEnvKey        = "BITRISE_IPA_SIGNATURE_PATH"
+	bitriseDSYMSignaturePthEnvKey       = "BITRISE_DSYM_SIGNATURE_PATH"
+	bitriseProvenanceSignaturePthEnvKey = "BITRISE_PROVENANCE_SIGNATURE_PATH"
+
+	// Build issue outputs, read from the archive's result bundle
+	bitriseXcresultPthEnvKey                = "BITRISE_XCRESULT_PATH"
+	bitriseXcodebuildIssuesPthEnvKey        = "BITRISE_XCODEBUILD_ISSUES_PATH"
+	bitriseXcodebuildErrorCountEnvKey       = "BITRISE_XCODEBUILD_ERROR_COUNT"
+	bitriseXcodebuildWarningCountEnvKey     = "BITRISE_XCODEBUILD_WARNING_COUNT"
+	bitriseXcodebuildDeprecationCountEnvKey = "BITRISE_XCODEBUILD_DEPRECATION_COUNT"
+
+	// Archive metadata outputs
+	bitriseArchivePlatformEnvKey         = "BITRISE_ARCHIVE_PLATFORM"
+	bitriseArchiveMinimumOSVersionEnvKey = "BITRISE_ARCHIVE_MINIMUM_OS_VERSION"
+	bitriseArchiveSDKEnvKey              = "BITRISE_ARCHIVE_SDK"
+	bitriseArchiveXcodeBuildEnvKey       = "BITRISE_ARCHIVE_XCODE_BUILD"
+	bitriseArchiveSchemeNameEnvKey       = "BITRISE_ARCHIVE_SCHEME_NAME"
+	bitriseArchiveCreationDateEnvKey     = "BITRISE_ARCHIVE_CREATION_DATE"
+	bitriseArchiveSigningIdentityEnvKey  = "BITRISE_ARCHIVE_SIGNING_IDENTITY"
+	bitriseAppVersionEnvKey              = "BITRISE_APP_VERSION"
+	bitriseAppBuildNumberEnvKey          = "BITRISE_APP_BUILD_NUMBER"
+	bitriseTeamIDEnvKey                  = "BITRISE_TEAM_ID"
+
+	// Binary size outputs, suffixed with the architecture (for example ARM64)
+	bitriseAppExecutableSizeEnvKeyPrefix        = "BITRISE_APP_EXECUTABLE_SIZE_"
+	bitriseFrameworksExecutableSizeEnvKeyPrefix = "BITRISE_FRAMEWORKS_EXECUTABLE_SIZE_"
 
 	// Code Signing Authentication Source
 	codeSignSourceOff     = "off"
 	codeSignSourceAPIKey  = "api-key"
 	codeSignSourceAppleID = "apple-id"
+	codeSignSourceAuto    = "auto"
+	codeSignSourceDetect  = "detect"
 
 	// Output tools
 	XcbeautifyTool = "xcbeautify"
 	XcodebuildTool = "xcodebuild"
 	XcprettyTool   = "xcpretty"
+	// CustomFormatterTool pipes the xcodebuild output into the command set by the LogFormatterCommand input
+	CustomFormatterTool = "custom"
 )
 
 // Inputs ...
 type Inputs struct {
-	ProjectPath  string `env:"project_path,file"`
-	Scheme       string `env:"scheme,required"`
-	ExportMethod string `env:"distribution_method,opt[app-store,ad-hoc,enterprise,development]"`
-	Platform     string `env:"platform,opt[detect,iOS,watchOS,tvOS,visionOS]"`
+	ProjectPath       string `env:"project_path"`
+	Scheme            string `env:"scheme"`
+	AdditionalSchemes string `env:"additional_schemes"`
+	ExportMethod      string `env:"distribution_method,opt[app-store,ad-hoc,enterprise,development]"`
+	Platform          string `env:"platform,opt[detect,iOS,watchOS,tvOS,visionOS]"`
+	Destination       string `env:"destination"`
+	BuildMode         string `env:"build_mode,opt[archive,simulator]"`
 
 	// xcodebuild configuration
-	Configuration      string `env:"configuration"`
-	XcconfigContent    string `env:"xcconfig_content"`
-	PerformCleanAction bool   `env:"perform_clean_action,opt[yes,no]"`
-	XcodebuildOptions  string `env:"xcodebuild_options"`
+	XcodeVersion             string `env:"xcode_version"`
+	Configuration            string `env:"configuration"`
+	AdditionalConfigurations string `env:"additional_configurations"`
+	XcconfigContent          string `env:"xcconfig_content"`
+	PerformCleanAction       bool   `env:"perform_clean_action,opt[yes,no]"`
+	XcodebuildOptions        string `env:"xcodebuild_options"`
+	BuildSettings            string `env:"build_settings"`
+	DerivedDataPath          string `env:"derived_data_path"`
+	ArchivePath              string `env:"archive_path"`
+
+	// Build number
+	BuildNumberMode string `env:"build_number_mode,opt[none,set,increment]"`
+	BuildNumber     string `env:"build_number"`
+	BuildNumberTool string `env:"build_number_tool,opt[build_settings,agvtool]"`
+
+	// Export compliance
+	EncryptionUsage string `env:"uses_non_exempt_encryption,opt[detect,yes,no]"`
 
 	// xcodebuild log formatting
-	LogFormatter string `env:"log_formatter,opt[xcbeautify,xcodebuild,xcpretty]"`
+	LogFormatter        string `env:"log_formatter,opt[xcbeautify,xcodebuild,xcpretty,custom]"`
+	LogFormatterCommand string `env:"log_formatter_command"`
+	XcprettyReports     string `env:"xcpretty_reports"`
 
 	// Automatic code signing
-	CodeSigningAuthSource           string          `env:"automatic_code_signing,opt[off,api-key,apple-id]"`
+	CodeSigningAuthSource           string          `env:"automatic_code_signing,opt[off,api-key,apple-id,auto,detect]"`
 	RegisterTestDevices             bool            `env:"register_test_devices,opt[yes,no]"`
+	IgnoreDisabledTestDevices       bool            `env:"ignore_disabled_test_devices,opt[yes,no]"`
+	TestDeviceSyncDryRun            bool            `env:"test_device_sync_dry_run,opt[yes,no]"`
 	TestDeviceListPath              string          `env:"test_device_list_path"`
 	MinDaysProfileValid             int             `env:"min_profile_validity,required"`
 	CertificateURLList              string          `env:"certificate_url_list"`
@@ -97,35 +188,115 @@ type Inputs struct {
 	KeychainPath                    string          `env:"keychain_path"`
 	KeychainPassword                stepconf.Secret `env:"keychain_password"`
 	FallbackProvisioningProfileURLs string          `env:"fallback_provisioning_profile_url_list"`
+	DumpProfilesOnFailure           bool            `env:"dump_profiles_on_failure,opt[yes,no]"`
+	BuildIsolation                  bool            `env:"build_isolation,opt[yes,no]"`
+	SigningRepairRetry              bool            `env:"signing_repair_retry,opt[yes,no]"`
+	ReadOnlyAppStoreConnect         bool            `env:"read_only_app_store_connect,opt[yes,no]"`
+	ExportSigningAssetBundle        bool            `env:"export_signing_asset_bundle,opt[yes,no]"`
+	SigningAssetBundlePath          string          `env:"signing_asset_bundle_path"`
 
 	// IPA export configuration
 	ExportDevelopmentTeam         string `env:"export_development_team"`
 	CompileBitcode                bool   `env:"compile_bitcode,opt[yes,no]"`
 	UploadBitcode                 bool   `env:"upload_bitcode,opt[yes,no]"`
+	UploadSymbols                 bool   `env:"upload_symbols,opt[yes,no]"`
 	ICloudContainerEnvironment    string `env:"icloud_container_environment"`
 	TestFlightInternalTestingOnly bool   `env:"testflight_internal_testing_only,opt[yes,no]"`
+	EmbedODRAssetPacksInBundle    bool   `env:"embed_on_demand_resources_asset_packs_in_bundle,opt[yes,no]"`
+	ODRAssetPacksBaseURL          string `env:"on_demand_resources_asset_packs_base_url"`
 	ExportOptionsPlistContent     string `env:"export_options_plist_content"`
+	ArchivedApplication           string `env:"archived_application"`
+	CompareExportOptions          bool   `env:"compare_export_options,opt[yes,no]"`
+	CompareEntitlements           bool   `env:"compare_entitlements,opt[yes,no]"`
+	SimulatorSliceAction          string `env:"simulator_slice_action,opt[warn,fail,strip]"`
+	MixedTeamCheck                string `env:"mixed_team_check,opt[off,warn,fail]"`
+	CheckBinaryHygiene            bool   `env:"check_binary_hygiene,opt[yes,no]"`
+	StripFrameworkProfiles        bool   `env:"strip_framework_profiles,opt[yes,no]"`
+	SanitizeFrameworks            bool   `env:"sanitize_frameworks,opt[yes,no]"`
+	StripBitcode                  bool   `env:"strip_bitcode,opt[yes,no]"`
+	ManualIPAFallback             bool   `env:"manual_ipa_fallback,opt[yes,no]"`
+	ExportSignedApp               bool   `env:"export_signed_app,opt[yes,no]"`
+	AdditionalExportMethods       string `env:"additional_export_methods"`
+	ExportConcurrency             int    `env:"export_concurrency,range[1..8]"`
+	ExportOptionsMutators         string `env:"export_options_mutators"`
 
 	// Step Output Export configuration
-	OutputDir      string `env:"output_dir,required"`
-	ExportAllDsyms bool   `env:"export_all_dsyms,opt[yes,no]"`
-	ArtifactName   string `env:"artifact_name"`
+	OutputDir             string `env:"output_dir,required"`
+	OutputOverwritePolicy string `env:"output_overwrite_policy,opt[overwrite,fail,unique-suffix]"`
+	ExportAllDsyms        bool   `env:"export_all_dsyms,opt[yes,no]"`
+	ArtifactName          string `env:"artifact_name"`
+	ArtifactNameTemplate  string `env:"artifact_name_template"`
+	IPANameTemplate       string `env:"ipa_name_template"`
+	SBOMFormat            string `env:"sbom_format,opt[none,cyclonedx,spdx]"`
+	BuildSummary          bool   `env:"build_summary,opt[yes,no]"`
+	HTMLReportDir         string `env:"html_report_dir"`
+	ExportBuildLogs       bool   `env:"export_build_logs,opt[yes,no]"`
+
+	ExportXCArchiveZip    bool   `env:"export_xcarchive_zip,opt[yes,no]"`
+	CopyToOrganizer       bool   `env:"copy_archive_to_organizer,opt[yes,no]"`
+	XCArchiveZipExcludes  string `env:"xcarchive_zip_excludes"`
+	CompressionLevel      int    `env:"compression_level,range[0..9]"`
+	DSYMArchiveFormat     string `env:"dsym_archive_format,opt[zip,zstd]"`
+	DeterministicArchives bool   `env:"deterministic_archives,opt[yes,no]"`
+	Checksums             string `env:"checksums,opt[none,outputs,file]"`
+	Provenance            bool   `env:"provenance,opt[yes,no]"`
+
+	// dSYMs
+	DSYMOnlyArchivePath string `env:"dsym_only_archive_path"`
+	DSYMUploadCommand   string `env:"dsym_upload_command"`
+
+	// Artifact signing
+	ArtifactSigningMethod        string          `env:"artifact_signing_method,opt[none,gpg,ssh]"`
+	ArtifactSigningKey           stepconf.Secret `env:"artifact_signing_key"`
+	ArtifactSigningKeyPassphrase stepconf.Secret `env:"artifact_signing_key_passphrase"`
+
+	// IPA size report
+	SizeReportTopFilesCount int `env:"size_report_top_files_count,required"`
+	MaxIPASizeMB            int `env:"max_ipa_size_mb,required"`
+	MaxAppSizeMB            int `env:"max_app_size_mb,required"`
+
+	// Warning budget
+	MaxWarnings             int    `env:"max_warnings,required"`
+	FailOnNewDeprecations   bool   `env:"fail_on_new_deprecations,opt[yes,no]"`
+	DeprecationBaselinePath string `env:"deprecation_baseline_path"`
+	WarningBudgetAction     string `env:"warning_budget_action,opt[fail,annotate]"`
+
+	// Swift Package Manager
+	ResolvePackageDependencies   bool            `env:"resolve_package_dependencies,opt[yes,no]"`
+	PackageResolutionRetries     int             `env:"package_resolution_retries,required"`
+	PackageMirrors               string          `env:"package_mirrors"`
+	PackageRegistryURL           string          `env:"package_registry_url"`
+	PackageSCMProvider           string          `env:"package_scm_provider,opt[xcode,system]"`
+	PackageAuthorizationProvider string          `env:"package_authorization_provider,opt[default,netrc,keychain]"`
+	PackageHost                  string          `env:"package_host"`
+	PackageHostToken             stepconf.Secret `env:"package_host_token"`
+	SkipPackagePluginValidation  bool            `env:"skip_package_plugin_validation,opt[yes,no]"`
+	SkipMacroValidation          bool            `env:"skip_macro_validation,opt[yes,no]"`
 
 	// Caching
-	CacheLevel string `env:"cache_level,opt[none,swift_packages]"`
+	CacheLevel string `env:"cache_level,opt[none,swift_packages,derived_data]"`
 
 	// App Store Connect connection override
-	APIKeyPath              stepconf.Secret `env:"api_key_path"`
-	APIKeyID                string          `env:"api_key_id"`
-	APIKeyIssuerID          string          `env:"api_key_issuer_id"`
-	APIKeyEnterpriseAccount bool            `env:"api_key_enterprise_account,opt[yes,no]"`
+	APIKeyPath                 stepconf.Secret `env:"api_key_path"`
+	APIKeyID                   string          `env:"api_key_id"`
+	APIKeyIssuerID             string          `env:"api_key_issuer_id"`
+	APIKeyEnterpriseAccount    bool            `env:"api_key_enterprise_account,opt[yes,no]"`
+	AppleID                    string          `env:"apple_id"`
+	AppleIDPassword            stepconf.Secret `env:"apple_id_password"`
+	AppleIDAppSpecificPassword stepconf.Secret `env:"apple_id_app_specific_password"`
+	AppleIDSession             stepconf.Secret `env:"apple_id_session"`
 
 	// Debugging
-	VerboseLog bool `env:"verbose_log,opt[yes,no]"`
+	VerboseLog      bool   `env:"verbose_log,opt[yes,no]"`
+	StructuredLog   bool   `env:"structured_log,opt[yes,no]"`
+	DryRun          bool   `env:"dry_run,opt[yes,no]"`
+	CompareArchives string `env:"compare_archives"`
 
 	// Hidden inputs
 	BuildURL      string          `env:"BITRISE_BUILD_URL"`
 	BuildAPIToken stepconf.Secret `env:"BITRISE_BUILD_API_TOKEN"`
+	GitCommit     string          `env:"BITRISE_GIT_COMMIT"`
+	BuildSlug     string          `env:"BITRISE_BUILD_SLUG"`
 }
 
 // Config ...
@@ -134,7 +305,26 @@ type Config struct {
 	DestinationPlatform         Platform
 	XcodeMajorVersion           int
 	XcodebuildAdditionalOptions []string
-	CodesignManager             *codesign.Manager // nil if automatic code signing is "off"
+	LogFormatterCommandArgs     []string
+	XcprettyReportList          []string
+	BuildSettingOverrides       []string
+	PackageMirrorList           []PackageMirror
+	XCArchiveZipExcludeList     []string
+	AdditionalExportMethodList  []string
+	ExportOptionsMutatorList    []string
+	DSYMUploadCommandArgs       []string
+	// ArchiveComparePaths are the archives compared instead of building, empty if the comparison is disabled
+	ArchiveComparePaths []string
+	// SchemeMatrix lists the archived schemes and configurations, starting with the Scheme input
+	SchemeMatrix    []SchemeMatrixEntry
+	CodesignManager *codesign.Manager // nil if automatic code signing is "off"
+	SigningRepairer *SigningRepairer  // nil if automatic code signing is "off" or the signing repair is disabled
+	// TestDeviceSyncer registers the test devices before the code signing, nil if no test device is registered
+	TestDeviceSyncer *TestDeviceSyncer
+	// Isolation is the per-build keychain, nil if build isolation is disabled
+	Isolation *BuildIsolation
+	// codeSigningMode is the code signing mode of the detect automatic code signing method, empty for the other methods
+	codeSigningMode codeSigningMode
 }
 
 type XcodebuildArchiveConfigParser struct {
@@ -156,6 +346,15 @@ type XcodebuildArchiver struct {
 	fileManager        fileutil.FileManager
 	logger             log.Logger
 	cmdFactory         command.Factory
+
+	buildSettingsProvider TargetBuildSettingsProvider
+	tracer                *Tracer
+	// sensitiveValues are redacted from the logged commands
+	sensitiveValues []string
+	// exportOptionsMutators adjust the generated export options
+	exportOptionsMutators []ExportOptionsMutator
+	// xcodebuildStderr captures the stderr of the xcodebuild commands, nil if not set
+	xcodebuildStderr *XcodebuildStderrCapture
 }
 
 func NewXcodeArchiveConfigParser(stepInputParser stepconf.InputParser, xcodeVersionReader xcodeversion.Reader, fileManager fileutil.FileManager, cmdFactory command.Factory, logger log.Logger) XcodebuildArchiveConfigParser {
@@ -169,7 +368,7 @@ func NewXcodeArchiveConfigParser(stepInputParser stepconf.InputParser, xcodeVers
 }
 
 // NewXcodebuildArchiver ...
-func NewXcodebuildArchiver(xcodecommandRunner xcodecommand.Runner, logFormatter string, xcodeVersionReader xcodeversion.Reader, pathProvider pathutil.PathProvider, pathChecker pathutil.PathChecker, pathModifier pathutil.PathModifier, fileManager fileutil.FileManager, cmdFactory command.Factory, logger log.Logger) XcodebuildArchiver {
+func NewXcodebuildArchiver(xcodecommandRunner xcodecommand.Runner, logFormatter string, xcodeVersionReader xcodeversion.Reader, pathProvider pathutil.PathProvider, pathChecker pathutil.PathChecker, pathModifier pathutil.PathModifier, fileManager fileutil.FileManager, cmdFactory command.Factory, logger log.Logger, tracer *Tracer) XcodebuildArchiver {
 	return XcodebuildArchiver{
 		xcodeCommandRunner: xcodecommandRunner,
 		logFormatter:       logFormatter,
@@ -180,6 +379,9 @@ func NewXcodebuildArchiver(xcodecommandRunner xcodecommand.Runner, logFormatter
 		fileManager:        fileManager,
 		logger:             logger,
 		cmdFactory:         cmdFactory,
+
+		buildSettingsProvider: NewCachedTargetBuildSettingsProvider(XcodeBuild{}),
+		tracer:                tracer,
 	}
 }
 
@@ -187,7 +389,7 @@ func NewXcodebuildArchiver(xcodecommandRunner xcodecommand.Runner, logFormatter
 func (s XcodebuildArchiveConfigParser) ProcessInputs() (Config, error) {
 	var inputs Inputs
 	if err := s.stepInputParser.Parse(&inputs); err != nil {
-		return Config{}, fmt.Errorf("issue with input: %s", err)
+		return Config{}, stepconfParseIssues(err).err()
 	}
 
 	stepconf.Print(inputs)
@@ -200,33 +402,96 @@ func (s XcodebuildArchiveConfigParser) ProcessInputs() (Config, error) {
 		logv1.SetEnableDebugLog(true)
 	}
 
-	var err error
+	// every issue of the inputs is collected, to report them at once
+	var (
+		issues                   inputIssues
+		additionalSchemes        []SchemeMatrixEntry
+		additionalConfigurations []SchemeMatrixEntry
+		err                      error
+	)
 	if config.DestinationPlatform, err = parsePlatform(config.Platform); err != nil {
-		return Config{}, fmt.Errorf("issue with input Platform: %w", err)
+		issues.add("Platform", err)
 	}
-
-	config.XcodebuildAdditionalOptions, err = shellquote.Split(inputs.XcodebuildOptions)
-	if err != nil {
-		return Config{}, fmt.Errorf("provided XcodebuildOptions (%s) are not valid CLI parameters: %s", inputs.XcodebuildOptions, err)
+	if config.XcodebuildAdditionalOptions, err = shellquote.Split(inputs.XcodebuildOptions); err != nil {
+		issues.addf("provided XcodebuildOptions (%s) are not valid CLI parameters: %s", inputs.XcodebuildOptions, err)
+	}
+	if config.LogFormatterCommandArgs, err = shellquote.Split(inputs.LogFormatterCommand); err != nil {
+		issues.addf("provided LogFormatterCommand (%s) is not a valid command: %s", inputs.LogFormatterCommand, err)
+	}
+	if config.XcprettyReportList, err = parseXcprettyReports(config.XcprettyReports); err != nil {
+		issues.add("XcprettyReports", err)
 	}
-
 	if strings.TrimSpace(config.XcconfigContent) == "" {
 		config.XcconfigContent = ""
 	}
-	if sliceutil.IsStringInSlice("-xcconfig", config.XcodebuildAdditionalOptions) &&
-		config.XcconfigContent != "" {
-		return Config{}, fmt.Errorf("`-xcconfig` option found in XcodebuildOptions (`xcodebuild_options`), please clear Build settings (xcconfig) (`xcconfig_content`) input as only one can be set")
+	if config.BuildSettingOverrides, err = parseBuildSettings(config.BuildSettings); err != nil {
+		issues.add("BuildSettings", err)
+	}
+	if additionalSchemes, err = parseSchemeMatrix(config.AdditionalSchemes); err != nil {
+		issues.add("AdditionalSchemes", err)
+	}
+	if additionalConfigurations, err = parseConfigurationMatrix(config.AdditionalConfigurations); err != nil {
+		issues.add("AdditionalConfigurations", err)
+	}
+	if config.AdditionalExportMethodList, err = parseExportMethods(config.AdditionalExportMethods, config.ExportMethod); err != nil {
+		issues.add("AdditionalExportMethods", err)
+	}
+	if config.ExportOptionsMutatorList, err = parseExportOptionsMutators(config.ExportOptionsMutators); err != nil {
+		issues.add("ExportOptionsMutators", err)
+	}
+	if config.XCArchiveZipExcludeList, err = parseArchiveExcludes(config.XCArchiveZipExcludes); err != nil {
+		issues.add("XCArchiveZipExcludes", err)
+	}
+	if config.PackageMirrorList, err = parsePackageMirrors(config.PackageMirrors); err != nil {
+		issues.add("PackageMirrors", err)
+	}
+	if config.ArchiveComparePaths, err = parseArchiveComparePaths(config.CompareArchives); err != nil {
+		issues.add("CompareArchives", err)
+	}
+	if config.DSYMOnlyArchivePath, err = parseDSYMOnlyArchivePath(config.DSYMOnlyArchivePath); err != nil {
+		issues.add("DSYMOnlyArchivePath", err)
+	}
+	if config.DSYMUploadCommandArgs, err = shellquote.Split(config.DSYMUploadCommand); err != nil {
+		issues.add("DSYMUploadCommand", err)
+	}
+	if config.ODRAssetPacksBaseURL, err = parseOnDemandResourcesBaseURL(config.ODRAssetPacksBaseURL); err != nil {
+		issues.add("ODRAssetPacksBaseURL", err)
+	}
+
+	issues = append(issues, validateInputRules(config, inputRules)...)
+	if err := issues.err(); err != nil {
+		return Config{}, err
 	}
 
-	if config.ExportOptionsPlistContent != "" {
-		var options map[string]interface{}
-		if _, err := plist.Unmarshal([]byte(config.ExportOptionsPlistContent), &options); err != nil {
-			return Config{}, fmt.Errorf("issue with input ExportOptionsPlistContent: %s", err)
+	if len(config.ArchiveComparePaths) > 0 {
+		// the project is not built in the archive comparison mode
+		return config, nil
+	}
+
+	if config.DSYMOnlyArchivePath != "" {
+		// the project is not built in the dSYM only mode, only the dSYMs of the archive are exported
+		if config.OutputDir, err = v1pathutil.AbsPath(config.OutputDir); err != nil {
+			return Config{}, fmt.Errorf("failed to expand OutputDir (%s), error: %s", config.OutputDir, err)
+		}
+		if err := os.MkdirAll(config.OutputDir, 0777); err != nil {
+			return Config{}, fmt.Errorf("failed to create OutputDir (%s), error: %s", config.OutputDir, err)
 		}
+		return config, nil
+	}
+
+	if config.ProjectPath, err = discoverProjectPath(config.ProjectPath, s.logger); err != nil {
+		return Config{}, fmt.Errorf("issue with input ProjectPath: %w", err)
 	}
 
-	if filepath.Ext(config.ProjectPath) != ".xcodeproj" && filepath.Ext(config.ProjectPath) != ".xcworkspace" {
-		return Config{}, fmt.Errorf("issue with input ProjectPath: should be and .xcodeproj or .xcworkspace path")
+	if config.XcodeVersion != "" {
+		developerDir, err := resolveDeveloperDir(config.XcodeVersion, xcodeApplicationsDir)
+		if err != nil {
+			return Config{}, fmt.Errorf("issue with input XcodeVersion: %w", err)
+		}
+		if err := os.Setenv(developerDirEnvKey, developerDir); err != nil {
+			return Config{}, fmt.Errorf("failed to set %s, error: %s", developerDirEnvKey, err)
+		}
+		s.logger.Printf("Selected Xcode developer directory: %s", developerDir)
 	}
 
 	s.logger.Infof("Xcode version:")
@@ -255,8 +520,9 @@ func (s XcodebuildArchiveConfigParser) ProcessInputs() (Config, error) {
 		s.logger.Println()
 		s.logger.Warnf("Ignoring the following options because ExportOptionsPlistContent provided:")
 		s.logger.Printf("- DistributionMethod: %s", config.ExportMethod)
-		s.logger.Printf("- UploadBitcode: %s", config.UploadBitcode)
-		s.logger.Printf("- CompileBitcode: %s", config.CompileBitcode)
+		s.logger.Printf("- UploadBitcode: %t", config.UploadBitcode)
+		s.logger.Printf("- UploadSymbols: %t", config.UploadSymbols)
+		s.logger.Printf("- CompileBitcode: %t", config.CompileBitcode)
 		s.logger.Printf("- ExportDevelopmentTeam: %s", config.ExportDevelopmentTeam)
 		s.logger.Printf("- ICloudContainerEnvironment: %s", config.ICloudContainerEnvironment)
 		s.logger.Println()
@@ -269,12 +535,39 @@ func (s XcodebuildArchiveConfigParser) ProcessInputs() (Config, error) {
 		s.logger.Println()
 	}
 
+	if exportOptionsPlistContent == "" {
+		exportMethods := append([]string{config.ExportMethod}, config.AdditionalExportMethodList...)
+		if warnings := exportOptionInputWarnings(exportMethods, config.UploadBitcode, config.UploadSymbols, config.CompileBitcode); len(warnings) > 0 {
+			s.logger.Println()
+			for _, warning := range warnings {
+				s.logger.Warnf(warning)
+			}
+			s.logger.Println()
+		}
+	}
+
 	absProjectPath, err := filepath.Abs(config.ProjectPath)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to get absolute project path, error: %s", err)
 	}
 	config.ProjectPath = absProjectPath
 
+	if config.Scheme, err = resolveScheme(config.ProjectPath, config.Scheme, s.logger); err != nil {
+		return Config{}, fmt.Errorf("issue with input Scheme: %w", err)
+	}
+
+	if config.DerivedDataPath != "" {
+		if config.DerivedDataPath, err = v1pathutil.AbsPath(config.DerivedDataPath); err != nil {
+			return Config{}, fmt.Errorf("failed to expand DerivedDataPath (%s), error: %s", config.DerivedDataPath, err)
+		}
+	}
+
+	if config.ArchivePath != "" {
+		if config.ArchivePath, err = v1pathutil.AbsPath(config.ArchivePath); err != nil {
+			return Config{}, fmt.Errorf("failed to expand ArchivePath (%s), error: %s", config.ArchivePath, err)
+		}
+	}
+
 	// abs out dir pth
 	absOutputDir, err := v1pathutil.AbsPath(config.OutputDir)
 	if err != nil {
@@ -290,12 +583,80 @@ func (s XcodebuildArchiveConfigParser) ProcessInputs() (Config, error) {
 		}
 	}
 
-	if config.CodeSigningAuthSource != codeSignSourceOff {
-		codesignManager, err := s.createCodesignManager(config)
+	primary := SchemeMatrixEntry{Scheme: config.Scheme, Configuration: config.Configuration, ExportMethod: config.ExportMethod}
+	if config.SchemeMatrix, err = newSchemeMatrix(primary, additionalConfigurations, additionalSchemes); err != nil {
+		return Config{}, fmt.Errorf("issue with input AdditionalConfigurations or AdditionalSchemes: %w", err)
+	}
+
+	if config.SigningAssetBundlePath != "" && config.BuildMode != buildModeSimulator {
+		if err := s.installSigningAssetBundle(config.SigningAssetBundlePath, config.KeychainPath); err != nil {
+			return Config{}, fmt.Errorf("failed to install the signing asset bundle: %w", err)
+		}
+		if config.CodeSigningAuthSource != codeSignSourceOff {
+			s.logger.Printf("The signing asset bundle provides the code signing assets, skipping automatic code signing")
+			config.CodeSigningAuthSource = codeSignSourceOff
+		}
+	}
+
+	if config.BuildMode == buildModeSimulator && config.CodeSigningAuthSource != codeSignSourceOff {
+		s.logger.Warnf("Simulator builds are not code signed, skipping automatic code signing")
+	} else if config.CodeSigningAuthSource != codeSignSourceOff {
+		serviceConnection, err := s.fetchBitriseConnection(config)
 		if err != nil {
 			return Config{}, fmt.Errorf("failed to prepare automatic code signing: %w", err)
 		}
-		config.CodesignManager = &codesignManager
+		if config.CodeSigningAuthSource, config.codeSigningMode, err = s.resolveCodeSigningMode(config, serviceConnection); err != nil {
+			return Config{}, fmt.Errorf("issue with input CodeSigningAuthSource: %w", err)
+		}
+		if config.CodeSigningAuthSource == codeSignSourceOff {
+			// manual signing with the installed code signing assets
+			return config, nil
+		}
+
+		if config.BuildIsolation {
+			if config.Isolation, err = s.createBuildIsolation(&config); err != nil {
+				return Config{}, fmt.Errorf("failed to prepare build isolation: %w", err)
+			}
+		}
+		if config.CodeSigningAuthSource, err = resolveCodeSigningAuthSource(config.CodeSigningAuthSource, serviceConnection, config.Inputs, s.logger); err != nil {
+			return Config{}, fmt.Errorf("issue with input CodeSigningAuthSource: %w", err)
+		}
+		if config.ReadOnlyAppStoreConnect {
+			s.logger.Printf("Read-only App Store Connect mode: only the installed and fallback provisioning profiles are used, nothing is changed on the Apple Developer Portal")
+		}
+		if config.TestDeviceSyncer, err = s.createTestDeviceSyncer(config, serviceConnection); err != nil {
+			return Config{}, fmt.Errorf("failed to prepare test device registration: %w", err)
+		}
+
+		// the profiles depend on the bundle IDs of the scheme's targets and on the distribution method,
+		// so every entry of the matrix gets its own manager
+		for i, entry := range config.SchemeMatrix {
+			entryConfig := config
+			entryConfig.Scheme = entry.Scheme
+			entryConfig.Configuration = entry.Configuration
+			entryConfig.ExportMethod = entry.ExportMethod
+
+			codesignManager, signingRepairer, err := s.createCodesignManager(entryConfig, serviceConnection)
+			if err != nil {
+				return Config{}, fmt.Errorf("failed to prepare automatic code signing: %w", err)
+			}
+			config.SchemeMatrix[i].CodesignManager = &codesignManager
+			for _, method := range config.AdditionalExportMethodList {
+				methodConfig := entryConfig
+				methodConfig.ExportMethod = method
+				methodCodesignManager, _, err := s.createCodesignManager(methodConfig, serviceConnection)
+				if err != nil {
+					return Config{}, fmt.Errorf("failed to prepare automatic code signing (%s): %w", method, err)
+				}
+				config.SchemeMatrix[i].AdditionalCodesignManagers = append(config.SchemeMatrix[i].AdditionalCodesignManagers, &methodCodesignManager)
+			}
+			// the signing repair regenerates the profiles on the Apple Developer Portal
+			if config.SigningRepairRetry && !config.ReadOnlyAppStoreConnect {
+				config.SchemeMatrix[i].SigningRepairer = signingRepairer
+			}
+		}
+		config.CodesignManager = config.SchemeMatrix[0].CodesignManager
+		config.SigningRepairer = config.SchemeMatrix[0].SigningRepairer
 	}
 
 	return config, nil
@@ -309,8 +670,12 @@ func (s *XcodebuildArchiver) EnsureDependencies() {
 		s.logger.Errorf("Selected log formatter is unavailable: %s", err)
 		s.logger.Infof("Switching back to xcodebuild log formatter.")
 
+		var cmdFactory command.Factory = s.cmdFactory
+		if s.xcodebuildStderr != nil {
+			cmdFactory = s.xcodebuildStderr
+		}
 		s.logFormatter = XcodebuildTool
-		s.xcodeCommandRunner = xcodecommand.NewRawCommandRunner(s.logger, s.cmdFactory)
+		s.xcodeCommandRunner = xcodecommand.NewRawCommandRunner(s.logger, cmdFactory)
 		return
 	}
 
@@ -325,18 +690,54 @@ type RunOpts struct {
 	ProjectPath         string
 	Scheme              string
 	DestinationPlatform Platform
+	Destination         string
 	Configuration       string
 	XcodeMajorVersion   int
 	ArtifactName        string
+	// ArtifactNameSuffix is appended to the artifact name, to distinguish the artifacts of the scheme matrix entries
+	ArtifactNameSuffix string
+	BuildMode          string
+	DryRun             bool
+	SensitiveValues    []string
 
 	// Code signing, nil if automatic code signing is "off"
 	CodesignManager *codesign.Manager
+	// SigningRepairer repairs the code signing assets after a signing failure of the export, nil if disabled
+	SigningRepairer *SigningRepairer
+	// AdditionalCodesignManagers prepare the code signing assets of the additional distribution methods before their export
+	AdditionalCodesignManagers []*codesign.Manager
+	// KeychainPath is unlocked before archiving, both for automatic and manual code signing
+	KeychainPath     string
+	KeychainPassword string
+	// IsolatedKeychain adds the build keychain (KeychainPath) to the keychain search list after installing the certificates
+	IsolatedKeychain bool
 
 	// Archive
+	// ArchivedApplication selects the exported application (name or bundle ID) of archives with multiple applications
+	ArchivedApplication         string
 	PerformCleanAction          bool
 	XcconfigContent             string
 	XcodebuildAdditionalOptions []string
+	BuildSettingOverrides       []string
+	DerivedDataPath             string
+	ArchivePath                 string
 	CacheLevel                  string
+	ExportBuildLogs             bool
+	XcprettyReports             []string
+	BuildNumberMode             string
+	BuildNumber                 string
+	BuildNumberTool             string
+	EncryptionUsage             string
+	ResolvePackageDependencies  bool
+	PackageResolutionRetries    int
+	PackageMirrors              []PackageMirror
+	PackageRegistryURL          string
+	PackageSCMProvider          string
+	PackageAuthProvider         string
+	PackageHost                 string
+	PackageHostToken            string
+	SkipPackagePluginValidation bool
+	SkipMacroValidation         bool
 
 	// IPA Export
 	CustomExportOptionsPlistContent string
@@ -345,20 +746,42 @@ type RunOpts struct {
 	ICloudContainerEnvironment      string
 	ExportDevelopmentTeam           string
 	UploadBitcode                   bool
+	UploadSymbols                   bool
 	CompileBitcode                  bool
+	OnDemandResources               OnDemandResourcesOpts
+	SimulatorSliceAction            string
+	MixedTeamCheck                  string
+	CheckBinaryHygiene              bool
+	StripFrameworkProfiles          bool
+	SanitizeFrameworks              bool
+	StripBitcode                    bool
+	ManualIPAFallback               bool
+	AdditionalExportMethods         []string
+	ExportConcurrency               int
 }
 
 // RunResult ...
 type RunResult struct {
 	Archive      *xcarchive.IosArchive
 	ArtifactName string
+	// Configuration is the build configuration used for archiving
+	Configuration string
+	// SimulatorAppPath is the .app built in simulator build mode
+	SimulatorAppPath string
+
+	ResultBundlePath string
+	BuildIssues      *BuildIssues
 
 	ExportOptionsPath string
 	IPAExportDir      string
+	// AdditionalIPAExports are the IPAs of the additional distribution methods
+	AdditionalIPAExports []AdditionalIPAExport
 
 	XcodebuildArchiveLog       string
 	XcodebuildExportArchiveLog string
 	IDEDistrubutionLogsDir     string
+	BuildLogsDir               string
+	XcprettyReports            []xcprettyReport
 }
 
 // Run ...
@@ -368,6 +791,8 @@ func (s XcodebuildArchiver) Run(opts RunOpts) (RunResult, error) {
 		authOptions *xcodebuild.AuthenticationParams
 	)
 
+	s.sensitiveValues = opts.SensitiveValues
+
 	s.logger.Println()
 
 
@@ -390,15 +815,39 @@ func (s XcodebuildArchiver) Run(opts RunOpts) (RunResult, error) {
 
 		opts.ArtifactName = productName
 	}
+	opts.ArtifactName = fileNameSafe(opts.ArtifactName + opts.ArtifactNameSuffix)
 	out.ArtifactName = opts.ArtifactName
 
+	if opts.BuildMode == buildModeSimulator {
+		return s.runSimulatorBuild(opts, out)
+	}
+
+	if !opts.DryRun {
+		// a locked keychain fails code signing with "User interaction is not allowed"
+		if err := s.prepareKeychain(opts.KeychainPath, opts.KeychainPassword); err != nil {
+			s.logger.Warnf("Failed to prepare keychain: %s", err)
+		}
+	}
+
 	if opts.CodesignManager != nil {
 		s.logger.Infof("Preparing code signing assets (certificates, profiles) before Archive action")
 
+		unlockProfiles, err := s.lockProfiles()
+		if err != nil {
+			return RunResult{}, fmt.Errorf("failed to lock provisioning profiles: %w", err)
+		}
+		endPhase := s.startPhase(phaseCodeSigning)
 		xcodebuildAuthParams, err := opts.CodesignManager.PrepareCodesigning()
+		endPhase(err)
+		unlockProfiles()
 		if err != nil {
 			return RunResult{}, fmt.Errorf("failed to manage code signing: %s", err)
 		}
+		if opts.IsolatedKeychain {
+			if err := s.addKeychainToSearchList(opts.KeychainPath); err != nil {
+				return RunResult{}, fmt.Errorf("failed to add build keychain to the keychain search list: %w", err)
+			}
+		}
 
 		if xcodebuildAuthParams != nil {
 			privateKey, err := xcodebuildAuthParams.WritePrivateKeyToFile()
@@ -427,24 +876,121 @@ func (s XcodebuildArchiver) Run(opts RunOpts) (RunResult, error) {
 		ProjectPath:         opts.ProjectPath,
 		Scheme:              opts.Scheme,
 		DestinationPlatform: opts.DestinationPlatform,
+		Destination:         opts.Destination,
 		Configuration:       opts.Configuration,
 		XcodeMajorVersion:   opts.XcodeMajorVersion,
 		ArtifactName:        opts.ArtifactName,
 		XcodeAuthOptions:    authOptions,
+		DryRun:              opts.DryRun,
+		ArchivedApplication: opts.ArchivedApplication,
 
 		PerformCleanAction: opts.PerformCleanAction,
 		XcconfigContent:    opts.XcconfigContent,
 		AdditionalOptions:  opts.XcodebuildAdditionalOptions,
+		BuildSettings:      opts.BuildSettingOverrides,
+		DerivedDataPath:    opts.DerivedDataPath,
+		ArchivePath:        opts.ArchivePath,
 		CacheLevel:         opts.CacheLevel,
+		ExportBuildLogs:    opts.ExportBuildLogs,
+		XcprettyReports:    opts.XcprettyReports,
+		BuildNumberMode:    opts.BuildNumberMode,
+		BuildNumber:        opts.BuildNumber,
+		BuildNumberTool:    opts.BuildNumberTool,
+		EncryptionUsage:    opts.EncryptionUsage,
+
+		ResolvePackageDependencies: opts.ResolvePackageDependencies,
+		PackageResolutionRetries:   opts.PackageResolutionRetries,
+		PackageMirrors:             opts.PackageMirrors,
+		PackageRegistryURL:         opts.PackageRegistryURL,
+		PackageSCMProvider:         opts.PackageSCMProvider,
+		PackageAuthProvider:        opts.PackageAuthProvider,
+		PackageHost:                opts.PackageHost,
+		PackageHostToken:           opts.PackageHostToken,
+
+		SkipPackagePluginValidation: opts.SkipPackagePluginValidation,
+		SkipMacroValidation:         opts.SkipMacroValidation,
 	}
+	endPhase := s.startPhase(phaseArchive)
 	archiveOut, err := s.xcodeArchive(archiveOpts)
+	endPhase(err)
 	out.XcodebuildArchiveLog = archiveOut.XcodebuildArchiveLog
+	out.ResultBundlePath = archiveOut.ResultBundlePath
+	out.BuildIssues = archiveOut.BuildIssues
+	out.BuildLogsDir = archiveOut.BuildLogsDir
+	out.XcprettyReports = archiveOut.XcprettyReports
+	out.Configuration = archiveOut.Configuration
 	if err != nil {
 		return out, err
 	}
 
+	if opts.DryRun {
+		return out, s.printPlannedIPAExport(xcodeIPAExportOpts{
+			XcodeMajorVersion: opts.XcodeMajorVersion,
+			XcodeAuthOptions:  authOptions,
+
+			CustomExportOptionsPlistContent: opts.CustomExportOptionsPlistContent,
+			ExportMethod:                    opts.ExportMethod,
+			TestFlightInternalTestingOnly:   opts.TestFlightInternalTestingOnly,
+			ICloudContainerEnvironment:      opts.ICloudContainerEnvironment,
+			ExportDevelopmentTeam:           opts.ExportDevelopmentTeam,
+			UploadBitcode:                   opts.UploadBitcode,
+			UploadSymbols:                   opts.UploadSymbols,
+			CompileBitcode:                  opts.CompileBitcode,
+			OnDemandResources:               opts.OnDemandResources,
+		}, archiveOut.ArchivePath, archiveOut.ProjectArchiveInfo)
+	}
+
 	out.Archive = archiveOut.Archive
 
+	if err := s.checkSimulatorSlices(NewArchive(*archiveOut.Archive), opts.SimulatorSliceAction); err != nil {
+		return out, err
+	}
+
+	if err := s.checkMixedTeams(NewArchive(*archiveOut.Archive), opts.MixedTeamCheck); err != nil {
+		return out, err
+	}
+
+	if err := s.checkAppClip(NewArchive(*archiveOut.Archive)); err != nil {
+		return out, err
+	}
+
+	if err := s.checkWatchApp(NewArchive(*archiveOut.Archive)); err != nil {
+		return out, err
+	}
+
+	if err := s.checkAppStoreAssets(NewArchive(*archiveOut.Archive), append([]string{opts.ExportMethod}, opts.AdditionalExportMethods...)...); err != nil {
+		return out, err
+	}
+
+	s.checkFrameworkMinOSVersions(NewArchive(*archiveOut.Archive))
+	s.checkDeviceCapabilities(NewArchive(*archiveOut.Archive))
+
+	if opts.CheckBinaryHygiene {
+		s.checkMachOHygiene(NewArchive(*archiveOut.Archive))
+	}
+
+	if err := s.cleanupFrameworkProfiles(NewArchive(*archiveOut.Archive), opts.StripFrameworkProfiles, append([]string{opts.ExportMethod}, opts.AdditionalExportMethods...)...); err != nil {
+		return out, err
+	}
+
+	if opts.SanitizeFrameworks {
+		if err := s.sanitizeFrameworks(NewArchive(*archiveOut.Archive)); err != nil {
+			return out, fmt.Errorf("failed to sanitize embedded frameworks: %w", err)
+		}
+	}
+
+	if opts.StripBitcode {
+		stripped, err := s.stripBitcode(NewArchive(*archiveOut.Archive))
+		if err != nil {
+			return out, fmt.Errorf("failed to strip bitcode: %w", err)
+		}
+		if stripped && (opts.UploadBitcode || opts.CompileBitcode) {
+			s.logger.Printf("Disabling uploadBitcode and compileBitcode in the export options, as the archive has no bitcode")
+			opts.UploadBitcode = false
+			opts.CompileBitcode = false
+		}
+	}
+
 	IPAExportOpts := xcodeIPAExportOpts{
 		XcodeMajorVersion: opts.XcodeMajorVersion,
 		XcodeAuthOptions:  authOptions,
@@ -456,51 +1002,168 @@ func (s XcodebuildArchiver) Run(opts RunOpts) (RunResult, error) {
 		ICloudContainerEnvironment:      opts.ICloudContainerEnvironment,
 		ExportDevelopmentTeam:           opts.ExportDevelopmentTeam,
 		UploadBitcode:                   opts.UploadBitcode,
+		UploadSymbols:                   opts.UploadSymbols,
 		CompileBitcode:                  opts.CompileBitcode,
+		OnDemandResources:               opts.OnDemandResources,
+		ManualIPAFallback:               opts.ManualIPAFallback,
 	}
+	if err := unsetRubyEnvironment(); err != nil {
+		return out, err
+	}
+	if _, err := prepareXCLoggingBaseDir(); err != nil {
+		s.logger.Warnf("Failed to create IDEDistribution logs directory: %s", err)
+	}
+
+	endPhase = s.startPhase(phaseExport)
 	exportOut, err := s.xcodeIPAExport(IPAExportOpts)
+	if err != nil && s.repairSigning(opts.SigningRepairer, *archiveOut.Archive, exportOut.XcodebuildExportArchiveLog) {
+		s.logger.Println()
+		s.logger.Infof("Retrying the export with the repaired code signing assets...")
+
+		failedExportLog := exportOut.XcodebuildExportArchiveLog
+		exportOut, err = s.xcodeIPAExport(IPAExportOpts)
+		exportOut.XcodebuildExportArchiveLog = failedExportLog + "\n" + exportOut.XcodebuildExportArchiveLog
+	}
+	endPhase(err)
 	out.XcodebuildExportArchiveLog = exportOut.XcodebuildExportArchiveLog
+	// set even if the manual IPA packaging fallback succeeded, to help finding the reason of the export failure
+	out.IDEDistrubutionLogsDir = exportOut.IDEDistrubutionLogsDir
 	if err != nil {
-		out.IDEDistrubutionLogsDir = exportOut.IDEDistrubutionLogsDir
 		return out, err
 	}
 
 	out.ExportOptionsPath = exportOut.ExportOptionsPath
 	out.IPAExportDir = exportOut.IPAExportDir
 
+	if len(opts.AdditionalExportMethods) > 0 {
+		if err := s.prepareAdditionalCodesigning(opts.AdditionalCodesignManagers); err != nil {
+			return out, err
+		}
+
+		s.logger.Println()
+		s.logger.Infof("Exporting the archive with additional distribution methods (%s), %d at a time...", strings.Join(opts.AdditionalExportMethods, ", "), opts.ExportConcurrency)
+
+		endPhase = s.startPhase(phaseExport)
+		additionalExports, err := s.exportAdditionalMethods(IPAExportOpts, opts.AdditionalExportMethods, opts.ExportConcurrency)
+		endPhase(err)
+		out.AdditionalIPAExports = additionalExports
+		out.XcodebuildExportArchiveLog += additionalExportsLog(additionalExports)
+		if err != nil {
+			return out, fmt.Errorf("failed to export IPA with additional distribution methods: %w", err)
+		}
+	}
+
 	return out, nil
 }
 
 // ExportOpts ...
 type ExportOpts struct {
-	OutputDir      string
-	ArtifactName   string
-	ExportAllDsyms bool
-
-	Archive *xcarchive.IosArchive
-
-	ExportOptionsPath string
-	IPAExportDir      string
+	OutputDir             string
+	OutputOverwritePolicy string
+	ProjectPath           string
+	Scheme                string
+	Configuration         string
+	GitCommit             string
+	ArtifactName          string
+	ArtifactNameTemplate  string
+	IPANameTemplate       string
+	SimulatorAppPath      string
+	ExportSignedApp       bool
+	ExportAllDsyms        bool
+	SBOMFormat            string
+	DerivedDataPath       string
+	BuildSummary          bool
+	HTMLReportDir         string
+	// MinProfileValidityDays is the minimum remaining validity of the provisioning profiles, reported in the summary
+	MinProfileValidityDays int
+	// ExportSigningAssetBundle packages the used provisioning profiles for the later builds
+	ExportSigningAssetBundle bool
+
+	ExportXCArchiveZip    bool
+	CopyToOrganizer       bool
+	XCArchiveZipExcludes  []string
+	CompressionLevel      int
+	DSYMArchiveFormat     string
+	DSYMUploadCommandArgs []string
+	DeterministicArchives bool
+	Checksums             string
+	// Provenance is nil if the provenance statement generation is disabled
+	Provenance *ProvenanceGenerator
+	// ExportedFiles collects the artifacts of every scheme in a multi-scheme run, nil otherwise
+	ExportedFiles *ExportedFiles
+
+	ArtifactSigningMethod        string
+	ArtifactSigningKey           string
+	ArtifactSigningKeyPassphrase string
+
+	SizeReportTopFilesCount int
+	MaxIPASizeMB            int
+	MaxAppSizeMB            int
+
+	MaxWarnings             int
+	FailOnNewDeprecations   bool
+	DeprecationBaselinePath string
+	WarningBudgetAction     string
+
+	Archive             *xcarchive.IosArchive
+	CompareEntitlements bool
+
+	ResultBundlePath string
+	BuildIssues      *BuildIssues
+
+	ExportOptionsPath    string
+	CompareExportOptions bool
+	IPAExportDir         string
+	AdditionalIPAExports []AdditionalIPAExport
 
 	XcodebuildArchiveLog       string
 	XcodebuildExportArchiveLog string
 	IDEDistrubutionLogsDir     string
+	BuildLogsDir               string
+	XcprettyReports            []xcprettyReport
 }
 
 // ExportOutput ...
-func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
+func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) (err error) {
+	endPhase := s.startPhase(phaseOutputs)
+	defer func() {
+		endPhase(err)
+	}()
+
 	s.logger.Println()
 	s.logger.TInfof("Exporting outputs...")
 
-	cleanup := func(pth string) error {
-		if exist, err := v1pathutil.IsPathExists(pth); err != nil {
-			return fmt.Errorf("failed to check if path (%s) exist, error: %s", pth, err)
-		} else if exist {
-			if err := os.RemoveAll(pth); err != nil {
-				return fmt.Errorf("failed to remove path (%s), error: %s", pth, err)
-			}
+	outputPath := func(pth string) (string, error) {
+		return resolveOutputPath(pth, opts.OutputOverwritePolicy)
+	}
+
+	nameValues := exportArtifactNameValues(opts)
+
+	if opts.DerivedDataPath != "" {
+		if err := exportEnvironmentWithEnvman(s.cmdFactory, bitriseDerivedDataPthEnvKey, opts.DerivedDataPath); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", bitriseDerivedDataPthEnvKey, err)
+		}
+		s.logger.Donef("The DerivedData path is now available in the Environment Variable: %s (value: %s)", bitriseDerivedDataPthEnvKey, opts.DerivedDataPath)
+	}
+
+	// artifacts are the deployed files, which are checksummed
+	var artifacts []exportedArtifact
+
+	var summary *buildSummary
+	if opts.BuildSummary && opts.Archive != nil {
+		archiveSummary := newBuildSummary(opts.Scheme, *opts.Archive)
+		summary = &archiveSummary
+	}
+
+	// selectedProfiles are the provisioning profiles of the archive, replaced by the profiles of the IPA if exported
+	var selectedProfiles []buildSummaryProfile
+	// usedProfileUUIDs are the provisioning profiles of the archive and of every exported IPA
+	var usedProfileUUIDs []string
+	if opts.Archive != nil {
+		selectedProfiles = summaryProfiles(opts.Archive.BundleIDProfileInfoMap())
+		for _, profile := range opts.Archive.BundleIDProfileInfoMap() {
+			usedProfileUUIDs = append(usedProfileUUIDs, profile.UUID)
 		}
-		return nil
 	}
 
 	if opts.Archive != nil {
@@ -510,18 +1173,36 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 		}
 		s.logger.Donef("The xcarchive path is now available in the Environment Variable: %s (value: %s)", bitriseXCArchivePthEnvKey, archivePath)
 
-		archiveZipPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".xcarchive.zip")
-		if err := cleanup(archiveZipPath); err != nil {
-			return err
+		if opts.ExportXCArchiveZip {
+			archiveZipName := artifactFileName(".xcarchive.zip", nameValues, opts.ArtifactNameTemplate)
+			archiveZipPath := filepath.Join(opts.OutputDir, archiveZipName+".xcarchive.zip")
+			if archiveZipPath, err = outputPath(archiveZipPath); err != nil {
+				return err
+			}
+
+			archiveCompression := ArchiveCompression{
+				Format:        archiveFormatZip,
+				Level:         opts.CompressionLevel,
+				Deterministic: opts.DeterministicArchives,
+				Excludes:      opts.XCArchiveZipExcludes,
+			}
+			if err := ExportOutputDirAsArchive(s.cmdFactory, archivePath, archiveZipPath, bitriseXCArchiveZipPthEnvKey, archiveCompression, s.logger); err != nil {
+				return fmt.Errorf("failed to export %s, error: %s", bitriseXCArchiveZipPthEnvKey, err)
+			}
+			s.logger.Donef("The xcarchive zip path is now available in the Environment Variable: %s (value: %s)", bitriseXCArchiveZipPthEnvKey, archiveZipPath)
+			artifacts = append(artifacts, exportedArtifact{Path: archiveZipPath, Type: artifactTypeXCArchiveZip, ChecksumEnvKey: bitriseXCArchiveZipSHA256EnvKey})
+		} else {
+			s.logger.Printf("Exporting the xcarchive zip is disabled")
 		}
 
-		if err := ExportOutputDirAsZip(s.cmdFactory, archivePath, archiveZipPath, bitriseXCArchiveZipPthEnvKey, s.logger); err != nil {
-			return fmt.Errorf("failed to export %s, error: %s", bitriseXCArchiveZipPthEnvKey, err)
+		if opts.CopyToOrganizer {
+			if err := s.copyArchiveToOrganizer(NewArchive(*opts.Archive)); err != nil {
+				s.logger.Warnf("Failed to copy the xcarchive to the Xcode Organizer: %s", err)
+			}
 		}
-		s.logger.Donef("The xcarchive zip path is now available in the Environment Variable: %s (value: %s)", bitriseXCArchiveZipPthEnvKey, archiveZipPath)
 
 		appPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".app")
-		if err := cleanup(appPath); err != nil {
+		if appPath, err = outputPath(appPath); err != nil {
 			return err
 		}
 
@@ -530,64 +1211,175 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 		}
 		s.logger.Donef("The app directory is now available in the Environment Variable: %s (value: %s)", bitriseAppDirPthEnvKey, appPath)
 
-		s.logger.Printf("Looking for app and framework dSYMs.")
-
-		appDSYMPaths, frameworkDSYMPaths, err := opts.Archive.FindDSYMs()
+		iconPath, err := FindLargestAppIcon(opts.Archive.Application.Path, opts.Archive.Application.InfoPlist, s.logger)
 		if err != nil {
-			return fmt.Errorf("failed to export dSYMs, error: %s", err)
-		}
-
-		appDSYMPathsCount := len(appDSYMPaths)
-		frameworkDSYMPathsCount := len(frameworkDSYMPaths)
-
-		s.logger.Printf("Found %d app dSYMs and %d framework dSYMs.", appDSYMPathsCount, frameworkDSYMPathsCount)
-
-		if appDSYMPathsCount > 0 || frameworkDSYMPathsCount > 0 {
-			dsymDir, err := v1pathutil.NormalizedOSTempDirPath("__dsyms__")
-			if err != nil {
-				return fmt.Errorf("failed to create tmp dir, error: %s", err)
+			s.logger.Warnf("Failed to find app icon: %s", err)
+		} else if iconPath == "" {
+			s.logger.Printf("No app icon found in the app bundle")
+		} else {
+			appIconPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".icon.png")
+			if appIconPath, err = outputPath(appIconPath); err != nil {
+				return err
 			}
 
-			if appDSYMPathsCount > 0 {
-				if err := ExportDSYMs(dsymDir, appDSYMPaths); err != nil {
-					return fmt.Errorf("failed to export dSYMs: %v", err)
-				}
+			if err := ExportOutputFile(s.cmdFactory, iconPath, appIconPath, bitriseAppIconPthEnvKey); err != nil {
+				s.logger.Warnf("Failed to export %s, error: %s", bitriseAppIconPthEnvKey, err)
 			} else {
-				s.logger.Warnf("No app dSYMs found to export")
+				s.logger.Donef("The app icon path is now available in the Environment Variable: %s (value: %s)", bitriseAppIconPthEnvKey, appIconPath)
 			}
+		}
 
-			if opts.ExportAllDsyms && frameworkDSYMPathsCount > 0 {
-				if err := ExportDSYMs(dsymDir, frameworkDSYMPaths); err != nil {
-					return fmt.Errorf("failed to export dSYMs: %v", err)
-				}
+		archive := NewArchive(*opts.Archive)
+		teamID, _ := archive.TeamID()
+		creationDate := ""
+		if date, ok := archive.CreationDate(); ok {
+			creationDate = date.UTC().Format(time.RFC3339)
+		}
+		applicationProperties, _ := archive.ApplicationProperties()
+		archiveMetadata := []struct {
+			key   string
+			value string
+		}{
+			{bitriseArchivePlatformEnvKey, archive.Platform()},
+			{bitriseArchiveMinimumOSVersionEnvKey, archive.MinimumOSVersion()},
+			{bitriseArchiveSDKEnvKey, archive.SDK()},
+			{bitriseArchiveXcodeBuildEnvKey, archive.XcodeBuild()},
+			{bitriseArchiveSchemeNameEnvKey, archive.SchemeName()},
+			{bitriseArchiveCreationDateEnvKey, creationDate},
+			{bitriseArchiveSigningIdentityEnvKey, applicationProperties.SigningIdentity},
+			{bitriseAppVersionEnvKey, archive.Version()},
+			{bitriseAppBuildNumberEnvKey, archive.BuildNumber()},
+			{bitriseTeamIDEnvKey, teamID},
+		}
+		for _, metadata := range archiveMetadata {
+			if metadata.value == "" {
+				continue
 			}
 
-			if err := ExportOutputDir(s.cmdFactory, dsymDir, dsymDir, bitriseDSYMDirPthEnvKey, s.logger); err != nil {
-				return fmt.Errorf("failed to export %s, error: %s", bitriseDSYMDirPthEnvKey, err)
+			if err := exportEnvironmentWithEnvman(s.cmdFactory, metadata.key, metadata.value); err != nil {
+				return fmt.Errorf("failed to export %s, error: %s", metadata.key, err)
 			}
-			s.logger.Donef("The dSYM dir path is now available in the Environment Variable: %s (value: %s)", bitriseDSYMDirPthEnvKey, dsymDir)
+			s.logger.Donef("The archive metadata is now available in the Environment Variable: %s (value: %s)", metadata.key, metadata.value)
+		}
 
-			dsymZipPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".dSYM.zip")
-			if err := cleanup(dsymZipPath); err != nil {
-				return err
+		if err := s.exportBinarySizes(archive); err != nil {
+			s.logger.Warnf("Failed to export binary sizes: %s", err)
+		}
+
+		if err := s.exportExportCompliance(opts.Archive.Application); err != nil {
+			s.logger.Warnf("Failed to detect export compliance: %s", err)
+		}
+
+		if opts.CompareEntitlements {
+			if err := s.compareEntitlementsWithPreviousBuild(archive, opts.ArtifactName); err != nil {
+				s.logger.Warnf("Failed to compare the entitlements with the previous build: %s", err)
 			}
+		}
+
+		s.logger.Printf("Looking for privacy manifests.")
+
+		if err := s.exportPrivacyReport(opts.Archive.Application.Path, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export privacy report: %s", err)
+		}
+
+		if err := s.exportLocalizationReport(archive, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export localization report: %s", err)
+		}
 
-			if err := ExportOutputDirAsZip(s.cmdFactory, dsymDir, dsymZipPath, bitriseDSYMPthEnvKey, s.logger); err != nil {
-				return fmt.Errorf("failed to export %s, error: %s", bitriseDSYMPthEnvKey, err)
+		if opts.SBOMFormat != "" && opts.SBOMFormat != sbomFormatNone {
+			s.logger.Printf("Generating SBOM (%s).", opts.SBOMFormat)
+
+			if err := s.exportSBOM(NewArchive(*opts.Archive), opts.ProjectPath, opts.SBOMFormat, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+				s.logger.Warnf("Failed to export SBOM: %s", err)
 			}
-			s.logger.Donef("The dSYM zip path is now available in the Environment Variable: %s (value: %s)", bitriseDSYMPthEnvKey, dsymZipPath)
+		}
+
+		if opts.ExportOptionsPath != "" {
+			s.printSymbolUpload(opts.ExportOptionsPath, opts.ExportAllDsyms)
+		}
+
+		dsymCompression := ArchiveCompression{Format: opts.DSYMArchiveFormat, Level: opts.CompressionLevel, Deterministic: opts.DeterministicArchives}
+		dsyms, err := s.exportDSYMs(*opts.Archive, dsymExportOpts{
+			ExportAllDsyms:    opts.ExportAllDsyms,
+			Compression:       dsymCompression,
+			OutputDir:         opts.OutputDir,
+			Name:              artifactFileName(".dSYM"+dsymCompression.Extension(), nameValues, opts.ArtifactNameTemplate),
+			UploadCommandArgs: opts.DSYMUploadCommandArgs,
+		}, outputPath)
+		if err != nil {
+			return err
+		}
+
+		if summary != nil {
+			summary.AppDSYMCount = dsyms.AppDSYMCount
+			summary.FrameworkDSYMCount = dsyms.FrameworkDSYMCount
+		}
+		if dsyms.ZipPath != "" {
+			artifacts = append(artifacts, exportedArtifact{Path: dsyms.ZipPath, Type: artifactTypeDSYM, ChecksumEnvKey: bitriseDSYMSHA256EnvKey, SignatureEnvKey: bitriseDSYMSignaturePthEnvKey})
 		}
 	}
 
-	if opts.ExportOptionsPath != "" {
-		exportOptionsPath := filepath.Join(opts.OutputDir, "export_options.plist")
-		if err := cleanup(exportOptionsPath); err != nil {
+	if opts.SimulatorAppPath != "" {
+		simulatorAppZipPath, err := s.exportSimulatorApp(opts.SimulatorAppPath, opts.OutputDir, artifactFileName(".simulator.app.zip", nameValues, opts.ArtifactNameTemplate), outputPath)
+		if err != nil {
 			return err
 		}
+		artifacts = append(artifacts, exportedArtifact{Path: simulatorAppZipPath, Type: artifactTypeSimulatorAppZip})
+	}
+
+	if opts.ResultBundlePath != "" {
+		if err := ExportOutputDir(s.cmdFactory, opts.ResultBundlePath, opts.ResultBundlePath, bitriseXcresultPthEnvKey, s.logger); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", bitriseXcresultPthEnvKey, err)
+		}
+		s.logger.Donef("The result bundle path is now available in the Environment Variable: %s (value: %s)", bitriseXcresultPthEnvKey, opts.ResultBundlePath)
+	}
+
+	if opts.BuildIssues != nil {
+		if err := s.exportBuildIssues(*opts.BuildIssues, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export build issues: %s", err)
+		}
+	}
+
+	budgetOpts := warningBudgetOpts{
+		MaxWarnings:             opts.MaxWarnings,
+		FailOnNewDeprecations:   opts.FailOnNewDeprecations,
+		DeprecationBaselinePath: opts.DeprecationBaselinePath,
+		Action:                  opts.WarningBudgetAction,
+	}
+	if budgetOpts.enabled() {
+		if opts.BuildIssues == nil {
+			s.logger.Warnf("The build issues are not available (no result bundle), skipping the warning budget check")
+		} else if err := s.checkWarningBudget(*opts.BuildIssues, budgetOpts); err != nil {
+			return fmt.Errorf("warning budget check failed: %w", err)
+		}
+	}
 
-		if err := v1command.CopyFile(opts.ExportOptionsPath, exportOptionsPath); err != nil {
+	if opts.ExportOptionsPath != "" {
+		exportOptionsPath := filepath.Join(opts.OutputDir, artifactOutputFileName(opts.ArtifactName, "export_options.plist"))
+		if exportOptionsPath, err = outputPath(exportOptionsPath); err != nil {
 			return err
 		}
+
+		if err := ExportOutputFile(s.cmdFactory, opts.ExportOptionsPath, exportOptionsPath, bitriseExportOptionsPthEnvKey); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", bitriseExportOptionsPthEnvKey, err)
+		}
+		s.logger.Donef("The export options path is now available in the Environment Variable: %s (value: %s)", bitriseExportOptionsPthEnvKey, exportOptionsPath)
+
+		if err := s.exportProvisioningProfileMap(opts.ExportOptionsPath); err != nil {
+			s.logger.Warnf("Failed to export the provisioning profiles: %s", err)
+		}
+
+		if opts.CompareExportOptions {
+			if err := s.compareExportOptionsWithPreviousBuild(opts.ExportOptionsPath, opts.ArtifactName); err != nil {
+				s.logger.Warnf("Failed to compare the export options with the previous build: %s", err)
+			}
+		}
+
+		if summary != nil {
+			if method, err := exportMethodFromExportOptions(opts.ExportOptionsPath); err == nil {
+				summary.ExportMethod = string(method)
+			}
+		}
 	}
 
 	if opts.IPAExportDir != "" {
@@ -617,8 +1409,10 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 			return fmt.Errorf("No .ipa file found at export dir: %s", opts.IPAExportDir)
 		}
 
-		ipaPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".ipa")
-		if err := cleanup(ipaPath); err != nil {
+		ipaName := artifactFileName(".ipa", nameValues, opts.IPANameTemplate, opts.ArtifactNameTemplate)
+
+		ipaPath := filepath.Join(opts.OutputDir, ipaName+".ipa")
+		if ipaPath, err = outputPath(ipaPath); err != nil {
 			return err
 		}
 
@@ -626,10 +1420,68 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 			return fmt.Errorf("failed to export %s, error: %s", bitriseIPAPthEnvKey, err)
 		}
 		s.logger.Donef("The ipa path is now available in the Environment Variable: %s (value: %s)", bitriseIPAPthEnvKey, ipaPath)
+		ipaArtifact := exportedArtifact{
+			Path:            ipaPath,
+			Type:            artifactTypeIPA,
+			ExportMethod:    nameValues.ExportMethod,
+			ChecksumEnvKey:  bitriseIPASHA256EnvKey,
+			SignatureEnvKey: bitriseIPASignaturePthEnvKey,
+		}
+		if len(ipaFiles) > 1 {
+			ipaArtifact.Variant = strings.TrimSuffix(filepath.Base(ipaFiles[0]), ".ipa")
+		}
+		artifacts = append(artifacts, ipaArtifact)
+
+		if err := s.exportOnDemandResources(opts.IPAExportDir, opts.OutputDir, ipaName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export the On-Demand Resources asset packs: %s", err)
+		}
+
+		if opts.ExportSignedApp {
+			signedApps, err := s.exportSignedApps(ipaPath, opts.OutputDir, ipaName, outputPath)
+			if err != nil {
+				s.logger.Warnf("Failed to export the signed app: %s", err)
+			}
+			artifacts = append(artifacts, signedApps...)
+		}
+
+		if ipaProfiles, err := ipaProvisioningProfiles(ipaPath); err != nil {
+			s.logger.Warnf("Failed to read the provisioning profiles of the IPA: %s", err)
+		} else {
+			selectedProfiles = summaryProfiles(profilesByBundleID(ipaProfiles))
+			for _, profile := range ipaProfiles {
+				usedProfileUUIDs = append(usedProfileUUIDs, profile.UUID)
+			}
+			if err := s.exportProvisionedDevices(ipaProfiles, nameValues.ExportMethod); err != nil {
+				s.logger.Warnf("Failed to export the provisioned devices: %s", err)
+			}
+		}
+
+		if summary != nil {
+			if info, err := os.Stat(ipaPath); err == nil {
+				summary.IPASize = info.Size()
+			}
+		}
+
+		if opts.SizeReportTopFilesCount > 0 || opts.MaxIPASizeMB > 0 || opts.MaxAppSizeMB > 0 {
+			s.logger.Println()
+			s.logger.Infof("IPA size report:")
+
+			if err := s.exportIPASizeReport(ipaSizeReportOpts{
+				IPAPath:       ipaPath,
+				TopFilesCount: opts.SizeReportTopFilesCount,
+				MaxIPASizeMB:  opts.MaxIPASizeMB,
+				MaxAppSizeMB:  opts.MaxAppSizeMB,
+				OutputDir:     opts.OutputDir,
+				ArtifactName:  opts.ArtifactName,
+				OutputPath:    outputPath,
+			}); err != nil {
+				return fmt.Errorf("IPA size check failed: %w", err)
+			}
+		}
 
 		if len(ipaFiles) > 1 {
 			s.logger.Warnf("More than 1 .ipa file found, exporting first one: %s", ipaFiles[0])
-			s.logger.Warnf("Moving every ipa to the BITRISE_DEPLOY_DIR")
+			s.logger.Warnf("Moving every ipa to the BITRISE_DEPLOY_DIR, their paths are listed in %s", bitriseExportedFilePathsEnvKey)
 
 			for i, pth := range ipaFiles {
 				if i == 0 {
@@ -637,18 +1489,35 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 				}
 
 				base := filepath.Base(pth)
-				deployPth := filepath.Join(opts.OutputDir, base)
+				deployPth, err := outputPath(filepath.Join(opts.OutputDir, base))
+				if err != nil {
+					return err
+				}
 
 				if err := v1command.CopyFile(pth, deployPth); err != nil {
 					return fmt.Errorf("failed to copy (%s) -> (%s), error: %s", pth, deployPth, err)
 				}
+				artifacts = append(artifacts, exportedArtifact{
+					Path:         deployPth,
+					Type:         artifactTypeIPA,
+					ExportMethod: nameValues.ExportMethod,
+					Variant:      strings.TrimSuffix(base, ".ipa"),
+				})
+			}
+		}
+
+		if len(opts.AdditionalIPAExports) > 0 {
+			additionalIPAs, err := s.exportAdditionalIPAs(opts.AdditionalIPAExports, opts, nameValues, ipaName, outputPath)
+			artifacts = append(artifacts, additionalIPAs...)
+			if err != nil {
+				return fmt.Errorf("failed to export the IPAs of the additional distribution methods: %w", err)
 			}
 		}
 	}
 
 	if opts.IDEDistrubutionLogsDir != "" {
-		ideDistributionLogsZipPath := filepath.Join(opts.OutputDir, "xcodebuild.xcdistributionlogs.zip")
-		if err := cleanup(ideDistributionLogsZipPath); err != nil {
+		ideDistributionLogsZipPath := filepath.Join(opts.OutputDir, artifactOutputFileName(opts.ArtifactName, "xcodebuild.xcdistributionlogs.zip"))
+		if ideDistributionLogsZipPath, err = outputPath(ideDistributionLogsZipPath); err != nil {
 			return err
 		}
 
@@ -659,9 +1528,22 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 		}
 	}
 
+	if opts.BuildLogsDir != "" {
+		buildLogsZipPath := filepath.Join(opts.OutputDir, opts.ArtifactName+".build-logs.zip")
+		if buildLogsZipPath, err = outputPath(buildLogsZipPath); err != nil {
+			return err
+		}
+
+		if err := ExportOutputDirAsZip(s.cmdFactory, opts.BuildLogsDir, buildLogsZipPath, bitriseBuildLogsZipPthEnvKey, s.logger); err != nil {
+			s.logger.Warnf("Failed to export %s, error: %s", bitriseBuildLogsZipPthEnvKey, err)
+		} else {
+			s.logger.Donef("The build logs zip path is now available in the Environment Variable: %s (value: %s)", bitriseBuildLogsZipPthEnvKey, buildLogsZipPath)
+		}
+	}
+
 	if opts.XcodebuildArchiveLog != "" {
-		xcodebuildArchiveLogPath := filepath.Join(opts.OutputDir, xcodebuildArchiveLogFilename)
-		if err := cleanup(xcodebuildArchiveLogPath); err != nil {
+		xcodebuildArchiveLogPath := filepath.Join(opts.OutputDir, artifactOutputFileName(opts.ArtifactName, xcodebuildArchiveLogFilename))
+		if xcodebuildArchiveLogPath, err = outputPath(xcodebuildArchiveLogPath); err != nil {
 			return err
 		}
 
@@ -672,9 +1554,13 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 		}
 	}
 
+	if err := s.exportXcprettyReports(opts.XcprettyReports, opts.OutputDir, outputPath); err != nil {
+		s.logger.Warnf("Failed to export xcpretty reports: %s", err)
+	}
+
 	if opts.XcodebuildExportArchiveLog != "" {
-		xcodebuildExportArchiveLogPath := filepath.Join(opts.OutputDir, xcodebuildExportArchiveLogFilename)
-		if err := cleanup(xcodebuildExportArchiveLogPath); err != nil {
+		xcodebuildExportArchiveLogPath := filepath.Join(opts.OutputDir, artifactOutputFileName(opts.ArtifactName, xcodebuildExportArchiveLogFilename))
+		if xcodebuildExportArchiveLogPath, err = outputPath(xcodebuildExportArchiveLogPath); err != nil {
 			return err
 		}
 
@@ -685,18 +1571,107 @@ func (s XcodebuildArchiver) ExportOutput(opts ExportOpts) error {
 		}
 	}
 
+	exportedFiles := artifacts
+	if opts.ExportedFiles != nil {
+		exportedFiles = opts.ExportedFiles.add(opts.Scheme, opts.Configuration, artifacts)
+	}
+
+	if len(exportedFiles) > 0 {
+		if err := s.exportExportedFiles(exportedFiles, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export the exported files manifest: %s", err)
+		}
+	}
+
+	if opts.Checksums != "" && opts.Checksums != checksumsNone && len(exportedFiles) > 0 {
+		if err := s.exportChecksums(exportedFiles, opts.Checksums, opts.OutputDir, outputPath); err != nil {
+			s.logger.Warnf("Failed to export checksums: %s", err)
+		}
+	}
+
+	if opts.Provenance != nil && len(artifacts) > 0 {
+		var xcodeBuild string
+		if opts.Archive != nil {
+			xcodeBuild = NewArchive(*opts.Archive).XcodeBuild()
+		}
+		if provenancePath, err := s.exportProvenance(*opts.Provenance, artifacts, xcodeBuild, opts.OutputDir, opts.ArtifactName, outputPath); err != nil {
+			s.logger.Warnf("Failed to export provenance statement: %s", err)
+		} else {
+			artifacts = append(artifacts, exportedArtifact{Path: provenancePath, SignatureEnvKey: bitriseProvenanceSignaturePthEnvKey})
+		}
+	}
+
+	if opts.ArtifactSigningMethod != "" && opts.ArtifactSigningMethod != artifactSigningNone {
+		signer := artifactSigner{
+			method:     opts.ArtifactSigningMethod,
+			key:        opts.ArtifactSigningKey,
+			passphrase: opts.ArtifactSigningKeyPassphrase,
+			cmdFactory: s.cmdFactory,
+		}
+		endSigningPhase := s.startPhase(phaseArtifactSigning)
+		err := s.exportArtifactSignatures(artifacts, signer)
+		endSigningPhase(err)
+		if err != nil {
+			return fmt.Errorf("failed to sign artifacts: %w", err)
+		}
+	}
+
+	if len(selectedProfiles) > 0 {
+		s.printProfileValidity(selectedProfiles, opts.MinProfileValidityDays, time.Now())
+	}
+
+	if opts.ExportSigningAssetBundle && len(usedProfileUUIDs) > 0 {
+		bundlePath, err := outputPath(filepath.Join(opts.OutputDir, opts.ArtifactName+".signing-assets.zip"))
+		if err != nil {
+			return err
+		}
+		if err := s.exportSigningAssetBundle(bundlePath, usedProfileUUIDs); err != nil {
+			s.logger.Warnf("Failed to export the signing asset bundle: %s", err)
+		}
+	}
+
+	if summary != nil {
+		summary.Profiles = selectedProfiles
+		summary.MinProfileValidityDays = opts.MinProfileValidityDays
+		if err := s.publishBuildSummary(*summary, opts.HTMLReportDir); err != nil {
+			s.logger.Warnf("Failed to publish build summary: %s", err)
+		}
+	}
+
 	return nil
 }
 
-func (s XcodebuildArchiveConfigParser) createCodesignManager(config Config) (codesign.Manager, error) {
+// codesignAuthType returns the authentication type of the automatic code signing source.
+func codesignAuthType(codeSigningAuthSource string) (codesign.AuthType, error) {
 	var authType codesign.AuthType
-	switch config.CodeSigningAuthSource {
+	switch codeSigningAuthSource {
 	case codeSignSourceAppleID:
 		authType = codesign.AppleIDAuth
 	case codeSignSourceAPIKey:
 		authType = codesign.APIKeyAuth
 	case codeSignSourceOff:
-		return codesign.Manager{}, fmt.Errorf("automatic code signing is disabled")
+		return authType, fmt.Errorf("automatic code signing is disabled")
+	}
+	return authType, nil
+}
+
+// loadTestDevices returns the devices of the test device list file, or the test devices of the Bitrise Apple Service connection.
+func loadTestDevices(config Config, serviceConnection *devportalservice.AppleDeveloperConnection) ([]devportalservice.TestDevice, error) {
+	if config.TestDeviceListPath != "" {
+		testDevices, err := devportalservice.ParseTestDevicesFromFile(config.TestDeviceListPath, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to process device list (%s): %s", config.TestDeviceListPath, err)
+		}
+		return testDevices, nil
+	} else if serviceConnection != nil {
+		return serviceConnection.TestDevices, nil
+	}
+	return nil, nil
+}
+
+func (s XcodebuildArchiveConfigParser) createCodesignManager(config Config, serviceConnection *devportalservice.AppleDeveloperConnection) (codesign.Manager, *SigningRepairer, error) {
+	authType, err := codesignAuthType(config.CodeSigningAuthSource)
+	if err != nil {
+		return codesign.Manager{}, nil, err
 	}
 
 	codesignInputs := codesign.Input{
@@ -709,39 +1684,32 @@ func (s XcodebuildArchiveConfigParser) createCodesignManager(config Config) (cod
 		FallbackProvisioningProfiles: config.FallbackProvisioningProfileURLs,
 	}
 
-	codesignConfig, err := codesign.ParseConfig(codesignInputs, s.cmdFactory)
+	cmdFactory := s.cmdFactory
+	if config.Isolation != nil {
+		cmdFactory = isolatedKeychainCommandFactory{Factory: s.cmdFactory}
+	}
+	codesignConfig, err := codesign.ParseConfig(codesignInputs, cmdFactory)
 	if err != nil {
-		return codesign.Manager{}, err
+		return codesign.Manager{}, nil, err
 	}
 
 	devPortalClientFactory := devportalclient.NewFactory(s.logger, s.fileManager)
 
-	var serviceConnection *devportalservice.AppleDeveloperConnection = nil
-	if config.BuildURL != "" && config.BuildAPIToken != "" {
-		if serviceConnection, err = devPortalClientFactory.CreateBitriseConnection(config.BuildURL, string(config.BuildAPIToken)); err != nil {
-			return codesign.Manager{}, err
-		}
-	}
-
-	connectionInputs := codesign.ConnectionOverrideInputs{
-		APIKeyPath:              config.Inputs.APIKeyPath,
-		APIKeyID:                config.Inputs.APIKeyID,
-		APIKeyIssuerID:          config.Inputs.APIKeyIssuerID,
-		APIKeyEnterpriseAccount: config.Inputs.APIKeyEnterpriseAccount,
-	}
-
-	appleAuthCredentials, err := codesign.SelectConnectionCredentials(authType, serviceConnection, connectionInputs, s.logger)
+	authType, appleAuthCredentials, err := selectConnectionCredentials(authType, serviceConnection, config.Inputs, s.logger)
 	if err != nil {
-		return codesign.Manager{}, err
+		return codesign.Manager{}, nil, err
 	}
 
+	// the test device syncer registers the test devices before the code signing
+	registerTestDevices := config.RegisterTestDevices && config.TestDeviceSyncer == nil
+
 	opts := codesign.Opts{
 		AuthType:                   authType,
-		ShouldConsiderXcodeSigning: true,
+		ShouldConsiderXcodeSigning: shouldConsiderXcodeSigning(config),
 		TeamID:                     config.ExportDevelopmentTeam,
 		ExportMethod:               codesignConfig.DistributionMethod,
 		XcodeMajorVersion:          config.XcodeMajorVersion,
-		RegisterTestDevices:        config.RegisterTestDevices,
+		RegisterTestDevices:        registerTestDevices,
 		SignUITests:                false,
 		MinDaysProfileValidity:     config.MinDaysProfileValid,
 		IsVerboseLog:               config.VerboseLog,
@@ -753,19 +1721,39 @@ func (s XcodebuildArchiveConfigParser) createCodesignManager(config Config) (cod
 		ConfigurationName:      config.Configuration,
 	})
 	if err != nil {
-		return codesign.Manager{}, err
+		return codesign.Manager{}, nil, err
+	}
+
+	if usesBitriseManagedSigning(appleAuthCredentials, opts, project.IsSigningManagedAutomatically) {
+		if err := s.checkRestrictedEntitlements(config); err != nil {
+			return codesign.Manager{}, nil, err
+		}
 	}
 
 	client := retry.NewHTTPClient().StandardClient()
 
-	var testDevices []devportalservice.TestDevice
-	if config.TestDeviceListPath != "" {
-		testDevices, err = devportalservice.ParseTestDevicesFromFile(config.TestDeviceListPath, time.Now())
-		if err != nil {
-			return codesign.Manager{}, fmt.Errorf("failed to process device list (%s): %s", config.TestDeviceListPath, err)
-		}
-	} else if serviceConnection != nil {
-		testDevices = serviceConnection.TestDevices
+	testDevices, err := loadTestDevices(config, serviceConnection)
+	if err != nil {
+		return codesign.Manager{}, nil, err
+	}
+
+	certDownloader := certdownloader.NewDownloader(codesignConfig.CertificatesAndPassphrases, client)
+	assetWriter := codesignasset.NewWriter(codesignConfig.Keychain)
+
+	var registeredTestDevices []devportalservice.TestDevice
+	if registerTestDevices {
+		registeredTestDevices = testDevices
+	}
+	signingRepairer := &SigningRepairer{
+		credentials:            appleAuthCredentials,
+		teamID:                 config.ExportDevelopmentTeam,
+		distributionType:       codesignConfig.DistributionMethod,
+		minProfileValidityDays: config.MinDaysProfileValid,
+		verboseLog:             config.VerboseLog,
+		testDevices:            registeredTestDevices,
+		devPortalClientFactory: devPortalClientFactory,
+		certDownloader:         certDownloader,
+		assetWriter:            assetWriter,
 	}
 
 	return codesign.NewManagerWithProject(
@@ -773,35 +1761,68 @@ func (s XcodebuildArchiveConfigParser) createCodesignManager(config Config) (cod
 		appleAuthCredentials,
 		testDevices,
 		devPortalClientFactory,
-		certdownloader.NewDownloader(codesignConfig.CertificatesAndPassphrases, client),
+		certDownloader,
 		profiledownloader.New(codesignConfig.FallbackProvisioningProfiles, client),
-		codesignasset.NewWriter(codesignConfig.Keychain),
-		localcodesignasset.NewManager(localcodesignasset.NewProvisioningProfileProvider(), localcodesignasset.NewProvisioningProfileConverter()),
+		assetWriter,
+		newProfileLookup(isMacCatalystBuild(config.Destination, config.XcodebuildAdditionalOptions), config.ReadOnlyAppStoreConnect, s.logger),
 		localcodesignasset.NewProvisioningProfileConverter(),
 		project,
 		s.logger,
-	), nil
+	), signingRepairer, nil
 }
 
 type xcodeArchiveOpts struct {
 	ProjectPath         string
 	Scheme              string
 	DestinationPlatform Platform
+	Destination         string
 	Configuration       string
 	XcodeMajorVersion   int
 	ArtifactName        string
 	XcodeAuthOptions    *xcodebuild.AuthenticationParams
+	DryRun              bool
+	ArchivedApplication string
 
 	PerformCleanAction bool
 	XcconfigContent    string
 	AdditionalOptions  []string
-
-	CacheLevel string
+	BuildSettings      []string
+	BuildNumberMode    string
+	BuildNumber        string
+	BuildNumberTool    string
+	EncryptionUsage    string
+
+	ResolvePackageDependencies bool
+	PackageResolutionRetries   int
+	PackageMirrors             []PackageMirror
+	PackageRegistryURL         string
+	PackageSCMProvider         string
+	PackageAuthProvider        string
+	PackageHost                string
+	PackageHostToken           string
+
+	SkipPackagePluginValidation bool
+	SkipMacroValidation         bool
+
+	DerivedDataPath string
+	ArchivePath     string
+	CacheLevel      string
+	ExportBuildLogs bool
+	XcprettyReports []string
 }
 
 type xcodeArchiveResult struct {
 	Archive              *xcarchive.IosArchive
 	XcodebuildArchiveLog string
+	ResultBundlePath     string
+	BuildIssues          *BuildIssues
+	BuildLogsDir         string
+	XcprettyReports      []xcprettyReport
+	Configuration        string
+
+	// Dry run only: the planned archive path and the export info read from the project
+	ArchivePath        string
+	ProjectArchiveInfo exportoptionsgenerator.ArchiveInfo
 }
 
 func (s XcodebuildArchiver) xcodeArchive(opts xcodeArchiveOpts) (xcodeArchiveResult, error) {
@@ -814,13 +1835,14 @@ func (s XcodebuildArchiver) xcodeArchive(opts xcodeArchiveOpts) (xcodeArchiveRes
 	if err != nil {
 		return out, fmt.Errorf("failed to open project: %s: %s", opts.ProjectPath, err)
 	}
+	out.Configuration = configuration
 
 	s.logger.TInfof("Reading xcode project")
 
 	if opts.DestinationPlatform == detectPlatform {
 		s.logger.TInfof("Platform is set to 'automatic', detecting platform from the project.")
 		s.logger.TWarnf("Define the platform step input manually to avoid this phase in the future.")
-		platform, err := BuildableTargetPlatform(xcodeProj, scheme, configuration, opts.AdditionalOptions, XcodeBuild{}, s.logger)
+		platform, err := BuildableTargetPlatform(xcodeProj, scheme, configuration, opts.AdditionalOptions, s.buildSettingsProvider, s.logger)
 		if err != nil {
 			return out, fmt.Errorf("failed to read project platform: %s: %s", opts.ProjectPath, err)
 		}
@@ -840,6 +1862,63 @@ Please select a scheme targeting an Application target to archive and export the
 and use 'Export iOS and tvOS Xcode archive' step to export an App Clip.`, opts.Scheme, mainTarget.Name)
 	}
 
+	buildNumberOptions, err := updateBuildNumber(buildNumberOpts{
+		Mode:        opts.BuildNumberMode,
+		BuildNumber: opts.BuildNumber,
+		Tool:        opts.BuildNumberTool,
+		XcodeProj:   xcodeProj,
+		DryRun:      opts.DryRun,
+	}, s.cmdFactory, s.logger)
+	if err != nil {
+		return out, fmt.Errorf("failed to update build number: %w", err)
+	}
+
+	if opts.DryRun && (len(opts.PackageMirrors) > 0 || opts.PackageHostToken != "") {
+		s.logger.Printf("Dry run, skipping the Swift package mirrors and credentials configuration")
+	}
+
+	if len(opts.PackageMirrors) > 0 && !opts.DryRun {
+		mirrorsPath, err := writePackageMirrors(opts.ProjectPath, opts.PackageMirrors)
+		if err != nil {
+			return out, fmt.Errorf("failed to configure package mirrors: %w", err)
+		}
+		s.logger.Printf("Package mirrors configured in: %s", mirrorsPath)
+	}
+
+	if opts.PackageHostToken != "" && !opts.DryRun {
+		if err := s.configurePackageAuthentication(opts.PackageHost, opts.PackageHostToken); err != nil {
+			return out, fmt.Errorf("failed to configure package authentication: %w", err)
+		}
+	}
+
+	if opts.DerivedDataPath != "" {
+		s.logger.Printf("Using DerivedData path: %s", opts.DerivedDataPath)
+	}
+
+	packageOptions := packageRegistryOptions(opts.PackageRegistryURL)
+	packageOptions = append(packageOptions, packageAuthOptions(opts.PackageSCMProvider, opts.PackageAuthProvider)...)
+	packageOptions = append(packageOptions, packageValidationOptions(opts.SkipPackagePluginValidation, opts.SkipMacroValidation)...)
+	if opts.ResolvePackageDependencies {
+		resolutionOpts := packageResolutionOpts{
+			ProjectPath:   opts.ProjectPath,
+			Scheme:        opts.Scheme,
+			Configuration: opts.Configuration,
+			Retries:       opts.PackageResolutionRetries,
+			CustomOptions: append(derivedDataOptions(opts.DerivedDataPath), packageOptions...),
+		}
+		if opts.DryRun {
+			s.logger.Println()
+			s.logger.Printf("Planned xcodebuild command: %s", printableCommand("xcodebuild", packageResolutionArgs(resolutionOpts), s.sensitiveValues))
+		} else {
+			endPhase := s.startPhase(phasePackageResolution)
+			err := s.resolvePackageDependencies(resolutionOpts)
+			endPhase(err)
+			if err != nil {
+				return out, err
+			}
+		}
+	}
+
 	// Create the Archive with Xcode Command Line tools
 	s.logger.Println()
 	s.logger.TInfof("Creating the Archive ...")
@@ -861,6 +1940,7 @@ and use 'Export iOS and tvOS Xcode archive' step to export an App Clip.`, opts.S
 		if err != nil {
 			return out, fmt.Errorf("failed to write xcconfig file contents: %w", err)
 		}
+		s.logger.Printf("Build settings are overridden with the xcconfig file: %s", xcconfigPath)
 		archiveCmd.SetXCConfigPath(xcconfigPath)
 	}
 
@@ -868,26 +1948,102 @@ and use 'Export iOS and tvOS Xcode archive' step to export an App Clip.`, opts.S
 	if err != nil {
 		return out, fmt.Errorf("failed to create temp dir, error: %s", err)
 	}
-	archivePth := filepath.Join(tmpDir, opts.ArtifactName+".xcarchive")
+	archivePth := resolveArchivePath(opts.ArchivePath, opts.ArtifactName, tmpDir)
+	if opts.ArchivePath != "" && !opts.DryRun {
+		if err := prepareArchivePath(archivePth, s.logger); err != nil {
+			return out, err
+		}
+	}
 
 	archiveCmd.SetArchivePath(archivePth)
 	if opts.XcodeAuthOptions != nil {
 		archiveCmd.SetAuthentication(*opts.XcodeAuthOptions)
 	}
 
-	additionalOptions := generateAdditionalOptions(string(opts.DestinationPlatform), opts.AdditionalOptions)
+	customOptions := opts.AdditionalOptions
+	if opts.Destination != "" {
+		customOptions = append([]string{"-destination", opts.Destination}, customOptions...)
+	}
+	additionalOptions := generateAdditionalOptions(string(opts.DestinationPlatform), customOptions)
+	additionalOptions = append(additionalOptions, buildNumberOptions...)
+	additionalOptions = append(additionalOptions, encryptionUsageBuildSettings(opts.EncryptionUsage)...)
+	additionalOptions = append(additionalOptions, buildLogsBuildSettings(opts.ExportBuildLogs)...)
+	additionalOptions = append(additionalOptions, derivedDataOptions(opts.DerivedDataPath)...)
+	additionalOptions = append(additionalOptions, packageOptions...)
+	additionalOptions = append(additionalOptions, opts.BuildSettings...)
+
+	resultBundlePath := resultBundlePathFromOptions(opts.AdditionalOptions)
+	if resultBundlePath == "" && opts.XcodeMajorVersion >= resultBundleMinXcodeMajorVersion {
+		resultBundlePath = filepath.Join(tmpDir, opts.ArtifactName+".xcresult")
+		additionalOptions = append(additionalOptions, resultBundlePathOption, resultBundlePath)
+	}
 	archiveCmd.SetCustomOptions(additionalOptions)
 
+	if opts.DryRun {
+		s.logger.Printf("Planned xcodebuild command: %s", printableCommand("xcodebuild", archiveCmd.CommandArgs(), s.sensitiveValues))
+
+		archiveInfo, err := exportoptionsgenerator.ReadArchiveInfoFromXcodeproject(xcodeProj, scheme, configuration)
+		if err != nil {
+			return out, fmt.Errorf("failed to read the export info from the project: %w", err)
+		}
+		out.ArchivePath = archivePth
+		out.ProjectArchiveInfo = archiveInfo
+		return out, nil
+	}
+
 	var swiftPackagesPath string
-	if opts.XcodeMajorVersion >= 11 {
+	if opts.DerivedDataPath != "" {
+		swiftPackagesPath = derivedDataSwiftPackagesPath(opts.DerivedDataPath)
+	} else if opts.XcodeMajorVersion >= 11 {
 		var err error
 		if swiftPackagesPath, err = cache.NewSwiftPackageCache().SwiftPackagesPath(opts.ProjectPath); err != nil {
 			return out, fmt.Errorf("failed to get Swift Packages path, error: %s", err)
 		}
 	}
 
-	xcodebuildLog, err := runArchiveCommandWithRetry(s.xcodeCommandRunner, s.logFormatter, archiveCmd, swiftPackagesPath, s.logger)
+	var logFormatterArgs []string
+	if s.logFormatter == XcprettyTool && len(opts.XcprettyReports) > 0 {
+		out.XcprettyReports = newXcprettyReports(tmpDir, opts.ArtifactName, opts.XcprettyReports)
+		logFormatterArgs = xcprettyReportArgs(out.XcprettyReports)
+	}
+
+	archiveStarted := time.Now()
+	xcodebuildLog, err := runArchiveCommandWithRetry(s.xcodeCommandRunner, s.logFormatter, logFormatterArgs, archiveCmd, swiftPackagesPath, resultBundlePath, s.sensitiveValues, s.logger)
 	out.XcodebuildArchiveLog = xcodebuildLog
+	if err != nil {
+		s.printXcodebuildStderr("archive", archiveCmd.CommandArgs())
+	}
+
+	if opts.ExportBuildLogs {
+		derivedDataPath := opts.DerivedDataPath
+		if derivedDataPath == "" {
+			var ddErr error
+			if derivedDataPath, ddErr = s.projectDerivedDataPath(xcodeProj, mainTarget.Name, configuration, opts.AdditionalOptions); ddErr != nil {
+				s.logger.Warnf("Failed to find the DerivedData directory: %s", ddErr)
+			}
+		}
+		if derivedDataPath != "" {
+			if buildLogsDir, logsErr := s.collectBuildLogs(derivedDataPath, archiveStarted); logsErr != nil {
+				s.logger.Warnf("Failed to collect build logs: %s", logsErr)
+			} else {
+				out.BuildLogsDir = buildLogsDir
+			}
+		}
+	}
+
+	if resultBundlePath != "" {
+		if exist, pathErr := v1pathutil.IsPathExists(resultBundlePath); pathErr != nil {
+			s.logger.Warnf("Failed to check if result bundle exist: %s", pathErr)
+		} else if exist {
+			out.ResultBundlePath = resultBundlePath
+			if issues, issuesErr := s.readResultBundleIssues(resultBundlePath, opts.XcodeMajorVersion); issuesErr != nil {
+				s.logger.Warnf("Failed to read build issues from the result bundle: %s", issuesErr)
+			} else {
+				out.BuildIssues = &issues
+			}
+		}
+	}
+
 	if err != nil {
 		return out, fmt.Errorf("failed to archive the project: %w", err)
 	}
@@ -899,7 +2055,7 @@ and use 'Export iOS and tvOS Xcode archive' step to export an App Clip.`, opts.S
 		return out, fmt.Errorf("no archive generated at: %s", archivePth)
 	}
 
-	archive, err := xcarchive.NewIosArchive(archivePth)
+	archive, err := s.newIosArchive(archivePth, opts.ArchivedApplication)
 	if err != nil {
 		return out, fmt.Errorf("failed to parse archive, error: %s", err)
 	}
@@ -914,13 +2070,32 @@ and use 'Export iOS and tvOS Xcode archive' step to export an App Clip.`, opts.S
 	s.logger.Printf("export: %s", mainApplication.ProvisioningProfile.ExportType)
 	s.logger.Printf("xcode managed profile: %v", profileutil.IsXcodeManaged(mainApplication.ProvisioningProfile.Name))
 
+	if extensions := NewArchive(archive).Extensions(); len(extensions) > 0 {
+		s.logger.Printf("extensions:")
+		for _, extension := range extensions {
+			s.logger.Printf("- %s (type: %s, deployment target: %s, embeds frameworks: %v)", extension.BundleIdentifier(), extension.ExtensionPointIdentifier, extension.DeploymentTarget, extension.EmbedsFrameworks)
+		}
+	}
+
 	// Cache swift PM
-	if opts.XcodeMajorVersion >= 11 && opts.CacheLevel == "swift_packages" {
-		if err := cache.NewSwiftPackageCache().CollectSwiftPackages(opts.ProjectPath); err != nil {
+	if opts.XcodeMajorVersion >= 11 && opts.CacheLevel == cacheLevelSwiftPackages {
+		if opts.DerivedDataPath != "" {
+			err = collectSwiftPackages(opts.DerivedDataPath)
+		} else {
+			err = cache.NewSwiftPackageCache().CollectSwiftPackages(opts.ProjectPath)
+		}
+		if err != nil {
 			s.logger.Warnf("Failed to mark swift packages for caching, error: %s", err)
 		}
 	}
 
+	// Cache DerivedData
+	if opts.CacheLevel == cacheLevelDerivedData {
+		if err := collectDerivedData(opts.DerivedDataPath); err != nil {
+			s.logger.Warnf("Failed to mark DerivedData for caching, error: %s", err)
+		}
+	}
+
 	return out, nil
 }
 
@@ -935,7 +2110,12 @@ type xcodeIPAExportOpts struct {
 	ICloudContainerEnvironment      string
 	ExportDevelopmentTeam           string
 	UploadBitcode                   bool
+	UploadSymbols                   bool
 	CompileBitcode                  bool
+	OnDemandResources               OnDemandResourcesOpts
+	ManualIPAFallback               bool
+	// ConcurrentExport is set if other exports of the build run at the same time, their IDEDistribution logs can't be told apart by time
+	ConcurrentExport bool
 }
 
 type xcodeIPAExportResult struct {
@@ -945,11 +2125,38 @@ type xcodeIPAExportResult struct {
 	IDEDistrubutionLogsDir     string
 }
 
-func (s XcodebuildArchiver) xcodeIPAExport(opts xcodeIPAExportOpts) (xcodeIPAExportResult, error) {
-	out := xcodeIPAExportResult{}
+// generateExportOptions generates the export options for the application described by the archive info.
+func (s XcodebuildArchiver) generateExportOptions(opts xcodeIPAExportOpts, archiveInfo exportoptionsgenerator.ArchiveInfo, archiveExportMethod exportoptions.Method, archivedWithXcodeManagedProfiles bool) (exportoptions.ExportOptions, error) {
+	exportMethod, err := determineExportMethod(opts.ExportMethod, archiveExportMethod, s.logger)
+	if err != nil {
+		return nil, err
+	}
 
-	// Exporting the ipa with Xcode Command Line tools
+	signingStyle := exportoptions.SigningStyleManual
+	if opts.XcodeAuthOptions != nil {
+		signingStyle = exportoptions.SigningStyleAutomatic
+	}
 
+	generator := exportoptionsgenerator.New(s.xcodeVersionReader, s.logger)
+	exportOptions, err := generator.GenerateApplicationExportOptions(exportoptionsgenerator.ExportProductApp, archiveInfo, exportMethod, signingStyle, exportoptionsgenerator.Opts{
+		ContainerEnvironment:             opts.ICloudContainerEnvironment,
+		TeamID:                           opts.ExportDevelopmentTeam,
+		UploadBitcode:                    opts.UploadBitcode,
+		CompileBitcode:                   opts.CompileBitcode,
+		ArchivedWithXcodeManagedProfiles: archivedWithXcodeManagedProfiles,
+		TestFlightInternalTestingOnly:    opts.TestFlightInternalTestingOnly,
+		ManageVersionAndBuildNumber:      false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate xcode export options: %s", err)
+	}
+	exportOptions = applySymbolUploadOption(exportOptions, opts.UploadSymbols)
+	return applyOnDemandResourcesOptions(exportOptions, opts.OnDemandResources), nil
+}
+
+// unsetRubyEnvironment unsets the Ruby environment before exporting the IPAs.
+// The exports may run concurrently, so the environment is modified once, before any of them.
+func unsetRubyEnvironment() error {
 	/*
 		You'll get an "Error Domain=IDEDistributionErrorDomain Code=14 "No applicable devices found."" error
 		if $GEM_HOME is set and the project's directory includes a Gemfile - to fix this
@@ -962,9 +2169,16 @@ func (s XcodebuildArchiver) xcodeIPAExport(opts xcodeIPAExportOpts) (xcodeIPAExp
 	envsToUnset := []string{"GEM_HOME", "GEM_PATH", "RUBYLIB", "RUBYOPT", "BUNDLE_BIN_PATH", "_ORIGINAL_GEM_PATH", "BUNDLE_GEMFILE"}
 	for _, key := range envsToUnset {
 		if err := os.Unsetenv(key); err != nil {
-			return out, fmt.Errorf("failed to unset (%s), error: %s", key, err)
+			return fmt.Errorf("failed to unset (%s), error: %s", key, err)
 		}
 	}
+	return nil
+}
+
+func (s XcodebuildArchiver) xcodeIPAExport(opts xcodeIPAExportOpts) (xcodeIPAExportResult, error) {
+	out := xcodeIPAExportResult{}
+
+	// Exporting the ipa with Xcode Command Line tools
 
 	s.logger.Println()
 	s.logger.Infof("Collecting export options...")
@@ -986,49 +2200,48 @@ func (s XcodebuildArchiver) xcodeIPAExport(opts xcodeIPAExportOpts) (xcodeIPAExp
 	} else {
 		s.logger.Printf("No custom export options content provided, generating export options...")
 
-		archiveExportMethod := opts.Archive.Application.ProvisioningProfile.ExportType
-
-		exportMethod, err := determineExportMethod(opts.ExportMethod, archiveExportMethod, s.logger)
+		archiveInfo, err := exportoptionsgenerator.ReadArchiveExportInfo(opts.Archive)
 		if err != nil {
-			return out, err
+			return out, fmt.Errorf("failed to read xcarchive: %s", err)
 		}
 
-		archiveCodeSignIsXcodeManaged := opts.Archive.IsXcodeManaged()
-		signingStyle := exportoptions.SigningStyleManual
-		if opts.XcodeAuthOptions != nil {
-			signingStyle = exportoptions.SigningStyleAutomatic
+		analysis := newArchiveAnalysis(archiveInfo, opts.Archive.Application.ProvisioningProfile.ExportType, opts.Archive.IsXcodeManaged())
+		if analysis, err = s.mutateArchiveAnalysis(analysis); err != nil {
+			return out, err
 		}
 
-		archiveInfo, err := exportoptionsgenerator.ReadArchiveExportInfo(opts.Archive)
+		exportOptions, err := s.generateExportOptions(opts, analysis.archiveInfo(), exportoptions.Method(analysis.ExportMethod), analysis.XcodeManaged)
 		if err != nil {
-			return out, fmt.Errorf("failed to read xcarchive: %s", err)
+			return out, err
 		}
 
-		generator := exportoptionsgenerator.New(s.xcodeVersionReader, s.logger)
-		exportOptions, err := generator.GenerateApplicationExportOptions(exportoptionsgenerator.ExportProductApp, archiveInfo, exportMethod, signingStyle, exportoptionsgenerator.Opts{
-			ContainerEnvironment:             opts.ICloudContainerEnvironment,
-			TeamID:                           opts.ExportDevelopmentTeam,
-			UploadBitcode:                    opts.UploadBitcode,
-			CompileBitcode:                   opts.CompileBitcode,
-			ArchivedWithXcodeManagedProfiles: archiveCodeSignIsXcodeManaged,
-			TestFlightInternalTestingOnly:    opts.TestFlightInternalTestingOnly,
-			ManageVersionAndBuildNumber:      false,
-		})
+		exportOptionsHash, err := s.mutateExportOptions(exportOptions.Hash(), analysis)
 		if err != nil {
-			return out, fmt.Errorf("failed to generate xcode export options: %s", err)
+			return out, err
 		}
 
 		s.logger.Println()
 		s.logger.Printf("generated export options content:")
 		s.logger.Println()
-		exportOptionsContent, err := exportOptions.String()
+		exportOptionsContent, err := exportOptionsContent(exportOptionsHash)
 		if err != nil {
 			return out, err
 		}
 		s.logger.Printf("%s", exportOptionsContent)
 
-		if err := exportOptions.WriteToFile(exportOptionsPath); err != nil {
-			return out, err
+		if err := v1fileutil.WriteStringToFile(exportOptionsPath, exportOptionsContent); err != nil {
+			return out, fmt.Errorf("failed to write export options to file, error: %s", err)
+		}
+	}
+
+	archive := NewArchive(opts.Archive)
+	s.printOnDemandResources(archive, opts.ExportMethod, opts.OnDemandResources)
+
+	if messagesExtensions := archive.MessagesExtensions(); len(messagesExtensions) > 0 {
+		s.logger.Println()
+		s.logger.Printf("Messages extensions found in the archive:")
+		for _, extension := range messagesExtensions {
+			s.logger.Printf("- %s", extension.BundleIdentifier())
 		}
 	}
 
@@ -1044,9 +2257,12 @@ func (s XcodebuildArchiver) xcodeIPAExport(opts xcodeIPAExportOpts) (xcodeIPAExp
 
 	s.logger.Println()
 	s.logger.Infof("Exporting IPA from the archive...")
-	exportArchiveLog, exportErr := runIPAExportCommand(s.xcodeCommandRunner, s.logFormatter, exportCmd, s.logger)
+	exportStarted := time.Now()
+	exportArchiveLog, exportErr := runIPAExportCommand(s.xcodeCommandRunner, s.logFormatter, exportCmd, s.sensitiveValues, s.logger)
 	out.XcodebuildExportArchiveLog = exportArchiveLog
 	if exportErr != nil {
+		s.printXcodebuildStderr("-exportArchive", exportCmd.CommandArgs())
+
 		s.logger.Println()
 		isRawLogOutput := s.logFormatter == XcodebuildTool
 		if !isRawLogOutput {
@@ -1057,9 +2273,15 @@ will be available in the $%s environment variable`, xcodebuildExportArchiveLogFi
 
 		// xcdistributionlogs
 		ideDistrubutionLogsDir, err := findIDEDistrubutionLogsPath(exportArchiveLog, s.logger)
+		if loggingBaseDir := os.Getenv(xcLoggingBaseDirEnvKey); err == nil && ideDistrubutionLogsDir == "" && loggingBaseDir != "" && !opts.ConcurrentExport {
+			// the logs path is not printed by every Xcode version, the logs are looked up in the logging base directory of the build
+			if ideDistrubutionLogsDir = findIDEDistributionLogsSince([]string{loggingBaseDir}, exportStarted); ideDistrubutionLogsDir != "" {
+				s.logger.Printf("Located IDE distrubution logs path in the logging base directory: %s", ideDistrubutionLogsDir)
+			}
+		}
 		if err != nil {
 			s.logger.Warnf("Failed to find xcdistributionlogs, error: %s", err)
-		} else {
+		} else if ideDistrubutionLogsDir != "" {
 			out.IDEDistrubutionLogsDir = ideDistrubutionLogsDir
 
 			criticalDistLogFilePth := filepath.Join(ideDistrubutionLogsDir, "IDEDistribution.critical.log")
@@ -1079,11 +2301,39 @@ is available in the $BITRISE_IDEDISTRIBUTION_LOGS_PATH environment variable`)
 			}
 		}
 
+		if opts.ManualIPAFallback {
+			s.logger.Println()
+			s.logger.Infof("Packaging the IPA manually...")
+
+			exportMethod, err := exportMethodFromExportOptions(exportOptionsPath)
+			if err != nil {
+				return out, fmt.Errorf("failed to export IPA: %w", exportErr)
+			}
+			manualIPAExportDir, err := s.packageIPAManually(opts.Archive, exportMethod)
+			if err != nil {
+				s.logger.Warnf("Failed to package the IPA manually: %s", err)
+				return out, fmt.Errorf("failed to export IPA: %w", exportErr)
+			}
+
+			s.logger.Warnf("xcodebuild -exportArchive failed (%s), the IPA was packaged manually from the archived app, it is signed with the archive's signing identity and profile", exportErr)
+			out.ExportOptionsPath = exportOptionsPath
+			out.IPAExportDir = manualIPAExportDir
+			return out, nil
+		}
+
 		return out, fmt.Errorf("failed to export IPA: %w", exportErr)
 	}
 
+	if err := checkMessagesApplicationSupport(archive, exportOptionsPath, ipaExportDir, s.logger); err != nil {
+		s.logger.Warnf("Failed to check Messages application support in the exported IPA: %s", err)
+	}
+
 	out.ExportOptionsPath = exportOptionsPath
 	out.IPAExportDir = ipaExportDir
 
+	if err := s.checkAppStoreSupportFiles(exportOptionsPath, ipaExportDir); err != nil {
+		return out, err
+	}
+
 	return out, nil
 }
@@ -0,0 +1,276 @@
+package step
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/retry"
+)
+
+const (
+	packageResolutionRetryWait = 10 * time.Second
+
+	packageSCMProviderXcode  = "xcode"
+	packageSCMProviderSystem = "system"
+
+	packageAuthorizationProviderDefault = "default"
+
+	// netrcLogin is the login used for token authentication, git hosts only check the token (password)
+	netrcLogin = "oauth2"
+)
+
+// transientPackageResolutionErrors are the network and git failures worth retrying the package resolution for
+var transientPackageResolutionErrors = []string{
+	"Could not resolve host",
+	"The network connection was lost",
+	"The request timed out",
+	"Connection reset by peer",
+	"Failed to clone repository",
+	"fatal: unable to access",
+	"error: RPC failed",
+	"early EOF",
+	"SSL_ERROR_SYSCALL",
+}
+
+type packageResolutionOpts struct {
+	ProjectPath   string
+	Scheme        string
+	Configuration string
+	Retries       int
+	CustomOptions []string
+}
+
+func packageResolutionArgs(opts packageResolutionOpts) []string {
+	var args []string
+	if filepath.Ext(opts.ProjectPath) == xcodeWorkspaceExt {
+		args = append(args, "-workspace", opts.ProjectPath)
+	} else {
+		args = append(args, "-project", opts.ProjectPath)
+	}
+	args = append(args, "-scheme", opts.Scheme)
+	if opts.Configuration != "" {
+		args = append(args, "-configuration", opts.Configuration)
+	}
+	args = append(args, "-resolvePackageDependencies")
+	return append(args, opts.CustomOptions...)
+}
+
+func isTransientPackageResolutionError(output string) bool {
+	for _, message := range transientPackageResolutionErrors {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePackageDependencies runs `xcodebuild -resolvePackageDependencies` before the archive,
+// retrying on transient network and git failures.
+func (s XcodebuildArchiver) resolvePackageDependencies(opts packageResolutionOpts) error {
+	s.logger.Println()
+	s.logger.TInfof("Resolving Swift package dependencies")
+
+	return retry.Times(uint(opts.Retries)).Wait(packageResolutionRetryWait).TryWithAbort(func(attempt uint) (error, bool) {
+		if attempt > 0 {
+			s.logger.Warnf("Retrying package resolution (attempt %d)", attempt+1)
+		}
+
+		cmd := s.cmdFactory.Create("xcodebuild", packageResolutionArgs(opts), nil)
+		s.logger.Printf("$ %s", printableCommand("xcodebuild", packageResolutionArgs(opts), s.sensitiveValues))
+		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+		if err == nil {
+			s.logger.Debugf("%s", out)
+			s.logger.Donef("Swift package dependencies resolved")
+			return nil, false
+		}
+
+		s.logger.Printf("%s", out)
+		if !isTransientPackageResolutionError(out) {
+			return fmt.Errorf("failed to resolve package dependencies: %w", err), true
+		}
+		return fmt.Errorf("failed to resolve package dependencies (transient error): %w", err), false
+	})
+}
+
+// PackageMirror is an entry of the SwiftPM mirrors configuration.
+type PackageMirror struct {
+	Original string `json:"original"`
+	Mirror   string `json:"mirror"`
+}
+
+// parsePackageMirrors parses the ORIGINAL=MIRROR lines.
+func parsePackageMirrors(content string) ([]PackageMirror, error) {
+	var mirrors []PackageMirror
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		original, mirror, found := strings.Cut(line, "=")
+		original, mirror = strings.TrimSpace(original), strings.TrimSpace(mirror)
+		if !found || original == "" || mirror == "" {
+			return nil, fmt.Errorf("line %d (%s) is not a valid ORIGINAL=MIRROR package mirror", i+1, line)
+		}
+		mirrors = append(mirrors, PackageMirror{Original: original, Mirror: mirror})
+	}
+	return mirrors, nil
+}
+
+// swiftPMConfigurationDir returns the directory Xcode reads the workspace level SwiftPM configuration from.
+func swiftPMConfigurationDir(projectPath string) string {
+	workspacePath := projectPath
+	if filepath.Ext(projectPath) == xcodeProjectExt {
+		workspacePath = filepath.Join(projectPath, "project.xcworkspace")
+	}
+	return filepath.Join(workspacePath, "xcshareddata", "swiftpm", "configuration")
+}
+
+// writePackageMirrors merges the mirrors into the workspace's mirrors.json and returns its path.
+func writePackageMirrors(projectPath string, mirrors []PackageMirror) (string, error) {
+	mirrorsPath := filepath.Join(swiftPMConfigurationDir(projectPath), "mirrors.json")
+
+	config := struct {
+		Object  []PackageMirror `json:"object"`
+		Version int             `json:"version"`
+	}{Version: 1}
+
+	if b, err := os.ReadFile(mirrorsPath); err == nil {
+		if err := json.Unmarshal(b, &config); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", mirrorsPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	for _, mirror := range mirrors {
+		replaced := false
+		for i, existing := range config.Object {
+			if existing.Original == mirror.Original {
+				config.Object[i] = mirror
+				replaced = true
+			}
+		}
+		if !replaced {
+			config.Object = append(config.Object, mirror)
+		}
+	}
+
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(mirrorsPath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(mirrorsPath, b, 0644); err != nil {
+		return "", err
+	}
+	return mirrorsPath, nil
+}
+
+// packageRegistryOptions returns the xcodebuild options for using a default package registry.
+func packageRegistryOptions(registryURL string) []string {
+	if registryURL == "" {
+		return nil
+	}
+	return []string{"-defaultPackageRegistryURL", registryURL}
+}
+
+// packageAuthOptions returns the xcodebuild options selecting the git implementation and the credential source of the package resolution.
+func packageAuthOptions(scmProvider, authorizationProvider string) []string {
+	var options []string
+	if scmProvider == packageSCMProviderSystem {
+		options = append(options, "-scmProvider", packageSCMProviderSystem)
+	}
+	if authorizationProvider != "" && authorizationProvider != packageAuthorizationProviderDefault {
+		options = append(options, "-packageAuthorizationProvider", authorizationProvider)
+	}
+	return options
+}
+
+// packageValidationOptions returns the xcodebuild options skipping the fingerprint trust prompt of package plugins and macros.
+func packageValidationOptions(skipPluginValidation, skipMacroValidation bool) []string {
+	var options []string
+	if skipPluginValidation {
+		options = append(options, "-skipPackagePluginValidation")
+	}
+	if skipMacroValidation {
+		options = append(options, "-skipMacroValidation")
+	}
+	return options
+}
+
+// netrcHasMachine ...
+func netrcHasMachine(netrcPath, host string) (bool, error) {
+	f, err := os.Open(netrcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanWords)
+	previous := ""
+	for scanner.Scan() {
+		if previous == "machine" && scanner.Text() == host {
+			return true, nil
+		}
+		previous = scanner.Text()
+	}
+	return false, scanner.Err()
+}
+
+// addNetrcEntry appends a token entry for the host to the netrc file, unless the host already has an entry.
+// Returns false if the file already contained the host.
+func addNetrcEntry(netrcPath, host, token string) (bool, error) {
+	if exist, err := netrcHasMachine(netrcPath, host); err != nil {
+		return false, err
+	} else if exist {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(netrcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := fmt.Fprintf(f, "\nmachine %s\n  login %s\n  password %s\n", host, netrcLogin, token); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// configurePackageAuthentication stores the package host token in the user's netrc file,
+// which is used by the system git (-scmProvider system) and the netrc authorization provider.
+func (s XcodebuildArchiver) configurePackageAuthentication(host, token string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	netrcPath := filepath.Join(homeDir, ".netrc")
+
+	added, err := addNetrcEntry(netrcPath, host, token)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", netrcPath, err)
+	}
+	if added {
+		s.logger.Printf("Package host credentials added to %s for: %s", netrcPath, host)
+	} else {
+		s.logger.Warnf("%s already contains credentials for %s, the package host token is not added", netrcPath, host)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePackageMirrors(t *testing.T) {
+	mirrors, err := parsePackageMirrors("# mirrors\nhttps://github.com/a/b.git = https://mirror.example.com/b.git\n\n")
+	require.NoError(t, err)
+	require.Equal(t, []PackageMirror{{Original: "https://github.com/a/b.git", Mirror: "https://mirror.example.com/b.git"}}, mirrors)
+
+	_, err = parsePackageMirrors("https://github.com/a/b.git")
+	require.Error(t, err)
+}
+
+func Test_writePackageMirrors(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "MyApp.xcodeproj")
+	configDir := filepath.Join(projectPath, "project.xcworkspace", "xcshareddata", "swiftpm", "configuration")
+	require.NoError(t, os.MkdirAll(configDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "mirrors.json"), []byte(`{"object":[{"mirror":"https://old.example.com/b.git","original":"https://github.com/a/b.git"},{"mirror":"https://mirror.example.com/c.git","original":"https://github.com/a/c.git"}],"version":1}`), 0600))
+
+	pth, err := writePackageMirrors(projectPath, []PackageMirror{{Original: "https://github.com/a/b.git", Mirror: "https://mirror.example.com/b.git"}})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(configDir, "mirrors.json"), pth)
+
+	b, err := os.ReadFile(pth)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"object":[{"mirror":"https://mirror.example.com/b.git","original":"https://github.com/a/b.git"},{"mirror":"https://mirror.example.com/c.git","original":"https://github.com/a/c.git"}],"version":1}`, string(b))
+}
+
+func Test_isTransientPackageResolutionError(t *testing.T) {
+	require.True(t, isTransientPackageResolutionError("xcodebuild: error: Could not resolve package dependencies:\n  Failed to clone repository https://github.com/a/b.git"))
+	require.False(t, isTransientPackageResolutionError("xcodebuild: error: Could not resolve package dependencies:\n  the package dependency graph could not be resolved"))
+}
+
+func Test_addNetrcEntry(t *testing.T) {
+	netrcPath := filepath.Join(t.TempDir(), ".netrc")
+	require.NoError(t, os.WriteFile(netrcPath, []byte("machine api.example.com login user password secret\n"), 0600))
+
+	added, err := addNetrcEntry(netrcPath, "github.com", "token")
+	require.NoError(t, err)
+	require.True(t, added)
+
+	added, err = addNetrcEntry(netrcPath, "github.com", "other-token")
+	require.NoError(t, err)
+	require.False(t, added)
+
+	b, err := os.ReadFile(netrcPath)
+	require.NoError(t, err)
+	require.Equal(t, "machine api.example.com login user password secret\n\nmachine github.com\n  login oauth2\n  password token\n", string(b))
+}
+
+func Test_packageAuthOptions(t *testing.T) {
+	require.Nil(t, packageAuthOptions("xcode", "default"))
+	require.Equal(t, []string{"-scmProvider", "system", "-packageAuthorizationProvider", "netrc"}, packageAuthOptions("system", "netrc"))
+}
+
+func Test_packageValidationOptions(t *testing.T) {
+	require.Nil(t, packageValidationOptions(false, false))
+	require.Equal(t, []string{"-skipPackagePluginValidation", "-skipMacroValidation"}, packageValidationOptions(true, true))
+}
@@ -0,0 +1,200 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+const (
+	swiftSupportDir    = "SwiftSupport"
+	watchKitSupportDir = "WatchKitSupport2"
+	watchKitStubPath   = "_WatchKitStub/WK"
+	swiftLibraryPrefix = "libswift"
+	swiftLibraryExt    = ".dylib"
+	// swiftToolchainLibDir holds the Swift runtime libraries of the developer directory, per platform
+	swiftToolchainLibDir = "Toolchains/XcodeDefault.xctoolchain/usr/lib/swift-5.0"
+)
+
+// ipaSupportFiles are the App Store support files of an IPA and the files they should be consistent with.
+type ipaSupportFiles struct {
+	// EmbeddedSwiftLibraries are the Swift runtime libraries embedded in the app's Frameworks directory
+	EmbeddedSwiftLibraries []string
+	// SwiftSupport maps the SwiftSupport/<platform>/<library> paths to their checksums
+	SwiftSupport map[string]string
+	// WatchKitStubs maps the stub executables of the watch apps to their checksums
+	WatchKitStubs map[string]string
+	// WatchKitSupport is the checksum of the WatchKitSupport2/WK stub, empty if missing
+	WatchKitSupport string
+}
+
+// readIPASupportFiles collects the SwiftSupport and WatchKit stub related files of the IPA.
+func readIPASupportFiles(ipaPath string) (ipaSupportFiles, error) {
+	reader, err := archivezip.OpenReader(ipaPath)
+	if err != nil {
+		return ipaSupportFiles{}, fmt.Errorf("failed to open ipa (%s): %s", ipaPath, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	files := ipaSupportFiles{
+		SwiftSupport:  map[string]string{},
+		WatchKitStubs: map[string]string{},
+	}
+	for _, file := range reader.File {
+		name := file.Name
+		parts := strings.Split(name, "/")
+		switch {
+		case len(parts) == 4 && parts[0] == "Payload" && parts[2] == "Frameworks" && isSwiftLibrary(parts[3]):
+			files.EmbeddedSwiftLibraries = append(files.EmbeddedSwiftLibraries, parts[3])
+		case len(parts) == 3 && parts[0] == swiftSupportDir && isSwiftLibrary(parts[2]):
+			if files.SwiftSupport[name], err = zipFileChecksum(file); err != nil {
+				return ipaSupportFiles{}, err
+			}
+		case strings.HasPrefix(name, "Payload/") && strings.HasSuffix(name, ".app/"+watchKitStubPath) && strings.Contains(name, "/Watch/"):
+			if files.WatchKitStubs[name], err = zipFileChecksum(file); err != nil {
+				return ipaSupportFiles{}, err
+			}
+		case name == watchKitSupportDir+"/WK":
+			if files.WatchKitSupport, err = zipFileChecksum(file); err != nil {
+				return ipaSupportFiles{}, err
+			}
+		}
+	}
+	sort.Strings(files.EmbeddedSwiftLibraries)
+	return files, nil
+}
+
+func isSwiftLibrary(name string) bool {
+	return strings.HasPrefix(name, swiftLibraryPrefix) && strings.HasSuffix(name, swiftLibraryExt)
+}
+
+func zipFileChecksum(file *archivezip.File) (string, error) {
+	r, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %s", file.Name, err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", fmt.Errorf("failed to read %s: %s", file.Name, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// issues returns the missing SwiftSupport libraries and WatchKit stubs, which App Store Connect rejects,
+// and the support files not matching the toolchain's libraries (found by toolchainChecksum) or the watch app's stub.
+// The mismatches are not necessarily invalid, for example Xcode packages back-deployment libraries of other toolchain directories.
+func (f ipaSupportFiles) issues(toolchainChecksum func(platform, library string) (string, bool)) (missing []string, mismatches []string) {
+
+	supportedLibraries := map[string]bool{}
+	var supportPaths []string
+	for pth := range f.SwiftSupport {
+		supportedLibraries[path.Base(pth)] = true
+		supportPaths = append(supportPaths, pth)
+	}
+	sort.Strings(supportPaths)
+
+	for _, library := range f.EmbeddedSwiftLibraries {
+		if !supportedLibraries[library] {
+			missing = append(missing, fmt.Sprintf("%s is embedded in the app, but it is missing from the %s directory", library, swiftSupportDir))
+		}
+	}
+
+	for _, pth := range supportPaths {
+		platform := strings.Split(pth, "/")[1]
+		if checksum, ok := toolchainChecksum(platform, path.Base(pth)); ok && checksum != f.SwiftSupport[pth] {
+			mismatches = append(mismatches, fmt.Sprintf("%s does not match the library of the selected Xcode's toolchain", pth))
+		}
+	}
+
+	var stubPaths []string
+	for pth := range f.WatchKitStubs {
+		stubPaths = append(stubPaths, pth)
+	}
+	sort.Strings(stubPaths)
+
+	for _, pth := range stubPaths {
+		if f.WatchKitSupport == "" {
+			missing = append(missing, fmt.Sprintf("the watch app ships %s, but the %s/WK stub is missing", pth, watchKitSupportDir))
+		} else if f.WatchKitSupport != f.WatchKitStubs[pth] {
+			mismatches = append(mismatches, fmt.Sprintf("the %s/WK stub does not match the watch app's stub (%s)", watchKitSupportDir, pth))
+		}
+	}
+
+	return missing, mismatches
+}
+
+// xcodeDeveloperDir returns the developer directory of the selected Xcode, empty if it can't be determined.
+func xcodeDeveloperDir(cmdFactory command.Factory) string {
+	if developerDir := os.Getenv(developerDirEnvKey); developerDir != "" {
+		return developerDir
+	}
+
+	var stdout bytes.Buffer
+	cmd := cmdFactory.Create("xcode-select", []string{"-p"}, &command.Opts{Stdout: &stdout})
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(stdout.String())
+}
+
+// swiftToolchainChecksum returns a function looking up the checksums of the Swift libraries of the developer directory's toolchain.
+func swiftToolchainChecksum(developerDir string) func(platform, library string) (string, bool) {
+	return func(platform, library string) (string, bool) {
+		if developerDir == "" {
+			return "", false
+		}
+
+		b, err := os.ReadFile(filepath.Join(developerDir, swiftToolchainLibDir, platform, library))
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(b)), true
+	}
+}
+
+// checkAppStoreSupportFiles validates the SwiftSupport and WatchKit stub contents of the App Store IPAs,
+// which App Store Connect only reports after the upload ("Invalid Swift Support").
+func (s XcodebuildArchiver) checkAppStoreSupportFiles(exportOptionsPath, ipaExportDir string) error {
+	method, err := exportMethodFromExportOptions(exportOptionsPath)
+	if err != nil || !method.IsAppStore() {
+		return nil
+	}
+
+	ipaPaths, err := filepath.Glob(filepath.Join(escapeGlobPath(ipaExportDir), "*.ipa"))
+	if err != nil {
+		return err
+	}
+
+	toolchainChecksum := swiftToolchainChecksum(xcodeDeveloperDir(s.cmdFactory))
+	for _, ipaPath := range ipaPaths {
+		files, err := readIPASupportFiles(ipaPath)
+		if err != nil {
+			s.logger.Warnf("Failed to check the SwiftSupport of the IPA: %s", err)
+			continue
+		}
+
+		missing, mismatches := files.issues(toolchainChecksum)
+		if len(mismatches) > 0 {
+			s.logger.Warnf("The Swift or WatchKit support files of the IPA (%s) differ from the selected Xcode's files:\n%s", filepath.Base(ipaPath), issueList(mismatches))
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("invalid Swift or WatchKit support in the IPA (%s):\n%s", filepath.Base(ipaPath), issueList(missing))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestIPA(t *testing.T, files map[string]string) string {
+	ipaPath := filepath.Join(t.TempDir(), "Sample.ipa")
+	f, err := os.Create(ipaPath)
+	require.NoError(t, err)
+
+	w := archivezip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+	return ipaPath
+}
+
+func Test_ipaSupportFiles_issues(t *testing.T) {
+	ipaPath := writeTestIPA(t, map[string]string{
+		"Payload/Sample.app/Sample":                                    "app",
+		"Payload/Sample.app/Frameworks/libswiftCore.dylib":             "core",
+		"Payload/Sample.app/Frameworks/libswiftFoundation.dylib":       "foundation",
+		"SwiftSupport/iphoneos/libswiftCore.dylib":                     "core (Xcode 15)",
+		"Payload/Sample.app/Watch/Watch.app/_WatchKitStub/WK":          "stub",
+		"WatchKitSupport2/WK":                                          "old stub",
+		"Payload/Sample.app/Frameworks/Sample.framework/libswiftX.txt": "ignored",
+	})
+
+	files, err := readIPASupportFiles(ipaPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"libswiftCore.dylib", "libswiftFoundation.dylib"}, files.EmbeddedSwiftLibraries)
+
+	toolchainChecksum := func(platform, library string) (string, bool) {
+		if platform == "iphoneos" && library == "libswiftCore.dylib" {
+			return fmt.Sprintf("%x", sha256.Sum256([]byte("core (Xcode 16)"))), true
+		}
+		return "", false
+	}
+	missing, mismatches := files.issues(toolchainChecksum)
+	require.Equal(t, []string{
+		"libswiftFoundation.dylib is embedded in the app, but it is missing from the SwiftSupport directory",
+	}, missing)
+	require.Equal(t, []string{
+		"SwiftSupport/iphoneos/libswiftCore.dylib does not match the library of the selected Xcode's toolchain",
+		"the WatchKitSupport2/WK stub does not match the watch app's stub (Payload/Sample.app/Watch/Watch.app/_WatchKitStub/WK)",
+	}, mismatches)
+
+	validIPAPath := writeTestIPA(t, map[string]string{
+		"Payload/Sample.app/Frameworks/libswiftCore.dylib":    "core",
+		"SwiftSupport/iphoneos/libswiftCore.dylib":            "core",
+		"Payload/Sample.app/Watch/Watch.app/_WatchKitStub/WK": "stub",
+		"WatchKitSupport2/WK":                                 "stub",
+	})
+	files, err = readIPASupportFiles(validIPAPath)
+	require.NoError(t, err)
+	missing, mismatches = files.issues(swiftToolchainChecksum(""))
+	require.Empty(t, missing)
+	require.Empty(t, mismatches)
+}
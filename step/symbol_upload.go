@@ -0,0 +1,54 @@
+package step
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
+)
+
+// applySymbolUploadOption sets the uploadSymbols key of App Store export options,
+// the symbols are uploaded to App Store Connect by default.
+func applySymbolUploadOption(exportOpts exportoptions.ExportOptions, uploadSymbols bool) exportoptions.ExportOptions {
+	options, ok := exportOpts.(exportoptions.AppStoreOptionsModel)
+	if !ok {
+		return exportOpts
+	}
+	options.UploadSymbols = uploadSymbols
+	return options
+}
+
+// symbolsUploadedToAppStore returns false if the export options are App Store export options with disabled symbol upload.
+func symbolsUploadedToAppStore(exportOptionsPath string) (bool, error) {
+	exportOptions, err := plistutil.NewPlistDataFromFile(exportOptionsPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read export options: %s", err)
+	}
+
+	method, _ := exportOptions.GetString(exportoptions.MethodKey)
+	if !exportoptions.Method(method).IsAppStore() {
+		return true, nil
+	}
+	uploadSymbols, ok := exportOptions.GetBool(exportoptions.UploadSymbolsKey)
+	return !ok || uploadSymbols, nil
+}
+
+// printSymbolUpload explains where the symbols of App Store exports end up, if their upload to App Store Connect is disabled.
+func (s XcodebuildArchiver) printSymbolUpload(exportOptionsPath string, exportAllDsyms bool) {
+	uploaded, err := symbolsUploadedToAppStore(exportOptionsPath)
+	if err != nil {
+		s.logger.Debugf("%s", err)
+		return
+	}
+	if uploaded {
+		return
+	}
+
+	s.logger.Println()
+	s.logger.Printf("The symbols are not uploaded to App Store Connect (uploadSymbols: false), crash reports won't be symbolicated by Apple.")
+	if exportAllDsyms {
+		s.logger.Printf("The app and framework dSYMs are exported locally (%s) for a selective upload.", bitriseDSYMPthEnvKey)
+	} else {
+		s.logger.Printf("The app dSYMs are exported locally (%s) for a selective upload, enable export_all_dsyms to export the framework dSYMs too.", bitriseDSYMPthEnvKey)
+	}
+}
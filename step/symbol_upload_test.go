@@ -0,0 +1,42 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_applySymbolUploadOption(t *testing.T) {
+	appStoreOptions := applySymbolUploadOption(exportoptions.NewAppStoreOptions(), false).Hash()
+	require.Equal(t, false, appStoreOptions[exportoptions.UploadSymbolsKey])
+
+	appStoreOptions = applySymbolUploadOption(exportoptions.NewAppStoreOptions(), true).Hash()
+	require.NotContains(t, appStoreOptions, exportoptions.UploadSymbolsKey)
+
+	adHocOptions := applySymbolUploadOption(exportoptions.NewNonAppStoreOptions(exportoptions.MethodAdHoc), false).Hash()
+	require.NotContains(t, adHocOptions, exportoptions.UploadSymbolsKey)
+}
+
+func Test_symbolsUploadedToAppStore(t *testing.T) {
+	dir := t.TempDir()
+	for name, tt := range map[string]struct {
+		options exportoptions.ExportOptions
+		want    bool
+	}{
+		"app-store.plist":            {options: applySymbolUploadOption(exportoptions.NewAppStoreOptions(), true), want: true},
+		"app-store-no-symbols.plist": {options: applySymbolUploadOption(exportoptions.NewAppStoreOptions(), false), want: false},
+		"ad-hoc.plist":               {options: exportoptions.NewNonAppStoreOptions(exportoptions.MethodAdHoc), want: true},
+	} {
+		content, err := tt.options.String()
+		require.NoError(t, err)
+		pth := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(pth, []byte(content), 0644))
+
+		uploaded, err := symbolsUploadedToAppStore(pth)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, uploaded, name)
+	}
+}
@@ -0,0 +1,141 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	mixedTeamCheckOff  = "off"
+	mixedTeamCheckWarn = "warn"
+	mixedTeamCheckFail = "fail"
+)
+
+var codesignTeamIdentifierPattern = regexp.MustCompile(`(?m)^TeamIdentifier=(.+)$`)
+
+// codesignNotSignedOutput is printed by `codesign -dv` (with a non-zero exit code) for unsigned code objects.
+const codesignNotSignedOutput = "code object is not signed at all"
+
+// signingTeam is the team a bundle or an embedded framework of the archive is signed with.
+type signingTeam struct {
+	Name   string
+	Kind   string
+	TeamID string
+}
+
+// parseCodesignTeamIdentifier returns the TeamIdentifier of the `codesign -dv` output, empty if the binary is not signed by a team.
+func parseCodesignTeamIdentifier(output string) string {
+	match := codesignTeamIdentifierPattern.FindStringSubmatch(output)
+	if match == nil || match[1] == "not set" {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// codeSignatureTeamID returns the team of the code signature, empty if the code object is not signed.
+func (s XcodebuildArchiver) codeSignatureTeamID(pth string) (string, error) {
+	cmd := s.cmdFactory.Create("codesign", []string{"-dv", pth}, nil)
+	output, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil && strings.Contains(output, codesignNotSignedOutput) {
+		s.logger.Debugf("%s is not signed, skipping", pth)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %s, output: %s", cmd.PrintableCommandArgs(), err, output)
+	}
+	return parseCodesignTeamIdentifier(output), nil
+}
+
+// signingTeams returns the team of the applications, extensions and embedded frameworks of the archive.
+// The teams of the bundles are read from their provisioning profiles, the frameworks have no profile, their code signature is read.
+func (s XcodebuildArchiver) signingTeams(archive Archive) ([]signingTeam, error) {
+	var teams []signingTeam
+	for _, bundle := range archive.Bundles() {
+		teams = append(teams, signingTeam{Name: bundle.BundleIdentifier(), Kind: bundle.Kind, TeamID: bundleTeamID(bundle.IosBaseApplication)})
+	}
+
+	binaries, err := frameworkBinaries(archive.Application.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedded frameworks: %w", err)
+	}
+	for _, binary := range binaries {
+		framework := filepath.Dir(binary)
+		teamID, err := s.codeSignatureTeamID(framework)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, signingTeam{Name: filepath.Base(framework), Kind: bundleKindFramework, TeamID: teamID})
+	}
+	return teams, nil
+}
+
+// mixedTeams returns the bundles and frameworks signed with a team other than the main application's team,
+// the unsigned ones are ignored.
+func mixedTeams(teams []signingTeam, mainTeamID string) []signingTeam {
+	var mixed []signingTeam
+	for _, team := range teams {
+		if team.TeamID != "" && team.TeamID != mainTeamID {
+			mixed = append(mixed, team)
+		}
+	}
+	return mixed
+}
+
+// signingTeamsTable lists the teams of the archive, marking the ones different from the main application's team.
+func signingTeamsTable(teams []signingTeam, mainTeamID string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "BUNDLE\tTYPE\tTEAM")
+	for _, team := range teams {
+		teamID := team.TeamID
+		switch {
+		case teamID == "":
+			teamID = "-"
+		case teamID != mainTeamID:
+			teamID += " (mismatch)"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", team.Name, team.Kind, teamID)
+	}
+	_ = w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// checkMixedTeams compares the team of the main application with the teams of the embedded bundles and frameworks,
+// as App Store Connect rejects the archives signed by multiple teams.
+func (s XcodebuildArchiver) checkMixedTeams(archive Archive, action string) error {
+	if action == mixedTeamCheckOff {
+		return nil
+	}
+
+	mainTeamID, err := archive.TeamID()
+	if err != nil {
+		s.logger.Debugf("Skipping the signing team check: %s", err)
+		return nil
+	}
+
+	s.logger.Println()
+	s.logger.Infof("Checking the signing teams of the archive")
+
+	teams, err := s.signingTeams(archive)
+	if err != nil {
+		s.logger.Warnf("Failed to check the signing teams: %s", err)
+		return nil
+	}
+
+	mixed := mixedTeams(teams, mainTeamID)
+	if len(mixed) == 0 {
+		s.logger.Donef("Every bundle is signed with the team of the main application (%s)", mainTeamID)
+		return nil
+	}
+
+	table := signingTeamsTable(teams, mainTeamID)
+	if action == mixedTeamCheckWarn {
+		s.logger.Warnf("%d bundles are signed with a team other than the main application's team (%s), App Store Connect rejects such archives:", len(mixed), mainTeamID)
+		s.logger.Printf("%s", table)
+		return nil
+	}
+	return fmt.Errorf("%d bundles are signed with a team other than the main application's team (%s), App Store Connect rejects such archives:\n%s", len(mixed), mainTeamID, table)
+}
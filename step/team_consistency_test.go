@@ -0,0 +1,50 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseCodesignTeamIdentifier(t *testing.T) {
+	output := `Executable=/tmp/Sample.app/Frameworks/Lib.framework/Lib
+Identifier=io.bitrise.Lib
+Format=bundle with Mach-O thin (arm64)
+Signature size=4797
+TeamIdentifier=ABCD1234
+Sealed Resources version=2 rules=10 files=2`
+	require.Equal(t, "ABCD1234", parseCodesignTeamIdentifier(output))
+	require.Equal(t, "", parseCodesignTeamIdentifier("Signature=adhoc\nTeamIdentifier=not set"))
+	require.Equal(t, "", parseCodesignTeamIdentifier("code object is not signed at all"))
+}
+
+func Test_mixedTeams(t *testing.T) {
+	teams := []signingTeam{
+		{Name: "io.bitrise.app", Kind: bundleKindApplication, TeamID: "TEAM1"},
+		{Name: "io.bitrise.app.widget", Kind: bundleKindExtension, TeamID: "TEAM2"},
+		{Name: "Lib.framework", Kind: bundleKindFramework, TeamID: ""},
+	}
+
+	require.Equal(t, []signingTeam{teams[1]}, mixedTeams(teams, "TEAM1"))
+	require.Equal(t, `BUNDLE                 TYPE       TEAM
+io.bitrise.app         app        TEAM1
+io.bitrise.app.widget  extension  TEAM2 (mismatch)
+Lib.framework          framework  -`, signingTeamsTable(teams, "TEAM1"))
+}
+
+func TestXcodebuildArchiver_codeSignatureTeamID_unsigned(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho \"$2: code object is not signed at all\" >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "codesign"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	archiver := XcodebuildArchiver{logger: log.NewLogger(), cmdFactory: command.NewFactory(env.NewRepository())}
+	teamID, err := archiver.codeSignatureTeamID("/tmp/Sample.app/Frameworks/Lib.framework")
+	require.NoError(t, err)
+	require.Empty(t, teamID)
+}
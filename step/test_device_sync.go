@@ -0,0 +1,168 @@
+package step
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient/appstoreconnect"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+)
+
+// missingTestDevices returns the test devices, which are not enabled on the Developer Portal.
+// The Developer Portal lists only the enabled devices, so the disabled ones are missing too.
+func missingTestDevices(testDevices []devportalservice.TestDevice, devPortalDevices []appstoreconnect.Device) []devportalservice.TestDevice {
+	var missing []devportalservice.TestDevice
+	for _, testDevice := range testDevices {
+		registered := false
+		for _, devPortalDevice := range devPortalDevices {
+			if devportalservice.IsEqualUDID(devPortalDevice.Attributes.UDID, testDevice.DeviceID) {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			missing = append(missing, testDevice)
+		}
+	}
+	return missing
+}
+
+// TestDeviceSyncer registers the test devices with the Apple Developer Portal, instead of the automatic code signing,
+// according to the device sync policy of the Step.
+type TestDeviceSyncer struct {
+	credentials devportalservice.Credentials
+	teamID      string
+	testDevices []devportalservice.TestDevice
+	// ignoreDisabled skips the devices rejected by the Developer Portal (disabled or invalid devices), instead of failing
+	ignoreDisabled bool
+	// dryRun only lists the devices, which would be registered
+	dryRun bool
+
+	devPortalClientFactory devportalclient.Factory
+}
+
+// syncTestDevices registers the test devices, which are missing from the Developer Portal, and returns the registered ones.
+func syncTestDevices(client autocodesign.DevPortalClient, testDevices []devportalservice.TestDevice, ignoreDisabled, dryRun bool, logger log.Logger) ([]devportalservice.TestDevice, error) {
+	// the iOS device platform includes the Apple Watch, iPad, iPhone, iPod and Apple TV devices
+	devPortalDevices, err := client.ListDevices("", appstoreconnect.IOSDevice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	logger.Printf("%d devices are registered on the Apple Developer Portal", len(devPortalDevices))
+
+	missing := missingTestDevices(testDevices, devPortalDevices)
+	if len(missing) == 0 {
+		logger.Printf("Every test device (%d) is registered", len(testDevices))
+		return nil, nil
+	}
+
+	if dryRun {
+		logger.Printf("Dry run, the following test devices would be registered:")
+		for _, device := range missing {
+			logger.Printf("- %s, %s, UDID (%s)", device.Title, device.DeviceType, device.DeviceID)
+		}
+		return nil, nil
+	}
+
+	var registered []devportalservice.TestDevice
+	var rejected []string
+	for _, device := range missing {
+		logger.Printf("Registering device: %s, %s, UDID (%s)", device.Title, device.DeviceType, device.DeviceID)
+		if _, err := client.RegisterDevice(device); err != nil {
+			var registrationError appstoreconnect.DeviceRegistrationError
+			if !errors.As(err, &registrationError) {
+				return registered, fmt.Errorf("failed to register device (%s): %w", device.DeviceID, err)
+			}
+			if ignoreDisabled {
+				logger.Warnf("Failed to register device (%s), ignoring it (can be caused by a disabled device, an invalid UDID or a Mac device): %s", device.DeviceID, registrationError.Reason)
+				continue
+			}
+			rejected = append(rejected, device.DeviceID)
+			logger.Errorf("Failed to register device (%s): %s", device.DeviceID, registrationError.Reason)
+			continue
+		}
+		registered = append(registered, device)
+	}
+
+	if len(rejected) > 0 {
+		return registered, fmt.Errorf("the Developer Portal rejected %d test device(s): %s, enable the disabled devices on the Developer Portal or remove them from the test devices", len(rejected), strings.Join(rejected, ", "))
+	}
+	return registered, nil
+}
+
+// Sync registers the missing test devices with the Developer Portal.
+func (s TestDeviceSyncer) Sync(logger log.Logger) error {
+	logger.Println()
+	logger.Infof("Syncing %d test device(s) with the Apple Developer Portal", len(s.testDevices))
+
+	client, err := s.devPortalClientFactory.Create(s.credentials, s.teamID)
+	if err != nil {
+		return err
+	}
+	if err := client.Login(); err != nil {
+		return fmt.Errorf("Developer Portal client login failed: %w", err)
+	}
+
+	registered, err := syncTestDevices(client, s.testDevices, s.ignoreDisabled, s.dryRun, logger)
+	if err != nil {
+		return err
+	}
+	if len(registered) > 0 {
+		logger.Donef("%d test device(s) registered", len(registered))
+	}
+	return nil
+}
+
+// createTestDeviceSyncer returns the test device syncer if the test devices are registered and any archived scheme
+// is exported with a distribution method requiring a device list, otherwise nil.
+func (s XcodebuildArchiveConfigParser) createTestDeviceSyncer(config Config, serviceConnection *devportalservice.AppleDeveloperConnection) (*TestDeviceSyncer, error) {
+	if !config.RegisterTestDevices {
+		return nil, nil
+	}
+
+	var distributionTypes []autocodesign.DistributionType
+	for _, entry := range config.SchemeMatrix {
+		distributionTypes = append(distributionTypes, autocodesign.DistributionType(entry.ExportMethod))
+	}
+	if !autocodesign.DistributionTypeRequiresDeviceList(distributionTypes) {
+		return nil, nil
+	}
+
+	testDevices, err := loadTestDevices(config, serviceConnection)
+	if err != nil {
+		return nil, err
+	}
+	if len(testDevices) == 0 {
+		return nil, nil
+	}
+
+	authType, err := codesignAuthType(config.CodeSigningAuthSource)
+	if err != nil {
+		return nil, err
+	}
+	_, credentials, err := selectConnectionCredentials(authType, serviceConnection, config.Inputs, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TestDeviceSyncer{
+		credentials:            credentials,
+		teamID:                 config.ExportDevelopmentTeam,
+		testDevices:            testDevices,
+		ignoreDisabled:         config.IgnoreDisabledTestDevices,
+		dryRun:                 config.TestDeviceSyncDryRun || config.DryRun,
+		devPortalClientFactory: devportalclient.NewFactory(s.logger, s.fileManager),
+	}, nil
+}
+
+// SyncTestDevices registers the test devices with the Developer Portal before the code signing of the archived schemes.
+func (s XcodebuildArchiver) SyncTestDevices(syncer *TestDeviceSyncer) error {
+	if syncer == nil {
+		return nil
+	}
+	return syncer.Sync(s.logger)
+}
@@ -0,0 +1,53 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign"
+	"github.com/bitrise-io/go-xcode/v2/autocodesign/devportalclient/appstoreconnect"
+	"github.com/bitrise-io/go-xcode/v2/devportalservice"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_syncTestDevices(t *testing.T) {
+	registered := devportalservice.TestDevice{DeviceID: "00008030-000000000000001E", Title: "Registered"}
+	missing := devportalservice.TestDevice{DeviceID: "00008030-000000000000002E", Title: "Missing"}
+	disabled := devportalservice.TestDevice{DeviceID: "00008030-000000000000003E", Title: "Disabled"}
+	testDevices := []devportalservice.TestDevice{registered, missing, disabled}
+
+	newClient := func(t *testing.T) *autocodesign.MockDevPortalClient {
+		client := autocodesign.NewMockDevPortalClient(t)
+		client.On("ListDevices", "", appstoreconnect.IOSDevice).Return([]appstoreconnect.Device{
+			{Attributes: appstoreconnect.DeviceAttributes{UDID: "00008030000000000000001e", Status: appstoreconnect.Enabled}},
+		}, nil)
+		return client
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		client := newClient(t)
+		got, err := syncTestDevices(client, testDevices, true, true, log.NewLogger())
+		require.NoError(t, err)
+		require.Empty(t, got)
+		client.AssertNotCalled(t, "RegisterDevice", mock.Anything)
+	})
+
+	t.Run("disabled devices ignored", func(t *testing.T) {
+		client := newClient(t)
+		client.On("RegisterDevice", missing).Return(&appstoreconnect.Device{}, nil)
+		client.On("RegisterDevice", disabled).Return(nil, appstoreconnect.DeviceRegistrationError{Reason: "device already exists"})
+		got, err := syncTestDevices(client, testDevices, true, false, log.NewLogger())
+		require.NoError(t, err)
+		require.Equal(t, []devportalservice.TestDevice{missing}, got)
+	})
+
+	t.Run("disabled devices fail", func(t *testing.T) {
+		client := newClient(t)
+		client.On("RegisterDevice", missing).Return(&appstoreconnect.Device{}, nil)
+		client.On("RegisterDevice", disabled).Return(nil, appstoreconnect.DeviceRegistrationError{Reason: "device already exists"})
+		got, err := syncTestDevices(client, testDevices, false, false, log.NewLogger())
+		require.EqualError(t, err, "the Developer Portal rejected 1 test device(s): 00008030-000000000000003E, enable the disabled devices on the Developer Portal or remove them from the test devices")
+		require.Equal(t, []devportalservice.TestDevice{missing}, got)
+	})
+}
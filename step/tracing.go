@@ -0,0 +1,266 @@
+package step
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/env"
+)
+
+const (
+	otlpTracesEndpointEnvKey = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	otlpEndpointEnvKey       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpHeadersEnvKey        = "OTEL_EXPORTER_OTLP_HEADERS"
+	otelServiceNameEnvKey    = "OTEL_SERVICE_NAME"
+	otelResourceAttrsEnvKey  = "OTEL_RESOURCE_ATTRIBUTES"
+	// traceParentEnvKey is a W3C trace context, the Step's spans are added to this trace if set
+	traceParentEnvKey = "TRACEPARENT"
+
+	defaultTraceServiceName = "steps-xcode-archive"
+	traceExportTimeout      = 10 * time.Second
+
+	// OTLP span kind and status codes
+	otlpSpanKindInternal = 1
+	otlpStatusCodeOK     = 1
+	otlpStatusCodeError  = 2
+)
+
+// traceResourceEnvKeys are the Bitrise build environment variables added to the trace resource attributes.
+var traceResourceEnvKeys = map[string]string{
+	"bitrise.app_slug":         "BITRISE_APP_SLUG",
+	"bitrise.build_slug":       "BITRISE_BUILD_SLUG",
+	"bitrise.build_number":     "BITRISE_BUILD_NUMBER",
+	"bitrise.workflow":         "BITRISE_TRIGGERED_WORKFLOW_ID",
+	"bitrise.stack":            "BITRISE_STACK_ID",
+	"vcs.repository.ref.name":  "BITRISE_GIT_BRANCH",
+	"vcs.repository.ref.value": "BITRISE_GIT_COMMIT",
+}
+
+type traceSpan struct {
+	name         string
+	spanID       string
+	parentSpanID string
+	start        time.Time
+	end          time.Time
+	err          error
+}
+
+// Tracer records the Step phases as spans and exports them to an OpenTelemetry collector (OTLP/HTTP with JSON encoding).
+// Spans can be started and ended concurrently.
+type Tracer struct {
+	endpoint   string
+	headers    map[string]string
+	attributes map[string]string
+	client     *http.Client
+	now        func() time.Time
+
+	traceID      string
+	parentSpanID string
+	root         traceSpan
+
+	mu    sync.Mutex
+	spans []traceSpan
+}
+
+// NewTracerFromEnv returns a Tracer configured with the standard OpenTelemetry environment variables,
+// or nil if no OTLP endpoint is configured.
+func NewTracerFromEnv(envRepository env.Repository) (*Tracer, error) {
+	endpoint := envRepository.Get(otlpTracesEndpointEnvKey)
+	if endpoint == "" {
+		if baseEndpoint := envRepository.Get(otlpEndpointEnvKey); baseEndpoint != "" {
+			endpoint = strings.TrimSuffix(baseEndpoint, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	headers, err := parseKeyValueList(envRepository.Get(otlpHeadersEnvKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", otlpHeadersEnvKey, err)
+	}
+
+	attributes, err := parseKeyValueList(envRepository.Get(otelResourceAttrsEnvKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", otelResourceAttrsEnvKey, err)
+	}
+	for attribute, envKey := range traceResourceEnvKeys {
+		if value := envRepository.Get(envKey); value != "" {
+			attributes[attribute] = value
+		}
+	}
+	attributes["service.name"] = defaultTraceServiceName
+	if serviceName := envRepository.Get(otelServiceNameEnvKey); serviceName != "" {
+		attributes["service.name"] = serviceName
+	}
+
+	tracer := &Tracer{
+		endpoint:   endpoint,
+		headers:    headers,
+		attributes: attributes,
+		client:     &http.Client{Timeout: traceExportTimeout},
+		now:        time.Now,
+	}
+
+	if traceID, parentSpanID, ok := parseTraceParent(envRepository.Get(traceParentEnvKey)); ok {
+		tracer.traceID, tracer.parentSpanID = traceID, parentSpanID
+	} else if tracer.traceID, err = randomHexID(16); err != nil {
+		return nil, err
+	}
+
+	rootSpanID, err := randomHexID(8)
+	if err != nil {
+		return nil, err
+	}
+	tracer.root = traceSpan{name: "xcode-archive", spanID: rootSpanID, parentSpanID: tracer.parentSpanID, start: tracer.now()}
+
+	return tracer, nil
+}
+
+// StartSpan starts a span of a Step phase and returns the function ending it.
+func (t *Tracer) StartSpan(name string) func(err error) {
+	spanID, err := randomHexID(8)
+	if err != nil {
+		return func(error) {}
+	}
+
+	span := traceSpan{name: name, spanID: spanID, parentSpanID: t.root.spanID, start: t.now()}
+	return func(err error) {
+		span.end = t.now()
+		span.err = err
+		t.mu.Lock()
+		t.spans = append(t.spans, span)
+		t.mu.Unlock()
+	}
+}
+
+// Export ends the Step's root span and sends the recorded spans to the collector.
+func (t *Tracer) Export(stepErr error) error {
+	t.root.end = t.now()
+	t.root.err = stepErr
+
+	b, err := json.Marshal(t.otlpRequest())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector (%s) responded with status code: %d", t.endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *Tracer) otlpRequest() map[string]interface{} {
+	var attributes []map[string]interface{}
+	for key, value := range t.attributes {
+		attributes = append(attributes, otlpAttribute(key, value))
+	}
+
+	t.mu.Lock()
+	recorded := append([]traceSpan{t.root}, t.spans...)
+	t.mu.Unlock()
+
+	var spans []map[string]interface{}
+	for _, span := range recorded {
+		spans = append(spans, t.otlpSpan(span))
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{"attributes": attributes},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": defaultTraceServiceName},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+func (t *Tracer) otlpSpan(span traceSpan) map[string]interface{} {
+	status := map[string]interface{}{"code": otlpStatusCodeOK}
+	if span.err != nil {
+		status = map[string]interface{}{"code": otlpStatusCodeError, "message": span.err.Error()}
+	}
+
+	otlpSpan := map[string]interface{}{
+		"traceId":           t.traceID,
+		"spanId":            span.spanID,
+		"name":              span.name,
+		"kind":              otlpSpanKindInternal,
+		"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+		"status":            status,
+	}
+	if span.parentSpanID != "" {
+		otlpSpan["parentSpanId"] = span.parentSpanID
+	}
+	return otlpSpan
+}
+
+func otlpAttribute(key, value string) map[string]interface{} {
+	return map[string]interface{}{"key": key, "value": map[string]interface{}{"stringValue": value}}
+}
+
+// parseKeyValueList parses the comma-separated key=value list format of the OpenTelemetry environment variables.
+func parseKeyValueList(list string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(item, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%s is not a key=value pair", item)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// parseTraceParent parses a W3C traceparent header value (version-traceid-parentid-flags).
+func parseTraceParent(traceParent string) (string, string, bool) {
+	components := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(components) != 4 || len(components[1]) != 32 || len(components[2]) != 16 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(components[1]); err != nil {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(components[2]); err != nil {
+		return "", "", false
+	}
+	return components[1], components[2], true
+}
+
+func randomHexID(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,126 @@
+package step
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEnvRepository map[string]string
+
+func (r testEnvRepository) List() []string {
+	var envs []string
+	for key, value := range r {
+		envs = append(envs, key+"="+value)
+	}
+	return envs
+}
+
+func (r testEnvRepository) Unset(key string) error {
+	delete(r, key)
+	return nil
+}
+
+func (r testEnvRepository) Get(key string) string {
+	return r[key]
+}
+
+func (r testEnvRepository) Set(key, value string) error {
+	r[key] = value
+	return nil
+}
+
+func TestNewTracerFromEnv_disabled(t *testing.T) {
+	tracer, err := NewTracerFromEnv(testEnvRepository{})
+	require.NoError(t, err)
+	require.Nil(t, tracer)
+}
+
+func TestTracer_Export(t *testing.T) {
+	var (
+		authorization string
+		request       struct {
+			ResourceSpans []struct {
+				Resource struct {
+					Attributes []struct {
+						Key   string `json:"key"`
+						Value struct {
+							StringValue string `json:"stringValue"`
+						} `json:"value"`
+					} `json:"attributes"`
+				} `json:"resource"`
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID      string `json:"traceId"`
+						SpanID       string `json:"spanId"`
+						ParentSpanID string `json:"parentSpanId"`
+						Name         string `json:"name"`
+						Status       struct {
+							Code    int    `json:"code"`
+							Message string `json:"message"`
+						} `json:"status"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &request))
+	}))
+	defer server.Close()
+
+	tracer, err := NewTracerFromEnv(testEnvRepository{
+		otlpEndpointEnvKey: server.URL + "/",
+		otlpHeadersEnvKey:  "Authorization=Bearer token",
+		traceParentEnvKey:  "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"BITRISE_APP_SLUG": "app-slug",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tracer)
+
+	tracer.StartSpan(phaseArchive)(nil)
+	tracer.StartSpan(phaseExport)(errors.New("export failed"))
+	require.NoError(t, tracer.Export(errors.New("export failed")))
+
+	require.Equal(t, "Bearer token", authorization)
+
+	attributes := map[string]string{}
+	for _, attribute := range request.ResourceSpans[0].Resource.Attributes {
+		attributes[attribute.Key] = attribute.Value.StringValue
+	}
+	require.Equal(t, map[string]string{"service.name": defaultTraceServiceName, "bitrise.app_slug": "app-slug"}, attributes)
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+	root := spans[0]
+	require.Equal(t, "xcode-archive", root.Name)
+	require.Equal(t, "b7ad6b7169203331", root.ParentSpanID)
+	for _, span := range spans {
+		require.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+	}
+	require.Equal(t, phaseArchive, spans[1].Name)
+	require.Equal(t, root.SpanID, spans[1].ParentSpanID)
+	require.Equal(t, otlpStatusCodeOK, spans[1].Status.Code)
+	require.Equal(t, phaseExport, spans[2].Name)
+	require.Equal(t, otlpStatusCodeError, spans[2].Status.Code)
+	require.Equal(t, "export failed", spans[2].Status.Message)
+}
+
+func Test_parseTraceParent(t *testing.T) {
+	traceID, spanID, ok := parseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	require.True(t, ok)
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", traceID)
+	require.Equal(t, "b7ad6b7169203331", spanID)
+
+	_, _, ok = parseTraceParent("invalid")
+	require.False(t, ok)
+}
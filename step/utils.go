@@ -11,10 +11,11 @@ import (
 	"github.com/bitrise-io/go-utils/stringutil"
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/bitrise-io/go-xcode/exportoptions"
+	"github.com/bitrise-io/go-xcode/plistutil"
 )
 
 func generateAdditionalOptions(platform string, customOptions []string) []string {
-	destination := "generic/platform=" + platform
+	destination := genericDestination(Platform(platform))
 	destinationOptions := []string{"-destination", destination}
 
 	var options []string
@@ -46,6 +47,16 @@ func determineExportMethod(desiredExportMethod string, archiveExportMethod expor
 	return exportMethod, nil
 }
 
+func exportMethodFromExportOptions(exportOptionsPath string) (exportoptions.Method, error) {
+	exportOptions, err := plistutil.NewPlistDataFromFile(exportOptionsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read export options: %s", err)
+	}
+
+	method, _ := exportOptions.GetString(exportoptions.MethodKey)
+	return exportoptions.Method(method), nil
+}
+
 func printLastLinesOfXcodebuildLog(logger log.Logger, xcodebuildLog string, isXcodebuildSuccess bool) {
 	const lastLinesMsg = "\nLast lines of the Xcode log:"
 	if isXcodebuildSuccess {
@@ -91,3 +102,23 @@ func findIDEDistrubutionLogsPath(output string, logger log.Logger) (string, erro
 
 	return "", nil
 }
+
+func escapeGlobPath(path string) string {
+	var escaped strings.Builder
+	for _, ch := range path {
+		if ch == '[' || ch == ']' || ch == '-' || ch == '*' || ch == '?' || ch == '\\' {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(ch)
+	}
+	return escaped.String()
+}
+
+// quoteCommandArg single quotes the argument if it contains whitespace, quotes or shell special characters,
+// so that the printed command can be copied into a shell.
+func quoteCommandArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`&|;<>()*?[]{}#~!") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -1,12 +1,27 @@
 package step
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/stretchr/testify/require"
 )
 
+func Test_escapeGlobPath(t *testing.T) {
+	for _, name := range []string{"Sample App", "Sample's \"Ápp\"", "[Release] Ünïcode-App", "Sample*?.app"} {
+		dir := filepath.Join(t.TempDir(), name)
+		ipaPath := filepath.Join(dir, "Sample.ipa")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(ipaPath, nil, 0644))
+
+		matches, err := filepath.Glob(filepath.Join(escapeGlobPath(dir), "*.ipa"))
+		require.NoError(t, err)
+		require.Equal(t, []string{ipaPath}, matches, name)
+	}
+}
+
 func Test_generateAdditionalOptions(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -31,6 +46,11 @@ func Test_generateAdditionalOptions(t *testing.T) {
 			customOptions: []string{"-scmProvider", "system", "-destination", "generic/platform=iOS"},
 			want:          []string{"-scmProvider", "system", "-destination", "generic/platform=iOS"},
 		},
+		{
+			name:     "macOS",
+			platform: "OS X",
+			want:     []string{"-destination", "generic/platform=macOS"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
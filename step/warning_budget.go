@@ -0,0 +1,134 @@
+package step
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	warningBudgetActionFail     = "fail"
+	warningBudgetActionAnnotate = "annotate"
+
+	warningBudgetAnnotationContext = "xcode-archive-warning-budget"
+	// maxListedBudgetIssues is the number of new deprecation warnings listed in the log and in the annotation
+	maxListedBudgetIssues = 10
+)
+
+type warningBudgetOpts struct {
+	// MaxWarnings is the maximum number of allowed warnings, -1 means no limit
+	MaxWarnings           int
+	FailOnNewDeprecations bool
+	// DeprecationBaselinePath is a build issues file of an earlier build, only the deprecations missing from it are new
+	DeprecationBaselinePath string
+	Action                  string
+}
+
+func (o warningBudgetOpts) enabled() bool {
+	return o.MaxWarnings >= 0 || o.FailOnNewDeprecations
+}
+
+// readBuildIssues reads a build issues file exported by an earlier build (BITRISE_XCODEBUILD_ISSUES_PATH).
+func readBuildIssues(pth string) (BuildIssues, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return BuildIssues{}, err
+	}
+
+	var issues BuildIssues
+	if err := json.Unmarshal(content, &issues); err != nil {
+		return BuildIssues{}, fmt.Errorf("failed to parse build issues file (%s): %s", pth, err)
+	}
+	return issues, nil
+}
+
+// deprecationKey identifies a deprecation warning across builds: the line numbers are ignored,
+// as unrelated changes of the file move the existing warnings.
+func deprecationKey(issue BuildIssue) string {
+	file := issue.Location
+	if i := strings.LastIndex(file, ":"); i != -1 {
+		file = file[:i]
+	}
+	return file + "\x00" + issue.Message
+}
+
+// newDeprecationWarnings returns the deprecation warnings missing from the baseline.
+func newDeprecationWarnings(issues, baseline BuildIssues) []BuildIssue {
+	known := map[string]bool{}
+	for _, warning := range baseline.DeprecationWarnings() {
+		known[deprecationKey(warning)] = true
+	}
+
+	var warnings []BuildIssue
+	for _, warning := range issues.DeprecationWarnings() {
+		if !known[deprecationKey(warning)] {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// warningBudgetViolations returns the description of each exceeded threshold.
+func warningBudgetViolations(issues BuildIssues, newDeprecations []BuildIssue, opts warningBudgetOpts) []string {
+	var violations []string
+	if opts.MaxWarnings >= 0 && len(issues.Warnings) > opts.MaxWarnings {
+		violations = append(violations, fmt.Sprintf("the number of warnings (%d) exceeds the limit of %d", len(issues.Warnings), opts.MaxWarnings))
+	}
+	if opts.FailOnNewDeprecations && len(newDeprecations) > 0 {
+		violations = append(violations, fmt.Sprintf("%d new deprecation warnings found", len(newDeprecations)))
+	}
+	return violations
+}
+
+func (s XcodebuildArchiver) checkWarningBudget(issues BuildIssues, opts warningBudgetOpts) error {
+	var newDeprecations []BuildIssue
+	if opts.FailOnNewDeprecations {
+		baseline := BuildIssues{}
+		if opts.DeprecationBaselinePath != "" {
+			var err error
+			if baseline, err = readBuildIssues(opts.DeprecationBaselinePath); err != nil {
+				return fmt.Errorf("failed to read deprecation baseline: %w", err)
+			}
+		}
+		newDeprecations = newDeprecationWarnings(issues, baseline)
+	}
+
+	violations := warningBudgetViolations(issues, newDeprecations, opts)
+	if len(violations) == 0 {
+		s.logger.Donef("Warning budget check passed.")
+		return nil
+	}
+
+	var listed []string
+	for i, warning := range newDeprecations {
+		if i == maxListedBudgetIssues {
+			listed = append(listed, fmt.Sprintf("... and %d more", len(newDeprecations)-maxListedBudgetIssues))
+			break
+		}
+		listed = append(listed, fmt.Sprintf("%s (%s)", warning.Message, warning.Location))
+	}
+	if len(listed) > 0 {
+		s.logger.Warnf("New deprecation warnings:")
+		for _, warning := range listed {
+			s.logger.Printf("- %s", warning)
+		}
+	}
+
+	message := "Warning budget exceeded: " + strings.Join(violations, ", ")
+	if opts.Action == warningBudgetActionFail {
+		return fmt.Errorf("%s", message)
+	}
+
+	s.logger.Warnf("%s", message)
+
+	markdown := "**" + message + "**\n"
+	for _, warning := range listed {
+		markdown += fmt.Sprintf("- %s\n", warning)
+	}
+	if _, err := s.annotateBuild(markdown, "warning", warningBudgetAnnotationContext); err != nil {
+		s.logger.Warnf("Failed to annotate the build: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package step
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDeprecationWarnings(t *testing.T) {
+	baseline := BuildIssues{Warnings: []BuildIssue{
+		{Type: deprecationIssueType, Message: "'UIWebView' was deprecated in iOS 12.0", Location: "/App/WebView.swift:12"},
+	}}
+	issues := BuildIssues{Warnings: []BuildIssue{
+		{Type: deprecationIssueType, Message: "'UIWebView' was deprecated in iOS 12.0", Location: "/App/WebView.swift:20"},
+		{Type: deprecationIssueType, Message: "'openURL' was deprecated in iOS 10.0", Location: "/App/AppDelegate.swift:8"},
+		{Type: "Swift Compiler Warning", Message: "variable 'x' was never used", Location: "/App/AppDelegate.swift:9"},
+	}}
+
+	require.Equal(t, []BuildIssue{
+		{Type: deprecationIssueType, Message: "'openURL' was deprecated in iOS 10.0", Location: "/App/AppDelegate.swift:8"},
+	}, newDeprecationWarnings(issues, baseline))
+	require.Len(t, newDeprecationWarnings(issues, BuildIssues{}), 2)
+}
+
+func Test_warningBudgetViolations(t *testing.T) {
+	issues := BuildIssues{Warnings: []BuildIssue{{Message: "a"}, {Message: "b"}}}
+	newDeprecations := []BuildIssue{{Type: deprecationIssueType, Message: "c"}}
+
+	require.Empty(t, warningBudgetViolations(issues, newDeprecations, warningBudgetOpts{MaxWarnings: -1}))
+	require.Empty(t, warningBudgetViolations(issues, nil, warningBudgetOpts{MaxWarnings: 2, FailOnNewDeprecations: true}))
+	require.Equal(t, []string{
+		"the number of warnings (2) exceeds the limit of 0",
+		"1 new deprecation warnings found",
+	}, warningBudgetViolations(issues, newDeprecations, warningBudgetOpts{MaxWarnings: 0, FailOnNewDeprecations: true}))
+}
+
+func TestXcodebuildArchiver_checkWarningBudget(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "baseline.build-issues.json")
+	require.NoError(t, os.WriteFile(baselinePath, []byte(`{"warnings":[{"type":"Deprecation","message":"'openURL' was deprecated in iOS 10.0","location":"/App/AppDelegate.swift:3"}],"errors":[]}`), 0644))
+
+	archiver := XcodebuildArchiver{logger: log.NewLogger()}
+	issues := BuildIssues{Warnings: []BuildIssue{
+		{Type: deprecationIssueType, Message: "'openURL' was deprecated in iOS 10.0", Location: "/App/AppDelegate.swift:8"},
+	}}
+
+	require.NoError(t, archiver.checkWarningBudget(issues, warningBudgetOpts{MaxWarnings: -1, FailOnNewDeprecations: true, DeprecationBaselinePath: baselinePath, Action: warningBudgetActionFail}))
+	require.EqualError(t, archiver.checkWarningBudget(issues, warningBudgetOpts{MaxWarnings: 0, Action: warningBudgetActionFail}), "Warning budget exceeded: the number of warnings (1) exceeds the limit of 0")
+}
@@ -0,0 +1,59 @@
+package step
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// watchCompanionAppBundleIDKey is the bundle ID of the iOS app in the watch app's Info.plist.
+	watchCompanionAppBundleIDKey = "WKCompanionAppBundleIdentifier"
+	// watchAppBundleIDKey is the bundle ID of the watch app in the WatchKit extension's NSExtensionAttributes.
+	watchAppBundleIDKey = "WKAppBundleIdentifier"
+)
+
+// watchAppIssues returns the issues of the watch app's and its extensions' relation to the iOS app, nil if the archive has no watch app.
+func watchAppIssues(archive Archive) []string {
+	watchApp := archive.Application.WatchApplication
+	if watchApp == nil {
+		return nil
+	}
+
+	appBundleID := archive.Application.BundleIdentifier()
+	watchBundleID := watchApp.BundleIdentifier()
+
+	var issues []string
+	if !strings.HasPrefix(watchBundleID, appBundleID+".") {
+		issues = append(issues, fmt.Sprintf("the watch app's bundle ID (%s) is not prefixed by the iOS app's bundle ID (%s.)", watchBundleID, appBundleID))
+	}
+	if companionBundleID, _ := watchApp.InfoPlist.GetString(watchCompanionAppBundleIDKey); companionBundleID == "" {
+		issues = append(issues, fmt.Sprintf("the watch app's %s is missing, it should be the iOS app's bundle ID (%s)", watchCompanionAppBundleIDKey, appBundleID))
+	} else if companionBundleID != appBundleID {
+		issues = append(issues, fmt.Sprintf("the watch app's %s (%s) does not match the iOS app's bundle ID (%s)", watchCompanionAppBundleIDKey, companionBundleID, appBundleID))
+	}
+
+	for _, extension := range watchApp.Extensions {
+		extensionBundleID := extension.BundleIdentifier()
+		if !strings.HasPrefix(extensionBundleID, watchBundleID+".") {
+			issues = append(issues, fmt.Sprintf("the watch extension's bundle ID (%s) is not prefixed by the watch app's bundle ID (%s.)", extensionBundleID, watchBundleID))
+		}
+
+		// only the WatchKit extensions reference the watch app, other extensions (for example widgets) don't have the key
+		attributes, _ := extension.InfoPlist.GetMapStringInterface("NSExtension")
+		if attributes, ok := attributes.GetMapStringInterface("NSExtensionAttributes"); ok {
+			if referencedBundleID, ok := attributes.GetString(watchAppBundleIDKey); ok && referencedBundleID != watchBundleID {
+				issues = append(issues, fmt.Sprintf("the watch extension's (%s) %s (%s) does not match the watch app's bundle ID (%s)", extensionBundleID, watchAppBundleIDKey, referencedBundleID, watchBundleID))
+			}
+		}
+	}
+	return issues
+}
+
+// checkWatchApp fails if the watch app's or its extensions' bundle IDs don't match the iOS app, the export would be rejected anyway.
+func (s XcodebuildArchiver) checkWatchApp(archive Archive) error {
+	issues := watchAppIssues(archive)
+	if len(issues) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid watch app (%s):\n%s", archive.Application.WatchApplication.BundleIdentifier(), issueList(issues))
+}
@@ -0,0 +1,81 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func testWatchAppArchive(watchInfoPlist, extensionInfoPlist plistutil.PlistData) Archive {
+	return NewArchive(xcarchive.IosArchive{
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: xcarchive.IosBaseApplication{
+				InfoPlist: plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.sample"},
+			},
+			WatchApplication: &xcarchive.IosWatchApplication{
+				IosBaseApplication: xcarchive.IosBaseApplication{InfoPlist: watchInfoPlist},
+				Extensions: []xcarchive.IosExtension{
+					{IosBaseApplication: xcarchive.IosBaseApplication{InfoPlist: extensionInfoPlist}},
+				},
+			},
+		},
+	})
+}
+
+func watchExtensionInfoPlist(bundleID, watchAppBundleID string) plistutil.PlistData {
+	return plistutil.PlistData{
+		"CFBundleIdentifier": bundleID,
+		"NSExtension": map[string]interface{}{
+			"NSExtensionAttributes": map[string]interface{}{watchAppBundleIDKey: watchAppBundleID},
+		},
+	}
+}
+
+func Test_watchAppIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive Archive
+		want    []string
+	}{
+		{
+			name:    "no watch app",
+			archive: NewArchive(xcarchive.IosArchive{}),
+		},
+		{
+			name: "valid watch app",
+			archive: testWatchAppArchive(
+				plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.sample.watchkitapp", watchCompanionAppBundleIDKey: "io.bitrise.sample"},
+				watchExtensionInfoPlist("io.bitrise.sample.watchkitapp.watchkitextension", "io.bitrise.sample.watchkitapp"),
+			),
+		},
+		{
+			name: "mismatching bundle IDs",
+			archive: testWatchAppArchive(
+				plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.watchkitapp", watchCompanionAppBundleIDKey: "io.bitrise.other"},
+				watchExtensionInfoPlist("io.bitrise.watchkitapp.watchkitextension", "io.bitrise.sample.watchkitapp"),
+			),
+			want: []string{
+				"the watch app's bundle ID (io.bitrise.watchkitapp) is not prefixed by the iOS app's bundle ID (io.bitrise.sample.)",
+				"the watch app's WKCompanionAppBundleIdentifier (io.bitrise.other) does not match the iOS app's bundle ID (io.bitrise.sample)",
+				"the watch extension's (io.bitrise.watchkitapp.watchkitextension) WKAppBundleIdentifier (io.bitrise.sample.watchkitapp) does not match the watch app's bundle ID (io.bitrise.watchkitapp)",
+			},
+		},
+		{
+			name: "missing companion bundle ID",
+			archive: testWatchAppArchive(
+				plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.sample.watchkitapp"},
+				plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.sample.watchkitapp.widget"},
+			),
+			want: []string{
+				"the watch app's WKCompanionAppBundleIdentifier is missing, it should be the iOS app's bundle ID (io.bitrise.sample)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, watchAppIssues(tt.archive))
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+)
+
+const (
+	messagesExtensionPointIdentifier = "com.apple.message-payload-provider"
+	messagesApplicationStubDir       = "MessagesApplicationStub"
+	messagesApplicationSupportDir    = "MessagesApplicationSupport"
+)
+
+// ArchiveExtension is an app extension found in the archive, extended with the metadata read from its Info.plist.
+type ArchiveExtension struct {
+	xcarchive.IosExtension
+	// ExtensionPointIdentifier is the NSExtensionPointIdentifier, for example com.apple.widgetkit-extension
+	ExtensionPointIdentifier string
+	// DeploymentTarget is the MinimumOSVersion the extension was built for
+	DeploymentTarget string
+	// EmbedsFrameworks is true if the extension bundle has its own Frameworks directory
+	EmbedsFrameworks bool
+}
+
+// NewArchiveExtension ...
+func NewArchiveExtension(extension xcarchive.IosExtension) ArchiveExtension {
+	deploymentTarget, _ := extension.InfoPlist.GetString("MinimumOSVersion")
+	embedsFrameworks, _ := v1pathutil.IsDirExists(filepath.Join(extension.Path, "Frameworks"))
+
+	return ArchiveExtension{
+		IosExtension:             extension,
+		ExtensionPointIdentifier: extensionPointIdentifier(extension.InfoPlist),
+		DeploymentTarget:         deploymentTarget,
+		EmbedsFrameworks:         embedsFrameworks,
+	}
+}
+
+// IsMessagesExtension returns true for iMessage sticker packs and Messages app extensions.
+func (e ArchiveExtension) IsMessagesExtension() bool {
+	return e.ExtensionPointIdentifier == messagesExtensionPointIdentifier
+}
+
+// Archive wraps the parsed xcarchive with helpers used by the step.
+type Archive struct {
+	xcarchive.IosArchive
+}
+
+// NewArchive ...
+func NewArchive(archive xcarchive.IosArchive) Archive {
+	return Archive{IosArchive: archive}
+}
+
+// Version returns the marketing version of the main application (CFBundleShortVersionString).
+func (a Archive) Version() string {
+	version, _ := a.Application.InfoPlist.GetString("CFBundleShortVersionString")
+	return version
+}
+
+// BuildNumber returns the build number of the main application (CFBundleVersion).
+func (a Archive) BuildNumber() string {
+	build, _ := a.Application.InfoPlist.GetString("CFBundleVersion")
+	return build
+}
+
+// Platform returns the platform the main application was built for (DTPlatformName), for example iphoneos.
+func (a Archive) Platform() string {
+	platform, _ := a.Application.InfoPlist.GetString("DTPlatformName")
+	return platform
+}
+
+// MinimumOSVersion returns the deployment target of the main application.
+func (a Archive) MinimumOSVersion() string {
+	version, _ := a.Application.InfoPlist.GetString("MinimumOSVersion")
+	return version
+}
+
+// SDK returns the SDK the main application was built with (DTSDKName), for example iphoneos17.2.
+func (a Archive) SDK() string {
+	sdk, _ := a.Application.InfoPlist.GetString("DTSDKName")
+	return sdk
+}
+
+// XcodeBuild returns the build version of the Xcode used to create the archive (DTXcodeBuild), for example 15C500b.
+func (a Archive) XcodeBuild() string {
+	build, _ := a.Application.InfoPlist.GetString("DTXcodeBuild")
+	return build
+}
+
+// SchemeName returns the name of the archived scheme (SchemeName of the xcarchive's Info.plist).
+func (a Archive) SchemeName() string {
+	name, _ := a.InfoPlist.GetString("SchemeName")
+	return name
+}
+
+// CreationDate returns the time the archive was created (CreationDate of the xcarchive's Info.plist), false if it is missing.
+func (a Archive) CreationDate() (time.Time, bool) {
+	return a.InfoPlist.GetTime("CreationDate")
+}
+
+// ArchiveApplicationProperties are the ApplicationProperties of the xcarchive's Info.plist, written by Xcode for the archived app.
+type ArchiveApplicationProperties struct {
+	ApplicationPath string
+	BundleID        string
+	Version         string
+	BuildNumber     string
+	SigningIdentity string
+	Team            string
+	Architectures   []string
+}
+
+// ApplicationProperties returns the ApplicationProperties of the xcarchive's Info.plist, false if it is missing.
+func (a Archive) ApplicationProperties() (ArchiveApplicationProperties, bool) {
+	properties, ok := a.InfoPlist.GetMapStringInterface("ApplicationProperties")
+	if !ok {
+		return ArchiveApplicationProperties{}, false
+	}
+
+	applicationProperties := ArchiveApplicationProperties{}
+	applicationProperties.ApplicationPath, _ = properties.GetString("ApplicationPath")
+	applicationProperties.BundleID, _ = properties.GetString("CFBundleIdentifier")
+	applicationProperties.Version, _ = properties.GetString("CFBundleShortVersionString")
+	applicationProperties.BuildNumber, _ = properties.GetString("CFBundleVersion")
+	applicationProperties.SigningIdentity, _ = properties.GetString("SigningIdentity")
+	applicationProperties.Team, _ = properties.GetString("Team")
+	applicationProperties.Architectures, _ = properties.GetStringArray("Architectures")
+	return applicationProperties, true
+}
+
+// teamIdentifierEntitlementKey is the team of the code signature, set for the bundles without an embedded provisioning profile.
+const teamIdentifierEntitlementKey = "com.apple.developer.team-identifier"
+
+// bundleTeamID returns the team of the bundle's provisioning profile, or the team of its entitlements if it has no embedded profile.
+func bundleTeamID(app xcarchive.IosBaseApplication) string {
+	if app.ProvisioningProfile.TeamID != "" {
+		return app.ProvisioningProfile.TeamID
+	}
+	teamID, _ := app.Entitlements.GetString(teamIdentifierEntitlementKey)
+	return teamID
+}
+
+// TeamID returns the team of the main application.
+// It replaces xcarchive.IosArchive.TeamID, which returns the team of an arbitrary bundle of the archive.
+func (a Archive) TeamID() (string, error) {
+	if teamID := bundleTeamID(a.Application.IosBaseApplication); teamID != "" {
+		return teamID, nil
+	}
+	return "", fmt.Errorf("team id not found for the main application (%s)", a.Application.BundleIdentifier())
+}
+
+const (
+	bundleKindApplication      = "app"
+	bundleKindExtension        = "extension"
+	bundleKindWatchApplication = "watch app"
+	bundleKindWatchExtension   = "watch extension"
+	bundleKindAppClip          = "app clip"
+	bundleKindFramework        = "framework"
+)
+
+// ArchiveBundle is the main application, or an application or extension embedded into it.
+type ArchiveBundle struct {
+	xcarchive.IosBaseApplication
+	Kind string
+}
+
+// Bundles returns the main application and the applications and extensions embedded into it.
+func (a Archive) Bundles() []ArchiveBundle {
+	bundles := []ArchiveBundle{{IosBaseApplication: a.Application.IosBaseApplication, Kind: bundleKindApplication}}
+	for _, extension := range a.Application.Extensions {
+		bundles = append(bundles, ArchiveBundle{IosBaseApplication: extension.IosBaseApplication, Kind: bundleKindExtension})
+	}
+	if watchApplication := a.Application.WatchApplication; watchApplication != nil {
+		bundles = append(bundles, ArchiveBundle{IosBaseApplication: watchApplication.IosBaseApplication, Kind: bundleKindWatchApplication})
+		for _, extension := range watchApplication.Extensions {
+			bundles = append(bundles, ArchiveBundle{IosBaseApplication: extension.IosBaseApplication, Kind: bundleKindWatchExtension})
+		}
+	}
+	if clipApplication := a.Application.ClipApplication; clipApplication != nil {
+		bundles = append(bundles, ArchiveBundle{IosBaseApplication: clipApplication.IosBaseApplication, Kind: bundleKindAppClip})
+	}
+	return bundles
+}
+
+// TeamIDsByBundleID returns the team of the main application and of the applications and extensions embedded into it.
+func (a Archive) TeamIDsByBundleID() map[string]string {
+	teamIDs := map[string]string{}
+	for _, bundle := range a.Bundles() {
+		teamIDs[bundle.BundleIdentifier()] = bundleTeamID(bundle.IosBaseApplication)
+	}
+	return teamIDs
+}
+
+// Extensions returns the extensions of the main application and its watch application.
+func (a Archive) Extensions() []ArchiveExtension {
+	var extensions []ArchiveExtension
+	for _, extension := range a.Application.Extensions {
+		extensions = append(extensions, NewArchiveExtension(extension))
+	}
+
+	if a.Application.WatchApplication != nil {
+		for _, extension := range a.Application.WatchApplication.Extensions {
+			extensions = append(extensions, NewArchiveExtension(extension))
+		}
+	}
+
+	return extensions
+}
+
+// ExtensionsByType groups the extensions by their extension point identifier.
+func (a Archive) ExtensionsByType() map[string][]ArchiveExtension {
+	extensionsByType := map[string][]ArchiveExtension{}
+	for _, extension := range a.Extensions() {
+		extensionsByType[extension.ExtensionPointIdentifier] = append(extensionsByType[extension.ExtensionPointIdentifier], extension)
+	}
+	return extensionsByType
+}
+
+// MessagesExtensions ...
+func (a Archive) MessagesExtensions() []ArchiveExtension {
+	var extensions []ArchiveExtension
+	for _, extension := range a.Extensions() {
+		if extension.IsMessagesExtension() {
+			extensions = append(extensions, extension)
+		}
+	}
+	return extensions
+}
+
+// HasMessagesApplicationStub returns true if the main application is a stub hosting only a Messages extension
+// (for example an iMessage sticker pack without a containing app).
+func (a Archive) HasMessagesApplicationStub() (bool, error) {
+	return v1pathutil.IsPathExists(filepath.Join(a.Application.Path, messagesApplicationStubDir))
+}
+
+func extensionPointIdentifier(infoPlist plistutil.PlistData) string {
+	extension, ok := infoPlist.GetMapStringInterface("NSExtension")
+	if !ok {
+		return ""
+	}
+
+	identifier, _ := extension.GetString("NSExtensionPointIdentifier")
+	return identifier
+}
+
+// ipaContainsMessagesApplicationSupport checks if the App Store .ipa ships the Messages stub support directory,
+// which App Store Connect requires for apps built around a Messages application stub.
+func ipaContainsMessagesApplicationSupport(ipaPath string) (bool, error) {
+	reader, err := archivezip.OpenReader(ipaPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open ipa (%s): %s", ipaPath, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	for _, file := range reader.File {
+		if strings.HasPrefix(file.Name, messagesApplicationSupportDir+"/") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,219 @@
+package step
+
+import (
+	archivezip "archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-xcode/plistutil"
+	"github.com/bitrise-io/go-xcode/v2/xcarchive"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_extensionPointIdentifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		infoPlist plistutil.PlistData
+		want      string
+	}{
+		{
+			name:      "no NSExtension",
+			infoPlist: plistutil.PlistData{"CFBundleIdentifier": "io.bitrise.app"},
+			want:      "",
+		},
+		{
+			name: "Messages extension",
+			infoPlist: plistutil.PlistData{
+				"NSExtension": map[string]interface{}{
+					"NSExtensionPointIdentifier": "com.apple.message-payload-provider",
+				},
+			},
+			want: "com.apple.message-payload-provider",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extensionPointIdentifier(tt.infoPlist)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ipaContainsMessagesApplicationSupport(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{
+			name:  "stub support included",
+			files: []string{"Payload/Stickers.app/Info.plist", "MessagesApplicationSupport/MessagesApplicationSupportStub"},
+			want:  true,
+		},
+		{
+			name:  "stub support missing",
+			files: []string{"Payload/Stickers.app/Info.plist"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipaPath := createZip(t, tt.files)
+
+			got, err := ipaContainsMessagesApplicationSupport(ipaPath)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func createZip(t *testing.T, files []string) string {
+	pth := filepath.Join(t.TempDir(), "test.ipa")
+	f, err := os.Create(pth)
+	require.NoError(t, err)
+
+	w := archivezip.NewWriter(f)
+	for _, file := range files {
+		_, err := w.Create(file)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	return pth
+}
+
+func TestArchive_ExtensionsByType(t *testing.T) {
+	widgetPath := filepath.Join(t.TempDir(), "Widget.appex")
+	require.NoError(t, os.MkdirAll(filepath.Join(widgetPath, "Frameworks"), 0755))
+
+	archive := NewArchive(xcarchive.IosArchive{
+		Application: xcarchive.IosApplication{
+			Extensions: []xcarchive.IosExtension{
+				newTestExtension(widgetPath, "io.bitrise.app.widget", "com.apple.widgetkit-extension", "17.0"),
+				newTestExtension(filepath.Join(t.TempDir(), "Stickers.appex"), "io.bitrise.app.stickers", "com.apple.message-payload-provider", "16.0"),
+			},
+		},
+	})
+
+	got := archive.ExtensionsByType()
+	require.Len(t, got, 2)
+	require.Len(t, got["com.apple.widgetkit-extension"], 1)
+
+	widget := got["com.apple.widgetkit-extension"][0]
+	require.Equal(t, "io.bitrise.app.widget", widget.BundleIdentifier())
+	require.Equal(t, "17.0", widget.DeploymentTarget)
+	require.True(t, widget.EmbedsFrameworks)
+
+	stickers := got["com.apple.message-payload-provider"][0]
+	require.True(t, stickers.IsMessagesExtension())
+	require.False(t, stickers.EmbedsFrameworks)
+}
+
+func newTestExtension(pth, bundleID, extensionPointIdentifier, minimumOSVersion string) xcarchive.IosExtension {
+	return xcarchive.IosExtension{
+		IosBaseApplication: xcarchive.IosBaseApplication{
+			Path: pth,
+			InfoPlist: plistutil.PlistData{
+				"CFBundleIdentifier": bundleID,
+				"MinimumOSVersion":   minimumOSVersion,
+				"NSExtension": map[string]interface{}{
+					"NSExtensionPointIdentifier": extensionPointIdentifier,
+				},
+			},
+		},
+	}
+}
+
+func TestArchive_metadata(t *testing.T) {
+	creationDate := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	archive := NewArchive(xcarchive.IosArchive{
+		InfoPlist: plistutil.PlistData{
+			"SchemeName":   "Sample",
+			"CreationDate": creationDate,
+			"ApplicationProperties": map[string]interface{}{
+				"ApplicationPath":            "Applications/Sample.app",
+				"CFBundleIdentifier":         "io.bitrise.sample",
+				"CFBundleShortVersionString": "1.0",
+				"CFBundleVersion":            "42",
+				"SigningIdentity":            "Apple Distribution: Bitrise (ABCD1234)",
+				"Team":                       "ABCD1234",
+				"Architectures":              []interface{}{"arm64"},
+			},
+		},
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: xcarchive.IosBaseApplication{
+				InfoPlist: plistutil.PlistData{
+					"DTPlatformName":   "iphoneos",
+					"MinimumOSVersion": "15.0",
+					"DTSDKName":        "iphoneos17.2",
+					"DTXcodeBuild":     "15C500b",
+				},
+			},
+		},
+	})
+
+	require.Equal(t, "iphoneos", archive.Platform())
+	require.Equal(t, "15.0", archive.MinimumOSVersion())
+	require.Equal(t, "iphoneos17.2", archive.SDK())
+	require.Equal(t, "15C500b", archive.XcodeBuild())
+	require.Equal(t, "Sample", archive.SchemeName())
+
+	date, ok := archive.CreationDate()
+	require.True(t, ok)
+	require.Equal(t, creationDate, date)
+
+	properties, ok := archive.ApplicationProperties()
+	require.True(t, ok)
+	require.Equal(t, ArchiveApplicationProperties{
+		ApplicationPath: "Applications/Sample.app",
+		BundleID:        "io.bitrise.sample",
+		Version:         "1.0",
+		BuildNumber:     "42",
+		SigningIdentity: "Apple Distribution: Bitrise (ABCD1234)",
+		Team:            "ABCD1234",
+		Architectures:   []string{"arm64"},
+	}, properties)
+
+	_, ok = NewArchive(xcarchive.IosArchive{}).ApplicationProperties()
+	require.False(t, ok)
+}
+
+func newTestBundle(bundleID, profileTeamID, entitlementsTeamID string) xcarchive.IosBaseApplication {
+	app := xcarchive.IosBaseApplication{InfoPlist: plistutil.PlistData{"CFBundleIdentifier": bundleID}}
+	app.ProvisioningProfile.TeamID = profileTeamID
+	if entitlementsTeamID != "" {
+		app.Entitlements = plistutil.PlistData{"com.apple.developer.team-identifier": entitlementsTeamID}
+	}
+	return app
+}
+
+func TestArchive_TeamID(t *testing.T) {
+	archive := NewArchive(xcarchive.IosArchive{
+		Application: xcarchive.IosApplication{
+			IosBaseApplication: newTestBundle("io.bitrise.app", "TEAM1", ""),
+			Extensions: []xcarchive.IosExtension{
+				{IosBaseApplication: newTestBundle("io.bitrise.app.widget", "TEAM2", "")},
+				{IosBaseApplication: newTestBundle("io.bitrise.app.share", "", "TEAM1")},
+			},
+			ClipApplication: &xcarchive.IosClipApplication{IosBaseApplication: newTestBundle("io.bitrise.app.clip", "", "TEAM3")},
+		},
+	})
+
+	teamID, err := archive.TeamID()
+	require.NoError(t, err)
+	require.Equal(t, "TEAM1", teamID)
+
+	require.Equal(t, map[string]string{
+		"io.bitrise.app":        "TEAM1",
+		"io.bitrise.app.widget": "TEAM2",
+		"io.bitrise.app.share":  "TEAM1",
+		"io.bitrise.app.clip":   "TEAM3",
+	}, archive.TeamIDsByBundleID())
+
+	unsigned := NewArchive(xcarchive.IosArchive{Application: xcarchive.IosApplication{IosBaseApplication: newTestBundle("io.bitrise.app", "", "")}})
+	_, err = unsigned.TeamID()
+	require.Error(t, err)
+}
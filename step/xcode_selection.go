@@ -0,0 +1,113 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"howett.net/plist"
+)
+
+const (
+	developerDirEnvKey = "DEVELOPER_DIR"
+
+	// xcodeApplicationsDir is where the Xcode versions of the build stack are installed
+	xcodeApplicationsDir = "/Applications"
+)
+
+// installedXcode is an Xcode application found on the stack.
+type installedXcode struct {
+	Path    string
+	Version *version.Version
+}
+
+// xcodeAppVersion reads the version of an Xcode application bundle.
+func xcodeAppVersion(appPath string) (*version.Version, error) {
+	b, err := os.ReadFile(filepath.Join(appPath, "Contents", "version.plist"))
+	if err != nil {
+		return nil, err
+	}
+
+	var versionPlist struct {
+		ShortVersion string `plist:"CFBundleShortVersionString"`
+	}
+	if _, err := plist.Unmarshal(b, &versionPlist); err != nil {
+		return nil, err
+	}
+	return version.NewVersion(versionPlist.ShortVersion)
+}
+
+// listInstalledXcodes returns the Xcode applications of the directory, the newest version first.
+func listInstalledXcodes(applicationsDir string) ([]installedXcode, error) {
+	appPaths, err := filepath.Glob(filepath.Join(escapeGlobPath(applicationsDir), "Xcode*.app"))
+	if err != nil {
+		return nil, err
+	}
+
+	var xcodes []installedXcode
+	for _, appPath := range appPaths {
+		v, err := xcodeAppVersion(appPath)
+		if err != nil {
+			continue
+		}
+		xcodes = append(xcodes, installedXcode{Path: appPath, Version: v})
+	}
+	sort.SliceStable(xcodes, func(i, j int) bool {
+		return xcodes[i].Version.GreaterThan(xcodes[j].Version)
+	})
+	return xcodes, nil
+}
+
+// developerDirOfXcode returns the developer directory of an Xcode application bundle or developer directory path.
+func developerDirOfXcode(xcodePath string) (string, error) {
+	developerDir := xcodePath
+	if filepath.Ext(xcodePath) == ".app" {
+		developerDir = filepath.Join(xcodePath, "Contents", "Developer")
+	}
+
+	if _, err := os.Stat(filepath.Join(developerDir, "usr", "bin", "xcodebuild")); err != nil {
+		return "", fmt.Errorf("xcodebuild not found in %s: %w", developerDir, err)
+	}
+	return developerDir, nil
+}
+
+// resolveDeveloperDir returns the developer directory of the requested Xcode,
+// given either as a path or as a version (for example 16 or 16.2), which selects the newest matching installed Xcode.
+func resolveDeveloperDir(xcode, applicationsDir string) (string, error) {
+	if strings.Contains(xcode, string(filepath.Separator)) {
+		return developerDirOfXcode(xcode)
+	}
+
+	requested, err := version.NewVersion(xcode)
+	if err != nil {
+		return "", fmt.Errorf("should be an Xcode path or version: %w", err)
+	}
+	requestedSegments := requested.Segments()[:len(strings.Split(xcode, "."))]
+
+	xcodes, err := listInstalledXcodes(applicationsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the installed Xcode versions: %w", err)
+	}
+
+	var available []string
+	for _, installed := range xcodes {
+		available = append(available, fmt.Sprintf("%s (%s)", installed.Version.Original(), installed.Path))
+
+		segments := installed.Version.Segments()
+		matches := true
+		for i, segment := range requestedSegments {
+			if i >= len(segments) || segments[i] != segment {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return developerDirOfXcode(installed.Path)
+		}
+	}
+
+	return "", fmt.Errorf("requested Xcode %s is not installed in %s, available versions: %s", xcode, applicationsDir, strings.Join(available, ", "))
+}
@@ -0,0 +1,95 @@
+package step
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestXcode(t *testing.T, applicationsDir, name, shortVersion string) string {
+	appPath := filepath.Join(applicationsDir, name)
+	binDir := filepath.Join(appPath, "Contents", "Developer", "usr", "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "xcodebuild"), nil, 0755))
+
+	versionPlist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>%s</string>
+</dict>
+</plist>`, shortVersion)
+	require.NoError(t, os.WriteFile(filepath.Join(appPath, "Contents", "version.plist"), []byte(versionPlist), 0644))
+
+	return appPath
+}
+
+func Test_resolveDeveloperDir(t *testing.T) {
+	applicationsDir := t.TempDir()
+	xcode15 := createTestXcode(t, applicationsDir, "Xcode-15.4.app", "15.4")
+	xcode16 := createTestXcode(t, applicationsDir, "Xcode.app", "16.2")
+	xcode161 := createTestXcode(t, applicationsDir, "Xcode-16.1.app", "16.1")
+
+	tests := []struct {
+		name    string
+		xcode   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "major version selects the newest",
+			xcode: "16",
+			want:  filepath.Join(xcode16, "Contents", "Developer"),
+		},
+		{
+			name:  "minor version",
+			xcode: "16.1",
+			want:  filepath.Join(xcode161, "Contents", "Developer"),
+		},
+		{
+			name:  "full version",
+			xcode: "15.4.0",
+			want:  filepath.Join(xcode15, "Contents", "Developer"),
+		},
+		{
+			name:  "application path",
+			xcode: xcode15,
+			want:  filepath.Join(xcode15, "Contents", "Developer"),
+		},
+		{
+			name:  "developer directory path",
+			xcode: filepath.Join(xcode161, "Contents", "Developer"),
+			want:  filepath.Join(xcode161, "Contents", "Developer"),
+		},
+		{
+			name:    "version not installed",
+			xcode:   "14",
+			wantErr: true,
+		},
+		{
+			name:    "path without xcodebuild",
+			xcode:   filepath.Join(applicationsDir, "Xcode-13.app"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			xcode:   "latest",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDeveloperDir(tt.xcode, applicationsDir)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
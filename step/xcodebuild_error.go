@@ -0,0 +1,61 @@
+package step
+
+import (
+	"fmt"
+	"strings"
+)
+
+// xcodebuildErrorExcerptLines is the maximum number of the relevant log lines included in the error.
+const xcodebuildErrorExcerptLines = 10
+
+// XcodebuildError is a failed xcodebuild command, with the relevant lines of its log,
+// so the failure reason is visible without opening the full log.
+type XcodebuildError struct {
+	Action   string
+	ExitCode int
+	Excerpt  []string
+	Err      error
+}
+
+func newXcodebuildError(action string, exitCode int, log string, err error) *XcodebuildError {
+	return &XcodebuildError{
+		Action:   action,
+		ExitCode: exitCode,
+		Excerpt:  xcodebuildErrorExcerpt(log, xcodebuildErrorExcerptLines),
+		Err:      err,
+	}
+}
+
+func (e *XcodebuildError) Error() string {
+	msg := fmt.Sprintf("xcodebuild %s failed with exit code %d", e.Action, e.ExitCode)
+	if len(e.Excerpt) == 0 {
+		return msg + ", check the xcodebuild log for details"
+	}
+	return msg + ":\n" + strings.Join(e.Excerpt, "\n")
+}
+
+// Unwrap returns the error of the command, including the exit status.
+func (e *XcodebuildError) Unwrap() error {
+	return e.Err
+}
+
+// xcodebuildErrorExcerpt returns the last n distinct error lines ("error:" and NSError "Error Domain" lines) of the log.
+func xcodebuildErrorExcerpt(log string, n int) []string {
+	var lines []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.Contains(line, "error:") && !strings.Contains(line, "Error Domain") {
+			continue
+		}
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,38 @@
+package step
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_xcodebuildErrorExcerpt(t *testing.T) {
+	log := `CompileSwift normal arm64 /Sample/ContentView.swift
+/Sample/ContentView.swift:12:5: error: cannot find 'foo' in scope
+/Sample/ContentView.swift:12:5: error: cannot find 'foo' in scope
+** ARCHIVE FAILED **
+error: exportArchive: No signing certificate "iOS Distribution" found
+Error Domain=IDEProvisioningErrorDomain Code=9 "No signing certificate found" UserInfo={}`
+
+	require.Equal(t, []string{
+		`/Sample/ContentView.swift:12:5: error: cannot find 'foo' in scope`,
+		`error: exportArchive: No signing certificate "iOS Distribution" found`,
+		`Error Domain=IDEProvisioningErrorDomain Code=9 "No signing certificate found" UserInfo={}`,
+	}, xcodebuildErrorExcerpt(log, 10))
+
+	require.Equal(t, []string{
+		`Error Domain=IDEProvisioningErrorDomain Code=9 "No signing certificate found" UserInfo={}`,
+	}, xcodebuildErrorExcerpt(log, 1))
+}
+
+func TestXcodebuildError(t *testing.T) {
+	exitErr := &exec.ExitError{}
+	err := newXcodebuildError("archive", 65, "** ARCHIVE FAILED **\nerror: Signing for \"Sample\" requires a development team.", exitErr)
+	require.EqualError(t, err, "xcodebuild archive failed with exit code 65:\nerror: Signing for \"Sample\" requires a development team.")
+	require.True(t, errors.As(err, &exitErr))
+
+	err = newXcodebuildError("-exportArchive", 70, "** EXPORT FAILED **", exitErr)
+	require.EqualError(t, err, "xcodebuild -exportArchive failed with exit code 70, check the xcodebuild log for details")
+}
@@ -0,0 +1,109 @@
+package step
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+)
+
+// xcodebuildStderrMaxLines limits the printed stderr, the stdout of a failed command is printed separately.
+const xcodebuildStderrMaxLines = 100
+
+// XcodebuildStderrCapture is a command factory capturing the stderr of the xcodebuild commands,
+// separately from the combined output the xcodecommand runners return.
+type XcodebuildStderrCapture struct {
+	command.Factory
+
+	mu     sync.Mutex
+	stderr map[string]*bytes.Buffer
+}
+
+// NewXcodebuildStderrCapture wraps the command factory used by the xcodecommand runner.
+func NewXcodebuildStderrCapture(factory command.Factory) *XcodebuildStderrCapture {
+	return &XcodebuildStderrCapture{
+		Factory: factory,
+		stderr:  map[string]*bytes.Buffer{},
+	}
+}
+
+func xcodebuildStderrKey(args []string) string {
+	return strings.Join(args, "\x00")
+}
+
+// Create ...
+func (c *XcodebuildStderrCapture) Create(name string, args []string, opts *command.Opts) command.Command {
+	if name != "xcodebuild" || opts == nil || opts.Stderr == nil {
+		return c.Factory.Create(name, args, opts)
+	}
+
+	buffer := &bytes.Buffer{}
+	c.mu.Lock()
+	c.stderr[xcodebuildStderrKey(args)] = buffer
+	c.mu.Unlock()
+
+	// The runners write stdout and stderr into the same writer, which is not safe for concurrent writes.
+	// Once stderr is teed, exec.Cmd copies the two streams on separate goroutines, so the writes are serialized here.
+	var writeMu sync.Mutex
+	captureOpts := *opts
+	captureOpts.Stdout = &lockedWriter{mu: &writeMu, w: opts.Stdout}
+	captureOpts.Stderr = &lockedWriter{mu: &writeMu, w: io.MultiWriter(opts.Stderr, buffer)}
+	if opts.Stdout == nil {
+		captureOpts.Stdout = nil
+	}
+	return c.Factory.Create(name, args, &captureOpts)
+}
+
+// Stderr returns the stderr of the last xcodebuild command run with the given arguments.
+func (c *XcodebuildStderrCapture) Stderr(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	buffer, ok := c.stderr[xcodebuildStderrKey(args)]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(buffer.String())
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// lastLines returns the last n lines of the content.
+func lastLines(content string, n int) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetXcodebuildStderrCapture sets the capture of the xcodebuild stderr, it should wrap the command factory of the xcodecommand runner.
+func (s *XcodebuildArchiver) SetXcodebuildStderrCapture(capture *XcodebuildStderrCapture) {
+	s.xcodebuildStderr = capture
+}
+
+// printXcodebuildStderr prints the stderr of the failed xcodebuild command, which is often buried in the formatted output.
+func (s XcodebuildArchiver) printXcodebuildStderr(action string, args []string) {
+	if s.xcodebuildStderr == nil {
+		return
+	}
+	stderr := s.xcodebuildStderr.Stderr(args)
+	if stderr == "" {
+		return
+	}
+
+	s.logger.Println()
+	s.logger.Errorf("xcodebuild %s stderr:", action)
+	s.logger.Printf("%s", lastLines(stderr, xcodebuildStderrMaxLines))
+}
@@ -0,0 +1,46 @@
+package step
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/command"
+	"github.com/bitrise-io/go-utils/v2/env"
+	"github.com/stretchr/testify/require"
+)
+
+// xcodebuildScriptFactory runs a shell script instead of xcodebuild.
+type xcodebuildScriptFactory struct {
+	command.Factory
+	script string
+}
+
+func (f xcodebuildScriptFactory) Create(name string, args []string, opts *command.Opts) command.Command {
+	if name == "xcodebuild" {
+		return f.Factory.Create("sh", []string{"-c", f.script}, opts)
+	}
+	return f.Factory.Create(name, args, opts)
+}
+
+func TestXcodebuildStderrCapture(t *testing.T) {
+	factory := xcodebuildScriptFactory{
+		Factory: command.NewFactory(env.NewRepository()),
+		script:  "echo archiving; echo 'error: exportArchive: No signing certificate' >&2; echo done",
+	}
+	capture := NewXcodebuildStderrCapture(factory)
+
+	var output bytes.Buffer
+	args := []string{"-exportArchive", "-archivePath", "Sample.xcarchive"}
+	cmd := capture.Create("xcodebuild", args, &command.Opts{Stdout: &output, Stderr: &output})
+	require.NoError(t, cmd.Run())
+
+	require.Contains(t, output.String(), "archiving")
+	require.Contains(t, output.String(), "error: exportArchive: No signing certificate")
+	require.Equal(t, "error: exportArchive: No signing certificate", capture.Stderr(args))
+	require.Empty(t, capture.Stderr([]string{"archive"}))
+}
+
+func Test_lastLines(t *testing.T) {
+	require.Equal(t, "b\nc", lastLines("a\nb\nc", 2))
+	require.Equal(t, "a", lastLines("a", 2))
+}
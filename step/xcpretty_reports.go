@@ -0,0 +1,92 @@
+package step
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	v1pathutil "github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	xcprettyReportHTML  = "html"
+	xcprettyReportJUnit = "junit"
+)
+
+// xcprettyReport is a report generated by xcpretty from the xcodebuild archive log.
+type xcprettyReport struct {
+	Type string
+	Path string
+}
+
+func (r xcprettyReport) envKey() string {
+	if r.Type == xcprettyReportHTML {
+		return bitriseXcprettyHTMLReportPthEnvKey
+	}
+	return bitriseXcprettyJUnitReportPthEnvKey
+}
+
+// parseXcprettyReports parses the xcpretty report types, separated by comma or newline.
+func parseXcprettyReports(content string) ([]string, error) {
+	var types []string
+	for _, reportType := range strings.FieldsFunc(content, func(r rune) bool { return r == ',' || r == '\n' }) {
+		reportType = strings.TrimSpace(reportType)
+		if reportType == "" {
+			continue
+		}
+		if reportType != xcprettyReportHTML && reportType != xcprettyReportJUnit {
+			return nil, fmt.Errorf("unknown report type (%s), available types: %s, %s", reportType, xcprettyReportHTML, xcprettyReportJUnit)
+		}
+		for _, existing := range types {
+			if existing == reportType {
+				return nil, fmt.Errorf("duplicated report type (%s)", reportType)
+			}
+		}
+		types = append(types, reportType)
+	}
+	return types, nil
+}
+
+// newXcprettyReports returns the reports generated into the given directory, named after the artifact.
+func newXcprettyReports(dir, artifactName string, types []string) []xcprettyReport {
+	var reports []xcprettyReport
+	for _, reportType := range types {
+		name := artifactName + "-xcpretty.html"
+		if reportType == xcprettyReportJUnit {
+			name = artifactName + "-xcpretty-junit.xml"
+		}
+		reports = append(reports, xcprettyReport{Type: reportType, Path: filepath.Join(dir, name)})
+	}
+	return reports
+}
+
+// xcprettyReportArgs returns the xcpretty arguments generating the reports.
+func xcprettyReportArgs(reports []xcprettyReport) []string {
+	var args []string
+	for _, report := range reports {
+		args = append(args, "--report", report.Type, "--output", report.Path)
+	}
+	return args
+}
+
+// exportXcprettyReports moves the generated xcpretty reports to the output directory.
+func (s XcodebuildArchiver) exportXcprettyReports(reports []xcprettyReport, outputDir string, outputPath func(string) (string, error)) error {
+	for _, report := range reports {
+		if exist, err := v1pathutil.IsPathExists(report.Path); err != nil {
+			return err
+		} else if !exist {
+			s.logger.Warnf("The xcpretty %s report was not generated", report.Type)
+			continue
+		}
+
+		reportPath, err := outputPath(filepath.Join(outputDir, filepath.Base(report.Path)))
+		if err != nil {
+			return err
+		}
+		if err := ExportOutputFile(s.cmdFactory, report.Path, reportPath, report.envKey()); err != nil {
+			return fmt.Errorf("failed to export %s, error: %s", report.envKey(), err)
+		}
+		s.logger.Donef("The xcpretty %s report path is now available in the Environment Variable: %s (value: %s)", report.Type, report.envKey(), reportPath)
+	}
+	return nil
+}
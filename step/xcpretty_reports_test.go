@@ -0,0 +1,36 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseXcprettyReports(t *testing.T) {
+	types, err := parseXcprettyReports("html, junit\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{xcprettyReportHTML, xcprettyReportJUnit}, types)
+
+	types, err = parseXcprettyReports("")
+	require.NoError(t, err)
+	require.Empty(t, types)
+
+	_, err = parseXcprettyReports("json-compilation-database")
+	require.EqualError(t, err, "unknown report type (json-compilation-database), available types: html, junit")
+
+	_, err = parseXcprettyReports("junit,junit")
+	require.EqualError(t, err, "duplicated report type (junit)")
+}
+
+func Test_xcprettyReportArgs(t *testing.T) {
+	reports := newXcprettyReports("/tmp/xcodeArchive", "Sample", []string{xcprettyReportJUnit, xcprettyReportHTML})
+	require.Equal(t, []xcprettyReport{
+		{Type: xcprettyReportJUnit, Path: "/tmp/xcodeArchive/Sample-xcpretty-junit.xml"},
+		{Type: xcprettyReportHTML, Path: "/tmp/xcodeArchive/Sample-xcpretty.html"},
+	}, reports)
+
+	require.Equal(t, []string{
+		"--report", "junit", "--output", "/tmp/xcodeArchive/Sample-xcpretty-junit.xml",
+		"--report", "html", "--output", "/tmp/xcodeArchive/Sample-xcpretty.html",
+	}, xcprettyReportArgs(reports))
+}